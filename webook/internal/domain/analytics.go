@@ -0,0 +1,108 @@
+package domain
+
+import "time"
+
+// AnalyticsGranularity 时间序列的粒度
+type AnalyticsGranularity uint8
+
+const (
+	AnalyticsGranularityUnknown AnalyticsGranularity = iota
+	AnalyticsGranularityDay
+	AnalyticsGranularityWeek
+	AnalyticsGranularityMonth
+)
+
+// Start t 所在区间第一天的零点，周从周一开始
+func (g AnalyticsGranularity) Start(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch g {
+	case AnalyticsGranularityWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case AnalyticsGranularityMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// Add 往后挪 n 个区间，start 必须是 Start 的返回值
+func (g AnalyticsGranularity) Add(start time.Time, n int) time.Time {
+	switch g {
+	case AnalyticsGranularityWeek:
+		return start.AddDate(0, 0, 7*n)
+	case AnalyticsGranularityMonth:
+		return start.AddDate(0, n, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// AnalyticsMetric 文章排行用的指标
+type AnalyticsMetric uint8
+
+const (
+	AnalyticsMetricRead AnalyticsMetric = iota
+	AnalyticsMetricLike
+	AnalyticsMetricCollect
+	AnalyticsMetricComment
+)
+
+// Of m 里面对应的数值
+func (metric AnalyticsMetric) Of(m AnalyticsMetrics) int64 {
+	switch metric {
+	case AnalyticsMetricLike:
+		return m.LikeCnt
+	case AnalyticsMetricCollect:
+		return m.CollectCnt
+	case AnalyticsMetricComment:
+		return m.CommentCnt
+	default:
+		return m.ReadCnt
+	}
+}
+
+// AnalyticsEvent 累加到汇总数据里面的事件，用来去重
+// 同一个人对同一个资源的点赞、收藏和关注只算一次，取消之后再来一次也不算，评论和回复按照 SourceId 区分
+type AnalyticsEvent struct {
+	Type     NotificationType
+	Actor    int64
+	Biz      string
+	BizId    int64
+	SourceId int64
+}
+
+// AnalyticsMetrics 一段时间里面新增的数据
+// 点赞、收藏和关注只统计新增，取消的不会扣掉
+type AnalyticsMetrics struct {
+	// ReadCnt 去重之后的阅读数，和 ArticleDailyViews.ReadCnt 的口径一样
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	// CommentCnt 包括回复
+	CommentCnt int64
+	// FollowerCnt 新增的粉丝，只有作者维度的数据有
+	FollowerCnt int64
+}
+
+func (m AnalyticsMetrics) Add(other AnalyticsMetrics) AnalyticsMetrics {
+	return AnalyticsMetrics{
+		ReadCnt:     m.ReadCnt + other.ReadCnt,
+		LikeCnt:     m.LikeCnt + other.LikeCnt,
+		CollectCnt:  m.CollectCnt + other.CollectCnt,
+		CommentCnt:  m.CommentCnt + other.CommentCnt,
+		FollowerCnt: m.FollowerCnt + other.FollowerCnt,
+	}
+}
+
+// AnalyticsPoint 时间序列上面的一个点
+type AnalyticsPoint struct {
+	// Start 区间第一天的零点，本地时区
+	Start   time.Time
+	Metrics AnalyticsMetrics
+}
+
+// ArticleAnalytics 文章在一段时间里面的汇总，给排行用
+type ArticleAnalytics struct {
+	Article Article
+	Metrics AnalyticsMetrics
+}
@@ -1,29 +1,98 @@
 package domain
 
-import "time"
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+)
 
 type Article struct {
 	Id      int64
 	Title   string
 	Content string
-	Author  Author
+	// Abstract 去掉了标记的纯文本摘要，保存的时候根据 Content 生成
+	Abstract string
+	Author   Author
+	Status   ArticleStatus
+	// Category 分类，一篇文章只有一个
+	Category string
+	// Tags 按照作者填写的顺序
+	Tags []string
+	// Cover 封面图片的 URL，没有封面的时候为空
+	Cover string
+	// Revision 当前的版本号，每次保存或者发表都会加一
+	Revision int64
+	// PublishAt 定时发表的时间，只有定时发表的文章才有
+	PublishAt time.Time
+	// DeletedAt 移到回收站的时间，只有回收站里面的文章才有
+	DeletedAt time.Time
+	Ctime     time.Time
+	Utime     time.Time
+	// Rendered 只有读者查看文章详情的时候才会填充
+	Rendered *RenderedContent
+}
+
+// RenderedContent 把 Markdown 格式的 Content 渲染之后的结果
+type RenderedContent struct {
+	// HTML 已经过滤过了，可以直接展示
+	HTML string
+	TOC  []TOCItem
+	// Abstract 去掉了标记的纯文本摘要
+	Abstract       string
+	WordCnt        int
+	ReadingMinutes int
+	// Digest 渲染的是哪个版本的内容，和 ContentDigest 不一致的时候要重新渲染
+	Digest string
+}
+
+// TOCItem 目录里面的一项，Anchor 是标题在 HTML 里面的 id
+type TOCItem struct {
+	Level  int
+	Text   string
+	Anchor string
+}
+
+// Tag 标签，Cnt 是使用了这个标签的已发表文章数量
+type Tag struct {
+	Id   int64
+	Name string
+	Cnt  int64
+}
+
+// ArticleRevision 文章的某个历史版本
+type ArticleRevision struct {
+	ArticleId int64
+	Revision  int64
+	AuthorId  int64
+	Title     string
+	// Content 在列表里面不会填充
+	Content string
 	Status  ArticleStatus
 	Ctime   time.Time
-	Utime   time.Time
 }
 
 type Author struct {
 	Id   int64
 	Name string
+	// Followers 和 Followed 只在读者查看文章详情的时候才会填充
+	Followers int64
+	Followed  bool
 }
 
-// Abstract 取部分作为摘要
-func (a Article) Abstract() string {
-	cs := []rune(a.Content)
-	if len(cs) < 100 {
-		return a.Content
-	}
-	return string(cs[:100])
+// ContentDigest 内容的摘要，用来判断内容有没有变化
+// 缓存里面的文章不一定有 Utime，所以不能用 Utime 来判断
+func (a Article) ContentDigest() string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(a.Content))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// RecycleBinRetention 回收站里面的文章保留多久，超过之后会被彻底删除
+const RecycleBinRetention = 30 * 24 * time.Hour
+
+// PurgeAt 回收站里面的文章什么时候会被彻底删除
+func (a Article) PurgeAt() time.Time {
+	return a.DeletedAt.Add(RecycleBinRetention)
 }
 
 type ArticleStatus uint8
@@ -53,4 +122,6 @@ const (
 	ArticleStatusPublished
 	// ArticleStatusPrivate 仅自己可见
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 定时发表，到了 PublishAt 才会同步到线上库
+	ArticleStatusScheduled
 )
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// ArticleDailyViews 文章某一天的阅读数据
+type ArticleDailyViews struct {
+	// Day 当天零点，本地时区
+	Day time.Time
+	// ReadCnt 去重之后的阅读数，和 Interactive.ReadCnt 的口径一样
+	ReadCnt int64
+	// UV 独立访客，用 HyperLogLog 统计，有大约 1% 的误差
+	UV int64
+}
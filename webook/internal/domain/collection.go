@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Collection 收藏夹
+type Collection struct {
+	Id    int64
+	Uid   int64
+	Name  string
+	Ctime time.Time
+	Utime time.Time
+}
+
+// CollectionItem 收藏夹里面的一个资源
+type CollectionItem struct {
+	Cid   int64
+	Biz   string
+	BizId int64
+	Uid   int64
+	// Ctime 收藏的时间
+	Ctime time.Time
+}
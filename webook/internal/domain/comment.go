@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Comment 评论，沿用 biz + bizId 的方式标识被评论的资源
+// 评论只展示两层：根评论，以及根评论下面平铺的所有回复
+type Comment struct {
+	Id    int64
+	Uid   int64
+	Biz   string
+	BizId int64
+	// RootId 为 0 代表自己就是根评论
+	RootId int64
+	// ParentId 回复的是哪一条评论，根评论为 0
+	ParentId int64
+	Content  string
+	// ReplyCnt 只有根评论才有，按照热度排序的时候使用
+	ReplyCnt int64
+	// Replies 根评论下面预先加载的几条回复
+	Replies []Comment
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+func (c Comment) IsRoot() bool {
+	return c.RootId == 0
+}
+
+type CommentSort uint8
+
+const (
+	// CommentSortByTime 按照时间倒序
+	CommentSortByTime CommentSort = iota
+	// CommentSortByHot 按照回复数倒序
+	CommentSortByHot
+)
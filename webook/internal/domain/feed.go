@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Feed 作者的订阅源，给 RSS 和 Atom 用
+type Feed struct {
+	Author Author
+	Items  []FeedItem
+	// Updated 作者的线上内容最后一次变化的时间，撤回和删除文章也算，
+	// 所以可能比最新一篇文章的更新时间还要晚，从来没有发表过文章的时候是零值
+	Updated time.Time
+}
+
+type FeedItem struct {
+	Id    int64
+	Title string
+	// Abstract 去掉了 Markdown 标记的纯文本摘要
+	Abstract string
+	Category string
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+// SitemapEntry 站点地图里面的一篇文章
+type SitemapEntry struct {
+	ArticleId int64
+	Utime     time.Time
+}
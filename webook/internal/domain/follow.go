@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// FollowRelation 关注关系，Follower 关注了 Followee
+type FollowRelation struct {
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics 某个用户的关注数据
+type FollowStatics struct {
+	// Followers 粉丝数
+	Followers int64
+	// Followees 关注了多少人
+	Followees int64
+	// Followed 当前登录的用户有没有关注他
+	Followed bool
+}
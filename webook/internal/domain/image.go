@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+type Image struct {
+	Id int64
+	// Hash 内容的 sha256，相同内容的图片只保存一份
+	Hash        string
+	Key         string
+	URL         string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Uploader    int64
+	Ctime       time.Time
+}
+
+// ImageUsage 图片的用途，不同用途的限制不一样
+type ImageUsage uint8
+
+const (
+	// ImageUsageContent 正文中的插图
+	ImageUsageContent ImageUsage = iota
+	// ImageUsageCover 文章的封面
+	ImageUsageCover
+)
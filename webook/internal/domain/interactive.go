@@ -1,10 +1,15 @@
 package domain
 
+import "time"
+
 // Interactive 这个是总体交互的计数
 type Interactive struct {
 	ReadCnt    int64 `json:"read_cnt"`
 	LikeCnt    int64 `json:"like_cnt"`
 	CollectCnt int64 `json:"collect_cnt"`
+	CommentCnt int64 `json:"comment_cnt"`
+	// Utime 最后一次有互动的时间，热榜用它来计算衰减
+	Utime time.Time `json:"utime"`
 	// 这个是当下这个资源，有没有点赞或者收集
 	// 也可以考虑把这两个字段分离出去，作为一个单独的结构体
 	Liked     bool `json:"liked"`
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+type NotificationType uint8
+
+const (
+	NotificationTypeUnknown NotificationType = iota
+	NotificationTypeLike
+	NotificationTypeCollect
+	NotificationTypeFollow
+	NotificationTypeComment
+	NotificationTypeReply
+)
+
+// Aggregatable 点赞、收藏和关注会聚合成一条通知，评论和回复每一条都是单独的通知
+func (t NotificationType) Aggregatable() bool {
+	switch t {
+	case NotificationTypeLike, NotificationTypeCollect, NotificationTypeFollow:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification 收件箱里面的一条通知
+// 同一个资源上面同一种类型的通知会聚合在一起，例如 "X 和其他 12 人赞了你的文章"
+type Notification struct {
+	Id       int64
+	Receiver int64
+	Type     NotificationType
+	Biz      string
+	BizId    int64
+	// SourceId 评论和回复的 ID，其它类型为 0
+	SourceId int64
+	// LastActor 最近一个触发通知的人
+	LastActor int64
+	// RecentActors 最近的几个人，最新的在前面
+	RecentActors []int64
+	// ActorCnt 一共有多少个人
+	ActorCnt int64
+	// Content 评论和回复的内容
+	Content string
+	Read    bool
+	Ctime   time.Time
+	Utime   time.Time
+}
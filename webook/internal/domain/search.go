@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// SearchArticle 搜索结果里面的文章
+// Highlight 是字段名到高亮片段的映射，片段已经做过 HTML 转义，关键字用 <em> 包起来
+type SearchArticle struct {
+	Id        int64
+	AuthorId  int64
+	Author    string
+	Title     string
+	Content   string
+	Abstract  string
+	Tags      []string
+	Utime     time.Time
+	Highlight map[string][]string
+}
+
+type SearchUser struct {
+	Id        int64
+	Nickname  string
+	Bio       string
+	Highlight map[string][]string
+}
+
+type SearchArticleResult struct {
+	// Total 命中的总数，用来分页
+	Total    int64
+	Articles []SearchArticle
+}
+
+type SearchUserResult struct {
+	Total int64
+	Users []SearchUser
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Series 专栏，作者把多篇文章按照顺序组织起来，比如分成好几篇的教程
+type Series struct {
+	Id          int64
+	Title       string
+	Description string
+	Author      Author
+	Status      SeriesStatus
+	// ArticleIds 章节的文章 ID，按照章节的顺序
+	ArticleIds []int64
+	// Articles 章节的文章，只有查询详情的时候才有，不包含内容
+	// 读者看到的只有已经发表的章节
+	Articles []Article
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+func (s Series) Published() bool {
+	return s.Status == SeriesStatusPublished
+}
+
+type SeriesStatus uint8
+
+func (s SeriesStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	// SeriesStatusUnknown 未知状态
+	SeriesStatusUnknown SeriesStatus = iota
+	// SeriesStatusUnpublished 未发表，读者看不到
+	SeriesStatusUnpublished
+	// SeriesStatusPublished 已发表
+	SeriesStatusPublished
+)
+
+// SeriesNav 文章详情页里面的专栏导航，Prev 和 Next 都只会是已经发表的章节
+type SeriesNav struct {
+	Series Series
+	// Prev 和 Next 为 nil 代表已经是第一篇或者最后一篇了
+	Prev *Article
+	Next *Article
+}
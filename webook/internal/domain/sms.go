@@ -0,0 +1,11 @@
+package domain
+
+// AsyncSms 异步发送的短信请求
+type AsyncSms struct {
+	Id      int64
+	Biz     string
+	Args    []string
+	Numbers []string
+	// RetryMax 最多重试几次
+	RetryMax int
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// ArticleTransfer 导出或者导入文章的后台任务
+type ArticleTransfer struct {
+	Id   int64
+	Uid  int64
+	Kind TransferKind
+	// Status 任务的状态，只有 TransferStatusDone 的导出任务可以下载
+	Status TransferStatus
+	// Total 一共要处理的文章数量，Done 和 Failed 是已经处理了的
+	Total  int
+	Done   int
+	Failed int
+	// Msg 失败的原因，导入的时候也会记录处理失败的文件
+	Msg   string
+	Ctime time.Time
+	Utime time.Time
+}
+
+// TransferRetention 结束了的任务保留多久，超过之后任务和导出的文件都会被删除
+const TransferRetention = 7 * 24 * time.Hour
+
+type TransferKind uint8
+
+const (
+	TransferKindUnknown TransferKind = iota
+	// TransferKindExport 把所有的文章打包成 zip
+	TransferKindExport
+	// TransferKindImport 从 zip 或者 Markdown 文件创建草稿
+	TransferKindImport
+)
+
+type TransferStatus uint8
+
+const (
+	TransferStatusUnknown TransferStatus = iota
+	TransferStatusPending
+	TransferStatusRunning
+	TransferStatusDone
+	TransferStatusFailed
+)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/IBM/sarama"
+	"strconv"
 	"time"
 	"webook/internal/events"
 	"webook/internal/repository"
@@ -16,6 +17,20 @@ const topicReadEvent = "article_read_event"
 type ReadEvent struct {
 	Aid int64
 	Uid int64
+	// Fingerprint 没有登录的读者用来去重的指纹
+	Fingerprint string
+}
+
+// Reader 去重和统计独立访客用的读者标识，登录了的用 Uid，否则用指纹
+// 两个都没有的时候返回空字符串，这种阅读不去重，也不计入独立访客
+func (evt ReadEvent) Reader() string {
+	if evt.Uid > 0 {
+		return "u:" + strconv.FormatInt(evt.Uid, 10)
+	}
+	if evt.Fingerprint != "" {
+		return "f:" + evt.Fingerprint
+	}
+	return ""
 }
 
 type Producer interface {
@@ -27,17 +42,21 @@ var _ events.Consumer = &InteractiveReadEventConsumer{}
 type InteractiveReadEventConsumer struct {
 	client sarama.Client
 	repo   repository.InteractiveRepository
-	l      logger.LoggerV1
+	// statsRepo 给阅读数去重，同时统计每天的阅读数和独立访客
+	statsRepo repository.ArticleStatsRepository
+	l         logger.LoggerV1
 }
 
 func NewInteractiveReadEventConsumer(
 	client sarama.Client,
 	l logger.LoggerV1,
-	repo repository.InteractiveRepository) *InteractiveReadEventConsumer {
+	repo repository.InteractiveRepository,
+	statsRepo repository.ArticleStatsRepository) *InteractiveReadEventConsumer {
 	ic := &InteractiveReadEventConsumer{
-		repo:   repo,
-		client: client,
-		l:      l,
+		repo:      repo,
+		statsRepo: statsRepo,
+		client:    client,
+		l:         l,
 	}
 	return ic
 }
@@ -63,10 +82,30 @@ func (r *InteractiveReadEventConsumer) Start() error {
 func (r *InteractiveReadEventConsumer) Consume(msg *sarama.ConsumerMessage, evt ReadEvent) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
+	if !r.recordRead(ctx, evt, msg.Timestamp) {
+		return nil
+	}
 	err := r.repo.IncrReadCnt(ctx, "article", evt.Aid)
 	return err
 }
 
+// recordRead 返回这一次阅读要不要计数
+// 按照消息的时间算是哪一天，消息积压或者重新消费的时候也不会算到消费的那一天
+func (r *InteractiveReadEventConsumer) recordRead(ctx context.Context, evt ReadEvent, readAt time.Time) bool {
+	// 老版本的 Kafka 消息里面没有时间
+	if readAt.IsZero() {
+		readAt = time.Now()
+	}
+	counted, err := r.statsRepo.RecordRead(ctx, evt.Aid, evt.Reader(), readAt)
+	if err != nil {
+		// 统计出问题的时候宁可多算，也不要丢掉阅读数
+		r.l.Warn("记录阅读失败", logger.Int64("aid", evt.Aid),
+			logger.Int64("uid", evt.Uid), logger.Error(err))
+		return true
+	}
+	return counted
+}
+
 func (r *InteractiveReadEventConsumer) StartBatch() error {
 	cg, err := sarama.NewConsumerGroupFromClient("interactive", r.client)
 	if err != nil {
@@ -89,9 +128,15 @@ func (r *InteractiveReadEventConsumer) BatchConsume(msgs []*sarama.ConsumerMessa
 	defer cancel()
 	bizs := make([]string, 0, len(msgs))
 	ids := make([]int64, 0, len(msgs))
-	for _, evt := range evts {
+	for i, evt := range evts {
+		if !r.recordRead(ctx, evt, msgs[i].Timestamp) {
+			continue
+		}
 		bizs = append(bizs, "article")
-		ids = append(ids, evt.Uid)
+		ids = append(ids, evt.Aid)
+	}
+	if len(ids) == 0 {
+		return nil
 	}
 	return r.repo.BatchIncrReadCnt(ctx, bizs, ids)
 }
@@ -114,7 +159,7 @@ func (s *SaramaSyncProducer) ProduceReadEvent(evt ReadEvent) error {
 	_, _, err = s.producer.
 		SendMessage(&sarama.ProducerMessage{
 			Topic: topicReadEvent,
-			Key:   sarama.ByteEncoder(val),
+			Value: sarama.ByteEncoder(val),
 		})
 	return err
 }
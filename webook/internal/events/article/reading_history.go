@@ -5,17 +5,30 @@ import (
 	"github.com/IBM/sarama"
 	"time"
 	"webook/internal/domain"
+	"webook/internal/events"
 	"webook/internal/repository"
 	"webook/pkg/logger"
 	"webook/pkg/saramax"
 )
 
+var _ events.Consumer = &HistoryConsumer{}
+
 type HistoryConsumer struct {
 	client sarama.Client
 	repo   repository.HistoryRecordRepository
 	l      logger.LoggerV1
 }
 
+func NewHistoryConsumer(client sarama.Client,
+	l logger.LoggerV1,
+	repo repository.HistoryRecordRepository) *HistoryConsumer {
+	return &HistoryConsumer{
+		client: client,
+		repo:   repo,
+		l:      l,
+	}
+}
+
 func (hc *HistoryConsumer) Start() error {
 	cg, err := sarama.NewConsumerGroupFromClient("history",
 		hc.client)
@@ -36,6 +49,10 @@ func (hc *HistoryConsumer) Start() error {
 func (hc *HistoryConsumer) Consume(
 	msg *sarama.ConsumerMessage,
 	evt ReadEvent) error {
+	// 没有登录的用户没有阅读历史
+	if evt.Uid <= 0 {
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 	return hc.repo.AddRecord(ctx, domain.HistoryRecord{
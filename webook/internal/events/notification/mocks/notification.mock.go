@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/events/notification/notification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/events/notification/notification.go -package=evtmocks -destination=webook/internal/events/notification/mocks/notification.mock.go
+//
+
+// Package evtmocks is a generated GoMock package.
+package evtmocks
+
+import (
+	reflect "reflect"
+	notification "webook/internal/events/notification"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProducer is a mock of Producer interface.
+type MockProducer struct {
+	ctrl     *gomock.Controller
+	recorder *MockProducerMockRecorder
+}
+
+// MockProducerMockRecorder is the mock recorder for MockProducer.
+type MockProducerMockRecorder struct {
+	mock *MockProducer
+}
+
+// NewMockProducer creates a new mock instance.
+func NewMockProducer(ctrl *gomock.Controller) *MockProducer {
+	mock := &MockProducer{ctrl: ctrl}
+	mock.recorder = &MockProducerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
+	return m.recorder
+}
+
+// ProduceNotificationEvent mocks base method.
+func (m *MockProducer) ProduceNotificationEvent(evt notification.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceNotificationEvent", evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceNotificationEvent indicates an expected call of ProduceNotificationEvent.
+func (mr *MockProducerMockRecorder) ProduceNotificationEvent(evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceNotificationEvent", reflect.TypeOf((*MockProducer)(nil).ProduceNotificationEvent), evt)
+}
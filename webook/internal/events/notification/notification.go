@@ -0,0 +1,208 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/events"
+	"webook/internal/repository"
+	"webook/internal/repository/article"
+	"webook/pkg/logger"
+	"webook/pkg/saramax"
+)
+
+const topicNotificationEvent = "notification_event"
+
+// Event 点赞、收藏、关注和评论之类的操作产生的事件
+type Event struct {
+	Type  domain.NotificationType
+	Actor int64
+	Biz   string
+	BizId int64
+	// Receiver 为 0 的时候由消费者根据 Biz 和 BizId 查询资源的作者
+	Receiver int64
+	// SourceId 评论和回复的 ID
+	SourceId int64
+	Content  string
+}
+
+type Producer interface {
+	ProduceNotificationEvent(evt Event) error
+}
+
+type SaramaSyncProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewSaramaSyncProducer(producer sarama.SyncProducer) Producer {
+	return &SaramaSyncProducer{
+		producer: producer,
+	}
+}
+
+func (s *SaramaSyncProducer) ProduceNotificationEvent(evt Event) error {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: topicNotificationEvent,
+		Value: sarama.ByteEncoder(val),
+	})
+	return err
+}
+
+var _ events.Consumer = &Consumer{}
+
+type Consumer struct {
+	client  sarama.Client
+	repo    repository.NotificationRepository
+	artRepo article.ArticleRepository
+	l       logger.LoggerV1
+}
+
+func NewConsumer(client sarama.Client,
+	l logger.LoggerV1,
+	repo repository.NotificationRepository,
+	artRepo article.ArticleRepository) *Consumer {
+	return &Consumer{
+		client:  client,
+		repo:    repo,
+		artRepo: artRepo,
+		l:       l,
+	}
+}
+
+func (c *Consumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification", c.client)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := cg.Consume(context.Background(),
+			[]string{topicNotificationEvent},
+			saramax.NewHandler[Event](c.l, c.Consume))
+		if err != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(err))
+		}
+	}()
+	return err
+}
+
+func (c *Consumer) Consume(msg *sarama.ConsumerMessage, evt Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	receiver := evt.Receiver
+	if receiver == 0 {
+		// 目前只有文章会被点赞和收藏
+		if evt.Biz != "article" {
+			return nil
+		}
+		// 事件发出之后文章可能已经撤回或者删除了，作者要从制作库查
+		art, err := c.artRepo.GetById(ctx, evt.BizId)
+		if err != nil {
+			return err
+		}
+		receiver = art.Author.Id
+	}
+	// 自己给自己点赞之类的不需要通知
+	if receiver <= 0 || receiver == evt.Actor {
+		return nil
+	}
+	return c.repo.Add(ctx, domain.Notification{
+		Receiver:  receiver,
+		Type:      evt.Type,
+		Biz:       evt.Biz,
+		BizId:     evt.BizId,
+		SourceId:  evt.SourceId,
+		LastActor: evt.Actor,
+		Content:   evt.Content,
+	})
+}
+
+var _ events.Consumer = &AnalyticsConsumer{}
+
+// AnalyticsConsumer 把点赞、收藏、评论和关注累加到作者后台的汇总数据里面
+type AnalyticsConsumer struct {
+	client  sarama.Client
+	repo    repository.AnalyticsRepository
+	artRepo article.ArticleRepository
+	l       logger.LoggerV1
+}
+
+func NewAnalyticsConsumer(client sarama.Client,
+	l logger.LoggerV1,
+	repo repository.AnalyticsRepository,
+	artRepo article.ArticleRepository) *AnalyticsConsumer {
+	return &AnalyticsConsumer{
+		client:  client,
+		repo:    repo,
+		artRepo: artRepo,
+		l:       l,
+	}
+}
+
+func (c *AnalyticsConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("analytics", c.client)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := cg.Consume(context.Background(),
+			[]string{topicNotificationEvent},
+			saramax.NewHandler[Event](c.l, c.Consume))
+		if err != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(err))
+		}
+	}()
+	return err
+}
+
+func (c *AnalyticsConsumer) Consume(msg *sarama.ConsumerMessage, evt Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 按照事件发生的时间算在哪一天，积压了也不会算到后面去
+	t := msg.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	key := domain.AnalyticsEvent{
+		Type:     evt.Type,
+		Actor:    evt.Actor,
+		Biz:      evt.Biz,
+		BizId:    evt.BizId,
+		SourceId: evt.SourceId,
+	}
+	var delta domain.AnalyticsMetrics
+	switch evt.Type {
+	case domain.NotificationTypeFollow:
+		if evt.Receiver <= 0 {
+			return nil
+		}
+		return c.repo.IncrAuthor(ctx, key, evt.Receiver, t, domain.AnalyticsMetrics{FollowerCnt: 1})
+	case domain.NotificationTypeLike:
+		delta.LikeCnt = 1
+	case domain.NotificationTypeCollect:
+		delta.CollectCnt = 1
+	case domain.NotificationTypeComment, domain.NotificationTypeReply:
+		// 回复的 Receiver 是被回复的人，所以作者要自己查
+		delta.CommentCnt = 1
+	default:
+		return nil
+	}
+	if evt.Biz != "article" {
+		return nil
+	}
+	// 和 Consumer 一样从制作库查作者，撤回或者删除了的文章之前的数据也要算上
+	art, err := c.artRepo.GetById(ctx, evt.BizId)
+	if err != nil {
+		return err
+	}
+	// 作者自己的操作不算
+	if art.Author.Id <= 0 || art.Author.Id == evt.Actor {
+		return nil
+	}
+	return c.repo.IncrArticle(ctx, key, evt.BizId, art.Author.Id, t, delta)
+}
@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/events/search/search.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/events/search/search.go -package=evtmocks -destination=webook/internal/events/search/mocks/search.mock.go
+//
+
+// Package evtmocks is a generated GoMock package.
+package evtmocks
+
+import (
+	reflect "reflect"
+	search "webook/internal/events/search"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProducer is a mock of Producer interface.
+type MockProducer struct {
+	ctrl     *gomock.Controller
+	recorder *MockProducerMockRecorder
+}
+
+// MockProducerMockRecorder is the mock recorder for MockProducer.
+type MockProducerMockRecorder struct {
+	mock *MockProducer
+}
+
+// NewMockProducer creates a new mock instance.
+func NewMockProducer(ctrl *gomock.Controller) *MockProducer {
+	mock := &MockProducer{ctrl: ctrl}
+	mock.recorder = &MockProducerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
+	return m.recorder
+}
+
+// ProduceArticleEvent mocks base method.
+func (m *MockProducer) ProduceArticleEvent(evt search.ArticleEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceArticleEvent", evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceArticleEvent indicates an expected call of ProduceArticleEvent.
+func (mr *MockProducerMockRecorder) ProduceArticleEvent(evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceArticleEvent", reflect.TypeOf((*MockProducer)(nil).ProduceArticleEvent), evt)
+}
+
+// ProduceUserEvent mocks base method.
+func (m *MockProducer) ProduceUserEvent(evt search.UserEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceUserEvent", evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ProduceUserEvent indicates an expected call of ProduceUserEvent.
+func (mr *MockProducerMockRecorder) ProduceUserEvent(evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceUserEvent", reflect.TypeOf((*MockProducer)(nil).ProduceUserEvent), evt)
+}
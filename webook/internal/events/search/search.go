@@ -0,0 +1,201 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/IBM/sarama"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/events"
+	"webook/internal/repository"
+	"webook/internal/repository/article"
+	"webook/pkg/logger"
+	"webook/pkg/saramax"
+)
+
+const (
+	topicSyncArticle = "sync_search_article"
+	topicSyncUser    = "sync_search_user"
+)
+
+// ArticleEvent 文章发表、更新或者撤回之后发送，不是发表状态的文章会从索引里面删掉
+type ArticleEvent struct {
+	Id       int64
+	AuthorId int64
+	Title    string
+	Content  string
+	Abstract string
+	// Tags 为 nil 的时候由消费者查询线上库
+	Tags   []string
+	Status uint8
+	Utime  int64
+}
+
+// UserEvent 用户修改了资料之后发送，消费者会重新查询用户
+type UserEvent struct {
+	Id int64
+}
+
+type Producer interface {
+	ProduceArticleEvent(evt ArticleEvent) error
+	ProduceUserEvent(evt UserEvent) error
+}
+
+type SaramaSyncProducer struct {
+	producer sarama.SyncProducer
+}
+
+func NewSaramaSyncProducer(producer sarama.SyncProducer) Producer {
+	return &SaramaSyncProducer{
+		producer: producer,
+	}
+}
+
+func (s *SaramaSyncProducer) ProduceArticleEvent(evt ArticleEvent) error {
+	return s.produce(topicSyncArticle, evt)
+}
+
+func (s *SaramaSyncProducer) ProduceUserEvent(evt UserEvent) error {
+	return s.produce(topicSyncUser, evt)
+}
+
+func (s *SaramaSyncProducer) produce(topic string, evt any) error {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(val),
+	})
+	return err
+}
+
+var _ events.Consumer = &ArticleConsumer{}
+
+type ArticleConsumer struct {
+	client   sarama.Client
+	repo     repository.SearchRepository
+	userRepo repository.UserRepository
+	artRepo  article.ArticleRepository
+	l        logger.LoggerV1
+}
+
+func NewArticleConsumer(client sarama.Client,
+	l logger.LoggerV1,
+	repo repository.SearchRepository,
+	userRepo repository.UserRepository,
+	artRepo article.ArticleRepository) *ArticleConsumer {
+	return &ArticleConsumer{
+		client:   client,
+		repo:     repo,
+		userRepo: userRepo,
+		artRepo:  artRepo,
+		l:        l,
+	}
+}
+
+func (c *ArticleConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("search_article", c.client)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := cg.Consume(context.Background(),
+			[]string{topicSyncArticle},
+			saramax.NewHandler[ArticleEvent](c.l, c.Consume))
+		if err != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(err))
+		}
+	}()
+	return err
+}
+
+func (c *ArticleConsumer) Consume(msg *sarama.ConsumerMessage, evt ArticleEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if domain.ArticleStatus(evt.Status).NonPublished() {
+		return c.repo.DeleteArticle(ctx, evt.Id)
+	}
+	tags := evt.Tags
+	if tags == nil {
+		art, err := c.artRepo.GetPublishedById(ctx, evt.Id)
+		if err != nil {
+			return err
+		}
+		tags = art.Tags
+	}
+	// 查不到作者也要索引，只是不能按照作者昵称搜索
+	var author string
+	u, err := c.userRepo.FindById(ctx, evt.AuthorId)
+	if err != nil {
+		c.l.Error("查询文章作者失败",
+			logger.Int64("aid", evt.Id),
+			logger.Int64("uid", evt.AuthorId),
+			logger.Error(err))
+	} else {
+		author = u.NickName
+	}
+	return c.repo.InputArticle(ctx, domain.SearchArticle{
+		Id:       evt.Id,
+		AuthorId: evt.AuthorId,
+		Author:   author,
+		Title:    evt.Title,
+		Content:  evt.Content,
+		Abstract: evt.Abstract,
+		Tags:     tags,
+		Utime:    time.UnixMilli(evt.Utime),
+	})
+}
+
+var _ events.Consumer = &UserConsumer{}
+
+type UserConsumer struct {
+	client   sarama.Client
+	repo     repository.SearchRepository
+	userRepo repository.UserRepository
+	l        logger.LoggerV1
+}
+
+func NewUserConsumer(client sarama.Client,
+	l logger.LoggerV1,
+	repo repository.SearchRepository,
+	userRepo repository.UserRepository) *UserConsumer {
+	return &UserConsumer{
+		client:   client,
+		repo:     repo,
+		userRepo: userRepo,
+		l:        l,
+	}
+}
+
+func (c *UserConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("search_user", c.client)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := cg.Consume(context.Background(),
+			[]string{topicSyncUser},
+			saramax.NewHandler[UserEvent](c.l, c.Consume))
+		if err != nil {
+			c.l.Error("退出了消费循环异常", logger.Error(err))
+		}
+	}()
+	return err
+}
+
+func (c *UserConsumer) Consume(msg *sarama.ConsumerMessage, evt UserEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 事件里面只有 ID，这样消息乱序了也不会把旧的资料写进索引
+	u, err := c.userRepo.FindById(ctx, evt.Id)
+	if err != nil {
+		return err
+	}
+	return c.repo.InputUser(ctx, domain.SearchUser{
+		Id:       u.Id,
+		Nickname: u.NickName,
+		Bio:      u.Bio,
+	})
+}
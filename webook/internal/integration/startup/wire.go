@@ -35,6 +35,33 @@ var interactiveSvcProvider = wire.NewSet(
 	cache.NewRedisInteractiveCache,
 )
 
+var followSvcProvider = wire.NewSet(
+	service.NewFollowService,
+	repository.NewCachedFollowRepository,
+	dao.NewGORMFollowDAO,
+	cache.NewRedisFollowCache,
+)
+
+var notificationSvcProvider = wire.NewSet(
+	service.NewNotificationService,
+	repository.NewCachedNotificationRepository,
+	dao.NewGORMNotificationDAO,
+	cache.NewRedisNotificationCache,
+)
+
+var seriesSvcProvider = wire.NewSet(
+	service.NewSeriesService,
+	article.NewSeriesRepository,
+	article2.NewGORMSeriesDAO,
+)
+
+var rankingSvcProvider = wire.NewSet(
+	service.NewBatchRankingService,
+	repository.NewCachedRankingRepository,
+	cache.NewRankingRedisCache,
+	cache.NewRankingLocalCache,
+)
+
 func InitWebServer() *gin.Engine {
 	wire.Build(
 		// 基础部分
@@ -42,19 +69,26 @@ func InitWebServer() *gin.Engine {
 		userSvcProvider,
 		articleSvcProvider,
 		interactiveSvcProvider,
+		rankingSvcProvider,
 
 		// 验证码缓存在redis中
 		cache.NewRedisCodeCache,
 		repository.NewCodeRepository,
 
 		// service 部分
-		ioc.InitSmsService, service.NewSMSCodeService,
+		dao.NewGORMAsyncSmsDAO, repository.NewAsyncSMSRepository,
+		dao.NewGORMHistoryDAO, repository.NewHistoryRecordRepository, service.NewHistoryService,
+		followSvcProvider,
+		dao.NewGORMCommentDAO, repository.NewCachedCommentRepository, service.NewCommentService,
+		notificationSvcProvider,
+		seriesSvcProvider,
+		ioc.InitAsyncSmsService, ioc.InitSmsService, service.NewSMSCodeService,
 
 		// 指定啥也不干的 wechat service
 		InitPhantomWechatService,
 
 		// handler 部分
-		web.NewUserHandler, web.NewArticleHandler, web.NewOAuth2WechatHandler, ioc.NewWechatHandlerConfig, ijwt.NewRedisJWTHandler,
+		web.NewUserHandler, web.NewArticleHandler, web.NewRankingHandler, web.NewCollectionHandler, web.NewHistoryHandler, web.NewFollowHandler, web.NewCommentHandler, web.NewNotificationHandler, web.NewSeriesHandler, web.NewOAuth2WechatHandler, ioc.NewWechatHandlerConfig, ijwt.NewRedisJWTHandler,
 
 		// gin 的中间件
 		ioc.InitMiddlewares,
@@ -70,7 +104,12 @@ func InitArticleHandler(dao article2.ArticleDAO) *web.ArticleHandler {
 		//article2.NewGORMArticleDAO,
 		userSvcProvider,
 		interactiveSvcProvider,
+		followSvcProvider,
+		seriesSvcProvider,
 		cache.NewRedisArticleCache,
+		repository.NewCachedRankingRepository,
+		cache.NewRankingRedisCache,
+		cache.NewRankingLocalCache,
 		service.NewArticleService,
 		web.NewArticleHandler,
 		article.NewArticleRepository,
@@ -80,7 +119,7 @@ func InitArticleHandler(dao article2.ArticleDAO) *web.ArticleHandler {
 
 func InitUserSvc() service.UserService {
 	wire.Build(thirdProvider, userSvcProvider)
-	return service.NewUserService(nil, nil)
+	return service.NewUserService(nil, nil, nil)
 }
 
 func InitJwtHdl() ijwt.Handler {
@@ -90,5 +129,5 @@ func InitJwtHdl() ijwt.Handler {
 
 func InitInteractiveService() service.InteractiveService {
 	wire.Build(thirdProvider, interactiveSvcProvider)
-	return service.NewInteractiveService(nil, nil)
+	return service.NewInteractiveService(nil, nil, nil)
 }
@@ -0,0 +1,44 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/service"
+)
+
+var _ Job = (*ArticleStatsFlushJob)(nil)
+
+// articleStatsFlushDays 除了今天之外还要刷的天数，和缓存 72 小时的过期时间对应
+// 阅读按照消息的时间算到哪一天，消费者积压的时候前几天的数据也会继续增加
+const articleStatsFlushDays = 3
+
+// ArticleStatsFlushJob 把 Redis 里面每天的阅读数和独立访客刷到数据库
+type ArticleStatsFlushJob struct {
+	svc     service.ArticleStatsService
+	timeout time.Duration
+}
+
+func NewArticleStatsFlushJob(svc service.ArticleStatsService, timeout time.Duration) *ArticleStatsFlushJob {
+	return &ArticleStatsFlushJob{
+		svc:     svc,
+		timeout: timeout,
+	}
+}
+
+func (a *ArticleStatsFlushJob) Name() string {
+	return "article_stats_flush"
+}
+
+func (a *ArticleStatsFlushJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	// 缓存里面还没有过期的每一天都要刷，从最早的开始
+	for i := articleStatsFlushDays; i >= 0; i-- {
+		if _, err := a.svc.Flush(ctx, today.AddDate(0, 0, -i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
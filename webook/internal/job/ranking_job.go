@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/service"
+)
+
+var _ Job = (*RankingJob)(nil)
+
+// RankingJob 定时计算热榜
+type RankingJob struct {
+	svc     service.RankingService
+	timeout time.Duration
+}
+
+func NewRankingJob(svc service.RankingService, timeout time.Duration) *RankingJob {
+	return &RankingJob{
+		svc:     svc,
+		timeout: timeout,
+	}
+}
+
+func (r *RankingJob) Name() string {
+	return "ranking"
+}
+
+func (r *RankingJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	return r.svc.TopN(ctx)
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/service"
+)
+
+var _ Job = (*RecycleBinPurgeJob)(nil)
+
+// RecycleBinPurgeJob 彻底删除在回收站里面超过保留时间的文章
+type RecycleBinPurgeJob struct {
+	svc     service.ArticleService
+	timeout time.Duration
+	// batchSize 一次查询多少篇过期的文章
+	batchSize int
+}
+
+func NewRecycleBinPurgeJob(svc service.ArticleService, timeout time.Duration) *RecycleBinPurgeJob {
+	return &RecycleBinPurgeJob{
+		svc:       svc,
+		timeout:   timeout,
+		batchSize: 100,
+	}
+}
+
+func (r *RecycleBinPurgeJob) Name() string {
+	return "recycle_bin_purge"
+}
+
+func (r *RecycleBinPurgeJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	now := time.Now()
+	for {
+		cnt, err := r.svc.PurgeExpired(ctx, now, r.batchSize)
+		// 有失败的话留到下一次再试，避免一直处理同一批文章
+		if err != nil {
+			return err
+		}
+		if cnt < r.batchSize {
+			return nil
+		}
+	}
+}
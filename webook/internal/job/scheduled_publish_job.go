@@ -0,0 +1,38 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/service"
+)
+
+var _ Job = (*ScheduledPublishJob)(nil)
+
+// ScheduledPublishJob 把到期的定时发表文章同步到线上库
+// 依赖 Scheduler 的分布式锁保证只有一个实例在发表
+type ScheduledPublishJob struct {
+	svc     service.ArticleService
+	timeout time.Duration
+	// batchSize 一次查询多少篇到期的文章
+	batchSize int
+}
+
+func NewScheduledPublishJob(svc service.ArticleService, timeout time.Duration) *ScheduledPublishJob {
+	return &ScheduledPublishJob{
+		svc:       svc,
+		timeout:   timeout,
+		batchSize: 100,
+	}
+}
+
+func (s *ScheduledPublishJob) Name() string {
+	return "scheduled_publish"
+}
+
+func (s *ScheduledPublishJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	// 失败的文章留到下一次再试
+	_, err := s.svc.PublishDue(ctx, time.Now(), s.batchSize)
+	return err
+}
@@ -0,0 +1,146 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"webook/pkg/logger"
+	"webook/pkg/redislock"
+)
+
+// Scheduler 按照固定的间隔执行注册了的任务
+// 多个实例部署的时候，每个任务只有抢到了分布式锁的实例才会执行
+type Scheduler struct {
+	entries []entry
+	l       logger.LoggerV1
+	lock    *redislock.Client
+	// lockExpiration 锁的过期时间，持有锁的实例会一直续约
+	lockExpiration time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+func NewScheduler(l logger.LoggerV1, lock *redislock.Client) *Scheduler {
+	return &Scheduler{
+		l:              l,
+		lock:           lock,
+		lockExpiration: time.Minute,
+	}
+}
+
+// Register 要在 Start 之前调用
+func (s *Scheduler) Register(j Job, interval time.Duration) *Scheduler {
+	s.entries = append(s.entries, entry{job: j, interval: interval})
+	return s
+}
+
+// Start 每一个任务都在自己的 goroutine 里面执行，抢到锁之后会立刻执行一次
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+}
+
+// Stop 会等待正在执行的任务结束，并且释放锁
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	var (
+		lock *redislock.Lock
+		// lost 续约失败的时候会收到信号
+		lost chan struct{}
+	)
+	defer func() {
+		if lock != nil {
+			s.unlock(lock, e.job.Name())
+		}
+	}()
+	for {
+		select {
+		case <-lost:
+			s.l.Warn("任务的分布式锁续约失败，等待重新抢锁", logger.String("job", e.job.Name()))
+			lock, lost = nil, nil
+		default:
+		}
+		if lock == nil {
+			lock, lost = s.tryLock(ctx, e.job.Name())
+		}
+		if lock != nil {
+			s.run(e.job)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tryLock 抢锁失败返回 nil，说明别的实例正在负责这个任务
+func (s *Scheduler) tryLock(ctx context.Context, name string) (*redislock.Lock, chan struct{}) {
+	lctx, cancel := context.WithTimeout(ctx, time.Second)
+	lock, err := s.lock.TryLock(lctx, s.lockKey(name), s.lockExpiration)
+	cancel()
+	if err != nil {
+		if !errors.Is(err, redislock.ErrFailedToPreemptLock) {
+			s.l.Error("任务抢锁失败", logger.String("job", name), logger.Error(err))
+		}
+		return nil, nil
+	}
+	lost := make(chan struct{})
+	go func() {
+		// 续约间隔要明显小于过期时间
+		er := lock.AutoRefresh(s.lockExpiration/3, time.Second)
+		if er != nil {
+			s.l.Error("任务续约失败", logger.String("job", name), logger.Error(er))
+			close(lost)
+		}
+	}()
+	return lock, lost
+}
+
+func (s *Scheduler) unlock(lock *redislock.Lock, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := lock.Unlock(ctx); err != nil {
+		s.l.Error("任务释放锁失败", logger.String("job", name), logger.Error(err))
+	}
+}
+
+func (s *Scheduler) lockKey(name string) string {
+	return "job:lock:" + name
+}
+
+func (s *Scheduler) run(j Job) {
+	start := time.Now()
+	err := j.Run()
+	if err != nil {
+		s.l.Error("执行任务失败",
+			logger.String("job", j.Name()),
+			logger.Error(err))
+		return
+	}
+	s.l.Debug("执行任务成功",
+		logger.String("job", j.Name()),
+		logger.Int64("duration", time.Since(start).Milliseconds()))
+}
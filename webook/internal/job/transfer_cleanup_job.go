@@ -0,0 +1,49 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/service"
+)
+
+var _ Job = (*TransferCleanupJob)(nil)
+
+// TransferCleanupJob 把中断了的导出导入任务标记为失败，删除过期的任务和导出的文件
+// 启动的时候抢到锁会立刻执行一次，重启之前没有执行完的任务也会被标记为失败
+type TransferCleanupJob struct {
+	svc     service.ArticleTransferService
+	timeout time.Duration
+	// batchSize 一次查询多少个过期的任务
+	batchSize int
+}
+
+func NewTransferCleanupJob(svc service.ArticleTransferService, timeout time.Duration) *TransferCleanupJob {
+	return &TransferCleanupJob{
+		svc:       svc,
+		timeout:   timeout,
+		batchSize: 100,
+	}
+}
+
+func (t *TransferCleanupJob) Name() string {
+	return "transfer_cleanup"
+}
+
+func (t *TransferCleanupJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	now := time.Now()
+	if _, err := t.svc.FailStale(ctx, now); err != nil {
+		return err
+	}
+	for {
+		cnt, err := t.svc.PurgeExpired(ctx, now, t.batchSize)
+		// 有失败的话留到下一次再试，避免一直处理同一批任务
+		if err != nil {
+			return err
+		}
+		if cnt < t.batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+)
+
+// AnalyticsRepository 作者后台的汇总数据
+// 点赞、收藏、评论和关注是消费者实时累加的，阅读数来自 ArticleDailyStats，
+// 要等定时任务把缓存刷到数据库，所以今天的阅读数会晚几分钟
+type AnalyticsRepository interface {
+	// IncrArticle 累加文章在 t 那一天的数据，同时累加到作者身上，evt 已经累加过的时候什么都不做
+	IncrArticle(ctx context.Context, evt domain.AnalyticsEvent, aid, author int64, t time.Time, delta domain.AnalyticsMetrics) error
+	// IncrAuthor 累加作者在 t 那一天的数据，evt 已经累加过的时候什么都不做
+	IncrAuthor(ctx context.Context, evt domain.AnalyticsEvent, uid int64, t time.Time, delta domain.AnalyticsMetrics) error
+	// ArticleDaily [from, to] 之间有数据的日子，按照日期升序
+	ArticleDaily(ctx context.Context, aid int64, from, to time.Time) ([]domain.AnalyticsPoint, error)
+	// AuthorDaily [from, to] 之间有数据的日子，按照日期升序
+	AuthorDaily(ctx context.Context, uid int64, from, to time.Time) ([]domain.AnalyticsPoint, error)
+	// ArticleTotals 作者每一篇有数据的文章在 [from, to] 之间的总和，Article 里面只有 Id
+	ArticleTotals(ctx context.Context, uid int64, from, to time.Time) ([]domain.ArticleAnalytics, error)
+}
+
+type analyticsRepository struct {
+	dao      dao.AnalyticsDAO
+	statsDAO dao.ArticleStatsDAO
+}
+
+func NewAnalyticsRepository(dao dao.AnalyticsDAO, statsDAO dao.ArticleStatsDAO) AnalyticsRepository {
+	return &analyticsRepository{
+		dao:      dao,
+		statsDAO: statsDAO,
+	}
+}
+
+func (r *analyticsRepository) IncrArticle(ctx context.Context, evt domain.AnalyticsEvent, aid, author int64,
+	t time.Time, delta domain.AnalyticsMetrics) error {
+	return r.dao.IncrArticle(ctx, r.toEventEntity(evt), dao.ArticleDailyAnalytics{
+		Aid:        aid,
+		AuthorId:   author,
+		Day:        t.In(time.Local).Format(statsDayLayout),
+		LikeCnt:    delta.LikeCnt,
+		CollectCnt: delta.CollectCnt,
+		CommentCnt: delta.CommentCnt,
+	})
+}
+
+func (r *analyticsRepository) IncrAuthor(ctx context.Context, evt domain.AnalyticsEvent, uid int64,
+	t time.Time, delta domain.AnalyticsMetrics) error {
+	return r.dao.IncrAuthor(ctx, r.toEventEntity(evt), dao.AuthorDailyAnalytics{
+		Uid:         uid,
+		Day:         t.In(time.Local).Format(statsDayLayout),
+		LikeCnt:     delta.LikeCnt,
+		CollectCnt:  delta.CollectCnt,
+		CommentCnt:  delta.CommentCnt,
+		FollowerCnt: delta.FollowerCnt,
+	})
+}
+
+func (r *analyticsRepository) ArticleDaily(ctx context.Context, aid int64,
+	from, to time.Time) ([]domain.AnalyticsPoint, error) {
+	f, t := from.Format(statsDayLayout), to.Format(statsDayLayout)
+	stats, err := r.statsDAO.FindDaily(ctx, aid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.dao.FindArticleDaily(ctx, aid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]domain.AnalyticsMetrics, len(stats)+len(data))
+	for _, s := range stats {
+		m := byDay[s.Day]
+		m.ReadCnt += s.ReadCnt
+		byDay[s.Day] = m
+	}
+	for _, d := range data {
+		m := byDay[d.Day]
+		m.LikeCnt += d.LikeCnt
+		m.CollectCnt += d.CollectCnt
+		m.CommentCnt += d.CommentCnt
+		byDay[d.Day] = m
+	}
+	return r.toPoints(byDay), nil
+}
+
+func (r *analyticsRepository) AuthorDaily(ctx context.Context, uid int64,
+	from, to time.Time) ([]domain.AnalyticsPoint, error) {
+	f, t := from.Format(statsDayLayout), to.Format(statsDayLayout)
+	stats, err := r.statsDAO.SumAuthorDaily(ctx, uid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.dao.FindAuthorDaily(ctx, uid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]domain.AnalyticsMetrics, len(stats)+len(data))
+	for _, s := range stats {
+		m := byDay[s.Day]
+		m.ReadCnt += s.ReadCnt
+		byDay[s.Day] = m
+	}
+	for _, d := range data {
+		m := byDay[d.Day]
+		m.LikeCnt += d.LikeCnt
+		m.CollectCnt += d.CollectCnt
+		m.CommentCnt += d.CommentCnt
+		m.FollowerCnt += d.FollowerCnt
+		byDay[d.Day] = m
+	}
+	return r.toPoints(byDay), nil
+}
+
+func (r *analyticsRepository) ArticleTotals(ctx context.Context, uid int64,
+	from, to time.Time) ([]domain.ArticleAnalytics, error) {
+	f, t := from.Format(statsDayLayout), to.Format(statsDayLayout)
+	stats, err := r.statsDAO.SumByArticle(ctx, uid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.dao.SumByArticle(ctx, uid, f, t)
+	if err != nil {
+		return nil, err
+	}
+	byAid := make(map[int64]domain.AnalyticsMetrics, len(stats)+len(data))
+	for _, s := range stats {
+		m := byAid[s.Aid]
+		m.ReadCnt += s.ReadCnt
+		byAid[s.Aid] = m
+	}
+	for _, d := range data {
+		m := byAid[d.Aid]
+		m.LikeCnt += d.LikeCnt
+		m.CollectCnt += d.CollectCnt
+		m.CommentCnt += d.CommentCnt
+		byAid[d.Aid] = m
+	}
+	res := make([]domain.ArticleAnalytics, 0, len(byAid))
+	for aid, m := range byAid {
+		res = append(res, domain.ArticleAnalytics{
+			Article: domain.Article{Id: aid},
+			Metrics: m,
+		})
+	}
+	slices.SortFunc(res, func(a, b domain.ArticleAnalytics) int {
+		return cmp.Compare(a.Article.Id, b.Article.Id)
+	})
+	return res, nil
+}
+
+func (r *analyticsRepository) toEventEntity(evt domain.AnalyticsEvent) dao.AnalyticsEvent {
+	return dao.AnalyticsEvent{
+		Type:     uint8(evt.Type),
+		Actor:    evt.Actor,
+		Biz:      evt.Biz,
+		BizId:    evt.BizId,
+		SourceId: evt.SourceId,
+	}
+}
+
+func (r *analyticsRepository) toPoints(byDay map[string]domain.AnalyticsMetrics) []domain.AnalyticsPoint {
+	res := make([]domain.AnalyticsPoint, 0, len(byDay))
+	for d, m := range byDay {
+		day, err := time.ParseInLocation(statsDayLayout, d, time.Local)
+		if err != nil {
+			continue
+		}
+		res = append(res, domain.AnalyticsPoint{Start: day, Metrics: m})
+	}
+	slices.SortFunc(res, func(a, b domain.AnalyticsPoint) int {
+		return a.Start.Compare(b.Start)
+	})
+	return res
+}
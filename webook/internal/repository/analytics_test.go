@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+)
+
+func TestAnalyticsRepository_AuthorDaily(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 6)
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.AnalyticsDAO, dao.ArticleStatsDAO)
+		want    []domain.AnalyticsPoint
+		wantErr error
+	}{
+		{
+			name: "合并阅读数和互动数据",
+			mock: func(ctrl *gomock.Controller) (dao.AnalyticsDAO, dao.ArticleStatsDAO) {
+				d := daomocks.NewMockAnalyticsDAO(ctrl)
+				statsDAO := daomocks.NewMockArticleStatsDAO(ctrl)
+				statsDAO.EXPECT().SumAuthorDaily(gomock.Any(), int64(123), "2024-05-01", "2024-05-07").
+					Return([]dao.ArticleDailyStats{
+						{Day: "2024-05-01", ReadCnt: 10},
+						{Day: "2024-05-03", ReadCnt: 4},
+					}, nil)
+				d.EXPECT().FindAuthorDaily(gomock.Any(), int64(123), "2024-05-01", "2024-05-07").
+					Return([]dao.AuthorDailyAnalytics{
+						{Uid: 123, Day: "2024-05-02", FollowerCnt: 2},
+						{Uid: 123, Day: "2024-05-03", LikeCnt: 1, CollectCnt: 2, CommentCnt: 3},
+					}, nil)
+				return d, statsDAO
+			},
+			want: []domain.AnalyticsPoint{
+				{Start: from, Metrics: domain.AnalyticsMetrics{ReadCnt: 10}},
+				{Start: from.AddDate(0, 0, 1), Metrics: domain.AnalyticsMetrics{FollowerCnt: 2}},
+				{Start: from.AddDate(0, 0, 2), Metrics: domain.AnalyticsMetrics{ReadCnt: 4, LikeCnt: 1, CollectCnt: 2, CommentCnt: 3}},
+			},
+		},
+		{
+			name: "查询阅读数失败",
+			mock: func(ctrl *gomock.Controller) (dao.AnalyticsDAO, dao.ArticleStatsDAO) {
+				d := daomocks.NewMockAnalyticsDAO(ctrl)
+				statsDAO := daomocks.NewMockArticleStatsDAO(ctrl)
+				statsDAO.EXPECT().SumAuthorDaily(gomock.Any(), int64(123), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("mock db error"))
+				return d, statsDAO
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, statsDAO := tt.mock(ctrl)
+			repo := NewAnalyticsRepository(d, statsDAO)
+			res, err := repo.AuthorDaily(context.Background(), 123, from, to)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
+
+func TestAnalyticsRepository_ArticleTotals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := daomocks.NewMockAnalyticsDAO(ctrl)
+	statsDAO := daomocks.NewMockArticleStatsDAO(ctrl)
+	statsDAO.EXPECT().SumByArticle(gomock.Any(), int64(123), "2024-05-01", "2024-05-07").
+		Return([]dao.ArticleDailyStats{{Aid: 2, ReadCnt: 7}, {Aid: 1, ReadCnt: 3}}, nil)
+	// 只有互动没有阅读的文章也要算上
+	d.EXPECT().SumByArticle(gomock.Any(), int64(123), "2024-05-01", "2024-05-07").
+		Return([]dao.ArticleDailyAnalytics{{Aid: 1, LikeCnt: 2}, {Aid: 3, CommentCnt: 1}}, nil)
+	repo := NewAnalyticsRepository(d, statsDAO)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	res, err := repo.ArticleTotals(context.Background(), 123, from, from.AddDate(0, 0, 6))
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ArticleAnalytics{
+		{Article: domain.Article{Id: 1}, Metrics: domain.AnalyticsMetrics{ReadCnt: 3, LikeCnt: 2}},
+		{Article: domain.Article{Id: 2}, Metrics: domain.AnalyticsMetrics{ReadCnt: 7}},
+		{Article: domain.Article{Id: 3}, Metrics: domain.AnalyticsMetrics{CommentCnt: 1}},
+	}, res)
+}
@@ -2,8 +2,10 @@ package article
 
 import (
 	"context"
+	"errors"
 	"github.com/ecodeclub/ekit/slice"
 	"gorm.io/gorm"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/repository"
 	"webook/internal/repository/cache"
@@ -19,10 +21,70 @@ type ArticleRepository interface {
 	// SyncStatus 仅仅同步状态
 	SyncStatus(ctx context.Context, uid, id int64, status domain.ArticleStatus) error
 	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// ListIds 按照 id 正序返回作者排在 after 后面的文章 id，after 为 0 的时候从头开始
+	ListIds(ctx context.Context, uid, after int64, limit int) ([]int64, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPublishedById(ctx context.Context, id int64) (domain.Article, error)
+	// ListPub 读者侧的列表，utime 和 id 是上一页最后一条的位置，为零值代表第一页
+	ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ListPubByIds 批量查询已发表的文章，按照 ids 的顺序返回，不存在的会被跳过
+	ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPubTitlesByIds 和 ListPubByIds 一样，但是只有 Id 和 Title，给只需要标题的地方用
+	ListPubTitlesByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPubByTag 和 ListPub 一样，只是限定了标签
+	ListPubByTag(ctx context.Context, tag string, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ListPubByCategory 和 ListPub 一样，只是限定了分类
+	ListPubByCategory(ctx context.Context, category string, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ListPubByAuthor 和 ListPub 一样，只是限定了作者
+	ListPubByAuthor(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ScanPub 按照 id 正序遍历已发表的文章，只有 Id 和 Utime
+	ScanPub(ctx context.Context, offset, limit int) ([]domain.Article, error)
+	CountPub(ctx context.Context) (int64, error)
+	// PubChangedAt 作者的线上内容最后一次变化的时间，包括撤回和删除，uid 为 0 的时候是全站的
+	// 没有记录的时候返回零值
+	PubChangedAt(ctx context.Context, uid int64) (time.Time, error)
+	// Render 渲染已经发表的文章，渲染的结果会缓存起来，文章更新之后重新渲染
+	Render(ctx context.Context, art domain.Article) domain.RenderedContent
+	// PopularTags 热门标签，最多返回 PopularTagsLimit 个
+	PopularTags(ctx context.Context, limit int) ([]domain.Tag, error)
+	// UpdateSchedule 修改定时发表的时间，publishAt 为零值代表取消定时发表
+	UpdateSchedule(ctx context.Context, uid, id int64, publishAt time.Time) error
+	// ListDueScheduled 到了发表时间的定时发表文章，按照 PublishAt, Id 正序分页，返回排在 after 后面的文章
+	// after 为零值的时候从头开始
+	ListDueScheduled(ctx context.Context, now time.Time, after domain.Article, limit int) ([]domain.Article, error)
+	// PublishScheduled 发表到期的定时发表文章，发表的是制作库里面当前的内容
+	// 已经取消、改期或者发表过的文章返回 ErrNotScheduled
+	PublishScheduled(ctx context.Context, id int64, now time.Time) (domain.Article, error)
+	// GetRevisions 作者的某篇文章的历史版本，按照版本号倒序
+	GetRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, uid, id, revision int64) (domain.ArticleRevision, error)
+	// GetLiveRevision 线上库的版本号，从来没有发表过的时候返回 0
+	GetLiveRevision(ctx context.Context, uid, id int64) (int64, error)
+
+	// SoftDelete 移到回收站，同时清理作者和读者两边的缓存
+	SoftDelete(ctx context.Context, uid, id int64) error
+	// Restore 只能恢复 since 之后删除的文章，否则返回 ErrNotInRecycleBin
+	Restore(ctx context.Context, uid, id int64, since time.Time) error
+	// ListDeleted 回收站里面 since 之后删除的文章，按照删除时间倒序
+	ListDeleted(ctx context.Context, uid int64, since time.Time, offset, limit int) ([]domain.Article, error)
+	// ListPurgeable before 之前删除的文章，给清理任务用
+	ListPurgeable(ctx context.Context, before time.Time, limit int) ([]domain.Article, error)
+	// Purge 彻底删除，包括线上库和对象存储里面的内容
+	Purge(ctx context.Context, id int64) error
 }
 
+// PopularTagsLimit 缓存里面的热门标签数量，也是能查询的上限
+const PopularTagsLimit = 50
+
+var (
+	ErrRevisionNotFound = dao.ErrRevisionNotFound
+	ErrNotScheduled     = dao.ErrNotScheduled
+	ErrVersionConflict  = dao.ErrVersionConflict
+	ErrNotInRecycleBin  = dao.ErrNotInRecycleBin
+	// ErrPossibleIncorrectAuthor 文章不存在或者不属于这个作者
+	ErrPossibleIncorrectAuthor = dao.ErrPossibleIncorrectAuthor
+)
+
 type CachedArticleRepository struct {
 	// 操作单一的库
 	dao dao.ArticleDAO
@@ -66,9 +128,27 @@ func (repo *CachedArticleRepository) Sync(ctx context.Context, art domain.Articl
 	if err != nil {
 		return 0, err
 	}
+	art.Id = id
+	repo.afterSync(ctx, art)
+	return id, nil
+}
+
+func (repo *CachedArticleRepository) PublishScheduled(ctx context.Context, id int64, now time.Time) (domain.Article, error) {
+	pub, err := repo.dao.PublishScheduled(ctx, id, now.UnixMilli())
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art := repo.toDomain(pub)
+	repo.afterSync(ctx, art)
+	return art, nil
+}
+
+// afterSync 发表之后删除缓存，再提前缓存线上库的文章
+func (repo *CachedArticleRepository) afterSync(ctx context.Context, art domain.Article) {
+	repo.delCache(ctx, art.Id)
 	go func() {
 		author := art.Author.Id
-		err = repo.cache.DelFirstPage(ctx, author)
+		err := repo.cache.DelFirstPage(ctx, author)
 		if err != nil {
 			repo.l.Error("删除第一页缓存失败",
 				logger.Int64("author", author), logger.Error(err))
@@ -82,13 +162,22 @@ func (repo *CachedArticleRepository) Sync(ctx context.Context, art domain.Articl
 			Id:   user.Id,
 			Name: user.NickName,
 		}
+		if art.Tags == nil {
+			// 没有修改标签，要从线上库里面拿到完整的标签
+			pub, er := repo.dao.GetPubById(ctx, art.Id)
+			if er != nil {
+				repo.l.Error("提前设置缓存准备标签失败",
+					logger.Int64("aid", art.Id), logger.Error(er))
+				return
+			}
+			art.Tags = pub.Tags
+		}
 		err = repo.cache.SetPub(ctx, art)
 		if err != nil {
 			repo.l.Error("提前设置缓存失败",
 				logger.Int64("author", author), logger.Error(err))
 		}
 	}()
-	return id, nil
 }
 
 func (repo *CachedArticleRepository) SyncV2(ctx context.Context, art domain.Article) (int64, error) {
@@ -169,6 +258,7 @@ func (repo *CachedArticleRepository) Update(ctx context.Context, art domain.Arti
 	if err != nil {
 		return err
 	}
+	repo.delCache(ctx, art.Id)
 	author := art.Author.Id
 	err = repo.cache.DelFirstPage(ctx, author)
 	if err != nil {
@@ -177,6 +267,68 @@ func (repo *CachedArticleRepository) Update(ctx context.Context, art domain.Arti
 	return nil
 }
 
+func (repo *CachedArticleRepository) SoftDelete(ctx context.Context, uid, id int64) error {
+	err := repo.dao.SoftDelete(ctx, uid, id, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+	repo.delCache(ctx, id)
+	if er := repo.cache.DelPub(ctx, id); er != nil {
+		repo.l.Error("删除线上文章缓存失败", logger.Int64("aid", id), logger.Error(er))
+	}
+	if er := repo.cache.DelFirstPage(ctx, uid); er != nil {
+		repo.l.Error("删除缓存失败", logger.Int64("author", uid), logger.Error(er))
+	}
+	return nil
+}
+
+func (repo *CachedArticleRepository) Restore(ctx context.Context, uid, id int64, since time.Time) error {
+	err := repo.dao.Restore(ctx, uid, id, since.UnixMilli())
+	if err != nil {
+		return err
+	}
+	repo.delCache(ctx, id)
+	if er := repo.cache.DelFirstPage(ctx, uid); er != nil {
+		repo.l.Error("删除缓存失败", logger.Int64("author", uid), logger.Error(er))
+	}
+	return nil
+}
+
+func (repo *CachedArticleRepository) ListDeleted(ctx context.Context, uid int64, since time.Time, offset, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListDeleted(ctx, uid, since.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *CachedArticleRepository) ListPurgeable(ctx context.Context, before time.Time, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPurgeable(ctx, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *CachedArticleRepository) Purge(ctx context.Context, id int64) error {
+	return repo.dao.Purge(ctx, id)
+}
+
+// delCache 版本号变了，制作库的缓存要同步删除，下一次保存才不会冲突
+func (repo *CachedArticleRepository) delCache(ctx context.Context, id int64) {
+	if err := repo.cache.Del(ctx, id); err != nil {
+		repo.l.Error("删除文章缓存失败", logger.Int64("aid", id), logger.Error(err))
+	}
+}
+
+func (repo *CachedArticleRepository) ListIds(ctx context.Context, uid, after int64, limit int) ([]int64, error) {
+	return repo.dao.GetIdsByAuthor(ctx, uid, after, limit)
+}
+
 func (repo *CachedArticleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	cachedArt, err := repo.cache.Get(ctx, id)
 	if err == nil {
@@ -218,10 +370,13 @@ func (repo *CachedArticleRepository) List(ctx context.Context, uid int64, offset
 	go func() {
 		repo.preCache(ctx, res)
 	}()
-	// 这里可做成异步
-	err = repo.cache.SetFirstPage(ctx, uid, res)
-	if err != nil {
-		repo.l.Error("刷新第一页文章的缓存失败", logger.Int64("author", uid), logger.Error(err))
+	// 只有第一页才写缓存，不然导出之类的翻页会覆盖掉第一页的缓存
+	if offset == 0 && limit == 100 {
+		// 这里可做成异步
+		err = repo.cache.SetFirstPage(ctx, uid, res)
+		if err != nil {
+			repo.l.Error("刷新第一页文章的缓存失败", logger.Int64("author", uid), logger.Error(err))
+		}
 	}
 	return res, nil
 }
@@ -240,14 +395,18 @@ func (repo *CachedArticleRepository) GetPublishedById(ctx context.Context, id in
 		return domain.Article{}, err
 	}
 	res = domain.Article{
-		Id:      art.Id,
-		Title:   art.Title,
-		Status:  domain.ArticleStatus(art.Status),
-		Content: art.Content,
+		Id:       art.Id,
+		Title:    art.Title,
+		Status:   domain.ArticleStatus(art.Status),
+		Content:  art.Content,
+		Abstract: art.Abstract,
 		Author: domain.Author{
 			Id:   user.Id,
 			Name: user.NickName,
 		},
+		Category: art.Category,
+		Tags:     art.Tags,
+		Cover:    art.Cover,
 	}
 	// 也可以同步
 	go func() {
@@ -259,6 +418,181 @@ func (repo *CachedArticleRepository) GetPublishedById(ctx context.Context, id in
 	return res, nil
 }
 
+func (repo *CachedArticleRepository) ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPub(ctx, repo.toMilli(utime), id, limit)
+	return repo.pubToDomain(arts, err)
+}
+
+func (repo *CachedArticleRepository) ListPubByTag(ctx context.Context, tag string, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPubByTag(ctx, tag, repo.toMilli(utime), id, limit)
+	return repo.pubToDomain(arts, err)
+}
+
+func (repo *CachedArticleRepository) ListPubByCategory(ctx context.Context, category string, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPubByCategory(ctx, category, repo.toMilli(utime), id, limit)
+	return repo.pubToDomain(arts, err)
+}
+
+func (repo *CachedArticleRepository) ListPubByAuthor(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListPubByAuthor(ctx, uid, repo.toMilli(utime), id, limit)
+	return repo.pubToDomain(arts, err)
+}
+
+func (repo *CachedArticleRepository) ScanPub(ctx context.Context, offset, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ScanPub(ctx, offset, limit)
+	return repo.pubToDomain(arts, err)
+}
+
+func (repo *CachedArticleRepository) CountPub(ctx context.Context) (int64, error) {
+	return repo.dao.CountPub(ctx)
+}
+
+func (repo *CachedArticleRepository) PubChangedAt(ctx context.Context, uid int64) (time.Time, error) {
+	ms, err := repo.dao.GetPubChange(ctx, uid)
+	return repo.toTime(ms), err
+}
+
+func (repo *CachedArticleRepository) pubToDomain(arts []dao.PublishedArticle, err error) ([]domain.Article, error) {
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](arts,
+		func(idx int, src dao.PublishedArticle) domain.Article {
+			return repo.toDomain(dao.Article(src))
+		}), nil
+}
+
+func (repo *CachedArticleRepository) Render(ctx context.Context, art domain.Article) domain.RenderedContent {
+	res, err := repo.cache.GetRendered(ctx, art.Id)
+	if err == nil && res.Digest == art.ContentDigest() {
+		return res
+	}
+	res = render(art)
+	if err = repo.cache.SetRendered(ctx, art.Id, res); err != nil {
+		repo.l.Error("缓存渲染之后的文章失败",
+			logger.Int64("aid", art.Id), logger.Error(err))
+	}
+	return res
+}
+
+func (repo *CachedArticleRepository) PopularTags(ctx context.Context, limit int) ([]domain.Tag, error) {
+	limit = min(limit, PopularTagsLimit)
+	tags, err := repo.cache.GetPopularTags(ctx)
+	if err == nil {
+		return tags[:min(limit, len(tags))], nil
+	}
+	// 缓存里面总是放满 PopularTagsLimit 个，不同的 limit 可以共用
+	cnts, err := repo.dao.PopularTags(ctx, PopularTagsLimit)
+	if err != nil {
+		return nil, err
+	}
+	tags = slice.Map[dao.TagCnt, domain.Tag](cnts, func(idx int, src dao.TagCnt) domain.Tag {
+		return domain.Tag{Id: src.Id, Name: src.Name, Cnt: src.Cnt}
+	})
+	if err = repo.cache.SetPopularTags(ctx, tags); err != nil {
+		repo.l.Error("缓存热门标签失败", logger.Error(err))
+	}
+	return tags[:min(limit, len(tags))], nil
+}
+
+func (repo *CachedArticleRepository) ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	if len(ids) == 0 {
+		return []domain.Article{}, nil
+	}
+	arts, err := repo.dao.GetPubByIds(ctx, ids)
+	return repo.orderByIds(ids, arts, err)
+}
+
+func (repo *CachedArticleRepository) ListPubTitlesByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	if len(ids) == 0 {
+		return []domain.Article{}, nil
+	}
+	arts, err := repo.dao.GetPubTitlesByIds(ctx, ids)
+	return repo.orderByIds(ids, arts, err)
+}
+
+// orderByIds 按照 ids 的顺序排列，查不到的跳过
+func (repo *CachedArticleRepository) orderByIds(ids []int64, arts []dao.PublishedArticle, err error) ([]domain.Article, error) {
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]dao.PublishedArticle, len(arts))
+	for _, art := range arts {
+		m[art.Id] = art
+	}
+	res := make([]domain.Article, 0, len(arts))
+	for _, id := range ids {
+		art, ok := m[id]
+		if !ok {
+			continue
+		}
+		res = append(res, repo.toDomain(dao.Article(art)))
+	}
+	return res, nil
+}
+
+func (repo *CachedArticleRepository) UpdateSchedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	err := repo.dao.UpdateSchedule(ctx, uid, id, repo.toMilli(publishAt))
+	if err != nil {
+		return err
+	}
+	// 第一页缓存里面有状态，所以要删掉
+	if err = repo.cache.DelFirstPage(ctx, uid); err != nil {
+		repo.l.Error("删除缓存失败", logger.Int64("author", uid), logger.Error(err))
+	}
+	return nil
+}
+
+func (repo *CachedArticleRepository) ListDueScheduled(ctx context.Context, now time.Time,
+	after domain.Article, limit int) ([]domain.Article, error) {
+	arts, err := repo.dao.ListDueScheduled(ctx, now.UnixMilli(), repo.toMilli(after.PublishAt), after.Id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts,
+		func(idx int, src dao.Article) domain.Article {
+			return repo.toDomain(src)
+		}), nil
+}
+
+func (repo *CachedArticleRepository) GetRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	revs, err := repo.dao.GetRevisions(ctx, uid, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ArticleRevision, domain.ArticleRevision](revs,
+		func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+			return repo.revisionToDomain(src)
+		}), nil
+}
+
+func (repo *CachedArticleRepository) GetRevision(ctx context.Context, uid, id, revision int64) (domain.ArticleRevision, error) {
+	rev, err := repo.dao.GetRevision(ctx, uid, id, revision)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return repo.revisionToDomain(rev), nil
+}
+
+func (repo *CachedArticleRepository) GetLiveRevision(ctx context.Context, uid, id int64) (int64, error) {
+	// 线上库的缓存里面没有版本号，并且作者要看的是准确的数据，所以直接查数据库
+	pub, err := repo.dao.GetPubById(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if pub.AuthorId != uid {
+		return 0, dao.ErrPossibleIncorrectAuthor
+	}
+	// 撤回之后线上库还留着数据，但是读者已经看不到了
+	if domain.ArticleStatus(pub.Status) != domain.ArticleStatusPublished {
+		return 0, nil
+	}
+	return pub.Revision, nil
+}
+
 func (repo *CachedArticleRepository) preCache(ctx context.Context, arts []domain.Article) {
 	// 小于1MB 不缓存大文档
 	const contentSizeThreshold = 1024 * 1024
@@ -271,13 +605,42 @@ func (repo *CachedArticleRepository) preCache(ctx context.Context, arts []domain
 
 func (repo *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
 	return domain.Article{
-		Id:      art.Id,
-		Title:   art.Title,
-		Status:  domain.ArticleStatus(art.Status),
-		Content: art.Content,
+		Id:       art.Id,
+		Title:    art.Title,
+		Status:   domain.ArticleStatus(art.Status),
+		Content:  art.Content,
+		Abstract: art.Abstract,
 		Author: domain.Author{
 			Id: art.AuthorId,
 		},
+		Category:  art.Category,
+		Tags:      art.Tags,
+		Cover:     art.Cover,
+		Revision:  art.Revision,
+		PublishAt: repo.toTime(art.PublishAt),
+		DeletedAt: repo.toTime(art.DeletedAt),
+		Ctime:     time.UnixMilli(art.Ctime),
+		Utime:     time.UnixMilli(art.Utime),
+	}
+}
+
+// toTime 0 代表没有设置，转成零值而不是 1970 年
+func (repo *CachedArticleRepository) toTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func (repo *CachedArticleRepository) revisionToDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		ArticleId: rev.ArticleId,
+		Revision:  rev.Revision,
+		AuthorId:  rev.AuthorId,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Status:    domain.ArticleStatus(rev.Status),
+		Ctime:     time.UnixMilli(rev.Ctime),
 	}
 }
 
@@ -286,10 +649,24 @@ func (repo *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
 		Id:       art.Id,
 		Title:    art.Title,
 		Content:  art.Content,
+		Abstract: art.Abstract,
 		AuthorId: art.Author.Id,
 		// 这一步，就是将领域状态转化为存储状态。
 		// 这里我们就是直接转换，
 		// 有些情况下，这里可能是借助一个 map 来转
-		Status: uint8(art.Status),
+		Status:   uint8(art.Status),
+		Category: art.Category,
+		Tags:     art.Tags,
+		Cover:    art.Cover,
+		// 不为 0 的时候作为乐观锁
+		Revision:  art.Revision,
+		PublishAt: repo.toMilli(art.PublishAt),
+	}
+}
+
+func (repo *CachedArticleRepository) toMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
 	}
+	return t.UnixMilli()
 }
@@ -0,0 +1,40 @@
+package article
+
+import (
+	"webook/internal/domain"
+	"webook/pkg/markdown"
+)
+
+const (
+	abstractLen = 100
+	// readingSpeed 每分钟阅读的字数
+	readingSpeed = 300
+)
+
+// Abstract 取内容的一部分作为摘要，Markdown 的标记会被去掉
+// 要渲染整篇内容，所以只在保存的时候算一次，列表直接用保存下来的结果
+func Abstract(content string) string {
+	return markdown.Abstract(markdown.Render(content).Text, abstractLen)
+}
+
+// render 渲染 Content，阅读时间不足一分钟的按照一分钟算
+func render(art domain.Article) domain.RenderedContent {
+	res := markdown.Render(art.Content)
+	toc := make([]domain.TOCItem, 0, len(res.TOC))
+	for _, h := range res.TOC {
+		toc = append(toc, domain.TOCItem{Level: h.Level, Text: h.Text, Anchor: h.Anchor})
+	}
+	wordCnt := markdown.WordCount(res.Text)
+	minutes := 0
+	if wordCnt > 0 {
+		minutes = (wordCnt + readingSpeed - 1) / readingSpeed
+	}
+	return domain.RenderedContent{
+		HTML:           res.HTML,
+		TOC:            toc,
+		Abstract:       markdown.Abstract(res.Text, abstractLen),
+		WordCnt:        wordCnt,
+		ReadingMinutes: minutes,
+		Digest:         art.ContentDigest(),
+	}
+}
@@ -0,0 +1,130 @@
+package article
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/internal/domain"
+	dao "webook/internal/repository/dao/article"
+)
+
+var (
+	ErrSeriesNotFound       = dao.ErrSeriesNotFound
+	ErrInvalidSeriesArticle = dao.ErrInvalidSeriesArticle
+	ErrArticleInOtherSeries = dao.ErrArticleInOtherSeries
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// Update 只修改标题和简介
+	Update(ctx context.Context, s domain.Series) error
+	UpdateStatus(ctx context.Context, uid, id int64, status domain.SeriesStatus) error
+	// SetArticles 整体替换章节，ids 的顺序就是章节的顺序
+	SetArticles(ctx context.Context, uid, id int64, ids []int64) error
+	Delete(ctx context.Context, uid, id int64) error
+	// GetById 带上章节的文章 ID
+	GetById(ctx context.Context, id int64) (domain.Series, error)
+	// List 作者的专栏列表，不带章节
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, error)
+	// GetByArticle 文章所在的专栏，带上章节的文章 ID
+	GetByArticle(ctx context.Context, articleId int64) (domain.Series, error)
+	// GetArticles 作者视角的章节，按照章节的顺序，不包含内容
+	GetArticles(ctx context.Context, id int64) ([]domain.Article, error)
+}
+
+type seriesRepository struct {
+	dao dao.SeriesDAO
+}
+
+func NewSeriesRepository(dao dao.SeriesDAO) SeriesRepository {
+	return &seriesRepository{
+		dao: dao,
+	}
+}
+
+func (repo *seriesRepository) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return repo.dao.Insert(ctx, repo.toEntity(s))
+}
+
+func (repo *seriesRepository) Update(ctx context.Context, s domain.Series) error {
+	return repo.dao.Update(ctx, repo.toEntity(s))
+}
+
+func (repo *seriesRepository) UpdateStatus(ctx context.Context, uid, id int64, status domain.SeriesStatus) error {
+	return repo.dao.UpdateStatus(ctx, uid, id, status.ToUint8())
+}
+
+func (repo *seriesRepository) SetArticles(ctx context.Context, uid, id int64, ids []int64) error {
+	return repo.dao.SetArticles(ctx, uid, id, ids)
+}
+
+func (repo *seriesRepository) Delete(ctx context.Context, uid, id int64) error {
+	return repo.dao.Delete(ctx, uid, id)
+}
+
+func (repo *seriesRepository) GetById(ctx context.Context, id int64) (domain.Series, error) {
+	s, err := repo.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return repo.toDomain(s), nil
+}
+
+func (repo *seriesRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, error) {
+	res, err := repo.dao.GetByAuthor(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Series, domain.Series](res, func(idx int, src dao.Series) domain.Series {
+		return repo.toDomain(src)
+	}), nil
+}
+
+func (repo *seriesRepository) GetByArticle(ctx context.Context, articleId int64) (domain.Series, error) {
+	s, err := repo.dao.GetByArticle(ctx, articleId)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return repo.toDomain(s), nil
+}
+
+func (repo *seriesRepository) GetArticles(ctx context.Context, id int64) ([]domain.Article, error) {
+	arts, err := repo.dao.GetArticles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Article, domain.Article](arts, func(idx int, src dao.Article) domain.Article {
+		return domain.Article{
+			Id:     src.Id,
+			Title:  src.Title,
+			Status: domain.ArticleStatus(src.Status),
+			Author: domain.Author{Id: src.AuthorId},
+			Cover:  src.Cover,
+			Ctime:  time.UnixMilli(src.Ctime),
+			Utime:  time.UnixMilli(src.Utime),
+		}
+	}), nil
+}
+
+func (repo *seriesRepository) toDomain(s dao.Series) domain.Series {
+	return domain.Series{
+		Id:          s.Id,
+		Title:       s.Title,
+		Description: s.Description,
+		Author:      domain.Author{Id: s.AuthorId},
+		Status:      domain.SeriesStatus(s.Status),
+		ArticleIds:  s.ArticleIds,
+		Ctime:       time.UnixMilli(s.Ctime),
+		Utime:       time.UnixMilli(s.Utime),
+	}
+}
+
+func (repo *seriesRepository) toEntity(s domain.Series) dao.Series {
+	return dao.Series{
+		Id:          s.Id,
+		Title:       s.Title,
+		Description: s.Description,
+		AuthorId:    s.Author.Id,
+		Status:      s.Status.ToUint8(),
+	}
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+)
+
+// statsDayLayout 数据库里面日期的格式
+const statsDayLayout = time.DateOnly
+
+type ArticleStatsConfig struct {
+	// DedupeWindow 同一个读者在这段时间里面重复阅读只算一次，0 表示不去重
+	DedupeWindow time.Duration
+}
+
+type ArticleStatsRepository interface {
+	// RecordRead 记录一次阅读，去重之后不需要计数的时候返回 false
+	RecordRead(ctx context.Context, aid int64, reader string, t time.Time) (bool, error)
+	// Flush 把缓存里面 day 这一天的数据写到数据库，返回写了多少篇文章
+	Flush(ctx context.Context, day time.Time) (int, error)
+	// DailyViews [from, to] 之间有阅读的日子，按照日期升序
+	// 今天的数据还没有刷到数据库，直接用缓存里面的
+	DailyViews(ctx context.Context, aid int64, from, to time.Time) ([]domain.ArticleDailyViews, error)
+}
+
+type CachedArticleStatsRepository struct {
+	cache cache.ArticleStatsCache
+	dao   dao.ArticleStatsDAO
+	cfg   ArticleStatsConfig
+	// flushBatch 每次从缓存里面拿多少篇文章
+	flushBatch int64
+}
+
+func NewCachedArticleStatsRepository(cache cache.ArticleStatsCache, dao dao.ArticleStatsDAO,
+	cfg ArticleStatsConfig) ArticleStatsRepository {
+	return &CachedArticleStatsRepository{
+		cache:      cache,
+		dao:        dao,
+		cfg:        cfg,
+		flushBatch: 500,
+	}
+}
+
+func (r *CachedArticleStatsRepository) RecordRead(ctx context.Context, aid int64, reader string, t time.Time) (bool, error) {
+	return r.cache.RecordRead(ctx, aid, reader, t, r.cfg.DedupeWindow)
+}
+
+func (r *CachedArticleStatsRepository) Flush(ctx context.Context, day time.Time) (int, error) {
+	cnt := 0
+	var cursor uint64
+	for {
+		reads, next, err := r.cache.ScanDaily(ctx, day, cursor, r.flushBatch)
+		if err != nil {
+			return cnt, err
+		}
+		if len(reads) > 0 {
+			aids := make([]int64, 0, len(reads))
+			for aid := range reads {
+				aids = append(aids, aid)
+			}
+			slices.Sort(aids)
+			uvs, err := r.cache.CountVisitors(ctx, day, aids)
+			if err != nil {
+				return cnt, err
+			}
+			stats := make([]dao.ArticleDailyStats, 0, len(aids))
+			for _, aid := range aids {
+				stats = append(stats, dao.ArticleDailyStats{
+					Aid:     aid,
+					Day:     day.Format(statsDayLayout),
+					ReadCnt: reads[aid],
+					UV:      uvs[aid],
+				})
+			}
+			if err = r.dao.Upsert(ctx, stats); err != nil {
+				return cnt, err
+			}
+			cnt += len(stats)
+		}
+		if next == 0 {
+			return cnt, nil
+		}
+		cursor = next
+	}
+}
+
+func (r *CachedArticleStatsRepository) DailyViews(ctx context.Context, aid int64,
+	from, to time.Time) ([]domain.ArticleDailyViews, error) {
+	stats, err := r.dao.FindDaily(ctx, aid, from.Format(statsDayLayout), to.Format(statsDayLayout))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	today := now.Format(statsDayLayout)
+	res := make([]domain.ArticleDailyViews, 0, len(stats)+1)
+	var flushed *domain.ArticleDailyViews
+	for _, s := range stats {
+		day, err := time.ParseInLocation(statsDayLayout, s.Day, time.Local)
+		if err != nil {
+			continue
+		}
+		v := domain.ArticleDailyViews{Day: day, ReadCnt: s.ReadCnt, UV: s.UV}
+		// 今天的以缓存为准，缓存出问题的时候再用刷过来的数据
+		if s.Day == today {
+			flushed = &v
+			continue
+		}
+		res = append(res, v)
+	}
+	if today < from.Format(statsDayLayout) || today > to.Format(statsDayLayout) {
+		return res, nil
+	}
+	cur, err := r.cache.GetDaily(ctx, aid, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	switch {
+	case err != nil && flushed != nil:
+		res = append(res, *flushed)
+	case err != nil:
+		return nil, err
+	case cur.ReadCnt > 0 || cur.UV > 0:
+		res = append(res, cur)
+	}
+	return res, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	cachemocks "webook/internal/repository/cache/mocks"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+)
+
+func TestCachedArticleStatsRepository_Flush(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO)
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "分批刷到数据库",
+			mock: func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO) {
+				c := cachemocks.NewMockArticleStatsCache(ctrl)
+				d := daomocks.NewMockArticleStatsDAO(ctrl)
+				c.EXPECT().ScanDaily(gomock.Any(), day, uint64(0), int64(500)).
+					Return(map[int64]int64{2: 5, 1: 3}, uint64(7), nil)
+				c.EXPECT().CountVisitors(gomock.Any(), day, []int64{1, 2}).
+					Return(map[int64]int64{1: 2, 2: 4}, nil)
+				d.EXPECT().Upsert(gomock.Any(), []dao.ArticleDailyStats{
+					{Aid: 1, Day: "2024-05-01", ReadCnt: 3, UV: 2},
+					{Aid: 2, Day: "2024-05-01", ReadCnt: 5, UV: 4},
+				}).Return(nil)
+				// 只有独立访客，没有计数的文章
+				c.EXPECT().ScanDaily(gomock.Any(), day, uint64(7), int64(500)).
+					Return(map[int64]int64{3: 0}, uint64(0), nil)
+				c.EXPECT().CountVisitors(gomock.Any(), day, []int64{3}).
+					Return(map[int64]int64{3: 1}, nil)
+				d.EXPECT().Upsert(gomock.Any(), []dao.ArticleDailyStats{
+					{Aid: 3, Day: "2024-05-01", ReadCnt: 0, UV: 1},
+				}).Return(nil)
+				return c, d
+			},
+			wantCnt: 3,
+		},
+		{
+			name: "写数据库失败",
+			mock: func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO) {
+				c := cachemocks.NewMockArticleStatsCache(ctrl)
+				d := daomocks.NewMockArticleStatsDAO(ctrl)
+				c.EXPECT().ScanDaily(gomock.Any(), day, uint64(0), int64(500)).
+					Return(map[int64]int64{1: 3}, uint64(0), nil)
+				c.EXPECT().CountVisitors(gomock.Any(), day, []int64{1}).
+					Return(map[int64]int64{1: 2}, nil)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return c, d
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c, d := tt.mock(ctrl)
+			repo := NewCachedArticleStatsRepository(c, d, ArticleStatsConfig{})
+			cnt, err := repo.Flush(context.Background(), day)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCnt, cnt)
+		})
+	}
+}
+
+func TestCachedArticleStatsRepository_DailyViews(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO)
+		want    []domain.ArticleDailyViews
+		wantErr error
+	}{
+		{
+			name: "今天的数据用缓存",
+			mock: func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO) {
+				c := cachemocks.NewMockArticleStatsCache(ctrl)
+				d := daomocks.NewMockArticleStatsDAO(ctrl)
+				d.EXPECT().FindDaily(gomock.Any(), int64(1), yesterday.Format(time.DateOnly), today.Format(time.DateOnly)).
+					Return([]dao.ArticleDailyStats{
+						{Aid: 1, Day: yesterday.Format(time.DateOnly), ReadCnt: 10, UV: 8},
+						{Aid: 1, Day: today.Format(time.DateOnly), ReadCnt: 1, UV: 1},
+					}, nil)
+				c.EXPECT().GetDaily(gomock.Any(), int64(1), today).
+					Return(domain.ArticleDailyViews{Day: today, ReadCnt: 3, UV: 2}, nil)
+				return c, d
+			},
+			want: []domain.ArticleDailyViews{
+				{Day: yesterday, ReadCnt: 10, UV: 8},
+				{Day: today, ReadCnt: 3, UV: 2},
+			},
+		},
+		{
+			name: "缓存出错的时候用数据库里面今天的数据",
+			mock: func(ctrl *gomock.Controller) (cache.ArticleStatsCache, dao.ArticleStatsDAO) {
+				c := cachemocks.NewMockArticleStatsCache(ctrl)
+				d := daomocks.NewMockArticleStatsDAO(ctrl)
+				d.EXPECT().FindDaily(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).
+					Return([]dao.ArticleDailyStats{
+						{Aid: 1, Day: today.Format(time.DateOnly), ReadCnt: 1, UV: 1},
+					}, nil)
+				c.EXPECT().GetDaily(gomock.Any(), int64(1), today).
+					Return(domain.ArticleDailyViews{}, errors.New("mock redis error"))
+				return c, d
+			},
+			want: []domain.ArticleDailyViews{
+				{Day: today, ReadCnt: 1, UV: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c, d := tt.mock(ctrl)
+			repo := NewCachedArticleStatsRepository(c, d, ArticleStatsConfig{})
+			res, err := repo.DailyViews(context.Background(), 1, yesterday, today)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
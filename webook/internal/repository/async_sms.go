@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/sqlx"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+)
+
+var ErrWaitingSMSNotFound = dao.ErrWaitingSMSNotFound
+
+type AsyncSmsRepository interface {
+	Add(ctx context.Context, s domain.AsyncSms) error
+	PreemptWaitingSMS(ctx context.Context) (domain.AsyncSms, error)
+	ReportScheduleResult(ctx context.Context, id int64, success bool) error
+}
+
+type asyncSmsRepository struct {
+	dao dao.AsyncSmsDAO
+}
+
+func NewAsyncSMSRepository(dao dao.AsyncSmsDAO) AsyncSmsRepository {
+	return &asyncSmsRepository{
+		dao: dao,
+	}
+}
+
+func (a *asyncSmsRepository) Add(ctx context.Context, s domain.AsyncSms) error {
+	return a.dao.Insert(ctx, dao.AsyncSms{
+		Config: sqlx.JsonColumn[dao.SmsConfig]{
+			Val: dao.SmsConfig{
+				Biz:     s.Biz,
+				Args:    s.Args,
+				Numbers: s.Numbers,
+			},
+			Valid: true,
+		},
+		RetryMax: s.RetryMax,
+	})
+}
+
+func (a *asyncSmsRepository) PreemptWaitingSMS(ctx context.Context) (domain.AsyncSms, error) {
+	as, err := a.dao.GetWaitingSMS(ctx)
+	if err != nil {
+		return domain.AsyncSms{}, err
+	}
+	return domain.AsyncSms{
+		Id:       as.Id,
+		Biz:      as.Config.Val.Biz,
+		Args:     as.Config.Val.Args,
+		Numbers:  as.Config.Val.Numbers,
+		RetryMax: as.RetryMax,
+	}, nil
+}
+
+func (a *asyncSmsRepository) ReportScheduleResult(ctx context.Context, id int64, success bool) error {
+	if success {
+		return a.dao.MarkSuccess(ctx, id)
+	}
+	return a.dao.MarkFailed(ctx, id)
+}
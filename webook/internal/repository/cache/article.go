@@ -21,10 +21,22 @@ type ArticleCache interface {
 
 	Set(ctx context.Context, art domain.Article) error
 	Get(ctx context.Context, id int64) (domain.Article, error)
+	// Del 制作库的文章更新之后删除，避免作者拿到旧的版本号
+	Del(ctx context.Context, id int64) error
 
 	// SetPub 正常来说，创作者和读者的 Redis 集群要分开，因为读者是一个核心中的核心
 	SetPub(ctx context.Context, article domain.Article) error
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
+	// DelPub 同时删掉渲染之后的内容
+	DelPub(ctx context.Context, id int64) error
+
+	// SetRendered 渲染之后的内容和线上库的文章放在一起，过期时间也一样
+	SetRendered(ctx context.Context, id int64, r domain.RenderedContent) error
+	GetRendered(ctx context.Context, id int64) (domain.RenderedContent, error)
+
+	// SetPopularTags 热门标签变化很慢，整体缓存一小段时间
+	SetPopularTags(ctx context.Context, tags []domain.Tag) error
+	GetPopularTags(ctx context.Context) ([]domain.Tag, error)
 }
 
 type RedisArticleCache struct {
@@ -54,7 +66,7 @@ func (r *RedisArticleCache) GetFirstPage(ctx context.Context, author int64) ([]d
 func (r *RedisArticleCache) SetFirstPage(ctx context.Context, author int64, arts []domain.Article) error {
 	for i := range arts {
 		// 只缓存摘要部分
-		arts[i].Content = arts[i].Abstract()
+		arts[i].Content = ""
 	}
 	bs, err := json.Marshal(arts)
 	if err != nil {
@@ -92,12 +104,21 @@ func (r *RedisArticleCache) Get(ctx context.Context, id int64) (domain.Article,
 	return res, err
 }
 
+func (r *RedisArticleCache) Del(ctx context.Context, id int64) error {
+	return r.client.Del(ctx, r.Key(id)).Err()
+}
+
+// pubKey 线上库的文章和制作库的要分开，否则作者会读到线上的版本
+func (r *RedisArticleCache) pubKey(id int64) string {
+	return fmt.Sprintf("article:pub:%d", id)
+}
+
 func (r *RedisArticleCache) SetPub(ctx context.Context, art domain.Article) error {
 	data, err := json.Marshal(art)
 	if err != nil {
 		return err
 	}
-	return r.client.Set(ctx, r.Key(art.Id),
+	return r.client.Set(ctx, r.pubKey(art.Id),
 		data,
 		// 设置长过期时间
 		time.Minute*30).Err()
@@ -105,7 +126,7 @@ func (r *RedisArticleCache) SetPub(ctx context.Context, art domain.Article) erro
 
 func (r *RedisArticleCache) GetPub(ctx context.Context, id int64) (domain.Article, error) {
 	// 可以直接使用 Bytes 方法来获得 []byte
-	data, err := r.client.Get(ctx, r.Key(id)).Bytes()
+	data, err := r.client.Get(ctx, r.pubKey(id)).Bytes()
 	if err != nil {
 		return domain.Article{}, err
 	}
@@ -113,3 +134,48 @@ func (r *RedisArticleCache) GetPub(ctx context.Context, id int64) (domain.Articl
 	err = json.Unmarshal(data, &res)
 	return res, err
 }
+
+func (r *RedisArticleCache) DelPub(ctx context.Context, id int64) error {
+	// 渲染之后的内容和线上库的文章一起删掉
+	return r.client.Del(ctx, r.pubKey(id), r.renderedKey(id)).Err()
+}
+
+func (r *RedisArticleCache) renderedKey(id int64) string {
+	return fmt.Sprintf("article:rendered:%d", id)
+}
+
+func (r *RedisArticleCache) SetRendered(ctx context.Context, id int64, rendered domain.RenderedContent) error {
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.renderedKey(id), data, time.Minute*30).Err()
+}
+
+func (r *RedisArticleCache) GetRendered(ctx context.Context, id int64) (domain.RenderedContent, error) {
+	data, err := r.client.Get(ctx, r.renderedKey(id)).Bytes()
+	if err != nil {
+		return domain.RenderedContent{}, err
+	}
+	var res domain.RenderedContent
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (r *RedisArticleCache) SetPopularTags(ctx context.Context, tags []domain.Tag) error {
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, "article:popular_tags", data, time.Minute*5).Err()
+}
+
+func (r *RedisArticleCache) GetPopularTags(ctx context.Context) ([]domain.Tag, error) {
+	data, err := r.client.Get(ctx, "article:popular_tags").Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Tag
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+)
+
+var (
+	//go:embed lua/article_record_read.lua
+	luaRecordRead string
+)
+
+// articleStatsExpiration 留足时间给定时任务把前一天的数据刷到数据库
+const articleStatsExpiration = 72 * time.Hour
+
+// ArticleStatsCache 每天的阅读数和独立访客，day 都按照本地时区的日期计算
+type ArticleStatsCache interface {
+	// RecordRead 同一个 reader 在 window 里面重复阅读只计一次，返回这一次有没有计数
+	// 不管有没有计数，reader 都会计入 day 这一天的独立访客，reader 为空的时候总是计数
+	RecordRead(ctx context.Context, aid int64, reader string, day time.Time, window time.Duration) (bool, error)
+	// ScanDaily 分批遍历 day 这一天有阅读的文章，返回它们的阅读数和下一次的游标，游标为 0 表示结束
+	ScanDaily(ctx context.Context, day time.Time, cursor uint64, count int64) (map[int64]int64, uint64, error)
+	// CountVisitors day 这一天每篇文章的独立访客
+	CountVisitors(ctx context.Context, day time.Time, aids []int64) (map[int64]int64, error)
+	// GetDaily 一篇文章 day 这一天的数据，没有阅读的时候都是 0
+	GetDaily(ctx context.Context, aid int64, day time.Time) (domain.ArticleDailyViews, error)
+}
+
+type RedisArticleStatsCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisArticleStatsCache(client redis.Cmdable) ArticleStatsCache {
+	return &RedisArticleStatsCache{
+		client: client,
+	}
+}
+
+func (r *RedisArticleStatsCache) RecordRead(ctx context.Context, aid int64, reader string,
+	day time.Time, window time.Duration) (bool, error) {
+	res, err := r.client.Eval(ctx, luaRecordRead,
+		[]string{r.dedupeKey(aid, reader), r.readKey(day), r.visitorKey(aid, day)},
+		aid, reader, int64(window/time.Second), int64(articleStatsExpiration/time.Second)).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+func (r *RedisArticleStatsCache) ScanDaily(ctx context.Context, day time.Time,
+	cursor uint64, count int64) (map[int64]int64, uint64, error) {
+	// HSCAN 返回的是交替出现的字段和值
+	kvs, next, err := r.client.HScan(ctx, r.readKey(day), cursor, "", count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make(map[int64]int64, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		aid, err := strconv.ParseInt(kvs[i], 10, 64)
+		if err != nil {
+			continue
+		}
+		cnt, _ := strconv.ParseInt(kvs[i+1], 10, 64)
+		res[aid] = cnt
+	}
+	return res, next, nil
+}
+
+func (r *RedisArticleStatsCache) CountVisitors(ctx context.Context, day time.Time, aids []int64) (map[int64]int64, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, 0, len(aids))
+	for _, aid := range aids {
+		cmds = append(cmds, pipe.PFCount(ctx, r.visitorKey(aid, day)))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(aids))
+	for i, cmd := range cmds {
+		res[aids[i]] = cmd.Val()
+	}
+	return res, nil
+}
+
+func (r *RedisArticleStatsCache) GetDaily(ctx context.Context, aid int64, day time.Time) (domain.ArticleDailyViews, error) {
+	pipe := r.client.Pipeline()
+	readCmd := pipe.HGet(ctx, r.readKey(day), strconv.FormatInt(aid, 10))
+	uvCmd := pipe.PFCount(ctx, r.visitorKey(aid, day))
+	_, err := pipe.Exec(ctx)
+	// 当天没有阅读的时候 HGET 返回的是 redis.Nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return domain.ArticleDailyViews{}, err
+	}
+	readCnt, _ := readCmd.Int64()
+	return domain.ArticleDailyViews{
+		Day:     day,
+		ReadCnt: readCnt,
+		UV:      uvCmd.Val(),
+	}, nil
+}
+
+func (r *RedisArticleStatsCache) dedupeKey(aid int64, reader string) string {
+	return fmt.Sprintf("article:read:dedupe:%d:%s", aid, reader)
+}
+
+func (r *RedisArticleStatsCache) readKey(day time.Time) string {
+	return fmt.Sprintf("article:read:daily:%s", day.Format("20060102"))
+}
+
+func (r *RedisArticleStatsCache) visitorKey(aid int64, day time.Time) string {
+	return fmt.Sprintf("article:uv:%s:%d", day.Format("20060102"), aid)
+}
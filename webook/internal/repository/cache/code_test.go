@@ -46,7 +46,7 @@ func TestRedisCodeCache_Set(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			nc := NewCodeCache(tt.mock(ctrl))
+			nc := NewRedisCodeCache(tt.mock(ctrl))
 			err := nc.Set(tt.c, tt.biz, tt.phone, tt.code)
 			assert.Equal(t, tt.wantErr, err)
 		})
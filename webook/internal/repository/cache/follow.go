@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+)
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type FollowCache interface {
+	// StaticsInfo 缓存不存在的时候返回 ErrKeyNotExist
+	StaticsInfo(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStaticsInfo(ctx context.Context, uid int64, statics domain.FollowStatics) error
+	// Follow 如果缓存存在，更新双方的计数
+	Follow(ctx context.Context, follower, followee int64) error
+	CancelFollow(ctx context.Context, follower, followee int64) error
+}
+
+type RedisFollowCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisFollowCache(client redis.Cmdable) FollowCache {
+	return &RedisFollowCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisFollowCache) StaticsInfo(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	data, err := r.client.HGetAll(ctx, r.staticsKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(data) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	// 理论上来说，这里不可能有 error
+	followers, _ := strconv.ParseInt(data[fieldFollowers], 10, 64)
+	followees, _ := strconv.ParseInt(data[fieldFollowees], 10, 64)
+	return domain.FollowStatics{
+		Followers: followers,
+		Followees: followees,
+	}, nil
+}
+
+func (r *RedisFollowCache) SetStaticsInfo(ctx context.Context, uid int64, statics domain.FollowStatics) error {
+	key := r.staticsKey(uid)
+	err := r.client.HMSet(ctx, key, fieldFollowers, statics.Followers, fieldFollowees, statics.Followees).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+func (r *RedisFollowCache) Follow(ctx context.Context, follower, followee int64) error {
+	return r.updateStatics(ctx, follower, followee, 1)
+}
+
+func (r *RedisFollowCache) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return r.updateStatics(ctx, follower, followee, -1)
+}
+
+func (r *RedisFollowCache) updateStatics(ctx context.Context, follower, followee int64, delta int) error {
+	err := r.client.Eval(ctx, luaIncrCnt, []string{r.staticsKey(followee)}, fieldFollowers, delta).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.staticsKey(follower)}, fieldFollowees, delta).Err()
+}
+
+func (r *RedisFollowCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webook/internal/domain"
+)
+
+// newMiniRedis 用 miniredis 执行真正的 lua 脚本
+func newMiniRedis(t *testing.T) redis.Cmdable {
+	mr := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestRedisFollowCache_Follow(t *testing.T) {
+	ctx := context.Background()
+	c := NewRedisFollowCache(newMiniRedis(t))
+
+	// 缓存不存在的时候什么都不做
+	require.NoError(t, c.Follow(ctx, 1, 2))
+	_, err := c.StaticsInfo(ctx, 2)
+	assert.Equal(t, ErrKeyNotExist, err)
+
+	require.NoError(t, c.SetStaticsInfo(ctx, 1, domain.FollowStatics{Followers: 3, Followees: 4}))
+	require.NoError(t, c.SetStaticsInfo(ctx, 2, domain.FollowStatics{Followers: 5, Followees: 6}))
+	require.NoError(t, c.Follow(ctx, 1, 2))
+	s1, err := c.StaticsInfo(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FollowStatics{Followers: 3, Followees: 5}, s1)
+	s2, err := c.StaticsInfo(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FollowStatics{Followers: 6, Followees: 6}, s2)
+
+	require.NoError(t, c.CancelFollow(ctx, 1, 2))
+	s2, err = c.StaticsInfo(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FollowStatics{Followers: 5, Followees: 6}, s2)
+}
@@ -19,6 +19,9 @@ const (
 	fieldReadCnt    = "read_cnt"
 	fieldCollectCnt = "collect_cnt"
 	fieldLikeCnt    = "like_cnt"
+	fieldCommentCnt = "comment_cnt"
+	// fieldUtime 最近一次互动的时间，回写缓存和计数变化的时候都会更新
+	fieldUtime = "utime"
 )
 
 type InteractiveCache interface {
@@ -27,9 +30,15 @@ type InteractiveCache interface {
 	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrCommentCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	// DecrCommentCntIfPresent 删除根评论的时候回复也会一起删除，所以要指定减少多少
+	DecrCommentCntIfPresent(ctx context.Context, biz string, bizId int64, cnt int64) error
 	// Get 查询缓存中数据
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
 	Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error
+	// GetByIds 批量查询，没有命中缓存的 bizId 不会出现在结果里面
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
 }
 
 type RedisInteractiveCache struct {
@@ -44,19 +53,36 @@ func NewRedisInteractiveCache(client redis.Cmdable) InteractiveCache {
 }
 
 func (r *RedisInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)}, fieldCollectCnt, 1).Err()
+	return r.incr(ctx, biz, bizId, fieldCollectCnt, 1)
+}
+
+func (r *RedisInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incr(ctx, biz, bizId, fieldCollectCnt, -1)
+}
+
+func (r *RedisInteractiveCache) IncrCommentCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incr(ctx, biz, bizId, fieldCommentCnt, 1)
+}
+
+func (r *RedisInteractiveCache) DecrCommentCntIfPresent(ctx context.Context, biz string, bizId int64, cnt int64) error {
+	return r.incr(ctx, biz, bizId, fieldCommentCnt, -cnt)
 }
 
 func (r *RedisInteractiveCache) IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)}, fieldReadCnt, 1).Err()
+	return r.incr(ctx, biz, bizId, fieldReadCnt, 1)
 }
 
 func (r *RedisInteractiveCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)}, fieldLikeCnt, 1).Err()
+	return r.incr(ctx, biz, bizId, fieldLikeCnt, 1)
 }
 
 func (r *RedisInteractiveCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
-	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)}, fieldLikeCnt, -1).Err()
+	return r.incr(ctx, biz, bizId, fieldLikeCnt, -1)
+}
+
+func (r *RedisInteractiveCache) incr(ctx context.Context, biz string, bizId int64, field string, delta int64) error {
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)},
+		field, delta, time.Now().UnixMilli()).Err()
 }
 
 func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
@@ -71,22 +97,53 @@ func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64
 		return domain.Interactive{}, ErrKeyNotExist
 	}
 
+	return r.toDomain(data), nil
+}
+
+func (r *RedisInteractiveCache) GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	// 用 pipeline 把多个 HGETALL 合并成一次网络往返
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, 0, len(bizIds))
+	for _, bizId := range bizIds {
+		cmds = append(cmds, pipe.HGetAll(ctx, r.key(biz, bizId)))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(bizIds))
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 {
+			continue
+		}
+		res[bizIds[i]] = r.toDomain(data)
+	}
+	return res, nil
+}
+
+func (r *RedisInteractiveCache) toDomain(data map[string]string) domain.Interactive {
 	// 理论上来说，这里不可能有 error
 	collectCnt, _ := strconv.ParseInt(data[fieldCollectCnt], 10, 64)
 	likeCnt, _ := strconv.ParseInt(data[fieldLikeCnt], 10, 64)
 	readCnt, _ := strconv.ParseInt(data[fieldReadCnt], 10, 64)
+	commentCnt, _ := strconv.ParseInt(data[fieldCommentCnt], 10, 64)
+	utime, _ := strconv.ParseInt(data[fieldUtime], 10, 64)
 
 	return domain.Interactive{
 		// 懒惰的写法
 		CollectCnt: collectCnt,
 		LikeCnt:    likeCnt,
 		ReadCnt:    readCnt,
-	}, err
+		CommentCnt: commentCnt,
+		Utime:      time.UnixMilli(utime),
+	}
 }
 
 func (r *RedisInteractiveCache) Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error {
 	key := r.key(biz, bizId)
-	err := r.client.HMSet(ctx, key, fieldLikeCnt, intr.LikeCnt, fieldCollectCnt, intr.CollectCnt, fieldReadCnt, intr.ReadCnt).Err()
+	err := r.client.HMSet(ctx, key, fieldLikeCnt, intr.LikeCnt, fieldCollectCnt, intr.CollectCnt, fieldReadCnt, intr.ReadCnt,
+		fieldCommentCnt, intr.CommentCnt, fieldUtime, intr.Utime.UnixMilli()).Err()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webook/internal/domain"
+)
+
+func TestRedisInteractiveCache_IncrLikeCntIfPresent(t *testing.T) {
+	ctx := context.Background()
+	c := NewRedisInteractiveCache(newMiniRedis(t))
+
+	old := time.UnixMilli(time.Now().Add(-time.Hour).UnixMilli())
+	require.NoError(t, c.Set(ctx, "article", 1, domain.Interactive{LikeCnt: 2, Utime: old}))
+	require.NoError(t, c.IncrLikeCntIfPresent(ctx, "article", 1))
+	intr, err := c.Get(ctx, "article", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), intr.LikeCnt)
+	// 计数变了，最近一次互动的时间也要更新
+	assert.True(t, intr.Utime.After(old))
+}
@@ -0,0 +1,19 @@
+-- KEYS[1] 去重的 key，KEYS[2] 当天阅读数的 hash，KEYS[3] 当天独立访客的 HyperLogLog
+-- ARGV[1] 文章 ID，ARGV[2] 读者，ARGV[3] 去重窗口的秒数，ARGV[4] 统计数据的过期时间
+local reader = ARGV[2]
+local window = tonumber(ARGV[3])
+local counted = 1
+if reader ~= "" and window > 0 then
+    -- 已经有这个 key 说明在窗口里面读过了
+    if not redis.call("SET", KEYS[1], 1, "NX", "EX", window) then
+        counted = 0
+    end
+end
+-- 没有计数也要写这个字段，保证只有独立访客的文章也会被刷到数据库
+redis.call("HINCRBY", KEYS[2], ARGV[1], counted)
+redis.call("EXPIRE", KEYS[2], ARGV[4])
+if reader ~= "" then
+    redis.call("PFADD", KEYS[3], reader)
+    redis.call("EXPIRE", KEYS[3], ARGV[4])
+end
+return counted
@@ -1,11 +1,17 @@
 local key = KEYS[1]
 local cntKey = ARGV[1]
 local delta = tonumber(ARGV[2])
+local utime = ARGV[3]
 local exists = redis.call("EXISTS", key)
 if exists == 1 then
     redis.call("HINCRBY", key, cntKey, delta)
+    -- 热榜按照最近一次互动的时间衰减，所以计数变了也要更新时间
+    -- 关注和未读通知也用这个脚本，它们不传时间
+    if utime then
+        redis.call("HSET", key, "utime", utime)
+    end
     -- 说明自增成功了
     return 1
 else
     return 0
-end
\ No newline at end of file
+end
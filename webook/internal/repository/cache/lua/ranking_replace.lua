@@ -0,0 +1,13 @@
+-- 热榜的 key
+local key = KEYS[1]
+-- 读出来的时候的值
+local old = ARGV[1]
+local new = ARGV[2]
+
+-- 这期间热榜被重新计算过，新的热榜已经不包含要去掉的文章了，不用管
+if redis.call("get", key) ~= old then
+    return 0
+end
+-- 保留原来的过期时间
+redis.call("set", key, new, "keepttl")
+return 1
@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/cache/article_stats.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/cache/article_stats.go -package=cachemocks -destination=webook/internal/repository/cache/mocks/article_stats.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleStatsCache is a mock of ArticleStatsCache interface.
+type MockArticleStatsCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleStatsCacheMockRecorder
+}
+
+// MockArticleStatsCacheMockRecorder is the mock recorder for MockArticleStatsCache.
+type MockArticleStatsCacheMockRecorder struct {
+	mock *MockArticleStatsCache
+}
+
+// NewMockArticleStatsCache creates a new mock instance.
+func NewMockArticleStatsCache(ctrl *gomock.Controller) *MockArticleStatsCache {
+	mock := &MockArticleStatsCache{ctrl: ctrl}
+	mock.recorder = &MockArticleStatsCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleStatsCache) EXPECT() *MockArticleStatsCacheMockRecorder {
+	return m.recorder
+}
+
+// CountVisitors mocks base method.
+func (m *MockArticleStatsCache) CountVisitors(ctx context.Context, day time.Time, aids []int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVisitors", ctx, day, aids)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountVisitors indicates an expected call of CountVisitors.
+func (mr *MockArticleStatsCacheMockRecorder) CountVisitors(ctx, day, aids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVisitors", reflect.TypeOf((*MockArticleStatsCache)(nil).CountVisitors), ctx, day, aids)
+}
+
+// GetDaily mocks base method.
+func (m *MockArticleStatsCache) GetDaily(ctx context.Context, aid int64, day time.Time) (domain.ArticleDailyViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDaily", ctx, aid, day)
+	ret0, _ := ret[0].(domain.ArticleDailyViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDaily indicates an expected call of GetDaily.
+func (mr *MockArticleStatsCacheMockRecorder) GetDaily(ctx, aid, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDaily", reflect.TypeOf((*MockArticleStatsCache)(nil).GetDaily), ctx, aid, day)
+}
+
+// RecordRead mocks base method.
+func (m *MockArticleStatsCache) RecordRead(ctx context.Context, aid int64, reader string, day time.Time, window time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRead", ctx, aid, reader, day, window)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordRead indicates an expected call of RecordRead.
+func (mr *MockArticleStatsCacheMockRecorder) RecordRead(ctx, aid, reader, day, window any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRead", reflect.TypeOf((*MockArticleStatsCache)(nil).RecordRead), ctx, aid, reader, day, window)
+}
+
+// ScanDaily mocks base method.
+func (m *MockArticleStatsCache) ScanDaily(ctx context.Context, day time.Time, cursor uint64, count int64) (map[int64]int64, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanDaily", ctx, day, cursor, count)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ScanDaily indicates an expected call of ScanDaily.
+func (mr *MockArticleStatsCacheMockRecorder) ScanDaily(ctx, day, cursor, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanDaily", reflect.TypeOf((*MockArticleStatsCache)(nil).ScanDaily), ctx, day, cursor, count)
+}
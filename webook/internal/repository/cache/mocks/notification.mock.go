@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/cache/notification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/cache/notification.go -package=cachemocks -destination=webook/internal/repository/cache/mocks/notification.mock.go
+//
+
+// Package cachemocks is a generated GoMock package.
+package cachemocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationCache is a mock of NotificationCache interface.
+type MockNotificationCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationCacheMockRecorder
+}
+
+// MockNotificationCacheMockRecorder is the mock recorder for MockNotificationCache.
+type MockNotificationCacheMockRecorder struct {
+	mock *MockNotificationCache
+}
+
+// NewMockNotificationCache creates a new mock instance.
+func NewMockNotificationCache(ctrl *gomock.Controller) *MockNotificationCache {
+	mock := &MockNotificationCache{ctrl: ctrl}
+	mock.recorder = &MockNotificationCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationCache) EXPECT() *MockNotificationCacheMockRecorder {
+	return m.recorder
+}
+
+// DelUnreadCnt mocks base method.
+func (m *MockNotificationCache) DelUnreadCnt(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DelUnreadCnt", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DelUnreadCnt indicates an expected call of DelUnreadCnt.
+func (mr *MockNotificationCacheMockRecorder) DelUnreadCnt(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DelUnreadCnt", reflect.TypeOf((*MockNotificationCache)(nil).DelUnreadCnt), ctx, uid)
+}
+
+// IncrUnreadCntIfPresent mocks base method.
+func (m *MockNotificationCache) IncrUnreadCntIfPresent(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrUnreadCntIfPresent", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrUnreadCntIfPresent indicates an expected call of IncrUnreadCntIfPresent.
+func (mr *MockNotificationCacheMockRecorder) IncrUnreadCntIfPresent(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrUnreadCntIfPresent", reflect.TypeOf((*MockNotificationCache)(nil).IncrUnreadCntIfPresent), ctx, uid)
+}
+
+// Publish mocks base method.
+func (m *MockNotificationCache) Publish(ctx context.Context, n domain.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, n)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockNotificationCacheMockRecorder) Publish(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockNotificationCache)(nil).Publish), ctx, n)
+}
+
+// SetUnreadCnt mocks base method.
+func (m *MockNotificationCache) SetUnreadCnt(ctx context.Context, uid, cnt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUnreadCnt", ctx, uid, cnt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUnreadCnt indicates an expected call of SetUnreadCnt.
+func (mr *MockNotificationCacheMockRecorder) SetUnreadCnt(ctx, uid, cnt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUnreadCnt", reflect.TypeOf((*MockNotificationCache)(nil).SetUnreadCnt), ctx, uid, cnt)
+}
+
+// Subscribe mocks base method.
+func (m *MockNotificationCache) Subscribe(ctx context.Context) (<-chan domain.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(<-chan domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotificationCacheMockRecorder) Subscribe(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotificationCache)(nil).Subscribe), ctx)
+}
+
+// UnreadCnt mocks base method.
+func (m *MockNotificationCache) UnreadCnt(ctx context.Context, uid int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnreadCnt", ctx, uid)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnreadCnt indicates an expected call of UnreadCnt.
+func (mr *MockNotificationCacheMockRecorder) UnreadCnt(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnreadCnt", reflect.TypeOf((*MockNotificationCache)(nil).UnreadCnt), ctx, uid)
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+)
+
+const (
+	fieldUnreadCnt = "unread_cnt"
+	// channelNotification 所有实例都订阅这个频道，再推送给连接在自己身上的用户
+	channelNotification = "notification:push"
+)
+
+var ErrSubscribeNotSupported = errors.New("redis 客户端不支持订阅")
+
+type NotificationCache interface {
+	// UnreadCnt 缓存不存在的时候返回 ErrKeyNotExist
+	UnreadCnt(ctx context.Context, uid int64) (int64, error)
+	SetUnreadCnt(ctx context.Context, uid int64, cnt int64) error
+	// IncrUnreadCntIfPresent 如果缓存存在，未读数 +1
+	IncrUnreadCntIfPresent(ctx context.Context, uid int64) error
+	DelUnreadCnt(ctx context.Context, uid int64) error
+	// Publish 把新的通知广播给所有的实例
+	Publish(ctx context.Context, n domain.Notification) error
+	// Subscribe 订阅所有的新通知，ctx 结束之后返回的 channel 会被关闭
+	Subscribe(ctx context.Context) (<-chan domain.Notification, error)
+}
+
+type RedisNotificationCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisNotificationCache(client redis.Cmdable) NotificationCache {
+	return &RedisNotificationCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisNotificationCache) UnreadCnt(ctx context.Context, uid int64) (int64, error) {
+	val, err := r.client.HGet(ctx, r.unreadKey(uid), fieldUnreadCnt).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrKeyNotExist
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (r *RedisNotificationCache) SetUnreadCnt(ctx context.Context, uid int64, cnt int64) error {
+	key := r.unreadKey(uid)
+	if err := r.client.HSet(ctx, key, fieldUnreadCnt, cnt).Err(); err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+func (r *RedisNotificationCache) IncrUnreadCntIfPresent(ctx context.Context, uid int64) error {
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.unreadKey(uid)}, fieldUnreadCnt, 1).Err()
+}
+
+func (r *RedisNotificationCache) DelUnreadCnt(ctx context.Context, uid int64) error {
+	return r.client.Del(ctx, r.unreadKey(uid)).Err()
+}
+
+func (r *RedisNotificationCache) Publish(ctx context.Context, n domain.Notification) error {
+	val, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, channelNotification, val).Err()
+}
+
+func (r *RedisNotificationCache) Subscribe(ctx context.Context) (<-chan domain.Notification, error) {
+	// redis.Cmdable 里面没有订阅的方法，只有 redis.Client 之类的具体客户端才能订阅
+	sub, ok := r.client.(redis.UniversalClient)
+	if !ok {
+		return nil, ErrSubscribeNotSupported
+	}
+	ps := sub.Subscribe(ctx, channelNotification)
+	// 确认订阅成功
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, err
+	}
+	res := make(chan domain.Notification, 64)
+	go func() {
+		defer close(res)
+		defer ps.Close()
+		msgs := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var n domain.Notification
+				// 格式不对的消息直接丢弃
+				if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil {
+					continue
+				}
+				select {
+				case res <- n:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return res, nil
+}
+
+func (r *RedisNotificationCache) unreadKey(uid int64) string {
+	return fmt.Sprintf("notification:unread:%d", uid)
+}
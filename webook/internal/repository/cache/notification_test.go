@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisNotificationCache_IncrUnreadCntIfPresent(t *testing.T) {
+	ctx := context.Background()
+	c := NewRedisNotificationCache(newMiniRedis(t))
+
+	// 缓存不存在的时候什么都不做
+	require.NoError(t, c.IncrUnreadCntIfPresent(ctx, 1))
+	_, err := c.UnreadCnt(ctx, 1)
+	assert.Equal(t, ErrKeyNotExist, err)
+
+	require.NoError(t, c.SetUnreadCnt(ctx, 1, 2))
+	require.NoError(t, c.IncrUnreadCntIfPresent(ctx, 1))
+	cnt, err := c.UnreadCnt(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cnt)
+}
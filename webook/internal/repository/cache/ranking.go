@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/atomic"
+	"slices"
+	"time"
+	"webook/internal/domain"
+)
+
+var ErrLocalCacheExpired = errors.New("本地缓存已经过期")
+
+//go:embed lua/ranking_replace.lua
+var luaRankingReplace string
+
+type RankingCache interface {
+	Set(ctx context.Context, arts []domain.Article) error
+	Get(ctx context.Context) ([]domain.Article, error)
+}
+
+// RankingRedisCache 因为有本地缓存和 Redis 两个实现，所以这里返回具体类型
+type RankingRedisCache struct {
+	client     redis.Cmdable
+	key        string
+	expiration time.Duration
+}
+
+func NewRankingRedisCache(client redis.Cmdable) *RankingRedisCache {
+	return &RankingRedisCache{
+		client: client,
+		key:    "ranking:top_n",
+		// 比计算热榜的间隔长很多，即便任务失败了几次，也还有数据
+		expiration: time.Minute * 30,
+	}
+}
+
+func (r *RankingRedisCache) Set(ctx context.Context, arts []domain.Article) error {
+	// 复制一份再改，调用者还要把原来的放到本地缓存里面
+	res := slices.Clone(arts)
+	for i := range res {
+		// 热榜不需要内容，只缓存摘要
+		res[i].Content = ""
+	}
+	val, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key, val, r.expiration).Err()
+}
+
+func (r *RankingRedisCache) Get(ctx context.Context) ([]domain.Article, error) {
+	val, err := r.client.Get(ctx, r.key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+// Remove 从热榜里面去掉一篇文章，不改变过期时间
+// 读出来之后热榜被重新计算过的话就不再写回去，免得覆盖新的热榜
+func (r *RankingRedisCache) Remove(ctx context.Context, id int64) error {
+	val, err := r.client.Get(ctx, r.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var arts []domain.Article
+	if err = json.Unmarshal(val, &arts); err != nil {
+		return err
+	}
+	res := slices.DeleteFunc(slices.Clone(arts), func(art domain.Article) bool {
+		return art.Id == id
+	})
+	if len(res) == len(arts) {
+		return nil
+	}
+	newVal, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return r.client.Eval(ctx, luaRankingReplace, []string{r.key}, val, newVal).Err()
+}
+
+// RankingLocalCache 进程内的热榜缓存
+// 热榜只有一份数据，所以直接用 atomic.Value 就可以了
+type RankingLocalCache struct {
+	topN       *atomic.Value
+	ddl        *atomic.Time
+	expiration time.Duration
+}
+
+func NewRankingLocalCache() *RankingLocalCache {
+	topN := &atomic.Value{}
+	topN.Store([]domain.Article{})
+	return &RankingLocalCache{
+		topN:       topN,
+		ddl:        atomic.NewTime(time.Time{}),
+		expiration: time.Minute * 10,
+	}
+}
+
+func (r *RankingLocalCache) Set(ctx context.Context, arts []domain.Article) error {
+	r.topN.Store(arts)
+	r.ddl.Store(time.Now().Add(r.expiration))
+	return nil
+}
+
+func (r *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
+	ddl := r.ddl.Load()
+	arts := r.topN.Load().([]domain.Article)
+	if len(arts) == 0 || ddl.Before(time.Now()) {
+		return nil, ErrLocalCacheExpired
+	}
+	return arts, nil
+}
+
+// ForceGet 不检查过期时间，用于 Redis 不可用的时候兜底
+func (r *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
+	arts := r.topN.Load().([]domain.Article)
+	if len(arts) == 0 {
+		return nil, ErrKeyNotExist
+	}
+	return arts, nil
+}
+
+// Remove 从热榜里面去掉一篇文章，不改变过期时间
+func (r *RankingLocalCache) Remove(ctx context.Context, id int64) error {
+	arts := r.topN.Load().([]domain.Article)
+	r.topN.Store(slices.DeleteFunc(slices.Clone(arts), func(art domain.Article) bool {
+		return art.Id == id
+	}))
+	return nil
+}
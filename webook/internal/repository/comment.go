@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+// ErrCommentNotFound 评论不存在，或者已经被删除了
+var ErrCommentNotFound = dao.ErrDataNotFound
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, c domain.Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	FindRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]domain.Comment, error)
+	FindHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]domain.Comment, error)
+	FindReplies(ctx context.Context, rid, maxId int64, limit int) ([]domain.Comment, error)
+	DeleteComment(ctx context.Context, c domain.Comment) error
+}
+
+type CachedCommentRepository struct {
+	dao dao.CommentDAO
+	// 评论数和点赞数之类的放在一起，文章页面不需要额外查询
+	intrCache cache.InteractiveCache
+	l         logger.LoggerV1
+}
+
+func NewCachedCommentRepository(dao dao.CommentDAO, intrCache cache.InteractiveCache, l logger.LoggerV1) CommentRepository {
+	return &CachedCommentRepository{
+		dao:       dao,
+		intrCache: intrCache,
+		l:         l,
+	}
+}
+
+func (c *CachedCommentRepository) CreateComment(ctx context.Context, cmt domain.Comment) (int64, error) {
+	id, err := c.dao.Insert(ctx, c.toEntity(cmt))
+	if err != nil {
+		return 0, err
+	}
+	// 评论已经写进去了，计数不准确可以接受
+	if er := c.intrCache.IncrCommentCntIfPresent(ctx, cmt.Biz, cmt.BizId); er != nil {
+		c.l.Error("更新评论数缓存失败",
+			logger.String("biz", cmt.Biz),
+			logger.Int64("bizId", cmt.BizId),
+			logger.Error(er))
+	}
+	return id, nil
+}
+
+func (c *CachedCommentRepository) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	cmt, err := c.dao.FindById(ctx, id)
+	if errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return c.toDomain(0, cmt), nil
+}
+
+func (c *CachedCommentRepository) FindRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]domain.Comment, error) {
+	cmts, err := c.dao.FindRoots(ctx, biz, bizId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](cmts, c.toDomain), nil
+}
+
+func (c *CachedCommentRepository) FindHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]domain.Comment, error) {
+	cmts, err := c.dao.FindHotRoots(ctx, biz, bizId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](cmts, c.toDomain), nil
+}
+
+func (c *CachedCommentRepository) FindReplies(ctx context.Context, rid, maxId int64, limit int) ([]domain.Comment, error) {
+	cmts, err := c.dao.FindReplies(ctx, rid, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Comment, domain.Comment](cmts, c.toDomain), nil
+}
+
+func (c *CachedCommentRepository) DeleteComment(ctx context.Context, cmt domain.Comment) error {
+	cnt, err := c.dao.Delete(ctx, c.toEntity(cmt))
+	if err != nil {
+		return err
+	}
+	if er := c.intrCache.DecrCommentCntIfPresent(ctx, cmt.Biz, cmt.BizId, cnt); er != nil {
+		c.l.Error("更新评论数缓存失败",
+			logger.String("biz", cmt.Biz),
+			logger.Int64("bizId", cmt.BizId),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (c *CachedCommentRepository) toEntity(cmt domain.Comment) dao.Comment {
+	return dao.Comment{
+		Id:       cmt.Id,
+		Uid:      cmt.Uid,
+		Biz:      cmt.Biz,
+		BizId:    cmt.BizId,
+		RootId:   cmt.RootId,
+		ParentId: cmt.ParentId,
+		Content:  cmt.Content,
+	}
+}
+
+func (c *CachedCommentRepository) toDomain(idx int, cmt dao.Comment) domain.Comment {
+	return domain.Comment{
+		Id:       cmt.Id,
+		Uid:      cmt.Uid,
+		Biz:      cmt.Biz,
+		BizId:    cmt.BizId,
+		RootId:   cmt.RootId,
+		ParentId: cmt.ParentId,
+		Content:  cmt.Content,
+		ReplyCnt: cmt.ReplyCnt,
+		Ctime:    time.UnixMilli(cmt.Ctime),
+		Utime:    time.UnixMilli(cmt.Utime),
+	}
+}
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// AnalyticsDAO 作者后台的汇总数据，day 的格式都是 2006-01-02
+type AnalyticsDAO interface {
+	// IncrArticle 累加文章某一天的数据，同一个事务里面也累加作者那一天的数据
+	// evt 已经累加过的时候什么都不做
+	IncrArticle(ctx context.Context, evt AnalyticsEvent, delta ArticleDailyAnalytics) error
+	// IncrAuthor 累加作者某一天的数据，关注这种和文章无关的事件用，evt 已经累加过的时候什么都不做
+	IncrAuthor(ctx context.Context, evt AnalyticsEvent, delta AuthorDailyAnalytics) error
+	// FindArticleDaily [from, to] 之间有数据的日子，按照日期升序
+	FindArticleDaily(ctx context.Context, aid int64, from, to string) ([]ArticleDailyAnalytics, error)
+	// FindAuthorDaily [from, to] 之间有数据的日子，按照日期升序
+	FindAuthorDaily(ctx context.Context, uid int64, from, to string) ([]AuthorDailyAnalytics, error)
+	// SumByArticle 作者每一篇文章在 [from, to] 之间的总和，结果里面 Day 是空的
+	SumByArticle(ctx context.Context, uid int64, from, to string) ([]ArticleDailyAnalytics, error)
+}
+
+type GORMAnalyticsDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMAnalyticsDAO(db *gorm.DB) AnalyticsDAO {
+	return &GORMAnalyticsDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMAnalyticsDAO) IncrArticle(ctx context.Context, evt AnalyticsEvent, delta ArticleDailyAnalytics) error {
+	now := time.Now().UnixMilli()
+	delta.Ctime = now
+	delta.Utime = now
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := dao.record(tx, evt, now)
+		if err != nil || !first {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"like_cnt":    gorm.Expr("`like_cnt` + ?", delta.LikeCnt),
+				"collect_cnt": gorm.Expr("`collect_cnt` + ?", delta.CollectCnt),
+				"comment_cnt": gorm.Expr("`comment_cnt` + ?", delta.CommentCnt),
+				"utime":       now,
+			}),
+		}).Create(&delta).Error
+		if err != nil {
+			return err
+		}
+		return dao.incrAuthor(tx, AuthorDailyAnalytics{
+			Uid:        delta.AuthorId,
+			Day:        delta.Day,
+			LikeCnt:    delta.LikeCnt,
+			CollectCnt: delta.CollectCnt,
+			CommentCnt: delta.CommentCnt,
+			Ctime:      now,
+			Utime:      now,
+		})
+	})
+}
+
+func (dao *GORMAnalyticsDAO) IncrAuthor(ctx context.Context, evt AnalyticsEvent, delta AuthorDailyAnalytics) error {
+	now := time.Now().UnixMilli()
+	delta.Ctime = now
+	delta.Utime = now
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := dao.record(tx, evt, now)
+		if err != nil || !first {
+			return err
+		}
+		return dao.incrAuthor(tx, delta)
+	})
+}
+
+// record 记下这个事件，返回是不是第一次出现
+// Kafka 重复投递，或者点赞之后取消再点赞，都不能重复计算
+func (dao *GORMAnalyticsDAO) record(tx *gorm.DB, evt AnalyticsEvent, now int64) (bool, error) {
+	evt.Ctime = now
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&evt)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (dao *GORMAnalyticsDAO) incrAuthor(tx *gorm.DB, delta AuthorDailyAnalytics) error {
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"like_cnt":     gorm.Expr("`like_cnt` + ?", delta.LikeCnt),
+			"collect_cnt":  gorm.Expr("`collect_cnt` + ?", delta.CollectCnt),
+			"comment_cnt":  gorm.Expr("`comment_cnt` + ?", delta.CommentCnt),
+			"follower_cnt": gorm.Expr("`follower_cnt` + ?", delta.FollowerCnt),
+			"utime":        delta.Utime,
+		}),
+	}).Create(&delta).Error
+}
+
+func (dao *GORMAnalyticsDAO) FindArticleDaily(ctx context.Context, aid int64, from, to string) ([]ArticleDailyAnalytics, error) {
+	var res []ArticleDailyAnalytics
+	err := dao.db.WithContext(ctx).
+		Where("aid = ? AND day BETWEEN ? AND ?", aid, from, to).
+		Order("day ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMAnalyticsDAO) FindAuthorDaily(ctx context.Context, uid int64, from, to string) ([]AuthorDailyAnalytics, error) {
+	var res []AuthorDailyAnalytics
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND day BETWEEN ? AND ?", uid, from, to).
+		Order("day ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMAnalyticsDAO) SumByArticle(ctx context.Context, uid int64, from, to string) ([]ArticleDailyAnalytics, error) {
+	var res []ArticleDailyAnalytics
+	err := dao.db.WithContext(ctx).Model(&ArticleDailyAnalytics{}).
+		Select("aid, author_id, SUM(like_cnt) AS like_cnt, SUM(collect_cnt) AS collect_cnt, SUM(comment_cnt) AS comment_cnt").
+		Where("author_id = ? AND day BETWEEN ? AND ?", uid, from, to).
+		Group("aid, author_id").
+		Find(&res).Error
+	return res, err
+}
+
+// AnalyticsEvent 已经累加过的事件，唯一索引用来去重
+type AnalyticsEvent struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Type     uint8  `gorm:"uniqueIndex:evt"`
+	Actor    int64  `gorm:"uniqueIndex:evt"`
+	Biz      string `gorm:"type:varchar(64);uniqueIndex:evt"`
+	BizId    int64  `gorm:"uniqueIndex:evt"`
+	SourceId int64  `gorm:"uniqueIndex:evt"`
+	Ctime    int64
+}
+
+// ArticleDailyAnalytics 文章每天新增的点赞、收藏和评论，由消费者根据通知事件累加
+// 阅读数在 ArticleDailyStats 里面
+type ArticleDailyAnalytics struct {
+	Id  int64  `gorm:"primaryKey,autoIncrement"`
+	Aid int64  `gorm:"uniqueIndex:aid_day"`
+	Day string `gorm:"type:char(10);uniqueIndex:aid_day;index:author_day,priority:2"`
+	// AuthorId 文章排行按照作者查
+	AuthorId   int64 `gorm:"index:author_day,priority:1"`
+	LikeCnt    int64
+	CollectCnt int64
+	CommentCnt int64
+	Ctime      int64
+	Utime      int64
+}
+
+// AuthorDailyAnalytics 作者每天新增的数据，文章维度的数据也会累加到这里
+type AuthorDailyAnalytics struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Uid         int64  `gorm:"uniqueIndex:uid_day"`
+	Day         string `gorm:"type:char(10);uniqueIndex:uid_day"`
+	LikeCnt     int64
+	CollectCnt  int64
+	CommentCnt  int64
+	FollowerCnt int64
+	Ctime       int64
+	Utime       int64
+}
@@ -1,13 +1,28 @@
 package article
 
 type Article struct {
-	Id       int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
-	Title    string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
-	Content  string `gorm:"type=BLOB" bson:"content,omitempty"`
+	Id      int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	Title   string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
+	Content string `gorm:"type=BLOB" bson:"content,omitempty"`
+	// Abstract 保存的时候生成的摘要，列表只需要它，不用再渲染 Content
+	Abstract string `gorm:"type:varchar(1024)" bson:"abstract,omitempty"`
 	AuthorId int64  `gorm:"index" bson:"author_id,omitempty"`
-	Status   uint8  `bson:"status,omitempty"`
-	Ctime    int64  `bson:"ctime,omitempty"`
-	Utime    int64  `bson:"utime,omitempty"`
+	Status   uint8  `gorm:"index:status_publish_at" bson:"status,omitempty"`
+	// Revision 最新的版本号，线上库里面就是线上的版本号
+	Revision int64 `bson:"revision,omitempty"`
+	// Category 一篇文章只有一个分类
+	Category string `gorm:"type:varchar(64);index" bson:"category,omitempty"`
+	// Tags 标签存在单独的表里面，这里只是为了方便传递
+	// 写入的时候 nil 代表不修改标签，空切片代表清空标签
+	Tags []string `gorm:"-" bson:"-"`
+	// Cover 封面图片的 URL
+	Cover string `gorm:"type:varchar(512)" bson:"cover,omitempty"`
+	// PublishAt 定时发表的时间，定时任务按照 status 和 publish_at 来查找到期的文章
+	PublishAt int64 `gorm:"index:status_publish_at" bson:"publish_at,omitempty"`
+	// DeletedAt 移到回收站的时间，0 代表没有删除，清理任务按照它来找过期的文章
+	DeletedAt int64 `gorm:"index" bson:"deleted_at,omitempty"`
+	Ctime     int64 `bson:"ctime,omitempty"`
+	Utime     int64 `gorm:"index" bson:"utime,omitempty"` // 读者侧列表按照 utime 倒序翻页
 }
 
 // PublishedArticle 衍生类型，偷个懒
@@ -19,6 +34,72 @@ type PublishedArticleV1 struct {
 	Title    string `gorm:"type=varchar(4096)" bson:"title,omitempty"`
 	AuthorId int64  `gorm:"index" bson:"author_id,omitempty"`
 	Status   uint8  `bson:"status,omitempty"`
-	Ctime    int64  `bson:"ctime,omitempty"`
-	Utime    int64  `bson:"utime,omitempty"`
+	// DeletedAt 和 Article 一样，内容在 OSS 上，要等清理的时候才删除
+	DeletedAt int64 `bson:"deleted_at,omitempty"`
+	Ctime     int64 `bson:"ctime,omitempty"`
+	Utime     int64 `bson:"utime,omitempty"`
+}
+
+// ArticleRevision 每一次保存或者发表都会产生一个版本，写入之后不会再修改
+type ArticleRevision struct {
+	Id        int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	ArticleId int64  `gorm:"uniqueIndex:article_id_revision" bson:"article_id,omitempty"`
+	Revision  int64  `gorm:"uniqueIndex:article_id_revision" bson:"revision,omitempty"`
+	AuthorId  int64  `bson:"author_id,omitempty"`
+	Title     string `gorm:"type:varchar(4096)" bson:"title,omitempty"`
+	Content   string `gorm:"type:BLOB" bson:"content,omitempty"`
+	// Status 产生这个版本的时候文章的状态，用来区分是保存还是发表
+	Status uint8 `bson:"status,omitempty"`
+	Ctime  int64 `bson:"ctime,omitempty"`
+}
+
+// Tag 标签，名字是唯一的
+type Tag struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	Name  string `gorm:"type:varchar(64);uniqueIndex" bson:"name,omitempty"`
+	Ctime int64  `bson:"ctime,omitempty"`
+}
+
+// ArticleTag 制作库里面文章和标签的关系，按照 id 的顺序就是作者填写标签的顺序
+type ArticleTag struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	ArticleId int64 `gorm:"uniqueIndex:article_id_tag_id" bson:"article_id,omitempty"`
+	// TagId 按照标签查询文章列表的时候使用
+	TagId int64 `gorm:"uniqueIndex:article_id_tag_id;index" bson:"tag_id,omitempty"`
+	Ctime int64 `bson:"ctime,omitempty"`
+}
+
+// PublishedArticleTag 线上库里面文章和标签的关系，发表的时候从制作库同步过来
+type PublishedArticleTag ArticleTag
+
+// PubChange 线上库最后一次变化的时间，AuthorId 为 0 的是全站的
+// 发表、修改、撤回、删除和恢复都会更新，而且只会往后走，
+// 订阅源和站点地图的 Last-Modified 用它，撤回或者删除之后也不会变回更早的时间
+type PubChange struct {
+	AuthorId int64 `gorm:"primaryKey;autoIncrement:false" bson:"author_id"`
+	Utime    int64 `bson:"utime"`
+}
+
+// Series 专栏，读者能不能看到只看 Status，和章节自己的状态没关系
+type Series struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement" bson:"id,omitempty"`
+	AuthorId    int64  `gorm:"index" bson:"author_id,omitempty"`
+	Title       string `gorm:"type:varchar(256)" bson:"title,omitempty"`
+	Description string `gorm:"type:varchar(1024)" bson:"description,omitempty"`
+	Status      uint8  `bson:"status,omitempty"`
+	// ArticleIds 按照章节顺序的文章 ID
+	// GORM 的实现存在 SeriesArticle 里面，MongoDB 的实现直接存成数组
+	ArticleIds []int64 `gorm:"-" bson:"article_ids"`
+	Ctime      int64   `bson:"ctime,omitempty"`
+	Utime      int64   `bson:"utime,omitempty"`
+}
+
+// SeriesArticle 专栏和文章的关系，一篇文章最多属于一个专栏
+type SeriesArticle struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	SeriesId  int64 `gorm:"index"`
+	ArticleId int64 `gorm:"uniqueIndex"`
+	// Position 章节的顺序，从 0 开始
+	Position int
+	Ctime    int64
 }
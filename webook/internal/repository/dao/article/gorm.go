@@ -3,9 +3,19 @@ package article
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
 	"time"
+	"webook/internal/domain"
+)
+
+var (
+	statusPublished   = domain.ArticleStatusPublished.ToUint8()
+	statusUnpublished = domain.ArticleStatusUnpublished.ToUint8()
+	statusScheduled   = domain.ArticleStatusScheduled.ToUint8()
 )
 
 type GORMArticleDAO struct {
@@ -38,7 +48,7 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, author, id int64, sta
 		if res.RowsAffected != 1 {
 			return ErrPossibleIncorrectAuthor
 		}
-		return nil
+		return touchPub(tx, author, time.Now().UnixMilli())
 	})
 }
 
@@ -46,67 +56,141 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 	tx := dao.db.WithContext(ctx).Begin()
 	now := time.Now().UnixMilli()
 	defer tx.Rollback()
-	txDAO := NewGORMArticleDAO(tx)
-	var (
-		id  = art.Id
-		err error
-	)
-	if id == 0 {
-		id, err = txDAO.Insert(ctx, art)
+	var err error
+	if art.Id == 0 {
+		art, err = dao.insert(tx, art)
 	} else {
-		err = txDAO.UpdateById(ctx, art)
+		art.Revision, err = dao.updateById(tx, art)
 	}
 	if err != nil {
 		return 0, err
 	}
-	art.Id = id
+	if err = dao.syncTags(tx, art.Id, now); err != nil {
+		return 0, err
+	}
+	id := art.Id
+	if err = dao.upsertPub(tx, art, now); err != nil {
+		return 0, err
+	}
+	tx.Commit()
+	return id, tx.Error
+}
+
+// upsertPub 把制作库的文章写到线上库
+func (dao *GORMArticleDAO) upsertPub(tx *gorm.DB, art Article, now int64) error {
 	publishArt := PublishedArticle(art)
 	publishArt.Utime = now
 	publishArt.Ctime = now
-	err = tx.Clauses(clause.OnConflict{
+	err := tx.Clauses(clause.OnConflict{
 		// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
 		Columns: []clause.Column{{Name: "id"}},
 		DoUpdates: clause.Assignments(map[string]interface{}{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
+			"title":    art.Title,
+			"content":  art.Content,
+			"abstract": art.Abstract,
+			"status":   art.Status,
+			"category": art.Category,
+			"cover":    art.Cover,
+			"revision": art.Revision,
+			"utime":    now,
 		}),
 	}).Create(&publishArt).Error
 	if err != nil {
-		return 0, err
+		return err
 	}
-	tx.Commit()
-	return id, tx.Error
+	return touchPub(tx, art.AuthorId, now)
+}
+
+// touchPub 记录作者和全站的线上内容在 now 发生了变化，要和修改线上库在同一个事务里面
+// 先写作者再写全站，所有事务加锁的顺序都一样，不会死锁
+func touchPub(tx *gorm.DB, author, now int64) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "author_id"}},
+		// 几个实例的时钟不完全一样，只往后走
+		DoUpdates: clause.Assignments(map[string]any{
+			"utime": gorm.Expr("GREATEST(`utime`, VALUES(`utime`))"),
+		}),
+	}).Create(&[]PubChange{{AuthorId: author, Utime: now}, {AuthorId: 0, Utime: now}}).Error
+}
+
+func (dao *GORMArticleDAO) PublishScheduled(ctx context.Context, id, now int64) (Article, error) {
+	var art Article
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		art, err = dao.publishScheduled(tx, id, now)
+		if err != nil {
+			return err
+		}
+		return dao.upsertPub(tx, art, now)
+	})
+	return art, err
+}
+
+// publishScheduled 把到期的定时发表文章改成已发表，同步标签并且记录版本，返回制作库里面最新的内容
+// tx 必须是一个事务
+func (dao *GORMArticleDAO) publishScheduled(tx *gorm.DB, id, now int64) (Article, error) {
+	// 条件更新会锁住这一行，取消或者修改定时发表要么已经生效，没有更新到任何行，
+	// 要么等这个事务提交之后因为状态不对而失败
+	res := tx.Model(&Article{}).
+		Where("id = ? AND status = ? AND publish_at <= ? AND deleted_at = 0", id, statusScheduled, now).
+		Updates(map[string]any{
+			"status":     statusPublished,
+			"publish_at": 0,
+			"revision":   gorm.Expr("`revision` + 1"),
+			"utime":      now,
+		})
+	if res.Error != nil {
+		return Article{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return Article{}, ErrNotScheduled
+	}
+	var art Article
+	if err := tx.Where("id = ?", id).First(&art).Error; err != nil {
+		return Article{}, err
+	}
+	if err := dao.syncTags(tx, id, now); err != nil {
+		return Article{}, err
+	}
+	return art, tx.Create(dao.newRevision(art, now)).Error
 }
 
 func (dao *GORMArticleDAO) SyncClosure(ctx context.Context, art Article) (int64, error) {
 	var (
 		id = art.Id
 	)
-	err := dao.db.Transaction(func(tx *gorm.DB) error {
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var err error
 		now := time.Now().UnixMilli()
-		txDAO := NewGORMArticleDAO(tx)
 		if id == 0 {
-			id, err = txDAO.Insert(ctx, art)
+			art, err = dao.insert(tx, art)
 		} else {
-			err = txDAO.UpdateById(ctx, art)
+			art.Revision, err = dao.updateById(tx, art)
 		}
 		if err != nil {
 			return err
 		}
-		art.Id = id
-		publishArt := art
+		if err = dao.syncTags(tx, art.Id, now); err != nil {
+			return err
+		}
+		id = art.Id
+		publishArt := PublishedArticle(art)
 		publishArt.Utime = now
 		publishArt.Ctime = now
+		if err = touchPub(tx, art.AuthorId, now); err != nil {
+			return err
+		}
 		return tx.Clauses(clause.OnConflict{
 			// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":   art.Title,
-				"content": art.Content,
-				"utime":   now,
+				"title":    art.Title,
+				"content":  art.Content,
+				"abstract": art.Abstract,
+				"category": art.Category,
+				"cover":    art.Cover,
+				"revision": art.Revision,
+				"utime":    now,
 			}),
 		}).Create(&publishArt).Error
 	})
@@ -114,38 +198,201 @@ func (dao *GORMArticleDAO) SyncClosure(ctx context.Context, art Article) (int64,
 }
 
 func (dao *GORMArticleDAO) Insert(ctx context.Context, art Article) (int64, error) {
+	var err error
+	err = dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		art, err = dao.insert(tx, art)
+		return err
+	})
+	return art.Id, err
+}
+
+// insert 插入文章，同时记录第一个版本，tx 必须是一个事务
+func (dao *GORMArticleDAO) insert(tx *gorm.DB, art Article) (Article, error) {
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := dao.db.WithContext(ctx).Create(&art).Error
-	return art.Id, err
+	art.Revision = 1
+	if err := tx.Create(&art).Error; err != nil {
+		return Article{}, err
+	}
+	if err := dao.saveTags(tx, art.Id, art.Tags, now); err != nil {
+		return Article{}, err
+	}
+	return art, tx.Create(dao.newRevision(art, now)).Error
 }
 
-// UpdateById 只更新标题、内容和状态
+// UpdateById 只更新标题、内容、状态、分类、封面、标签和定时发表时间
 func (dao *GORMArticleDAO) UpdateById(ctx context.Context, art Article) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := dao.updateById(tx, art)
+		return err
+	})
+}
+
+// updateById 更新文章并且记录一个新的版本，返回新的版本号，tx 必须是一个事务
+// art.Revision 是乐观锁，只有数据库里面还是这个版本才会更新，版本号从 1 开始，0 永远都是冲突
+func (dao *GORMArticleDAO) updateById(tx *gorm.DB, art Article) (int64, error) {
 	now := time.Now().UnixMilli()
-	res := dao.db.Model(&Article{}).WithContext(ctx).
-		Where("id=? AND author_id = ? ", art.Id, art.AuthorId).
-		Updates(map[string]any{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   now,
-		})
+	// 回收站里面的文章不能再修改，否则还可能被重新发表出去
+	query := tx.Model(&Article{}).
+		Where("id=? AND author_id = ? AND deleted_at = 0 AND revision = ?", art.Id, art.AuthorId, art.Revision)
+	updates := map[string]any{
+		"title":    art.Title,
+		"content":  art.Content,
+		"abstract": art.Abstract,
+		"status":   art.Status,
+		"category": art.Category,
+		"cover":    art.Cover,
+		// 发表和定时发表的时候直接覆盖，发表的时候是 0，相当于清掉了之前的定时
+		"publish_at": art.PublishAt,
+		"revision":   gorm.Expr("`revision` + 1"),
+		"utime":      now,
+	}
+	if art.Status == statusUnpublished {
+		// 保存草稿不影响定时发表，publish_at 不变，定时发表的文章保持定时发表的状态
+		delete(updates, "publish_at")
+		updates["status"] = gorm.Expr("CASE WHEN `status` = ? THEN `status` ELSE ? END",
+			statusScheduled, statusUnpublished)
+	}
+	res := query.Updates(updates)
 	err := res.Error
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if res.RowsAffected == 0 {
-		return errors.New("更新数据失败")
+		return 0, dao.versionConflict(tx, art)
+	}
+	if err = dao.saveTags(tx, art.Id, art.Tags, now); err != nil {
+		return 0, err
+	}
+	// 在同一个事务里面，读到的就是自己刚刚更新的版本号
+	var cur Article
+	err = tx.Select("revision").Where("id = ?", art.Id).First(&cur).Error
+	if err != nil {
+		return 0, err
+	}
+	art.Revision = cur.Revision
+	return art.Revision, tx.Create(dao.newRevision(art, now)).Error
+}
+
+// versionConflict 区分是版本号不对，还是文章不存在或者不是这个作者的
+func (dao *GORMArticleDAO) versionConflict(tx *gorm.DB, art Article) error {
+	var cnt int64
+	err := tx.Model(&Article{}).
+		Where("id=? AND author_id = ? AND deleted_at = 0", art.Id, art.AuthorId).
+		Count(&cnt).Error
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return ErrVersionConflict
+	}
+	return errors.New("更新数据失败")
+}
+
+// saveTags 用 tags 覆盖制作库里面文章的标签，tags 为 nil 的时候什么也不做
+func (dao *GORMArticleDAO) saveTags(tx *gorm.DB, id int64, tags []string, now int64) error {
+	if tags == nil {
+		return nil
+	}
+	err := tx.Where("article_id = ?", id).Delete(&ArticleTag{}).Error
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	// 标签名字的唯一索引不区分大小写，Go 和 go 是同一个标签
+	seen := make(map[string]struct{}, len(tags))
+	tags = slice.FilterMap[string, string](tags, func(idx int, src string) (string, bool) {
+		key := strings.ToLower(src)
+		if _, ok := seen[key]; ok {
+			return "", false
+		}
+		seen[key] = struct{}{}
+		return src, true
+	})
+	tagIds, err := dao.tagIds(tx, tags, now)
+	if err != nil {
+		return err
+	}
+	rels := slice.Map[int64, ArticleTag](tagIds, func(idx int, src int64) ArticleTag {
+		return ArticleTag{ArticleId: id, TagId: src, Ctime: now}
+	})
+	return tx.Create(&rels).Error
+}
+
+// tagIds 不存在的标签会被创建出来，返回的 ID 和 names 的顺序一致
+// 按照不区分大小写的方式匹配已有的标签，和 MySQL 默认的排序规则一致，names 里面不能有只是大小写不同的重复标签
+func (dao *GORMArticleDAO) tagIds(tx *gorm.DB, names []string, now int64) ([]int64, error) {
+	tags := slice.Map[string, Tag](names, func(idx int, src string) Tag {
+		return Tag{Name: src, Ctime: now}
+	})
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	var found []Tag
+	err = tx.Where("name IN ?", names).Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]int64, len(found))
+	for _, t := range found {
+		m[strings.ToLower(t.Name)] = t.Id
+	}
+	res := make([]int64, 0, len(names))
+	for _, name := range names {
+		tid, ok := m[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("找不到标签 %s", name)
+		}
+		res = append(res, tid)
+	}
+	return res, nil
+}
+
+// syncTags 线上库的标签和制作库保持一致
+func (dao *GORMArticleDAO) syncTags(tx *gorm.DB, id int64, now int64) error {
+	var rels []ArticleTag
+	err := tx.Where("article_id = ?", id).Order("id ASC").Find(&rels).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("article_id = ?", id).Delete(&PublishedArticleTag{}).Error
+	if err != nil || len(rels) == 0 {
+		return err
+	}
+	pubs := slice.Map[ArticleTag, PublishedArticleTag](rels, func(idx int, src ArticleTag) PublishedArticleTag {
+		return PublishedArticleTag{ArticleId: id, TagId: src.TagId, Ctime: now}
+	})
+	return tx.Create(&pubs).Error
+}
+
+// findTags table 是 article_tags 或者 published_article_tags，决定了查询制作库还是线上库
+func (dao *GORMArticleDAO) findTags(ctx context.Context, table string, id int64) ([]string, error) {
+	var names []string
+	err := dao.db.WithContext(ctx).Table(table+" AS r").
+		Joins("JOIN tags ON tags.id = r.tag_id").
+		Where("r.article_id = ?", id).
+		Order("r.id ASC").
+		Pluck("tags.name", &names).Error
+	return names, err
+}
+
+func (dao *GORMArticleDAO) newRevision(art Article, now int64) *ArticleRevision {
+	return &ArticleRevision{
+		ArticleId: art.Id,
+		Revision:  art.Revision,
+		AuthorId:  art.AuthorId,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status,
+		Ctime:     now,
 	}
-	return nil
 }
 
 func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error) {
 	var arts []Article
 	err := dao.db.WithContext(ctx).Model(&Article{}).
-		Where("author_id = ?", author).
+		Where("author_id = ? AND deleted_at = 0", author).
 		Offset(offset).
 		Limit(limit).
 		// 升序排序 utime ASC
@@ -156,18 +403,292 @@ func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, author int64, offset
 	return arts, err
 }
 
+func (dao *GORMArticleDAO) GetIdsByAuthor(ctx context.Context, author, after int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("author_id = ? AND id > ? AND deleted_at = 0", author, after).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
 func (dao *GORMArticleDAO) GetById(ctx context.Context, id int64) (Article, error) {
 	var art Article
 	err := dao.db.WithContext(ctx).Model(&Article{}).
 		Where("id = ?", id).
 		First(&art).Error
+	if err != nil {
+		return art, err
+	}
+	art.Tags, err = dao.findTags(ctx, "article_tags", id)
 	return art, err
 }
 
 func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var pub PublishedArticle
 	err := dao.db.WithContext(ctx).
-		Where("id = ?", id).
+		Where("id = ? AND deleted_at = 0", id).
 		First(&pub).Error
+	if err != nil {
+		return pub, err
+	}
+	pub.Tags, err = dao.findTags(ctx, "published_article_tags", id)
 	return pub, err
 }
+
+func (dao *GORMArticleDAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Where("id IN ? AND status = ? AND deleted_at = 0", ids, statusPublished).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetPubTitlesByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Select("id", "title").
+		Where("id IN ? AND status = ? AND deleted_at = 0", ids, statusPublished).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) ListPub(ctx context.Context, utime, id int64, limit int) ([]PublishedArticle, error) {
+	db := dao.db.WithContext(ctx).Model(&PublishedArticle{})
+	return dao.listPub(db, utime, id, limit)
+}
+
+func (dao *GORMArticleDAO) ListPubByTag(ctx context.Context, tag string, utime, id int64, limit int) ([]PublishedArticle, error) {
+	db := dao.db.WithContext(ctx)
+	tagIds := db.Model(&Tag{}).Select("id").Where("name = ?", tag)
+	artIds := db.Model(&PublishedArticleTag{}).Select("article_id").Where("tag_id IN (?)", tagIds)
+	return dao.listPub(db.Model(&PublishedArticle{}).Where("id IN (?)", artIds), utime, id, limit)
+}
+
+func (dao *GORMArticleDAO) ListPubByCategory(ctx context.Context, category string, utime, id int64, limit int) ([]PublishedArticle, error) {
+	db := dao.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("category = ?", category)
+	return dao.listPub(db, utime, id, limit)
+}
+
+// ListPubByAuthor 作者主页的文章列表，分页方式和 ListPub 一样
+func (dao *GORMArticleDAO) ListPubByAuthor(ctx context.Context, author int64, utime, id int64, limit int) ([]PublishedArticle, error) {
+	db := dao.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("author_id = ?", author)
+	return dao.listPub(db, utime, id, limit)
+}
+
+// ScanPub 站点地图用，只查询 id 和 utime，按照 id 正序遍历
+func (dao *GORMArticleDAO) ScanPub(ctx context.Context, offset, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Select("id", "utime").
+		Where("status = ? AND deleted_at = 0", statusPublished).
+		Order("id ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetPubChange(ctx context.Context, author int64) (int64, error) {
+	var c PubChange
+	err := dao.db.WithContext(ctx).Where("author_id = ?", author).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return c.Utime, err
+}
+
+// CountPub 站点地图用来计算分成多少个文件
+func (dao *GORMArticleDAO) CountPub(ctx context.Context) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("status = ? AND deleted_at = 0", statusPublished).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+// listPub 读者侧列表的公共部分，db 里面带上了额外的查询条件
+func (dao *GORMArticleDAO) listPub(db *gorm.DB, utime, id int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	db = db.Where("status = ? AND deleted_at = 0", statusPublished)
+	if utime > 0 {
+		// 游标分页，utime 可能重复，所以要带上 id 来保证不重复不遗漏
+		db = db.Where("utime < ? OR (utime = ? AND id < ?)", utime, utime, id)
+	}
+	err := db.Order("utime DESC").Order("id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) PopularTags(ctx context.Context, limit int) ([]TagCnt, error) {
+	var res []TagCnt
+	err := dao.db.WithContext(ctx).Table("published_article_tags AS r").
+		Select("tags.id AS id, tags.name AS name, COUNT(*) AS cnt").
+		Joins("JOIN published_articles AS a ON a.id = r.article_id").
+		Joins("JOIN tags ON tags.id = r.tag_id").
+		// 仅自己可见的文章不算
+		Where("a.status = ? AND a.deleted_at = 0", statusPublished).
+		Group("tags.id, tags.name").
+		Order("cnt DESC").
+		Limit(limit).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) UpdateSchedule(ctx context.Context, author, id, publishAt int64) error {
+	// 改了状态也要加版本号，别的标签页里面打开的草稿再保存的时候才会冲突
+	updates := map[string]any{
+		"publish_at": publishAt,
+		"revision":   gorm.Expr("`revision` + 1"),
+		"utime":      time.Now().UnixMilli(),
+	}
+	if publishAt == 0 {
+		updates["status"] = statusUnpublished
+	}
+	// 带上 status 条件，防止和定时任务并发的时候把已经发表的文章改回去
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ? AND deleted_at = 0", id, author, statusScheduled).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotScheduled
+	}
+	return nil
+}
+
+func (dao *GORMArticleDAO) ListDueScheduled(ctx context.Context, now, publishAt, id int64, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ? AND deleted_at = 0", statusScheduled, now).
+		Where("publish_at > ? OR (publish_at = ? AND id > ?)", publishAt, publishAt, id).
+		Order("publish_at ASC, id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetRevisions(ctx context.Context, author, id int64, offset, limit int) ([]ArticleRevision, error) {
+	var res []ArticleRevision
+	err := dao.db.WithContext(ctx).
+		// 列表不需要内容
+		Select("id", "article_id", "revision", "author_id", "title", "status", "ctime").
+		Where("article_id = ? AND author_id = ?", id, author).
+		Order("revision DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetRevision(ctx context.Context, author, id, revision int64) (ArticleRevision, error) {
+	var res ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Where("article_id = ? AND author_id = ? AND revision = ?", id, author, revision).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) SoftDelete(ctx context.Context, author, id, now int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return dao.softDelete(tx, author, id, now)
+	})
+}
+
+// softDelete 在 tx 里面把制作库和线上库的文章移到回收站，S3DAO 会在同一个事务里面再处理自己的线上库
+func (dao *GORMArticleDAO) softDelete(tx *gorm.DB, author, id, now int64) error {
+	res := tx.Model(&Article{}).
+		Where("id = ? AND author_id = ? AND deleted_at = 0", id, author).
+		Updates(map[string]any{"deleted_at": now, "utime": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	// 没有发表过的文章在线上库里面没有数据，所以不检查影响的行数
+	// utime 保持不变，恢复之后还在读者列表原来的位置
+	res = tx.Model(&PublishedArticle{}).
+		Where("id = ? AND author_id = ?", id, author).
+		Update("deleted_at", now)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+	return touchPub(tx, author, now)
+}
+
+func (dao *GORMArticleDAO) Restore(ctx context.Context, author, id, since int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return dao.restore(tx, author, id, since)
+	})
+}
+
+// restore 和 softDelete 一样，由调用者开启事务
+func (dao *GORMArticleDAO) restore(tx *gorm.DB, author, id, since int64) error {
+	res := tx.Model(&Article{}).
+		Where("id = ? AND author_id = ? AND deleted_at >= ? AND deleted_at > 0", id, author, since).
+		Updates(map[string]any{"deleted_at": 0, "utime": time.Now().UnixMilli()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrNotInRecycleBin
+	}
+	res = tx.Model(&PublishedArticle{}).
+		Where("id = ? AND author_id = ?", id, author).
+		Update("deleted_at", 0)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+	return touchPub(tx, author, time.Now().UnixMilli())
+}
+
+func (dao *GORMArticleDAO) ListDeleted(ctx context.Context, author, since int64, offset, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ? AND deleted_at >= ? AND deleted_at > 0", author, since).
+		Order("deleted_at DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) ListPurgeable(ctx context.Context, before int64, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("deleted_at > 0 AND deleted_at < ?", before).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) Purge(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := dao.purge(tx, id)
+		return err
+	})
+}
+
+// purge 返回是否真的删除了，tx 必须是一个事务
+// 标签本身可能还有别的文章在用，所以只删除关系
+func (dao *GORMArticleDAO) purge(tx *gorm.DB, id int64) (bool, error) {
+	res := tx.Where("id = ? AND deleted_at > 0", id).Delete(&Article{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 已经被清理过了，或者在清理之前被恢复了
+		return false, nil
+	}
+	// 专栏里面也要去掉这篇文章
+	for _, model := range []any{&PublishedArticleTag{}, &ArticleTag{}, &ArticleRevision{}, &SeriesArticle{}} {
+		if err := tx.Where("article_id = ?", id).Delete(model).Error; err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Where("id = ?", id).Delete(&PublishedArticle{}).Error
+}
@@ -40,6 +40,21 @@ func (m *MockArticleDAO) EXPECT() *MockArticleDAOMockRecorder {
 	return m.recorder
 }
 
+// CountPub mocks base method.
+func (m *MockArticleDAO) CountPub(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPub", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPub indicates an expected call of CountPub.
+func (mr *MockArticleDAOMockRecorder) CountPub(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPub", reflect.TypeOf((*MockArticleDAO)(nil).CountPub), ctx)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockArticleDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]article.Article, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +85,21 @@ func (mr *MockArticleDAOMockRecorder) GetById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleDAO)(nil).GetById), ctx, id)
 }
 
+// GetIdsByAuthor mocks base method.
+func (m *MockArticleDAO) GetIdsByAuthor(ctx context.Context, author, after int64, limit int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdsByAuthor", ctx, author, after, limit)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdsByAuthor indicates an expected call of GetIdsByAuthor.
+func (mr *MockArticleDAOMockRecorder) GetIdsByAuthor(ctx, author, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdsByAuthor", reflect.TypeOf((*MockArticleDAO)(nil).GetIdsByAuthor), ctx, author, after, limit)
+}
+
 // GetPubById mocks base method.
 func (m *MockArticleDAO) GetPubById(ctx context.Context, id int64) (article.PublishedArticle, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +115,81 @@ func (mr *MockArticleDAOMockRecorder) GetPubById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleDAO)(nil).GetPubById), ctx, id)
 }
 
+// GetPubByIds mocks base method.
+func (m *MockArticleDAO) GetPubByIds(ctx context.Context, ids []int64) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubByIds", ctx, ids)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubByIds indicates an expected call of GetPubByIds.
+func (mr *MockArticleDAOMockRecorder) GetPubByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubByIds", reflect.TypeOf((*MockArticleDAO)(nil).GetPubByIds), ctx, ids)
+}
+
+// GetPubChange mocks base method.
+func (m *MockArticleDAO) GetPubChange(ctx context.Context, author int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubChange", ctx, author)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubChange indicates an expected call of GetPubChange.
+func (mr *MockArticleDAOMockRecorder) GetPubChange(ctx, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubChange", reflect.TypeOf((*MockArticleDAO)(nil).GetPubChange), ctx, author)
+}
+
+// GetPubTitlesByIds mocks base method.
+func (m *MockArticleDAO) GetPubTitlesByIds(ctx context.Context, ids []int64) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubTitlesByIds", ctx, ids)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubTitlesByIds indicates an expected call of GetPubTitlesByIds.
+func (mr *MockArticleDAOMockRecorder) GetPubTitlesByIds(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubTitlesByIds", reflect.TypeOf((*MockArticleDAO)(nil).GetPubTitlesByIds), ctx, ids)
+}
+
+// GetRevision mocks base method.
+func (m *MockArticleDAO) GetRevision(ctx context.Context, author, id, revision int64) (article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, author, id, revision)
+	ret0, _ := ret[0].(article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockArticleDAOMockRecorder) GetRevision(ctx, author, id, revision any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockArticleDAO)(nil).GetRevision), ctx, author, id, revision)
+}
+
+// GetRevisions mocks base method.
+func (m *MockArticleDAO) GetRevisions(ctx context.Context, author, id int64, offset, limit int) ([]article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", ctx, author, id, offset, limit)
+	ret0, _ := ret[0].([]article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockArticleDAOMockRecorder) GetRevisions(ctx, author, id, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockArticleDAO)(nil).GetRevisions), ctx, author, id, offset, limit)
+}
+
 // Insert mocks base method.
 func (m *MockArticleDAO) Insert(ctx context.Context, art article.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +205,198 @@ func (mr *MockArticleDAOMockRecorder) Insert(ctx, art any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleDAO)(nil).Insert), ctx, art)
 }
 
+// ListDeleted mocks base method.
+func (m *MockArticleDAO) ListDeleted(ctx context.Context, author, since int64, offset, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, author, since, offset, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockArticleDAOMockRecorder) ListDeleted(ctx, author, since, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockArticleDAO)(nil).ListDeleted), ctx, author, since, offset, limit)
+}
+
+// ListDueScheduled mocks base method.
+func (m *MockArticleDAO) ListDueScheduled(ctx context.Context, now, publishAt, id int64, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueScheduled", ctx, now, publishAt, id, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueScheduled indicates an expected call of ListDueScheduled.
+func (mr *MockArticleDAOMockRecorder) ListDueScheduled(ctx, now, publishAt, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueScheduled", reflect.TypeOf((*MockArticleDAO)(nil).ListDueScheduled), ctx, now, publishAt, id, limit)
+}
+
+// ListPub mocks base method.
+func (m *MockArticleDAO) ListPub(ctx context.Context, utime, id int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPub", ctx, utime, id, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPub indicates an expected call of ListPub.
+func (mr *MockArticleDAOMockRecorder) ListPub(ctx, utime, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleDAO)(nil).ListPub), ctx, utime, id, limit)
+}
+
+// ListPubByAuthor mocks base method.
+func (m *MockArticleDAO) ListPubByAuthor(ctx context.Context, author, utime, id int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByAuthor", ctx, author, utime, id, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByAuthor indicates an expected call of ListPubByAuthor.
+func (mr *MockArticleDAOMockRecorder) ListPubByAuthor(ctx, author, utime, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByAuthor", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByAuthor), ctx, author, utime, id, limit)
+}
+
+// ListPubByCategory mocks base method.
+func (m *MockArticleDAO) ListPubByCategory(ctx context.Context, category string, utime, id int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByCategory", ctx, category, utime, id, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByCategory indicates an expected call of ListPubByCategory.
+func (mr *MockArticleDAOMockRecorder) ListPubByCategory(ctx, category, utime, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByCategory", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByCategory), ctx, category, utime, id, limit)
+}
+
+// ListPubByTag mocks base method.
+func (m *MockArticleDAO) ListPubByTag(ctx context.Context, tag string, utime, id int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, utime, id, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleDAOMockRecorder) ListPubByTag(ctx, tag, utime, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleDAO)(nil).ListPubByTag), ctx, tag, utime, id, limit)
+}
+
+// ListPurgeable mocks base method.
+func (m *MockArticleDAO) ListPurgeable(ctx context.Context, before int64, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPurgeable", ctx, before, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPurgeable indicates an expected call of ListPurgeable.
+func (mr *MockArticleDAOMockRecorder) ListPurgeable(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPurgeable", reflect.TypeOf((*MockArticleDAO)(nil).ListPurgeable), ctx, before, limit)
+}
+
+// PopularTags mocks base method.
+func (m *MockArticleDAO) PopularTags(ctx context.Context, limit int) ([]article.TagCnt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopularTags", ctx, limit)
+	ret0, _ := ret[0].([]article.TagCnt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopularTags indicates an expected call of PopularTags.
+func (mr *MockArticleDAOMockRecorder) PopularTags(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopularTags", reflect.TypeOf((*MockArticleDAO)(nil).PopularTags), ctx, limit)
+}
+
+// PublishScheduled mocks base method.
+func (m *MockArticleDAO) PublishScheduled(ctx context.Context, id, now int64) (article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishScheduled", ctx, id, now)
+	ret0, _ := ret[0].(article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishScheduled indicates an expected call of PublishScheduled.
+func (mr *MockArticleDAOMockRecorder) PublishScheduled(ctx, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishScheduled", reflect.TypeOf((*MockArticleDAO)(nil).PublishScheduled), ctx, id, now)
+}
+
+// Purge mocks base method.
+func (m *MockArticleDAO) Purge(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockArticleDAOMockRecorder) Purge(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockArticleDAO)(nil).Purge), ctx, id)
+}
+
+// Restore mocks base method.
+func (m *MockArticleDAO) Restore(ctx context.Context, author, id, since int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, author, id, since)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockArticleDAOMockRecorder) Restore(ctx, author, id, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockArticleDAO)(nil).Restore), ctx, author, id, since)
+}
+
+// ScanPub mocks base method.
+func (m *MockArticleDAO) ScanPub(ctx context.Context, offset, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanPub", ctx, offset, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanPub indicates an expected call of ScanPub.
+func (mr *MockArticleDAOMockRecorder) ScanPub(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanPub", reflect.TypeOf((*MockArticleDAO)(nil).ScanPub), ctx, offset, limit)
+}
+
+// SoftDelete mocks base method.
+func (m *MockArticleDAO) SoftDelete(ctx context.Context, author, id, now int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDelete", ctx, author, id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDelete indicates an expected call of SoftDelete.
+func (mr *MockArticleDAOMockRecorder) SoftDelete(ctx, author, id, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDelete", reflect.TypeOf((*MockArticleDAO)(nil).SoftDelete), ctx, author, id, now)
+}
+
 // Sync mocks base method.
 func (m *MockArticleDAO) Sync(ctx context.Context, art article.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -142,3 +439,17 @@ func (mr *MockArticleDAOMockRecorder) UpdateById(ctx, art any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockArticleDAO)(nil).UpdateById), ctx, art)
 }
+
+// UpdateSchedule mocks base method.
+func (m *MockArticleDAO) UpdateSchedule(ctx context.Context, author, id, publishAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", ctx, author, id, publishAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockArticleDAOMockRecorder) UpdateSchedule(ctx, author, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockArticleDAO)(nil).UpdateSchedule), ctx, author, id, publishAt)
+}
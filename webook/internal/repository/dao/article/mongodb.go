@@ -11,10 +11,25 @@ import (
 	"time"
 )
 
+var _ ArticleDAO = (*MongoDBDAO)(nil)
+
+// notDeleted 没有删除的文章没有 deleted_at 字段，恢复的时候也会把这个字段去掉
+var notDeleted = bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$not", Value: bson.D{bson.E{Key: "$gt", Value: 0}}}}}
+
 type MongoDBDAO struct {
 	col     *mongo.Collection
 	liveCol *mongo.Collection
-	node    *snowflake.Node // 雪花算法
+	// revCol 文章的历史版本
+	revCol *mongo.Collection
+	// tagCol 标签，tagRelCol 和 liveTagRelCol 分别是制作库和线上库里面文章和标签的关系
+	tagCol        *mongo.Collection
+	tagRelCol     *mongo.Collection
+	liveTagRelCol *mongo.Collection
+	// seriesCol 专栏，彻底删除文章的时候要把文章从专栏里面去掉
+	seriesCol *mongo.Collection
+	// changeCol 线上库最后一次变化的时间，和 GORM 的 PubChange 一样
+	changeCol *mongo.Collection
+	node      *snowflake.Node // 雪花算法
 }
 
 func InitCollections(db *mongo.Database) error {
@@ -37,6 +52,105 @@ func InitCollections(db *mongo.Database) error {
 		return err
 	}
 	_, err = db.Collection("published_articles").Indexes().CreateMany(ctx, index)
+	if err != nil {
+		return err
+	}
+	// 读者侧列表按照 utime, id 倒序翻页
+	_, err = db.Collection("published_articles").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "utime", Value: -1},
+			bson.E{Key: "id", Value: -1},
+		},
+		Options: options.Index(),
+	})
+	if err != nil {
+		return err
+	}
+	// 清理回收站的任务按照 deleted_at 查找过期的文章
+	_, err = db.Collection("articles").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "deleted_at", Value: 1}},
+		// 大部分文章没有删除，没有这个字段
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		return err
+	}
+	// 定时发表的任务按照 status 和 publish_at 查找到期的文章
+	_, err = db.Collection("articles").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "status", Value: 1},
+			bson.E{Key: "publish_at", Value: 1},
+		},
+		Options: options.Index(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("article_revisions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{bson.E{Key: "article_id", Value: 1},
+			bson.E{Key: "revision", Value: -1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("tags").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{bson.E{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	tagRelIndex := []mongo.IndexModel{
+		{
+			Keys: bson.D{bson.E{Key: "article_id", Value: 1},
+				bson.E{Key: "tag_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// 按照标签查询文章列表
+			Keys: bson.D{bson.E{Key: "tag_id", Value: 1}},
+		},
+	}
+	_, err = db.Collection("article_tags").Indexes().CreateMany(ctx, tagRelIndex)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("published_article_tags").Indexes().CreateMany(ctx, tagRelIndex)
+	if err != nil {
+		return err
+	}
+	_, err = db.Collection("series").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{bson.E{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{bson.E{Key: "author_id", Value: 1},
+				bson.E{Key: "ctime", Value: -1},
+			},
+		},
+		{
+			// 按照文章查询所在的专栏，空数组的专栏很多，所以没办法做成唯一索引
+			Keys: bson.D{bson.E{Key: "article_ids", Value: 1}},
+		},
+		{
+			// 一篇文章只能在一个专栏里面，由这个索引保证
+			// 空数组不满足过滤条件，不会进索引；查询不会用到部分索引，所以和上面的索引分开，用倒序区分
+			Keys: bson.D{bson.E{Key: "article_ids", Value: -1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{bson.E{Key: "article_ids",
+					Value: bson.D{bson.E{Key: "$type", Value: "long"}}}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// 并发 upsert 的时候靠唯一索引保证每个作者只有一条
+	_, err = db.Collection("pub_changes").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{bson.E{Key: "author_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
 	return err
 }
 
@@ -44,64 +158,171 @@ func NewMongoDBDAO(db *mongo.Database, node *snowflake.Node) *MongoDBDAO {
 	return &MongoDBDAO{
 		col:     db.Collection("articles"),
 		liveCol: db.Collection("published_articles"),
-		node:    node,
+		revCol:  db.Collection("article_revisions"),
+		tagCol:  db.Collection("tags"),
+		// 和 GORM 的表名保持一致
+		tagRelCol:     db.Collection("article_tags"),
+		liveTagRelCol: db.Collection("published_article_tags"),
+		seriesCol:     db.Collection("series"),
+		changeCol:     db.Collection("pub_changes"),
+		node:          node,
 	}
 }
 
 func (m *MongoDBDAO) Insert(ctx context.Context, art Article) (int64, error) {
+	art, err := m.insert(ctx, art)
+	return art.Id, err
+}
+
+func (m *MongoDBDAO) insert(ctx context.Context, art Article) (Article, error) {
 	art.Id = m.node.Generate().Int64()
 	now := time.Now().UnixMilli()
 	art.Utime = now
 	art.Ctime = now
-	_, err := m.col.InsertOne(ctx, art)
-	return art.Id, err
+	art.Revision = 1
+	if _, err := m.col.InsertOne(ctx, art); err != nil {
+		return Article{}, err
+	}
+	if err := m.saveTags(ctx, art.Id, art.Tags, now); err != nil {
+		return Article{}, err
+	}
+	return art, m.insertRevision(ctx, art, now)
 }
 
 func (m *MongoDBDAO) UpdateById(ctx context.Context, art Article) error {
-	filter := bson.D{bson.E{Key: "id", Value: art.Id}, bson.E{Key: "author_id", Value: art.AuthorId}}
+	_, err := m.updateById(ctx, art)
+	return err
+}
+
+// updateById 返回新的版本号，art.Revision 是乐观锁，和 GORM 的实现一样
+func (m *MongoDBDAO) updateById(ctx context.Context, art Article) (int64, error) {
+	now := time.Now().UnixMilli()
+	// 回收站里面的文章不能再修改
+	filter := bson.D{bson.E{Key: "id", Value: art.Id}, bson.E{Key: "author_id", Value: art.AuthorId}, notDeleted,
+		bson.E{Key: "revision", Value: art.Revision}}
+	status, publishAt := art.Status, art.PublishAt
+	if art.Status == statusUnpublished {
+		// 保存草稿不影响定时发表。先查出现在的状态，再带上状态作为条件更新，中间状态变了的话更新失败
+		var cur Article
+		err := m.col.FindOne(ctx, filter, options.FindOne().
+			SetProjection(bson.D{bson.E{Key: "status", Value: 1}, bson.E{Key: "publish_at", Value: 1}})).
+			Decode(&cur)
+		if err == nil {
+			filter = append(filter, bson.E{Key: "status", Value: cur.Status})
+			if cur.Status == statusScheduled {
+				status, publishAt = cur.Status, cur.PublishAt
+			}
+		}
+	}
 	sets := bson.D{bson.E{Key: "$set",
 		// 这里你可以考虑直接使用整个 art，因为会忽略零值。
 		// 参考 Sync 中的写法
 		// 但是我一般都喜欢显式指定要被更新的字段，确保可读性和可维护性
 		Value: bson.D{bson.E{Key: "title", Value: art.Title},
 			bson.E{Key: "content", Value: art.Content},
-			bson.E{Key: "status", Value: art.Status},
-			bson.E{Key: "utime", Value: time.Now().UnixMilli()},
-		}}}
-	res, err := m.col.UpdateOne(ctx, filter, sets)
-	if err != nil {
-		return err
+			bson.E{Key: "abstract", Value: art.Abstract},
+			bson.E{Key: "status", Value: status},
+			bson.E{Key: "category", Value: art.Category},
+			bson.E{Key: "cover", Value: art.Cover},
+			bson.E{Key: "publish_at", Value: publishAt},
+			bson.E{Key: "utime", Value: now},
+		}},
+		bson.E{Key: "$inc", Value: bson.D{bson.E{Key: "revision", Value: 1}}},
 	}
-	if res.MatchedCount != 1 {
+	var cur Article
+	err := m.col.FindOneAndUpdate(ctx, filter, sets,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&cur)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		cnt, er := m.col.CountDocuments(ctx, filter[:3])
+		if er != nil {
+			return 0, er
+		}
+		if cnt > 0 {
+			return 0, ErrVersionConflict
+		}
 		// 比较可能就是有人更新别人的文章，比如说攻击者跟你过不去
-		return errors.New("更新失败")
+		return 0, errors.New("更新失败")
 	}
-	return nil
+	if err != nil {
+		return 0, err
+	}
+	art.Revision = cur.Revision
+	if err = m.saveTags(ctx, art.Id, art.Tags, now); err != nil {
+		return 0, err
+	}
+	return art.Revision, m.insertRevision(ctx, art, now)
+}
+
+// insertRevision MongoDB 这里没有用事务，版本写入失败的时候文章本身已经更新了
+func (m *MongoDBDAO) insertRevision(ctx context.Context, art Article, now int64) error {
+	_, err := m.revCol.InsertOne(ctx, ArticleRevision{
+		Id:        m.node.Generate().Int64(),
+		ArticleId: art.Id,
+		Revision:  art.Revision,
+		AuthorId:  art.AuthorId,
+		Title:     art.Title,
+		Content:   art.Content,
+		Status:    art.Status,
+		Ctime:     now,
+	})
+	return err
 }
 
 func (m *MongoDBDAO) Sync(ctx context.Context, art Article) (int64, error) {
-	var (
-		id  = art.Id
-		err error
-	)
-	if id > 0 {
-		err = m.UpdateById(ctx, art)
+	var err error
+	if art.Id > 0 {
+		art.Revision, err = m.updateById(ctx, art)
 	} else {
-		id, err = m.Insert(ctx, art)
+		art, err = m.insert(ctx, art)
 	}
 	if err != nil {
+		return art.Id, err
+	}
+	id := art.Id
+	now := time.Now().UnixMilli()
+	if err = m.syncTags(ctx, id, now); err != nil {
 		return id, err
 	}
-	art.Id = id
+	return id, m.upsertPub(ctx, art, now)
+}
+
+// upsertPub 把制作库的文章写到线上库
+func (m *MongoDBDAO) upsertPub(ctx context.Context, art Article, now int64) error {
 	filter := bson.D{bson.E{Key: "id", Value: art.Id}, bson.E{Key: "author_id", Value: art.AuthorId}}
-	now := time.Now().UnixMilli()
 	art.Utime = now
-	_, err = m.liveCol.UpdateOne(ctx, filter,
+	// ctime 只能在 $setOnInsert 里面出现
+	art.Ctime = 0
+	_, err := m.liveCol.UpdateOne(ctx, filter,
 		bson.D{bson.E{Key: "$set", Value: art},
 			bson.E{Key: "$setOnInsert",
 				Value: bson.D{bson.E{Key: "ctime", Value: now}}}},
 		options.Update().SetUpsert(true))
-	return id, err
+	if err != nil {
+		return err
+	}
+	return m.touchPub(ctx, art.AuthorId, now)
+}
+
+// touchPub 记录作者和全站的线上内容在 now 发生了变化，没有事务，只能在修改线上库之后调用
+func (m *MongoDBDAO) touchPub(ctx context.Context, author, now int64) error {
+	for _, id := range []int64{author, 0} {
+		_, err := m.changeCol.UpdateOne(ctx, bson.D{bson.E{Key: "author_id", Value: id}},
+			bson.D{bson.E{Key: "$max", Value: bson.D{bson.E{Key: "utime", Value: now}}}},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MongoDBDAO) GetPubChange(ctx context.Context, author int64) (int64, error) {
+	var c PubChange
+	err := m.changeCol.FindOne(ctx, bson.D{bson.E{Key: "author_id", Value: author}}).Decode(&c)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return c.Utime, err
 }
 
 func (m *MongoDBDAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
@@ -114,7 +335,7 @@ func (m *MongoDBDAO) SyncStatus(ctx context.Context, author, id int64, status ui
 	if res.ModifiedCount != 1 {
 		return fmt.Errorf("incre")
 	}
-	return nil
+	return m.touchPub(ctx, author, time.Now().UnixMilli())
 }
 
 func (m *MongoDBDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error) {
@@ -122,12 +343,507 @@ func (m *MongoDBDAO) GetByAuthor(ctx context.Context, author int64, offset, limi
 	panic("implement me")
 }
 
+func (m *MongoDBDAO) GetIdsByAuthor(ctx context.Context, author, after int64, limit int) ([]int64, error) {
+	filter := bson.D{
+		bson.E{Key: "author_id", Value: author},
+		bson.E{Key: "id", Value: bson.D{bson.E{Key: "$gt", Value: after}}},
+		notDeleted,
+	}
+	opts := options.Find().
+		SetProjection(bson.D{bson.E{Key: "id", Value: 1}}).
+		SetSort(bson.D{bson.E{Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var arts []Article
+	if err = cursor.All(ctx, &arts); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	return ids, nil
+}
+
 func (m *MongoDBDAO) GetById(ctx context.Context, id int64) (Article, error) {
-	//TODO implement me
-	panic("implement me")
+	var art Article
+	err := m.col.FindOne(ctx, bson.D{bson.E{Key: "id", Value: id}}).Decode(&art)
+	if err != nil {
+		return art, err
+	}
+	art.Tags, err = m.findTags(ctx, m.tagRelCol, id)
+	return art, err
 }
 
 func (m *MongoDBDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+	var art PublishedArticle
+	err := m.liveCol.FindOne(ctx, bson.D{bson.E{Key: "id", Value: id}, notDeleted}).Decode(&art)
+	if err != nil {
+		return art, err
+	}
+	art.Tags, err = m.findTags(ctx, m.liveTagRelCol, id)
+	return art, err
+}
+
+func (m *MongoDBDAO) GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	return m.getPubByIds(ctx, ids)
+}
+
+func (m *MongoDBDAO) GetPubTitlesByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	return m.getPubByIds(ctx, ids, options.Find().
+		SetProjection(bson.D{bson.E{Key: "id", Value: 1}, bson.E{Key: "title", Value: 1}}))
+}
+
+func (m *MongoDBDAO) getPubByIds(ctx context.Context, ids []int64, opts ...*options.FindOptions) ([]PublishedArticle, error) {
+	filter := bson.D{
+		bson.E{Key: "id", Value: bson.D{bson.E{Key: "$in", Value: ids}}},
+		bson.E{Key: "status", Value: statusPublished},
+		notDeleted,
+	}
+	cursor, err := m.liveCol.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var res []PublishedArticle
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) ListPub(ctx context.Context, utime, id int64, limit int) ([]PublishedArticle, error) {
+	return m.listPub(ctx, bson.D{}, utime, id, limit)
+}
+
+func (m *MongoDBDAO) ListPubByTag(ctx context.Context, tag string, utime, id int64, limit int) ([]PublishedArticle, error) {
+	var t Tag
+	err := m.tagCol.FindOne(ctx, bson.D{bson.E{Key: "name", Value: tag}}).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return []PublishedArticle{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 热门标签下面的文章可能非常多，不能先把所有的 ID 查出来再 $in，
+	// 而是按照列表的顺序遍历线上库，逐篇检查有没有这个标签，凑够 limit 篇就停下来
+	pipeline := mongo.Pipeline{
+		bson.D{bson.E{Key: "$match", Value: m.pubFilter(bson.D{}, utime, id)}},
+		bson.D{bson.E{Key: "$sort", Value: bson.D{bson.E{Key: "utime", Value: -1}, bson.E{Key: "id", Value: -1}}}},
+		bson.D{bson.E{Key: "$lookup", Value: bson.D{
+			bson.E{Key: "from", Value: "published_article_tags"},
+			bson.E{Key: "localField", Value: "id"},
+			bson.E{Key: "foreignField", Value: "article_id"},
+			bson.E{Key: "pipeline", Value: bson.A{
+				bson.D{bson.E{Key: "$match", Value: bson.D{bson.E{Key: "tag_id", Value: t.Id}}}},
+			}},
+			bson.E{Key: "as", Value: "tag_rels"},
+		}}},
+		bson.D{bson.E{Key: "$match", Value: bson.D{bson.E{Key: "tag_rels", Value: bson.D{bson.E{Key: "$ne", Value: bson.A{}}}}}}},
+		bson.D{bson.E{Key: "$limit", Value: limit}},
+		bson.D{bson.E{Key: "$project", Value: bson.D{bson.E{Key: "tag_rels", Value: 0}}}},
+	}
+	cursor, err := m.liveCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var res []PublishedArticle
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) ListPubByCategory(ctx context.Context, category string, utime, id int64, limit int) ([]PublishedArticle, error) {
+	return m.listPub(ctx, bson.D{bson.E{Key: "category", Value: category}}, utime, id, limit)
+}
+
+func (m *MongoDBDAO) ListPubByAuthor(ctx context.Context, author int64, utime, id int64, limit int) ([]PublishedArticle, error) {
+	return m.listPub(ctx, bson.D{bson.E{Key: "author_id", Value: author}}, utime, id, limit)
+}
+
+func (m *MongoDBDAO) ScanPub(ctx context.Context, offset, limit int) ([]PublishedArticle, error) {
+	filter := bson.D{bson.E{Key: "status", Value: statusPublished}, notDeleted}
+	opts := options.Find().
+		SetProjection(bson.D{bson.E{Key: "id", Value: 1}, bson.E{Key: "utime", Value: 1}}).
+		SetSort(bson.D{bson.E{Key: "id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []PublishedArticle
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) CountPub(ctx context.Context) (int64, error) {
+	return m.liveCol.CountDocuments(ctx, bson.D{bson.E{Key: "status", Value: statusPublished}, notDeleted})
+}
+
+// listPub 读者侧列表的公共部分，filter 是额外的查询条件
+func (m *MongoDBDAO) listPub(ctx context.Context, filter bson.D, utime, id int64, limit int) ([]PublishedArticle, error) {
+	filter = m.pubFilter(filter, utime, id)
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "utime", Value: -1}, bson.E{Key: "id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.liveCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []PublishedArticle
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+// pubFilter 在 filter 的基础上加上读者能看到的条件，utime 大于 0 的时候只要排在 (utime, id) 后面的文章
+func (m *MongoDBDAO) pubFilter(filter bson.D, utime, id int64) bson.D {
+	filter = append(filter, bson.E{Key: "status", Value: statusPublished}, notDeleted)
+	if utime > 0 {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{bson.E{Key: "utime", Value: bson.D{bson.E{Key: "$lt", Value: utime}}}},
+			bson.D{bson.E{Key: "utime", Value: utime},
+				bson.E{Key: "id", Value: bson.D{bson.E{Key: "$lt", Value: id}}}},
+		}})
+	}
+	return filter
+}
+
+func (m *MongoDBDAO) GetRevisions(ctx context.Context, author, id int64, offset, limit int) ([]ArticleRevision, error) {
+	filter := bson.D{bson.E{Key: "article_id", Value: id}, bson.E{Key: "author_id", Value: author}}
+	opts := options.Find().
+		// 列表不需要内容
+		SetProjection(bson.D{bson.E{Key: "content", Value: 0}}).
+		SetSort(bson.D{bson.E{Key: "revision", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.revCol.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []ArticleRevision
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) GetRevision(ctx context.Context, author, id, revision int64) (ArticleRevision, error) {
+	filter := bson.D{bson.E{Key: "article_id", Value: id},
+		bson.E{Key: "author_id", Value: author},
+		bson.E{Key: "revision", Value: revision}}
+	var res ArticleRevision
+	err := m.revCol.FindOne(ctx, filter).Decode(&res)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return res, ErrRevisionNotFound
+	}
+	return res, err
+}
+
+func (m *MongoDBDAO) UpdateSchedule(ctx context.Context, author, id, publishAt int64) error {
+	filter := bson.D{bson.E{Key: "id", Value: id},
+		bson.E{Key: "author_id", Value: author},
+		bson.E{Key: "status", Value: statusScheduled},
+		notDeleted}
+	sets := bson.D{bson.E{Key: "publish_at", Value: publishAt},
+		bson.E{Key: "utime", Value: time.Now().UnixMilli()}}
+	if publishAt == 0 {
+		sets = append(sets, bson.E{Key: "status", Value: statusUnpublished})
+	}
+	// 改了状态也要加版本号，别的标签页里面打开的草稿再保存的时候才会冲突
+	res, err := m.col.UpdateOne(ctx, filter, bson.D{bson.E{Key: "$set", Value: sets},
+		bson.E{Key: "$inc", Value: bson.D{bson.E{Key: "revision", Value: 1}}}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotScheduled
+	}
+	return nil
+}
+
+func (m *MongoDBDAO) ListDueScheduled(ctx context.Context, now, publishAt, id int64, limit int) ([]Article, error) {
+	filter := bson.D{bson.E{Key: "status", Value: statusScheduled},
+		bson.E{Key: "publish_at", Value: bson.D{bson.E{Key: "$lte", Value: now}}},
+		notDeleted,
+		bson.E{Key: "$or", Value: bson.A{
+			bson.D{bson.E{Key: "publish_at", Value: bson.D{bson.E{Key: "$gt", Value: publishAt}}}},
+			bson.D{bson.E{Key: "publish_at", Value: publishAt},
+				bson.E{Key: "id", Value: bson.D{bson.E{Key: "$gt", Value: id}}}},
+		}}}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "publish_at", Value: 1}, bson.E{Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []Article
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) PublishScheduled(ctx context.Context, id, now int64) (Article, error) {
+	filter := bson.D{bson.E{Key: "id", Value: id},
+		bson.E{Key: "status", Value: statusScheduled},
+		bson.E{Key: "publish_at", Value: bson.D{bson.E{Key: "$lte", Value: now}}},
+		notDeleted}
+	sets := bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: "status", Value: statusPublished},
+		bson.E{Key: "publish_at", Value: 0},
+		bson.E{Key: "utime", Value: now}}},
+		bson.E{Key: "$inc", Value: bson.D{bson.E{Key: "revision", Value: 1}}},
+	}
+	// 带上状态和时间作为条件，列出来之后取消或者修改了定时发表的文章不会被发表
+	var art Article
+	err := m.col.FindOneAndUpdate(ctx, filter, sets,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&art)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Article{}, ErrNotScheduled
+	}
+	if err != nil {
+		return Article{}, err
+	}
+	if err = m.syncTags(ctx, id, now); err != nil {
+		return Article{}, err
+	}
+	if err = m.insertRevision(ctx, art, now); err != nil {
+		return Article{}, err
+	}
+	return art, m.upsertPub(ctx, art, now)
+}
+
+func (m *MongoDBDAO) PopularTags(ctx context.Context, limit int) ([]TagCnt, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{bson.E{Key: "$lookup", Value: bson.D{
+			bson.E{Key: "from", Value: "published_articles"},
+			bson.E{Key: "localField", Value: "article_id"},
+			bson.E{Key: "foreignField", Value: "id"},
+			bson.E{Key: "as", Value: "art"},
+		}}},
+		// 仅自己可见的文章不算
+		bson.D{bson.E{Key: "$match", Value: bson.D{bson.E{Key: "art.status", Value: statusPublished},
+			bson.E{Key: "art.deleted_at", Value: bson.D{bson.E{Key: "$not", Value: bson.D{bson.E{Key: "$gt", Value: 0}}}}}}}},
+		bson.D{bson.E{Key: "$group", Value: bson.D{
+			bson.E{Key: "_id", Value: "$tag_id"},
+			bson.E{Key: "cnt", Value: bson.D{bson.E{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{bson.E{Key: "$sort", Value: bson.D{bson.E{Key: "cnt", Value: -1}}}},
+		bson.D{bson.E{Key: "$limit", Value: limit}},
+		bson.D{bson.E{Key: "$lookup", Value: bson.D{
+			bson.E{Key: "from", Value: "tags"},
+			bson.E{Key: "localField", Value: "_id"},
+			bson.E{Key: "foreignField", Value: "id"},
+			bson.E{Key: "as", Value: "tag"},
+		}}},
+		bson.D{bson.E{Key: "$unwind", Value: "$tag"}},
+		bson.D{bson.E{Key: "$project", Value: bson.D{
+			bson.E{Key: "_id", Value: 0},
+			bson.E{Key: "id", Value: "$_id"},
+			bson.E{Key: "name", Value: "$tag.name"},
+			bson.E{Key: "cnt", Value: 1},
+		}}},
+	}
+	cursor, err := m.liveTagRelCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var res []TagCnt
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+// saveTags 用 tags 覆盖制作库里面文章的标签，tags 为 nil 的时候什么也不做
+func (m *MongoDBDAO) saveTags(ctx context.Context, id int64, tags []string, now int64) error {
+	if tags == nil {
+		return nil
+	}
+	_, err := m.tagRelCol.DeleteMany(ctx, bson.D{bson.E{Key: "article_id", Value: id}})
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	rels := make([]any, 0, len(tags))
+	for _, name := range tags {
+		tagId, er := m.tagId(ctx, name, now)
+		if er != nil {
+			return er
+		}
+		// 雪花算法的 ID 是递增的，所以按照 id 排序就是标签的顺序
+		rels = append(rels, ArticleTag{
+			Id:        m.node.Generate().Int64(),
+			ArticleId: id,
+			TagId:     tagId,
+			Ctime:     now,
+		})
+	}
+	_, err = m.tagRelCol.InsertMany(ctx, rels)
+	return err
+}
+
+// tagId 标签不存在的时候会创建出来
+func (m *MongoDBDAO) tagId(ctx context.Context, name string, now int64) (int64, error) {
+	var t Tag
+	err := m.tagCol.FindOneAndUpdate(ctx,
+		bson.D{bson.E{Key: "name", Value: name}},
+		bson.D{bson.E{Key: "$setOnInsert", Value: bson.D{
+			bson.E{Key: "id", Value: m.node.Generate().Int64()},
+			bson.E{Key: "ctime", Value: now},
+		}}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).
+		Decode(&t)
+	return t.Id, err
+}
+
+// syncTags 线上库的标签和制作库保持一致
+func (m *MongoDBDAO) syncTags(ctx context.Context, id int64, now int64) error {
+	filter := bson.D{bson.E{Key: "article_id", Value: id}}
+	cursor, err := m.tagRelCol.Find(ctx, filter,
+		options.Find().SetSort(bson.D{bson.E{Key: "id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	var rels []ArticleTag
+	if err = cursor.All(ctx, &rels); err != nil {
+		return err
+	}
+	_, err = m.liveTagRelCol.DeleteMany(ctx, filter)
+	if err != nil || len(rels) == 0 {
+		return err
+	}
+	pubs := make([]any, 0, len(rels))
+	for _, rel := range rels {
+		rel.Ctime = now
+		pubs = append(pubs, PublishedArticleTag(rel))
+	}
+	_, err = m.liveTagRelCol.InsertMany(ctx, pubs)
+	return err
+}
+
+// findTags relCol 是 tagRelCol 或者 liveTagRelCol，决定了查询制作库还是线上库
+func (m *MongoDBDAO) findTags(ctx context.Context, relCol *mongo.Collection, id int64) ([]string, error) {
+	cursor, err := relCol.Find(ctx, bson.D{bson.E{Key: "article_id", Value: id}},
+		options.Find().SetSort(bson.D{bson.E{Key: "id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var rels []ArticleTag
+	if err = cursor.All(ctx, &rels); err != nil || len(rels) == 0 {
+		return nil, err
+	}
+	tagIds := make([]int64, 0, len(rels))
+	for _, rel := range rels {
+		tagIds = append(tagIds, rel.TagId)
+	}
+	cursor, err = m.tagCol.Find(ctx, bson.D{bson.E{Key: "id", Value: bson.D{bson.E{Key: "$in", Value: tagIds}}}})
+	if err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(tags))
+	for _, t := range tags {
+		names[t.Id] = t.Name
+	}
+	res := make([]string, 0, len(tagIds))
+	for _, tid := range tagIds {
+		res = append(res, names[tid])
+	}
+	return res, nil
+}
+
+// SoftDelete MongoDB 这里没有用事务，线上库更新失败的时候制作库已经删除了，可以重试
+func (m *MongoDBDAO) SoftDelete(ctx context.Context, author, id, now int64) error {
+	filter := bson.D{bson.E{Key: "id", Value: id}, bson.E{Key: "author_id", Value: author}}
+	res, err := m.col.UpdateOne(ctx, append(filter, notDeleted), bson.D{bson.E{Key: "$set",
+		Value: bson.D{bson.E{Key: "deleted_at", Value: now}, bson.E{Key: "utime", Value: now}}}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrPossibleIncorrectAuthor
+	}
+	// 没有发表过的文章在线上库里面没有数据
+	res, err = m.liveCol.UpdateOne(ctx, filter, bson.D{bson.E{Key: "$set",
+		Value: bson.D{bson.E{Key: "deleted_at", Value: now}}}})
+	if err != nil || res.MatchedCount == 0 {
+		return err
+	}
+	return m.touchPub(ctx, author, now)
+}
+
+func (m *MongoDBDAO) Restore(ctx context.Context, author, id, since int64) error {
+	filter := bson.D{bson.E{Key: "id", Value: id}, bson.E{Key: "author_id", Value: author}}
+	deleted := bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$gte", Value: max(since, 1)}}}
+	res, err := m.col.UpdateOne(ctx, append(filter, deleted), bson.D{
+		bson.E{Key: "$unset", Value: bson.D{bson.E{Key: "deleted_at", Value: ""}}},
+		bson.E{Key: "$set", Value: bson.D{bson.E{Key: "utime", Value: time.Now().UnixMilli()}}},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotInRecycleBin
+	}
+	res, err = m.liveCol.UpdateOne(ctx, filter, bson.D{
+		bson.E{Key: "$unset", Value: bson.D{bson.E{Key: "deleted_at", Value: ""}}}})
+	if err != nil || res.MatchedCount == 0 {
+		return err
+	}
+	return m.touchPub(ctx, author, time.Now().UnixMilli())
+}
+
+func (m *MongoDBDAO) ListDeleted(ctx context.Context, author, since int64, offset, limit int) ([]Article, error) {
+	filter := bson.D{bson.E{Key: "author_id", Value: author},
+		bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$gte", Value: max(since, 1)}}}}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "deleted_at", Value: -1}, bson.E{Key: "id", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []Article
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBDAO) ListPurgeable(ctx context.Context, before int64, limit int) ([]Article, error) {
+	filter := bson.D{bson.E{Key: "deleted_at", Value: bson.D{
+		bson.E{Key: "$gt", Value: 0},
+		bson.E{Key: "$lt", Value: before}}}}
+	opts := options.Find().
+		SetSort(bson.D{bson.E{Key: "deleted_at", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []Article
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+// Purge 先删除关联的数据，最后删除制作库，中途失败的话下一次清理还能找到这篇文章
+func (m *MongoDBDAO) Purge(ctx context.Context, id int64) error {
+	filter := bson.D{bson.E{Key: "id", Value: id},
+		bson.E{Key: "deleted_at", Value: bson.D{bson.E{Key: "$gt", Value: 0}}}}
+	cnt, err := m.col.CountDocuments(ctx, filter)
+	if err != nil || cnt == 0 {
+		return err
+	}
+	byArticle := bson.D{bson.E{Key: "article_id", Value: id}}
+	for _, col := range []*mongo.Collection{m.liveTagRelCol, m.tagRelCol, m.revCol} {
+		if _, err = col.DeleteMany(ctx, byArticle); err != nil {
+			return err
+		}
+	}
+	if _, err = m.liveCol.DeleteOne(ctx, bson.D{bson.E{Key: "id", Value: id}}); err != nil {
+		return err
+	}
+	_, err = m.seriesCol.UpdateMany(ctx, bson.D{bson.E{Key: "article_ids", Value: id}},
+		bson.D{bson.E{Key: "$pull", Value: bson.D{bson.E{Key: "article_ids", Value: id}}}})
+	if err != nil {
+		return err
+	}
+	_, err = m.col.DeleteOne(ctx, filter)
+	return err
 }
@@ -23,12 +23,10 @@ type S3DAO struct {
 }
 
 // NewOssDAO 因为组合 GORMArticleDAO 是一个内部实现细节, 所以这里要直接传入 DB
-func NewOssDAO(oss *s3.S3, db *gorm.DB) ArticleDAO {
+func NewOssDAO(oss *s3.S3, db *gorm.DB, bucket string) ArticleDAO {
 	return &S3DAO{
-		oss: oss,
-		// 也可以考虑利用依赖注入来传入。
-		// 但是事实上这个很少变，所以可以延迟到必要的时候再注入
-		bucket: ekit.ToPtr[string]("webook-1314583317"),
+		oss:    oss,
+		bucket: ekit.ToPtr[string](bucket),
 		GORMArticleDAO: GORMArticleDAO{
 			db: db,
 		},
@@ -53,38 +51,65 @@ func (o *S3DAO) Sync(ctx context.Context, art Article) (int64, error) {
 			return err
 		}
 		art.Id = id
-		// PublishedArticleV1 不具备 Content
-		publishArt := PublishedArticleV1{
-			Id:       art.Id,
-			Title:    art.Title,
-			AuthorId: art.AuthorId,
-			Status:   art.Status,
-			Ctime:    now,
-			Utime:    now,
-		}
-		return tx.Clauses(clause.OnConflict{
-			// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
-			Columns: []clause.Column{{Name: "id"}},
-			// 这里没有更新 Content，
-			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  publishArt.Title,
-				"status": publishArt.Status,
-				"utime":  now,
-			}),
-		}).Create(&publishArt).Error
+		return o.upsertPubV1(tx, art, now)
 	})
 	if err != nil {
 		return 0, err
 	}
+	return art.Id, o.putContent(ctx, art)
+}
+
+func (o *S3DAO) PublishScheduled(ctx context.Context, id, now int64) (Article, error) {
+	var art Article
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		art, err = o.publishScheduled(tx, id, now)
+		if err != nil {
+			return err
+		}
+		return o.upsertPubV1(tx, art, now)
+	})
+	if err != nil {
+		return Article{}, err
+	}
+	return art, o.putContent(ctx, art)
+}
 
-	// 最后同步到 OSS 上，但是只同步了 Content
-	_, err = o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
+// upsertPubV1 PublishedArticleV1 不具备 Content，内容要另外调用 putContent
+func (o *S3DAO) upsertPubV1(tx *gorm.DB, art Article, now int64) error {
+	publishArt := PublishedArticleV1{
+		Id:       art.Id,
+		Title:    art.Title,
+		AuthorId: art.AuthorId,
+		Status:   art.Status,
+		Ctime:    now,
+		Utime:    now,
+	}
+	err := tx.Clauses(clause.OnConflict{
+		// ID 冲突的时候。实际上，在 MYSQL 里面你写不写都可以
+		Columns: []clause.Column{{Name: "id"}},
+		// 这里没有更新 Content，
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"title":  publishArt.Title,
+			"status": publishArt.Status,
+			"utime":  now,
+		}),
+	}).Create(&publishArt).Error
+	if err != nil {
+		return err
+	}
+	return touchPub(tx, art.AuthorId, now)
+}
+
+// putContent 最后同步到 OSS 上，但是只同步了 Content
+func (o *S3DAO) putContent(ctx context.Context, art Article) error {
+	_, err := o.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
 		Bucket:      o.bucket,
 		Key:         ekit.ToPtr[string](strconv.FormatInt(art.Id, 10)),
 		Body:        bytes.NewReader([]byte(art.Content)),
 		ContentType: ekit.ToPtr[string]("text/plain;charset=utf-8"),
 	})
-	return art.Id, err
+	return err
 }
 
 func (o *S3DAO) SyncStatus(ctx context.Context, author, id int64, status uint8) error {
@@ -104,7 +129,7 @@ func (o *S3DAO) SyncStatus(ctx context.Context, author, id int64, status uint8)
 		if res.RowsAffected != 1 {
 			return ErrPossibleIncorrectAuthor
 		}
-		return nil
+		return touchPub(tx, author, time.Now().UnixMilli())
 	})
 	if err != nil {
 		return err
@@ -117,3 +142,55 @@ func (o *S3DAO) SyncStatus(ctx context.Context, author, id int64, status uint8)
 	}
 	return err
 }
+
+func (o *S3DAO) SoftDelete(ctx context.Context, author, id, now int64) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := o.softDelete(tx, author, id, now); err != nil {
+			return err
+		}
+		// OSS 上的内容要等到清理的时候才删除，恢复的时候就不需要重新上传
+		res := tx.Model(&PublishedArticleV1{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Update("deleted_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return touchPub(tx, author, now)
+	})
+}
+
+func (o *S3DAO) Restore(ctx context.Context, author, id, since int64) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := o.restore(tx, author, id, since); err != nil {
+			return err
+		}
+		res := tx.Model(&PublishedArticleV1{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Update("deleted_at", 0)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return touchPub(tx, author, time.Now().UnixMilli())
+	})
+}
+
+func (o *S3DAO) Purge(ctx context.Context, id int64) error {
+	var purged bool
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		purged, err = o.purge(tx, id)
+		if err != nil || !purged {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&PublishedArticleV1{}).Error
+	})
+	if err != nil || !purged {
+		return err
+	}
+	// 数据库已经删除了，OSS 删除失败的话只能靠 bucket 的生命周期规则兜底
+	_, err = o.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: o.bucket,
+		Key:    ekit.ToPtr[string](strconv.FormatInt(id, 10)),
+	})
+	return err
+}
@@ -0,0 +1,37 @@
+package article
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrSeriesNotFound 不管是哪个实现，找不到专栏都返回这个
+	ErrSeriesNotFound = errors.New("专栏不存在")
+	// ErrInvalidSeriesArticle 章节里面有不存在、已经删除或者不属于这个作者的文章
+	ErrInvalidSeriesArticle = errors.New("专栏的章节不合法")
+	// ErrArticleInOtherSeries 文章已经在别的专栏里面了
+	ErrArticleInOtherSeries = errors.New("文章已经属于别的专栏")
+)
+
+type SeriesDAO interface {
+	Insert(ctx context.Context, s Series) (int64, error)
+	// Update 只修改标题和简介，不是这个作者的专栏返回 ErrPossibleIncorrectAuthor
+	Update(ctx context.Context, s Series) error
+	// UpdateStatus 不是这个作者的专栏返回 ErrPossibleIncorrectAuthor
+	UpdateStatus(ctx context.Context, author, id int64, status uint8) error
+	// SetArticles 用 ids 整体替换章节，ids 的顺序就是章节的顺序
+	// 文章必须是作者自己的并且没有删除，否则返回 ErrInvalidSeriesArticle，
+	// 已经在别的专栏里面的文章返回 ErrArticleInOtherSeries
+	SetArticles(ctx context.Context, author, id int64, ids []int64) error
+	// Delete 只删除专栏本身，章节的文章不受影响
+	Delete(ctx context.Context, author, id int64) error
+	// GetById 带上章节的文章 ID，找不到返回 ErrSeriesNotFound
+	GetById(ctx context.Context, id int64) (Series, error)
+	// GetByAuthor 按照创建时间倒序，不带章节
+	GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Series, error)
+	// GetByArticle 文章所在的专栏，带上章节，找不到返回 ErrSeriesNotFound
+	GetByArticle(ctx context.Context, articleId int64) (Series, error)
+	// GetArticles 作者视角的章节，按照章节的顺序，不包含内容，已经删除的文章不会出现
+	GetArticles(ctx context.Context, id int64) ([]Article, error)
+}
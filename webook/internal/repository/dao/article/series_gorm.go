@@ -0,0 +1,182 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"time"
+)
+
+var _ SeriesDAO = (*GORMSeriesDAO)(nil)
+
+type GORMSeriesDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMSeriesDAO(db *gorm.DB) SeriesDAO {
+	return &GORMSeriesDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMSeriesDAO) Insert(ctx context.Context, s Series) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	err := dao.db.WithContext(ctx).Create(&s).Error
+	return s.Id, err
+}
+
+func (dao *GORMSeriesDAO) Update(ctx context.Context, s Series) error {
+	res := dao.db.WithContext(ctx).Model(&Series{}).
+		Where("id = ? AND author_id = ?", s.Id, s.AuthorId).
+		Updates(map[string]any{
+			"title":       s.Title,
+			"description": s.Description,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (dao *GORMSeriesDAO) UpdateStatus(ctx context.Context, author, id int64, status uint8) error {
+	res := dao.db.WithContext(ctx).Model(&Series{}).
+		Where("id = ? AND author_id = ?", id, author).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (dao *GORMSeriesDAO) SetArticles(ctx context.Context, author, id int64, ids []int64) error {
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 顺便锁住专栏，避免并发修改章节
+		res := tx.Model(&Series{}).
+			Where("id = ? AND author_id = ?", id, author).
+			Update("utime", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		if len(ids) > 0 {
+			// ids 里面有重复的话数量也对不上
+			var cnt int64
+			err := tx.Model(&Article{}).
+				Where("id IN ? AND author_id = ? AND deleted_at = 0", ids, author).
+				Count(&cnt).Error
+			if err != nil {
+				return err
+			}
+			if cnt != int64(len(ids)) {
+				return ErrInvalidSeriesArticle
+			}
+		}
+		err := tx.Where("series_id = ?", id).Delete(&SeriesArticle{}).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		rels := make([]SeriesArticle, 0, len(ids))
+		for i, aid := range ids {
+			rels = append(rels, SeriesArticle{
+				SeriesId:  id,
+				ArticleId: aid,
+				Position:  i,
+				Ctime:     now,
+			})
+		}
+		return tx.Create(&rels).Error
+	})
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueIndexErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueIndexErrNo {
+			return ErrArticleInOtherSeries
+		}
+	}
+	return err
+}
+
+func (dao *GORMSeriesDAO) Delete(ctx context.Context, author, id int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND author_id = ?", id, author).Delete(&Series{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return ErrPossibleIncorrectAuthor
+		}
+		return tx.Where("series_id = ?", id).Delete(&SeriesArticle{}).Error
+	})
+}
+
+func (dao *GORMSeriesDAO) GetById(ctx context.Context, id int64) (Series, error) {
+	var s Series
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s, ErrSeriesNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	s.ArticleIds, err = dao.articleIds(ctx, id)
+	return s, err
+}
+
+func (dao *GORMSeriesDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Series, error) {
+	var res []Series
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ?", author).
+		Order("ctime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMSeriesDAO) GetByArticle(ctx context.Context, articleId int64) (Series, error) {
+	var rel SeriesArticle
+	err := dao.db.WithContext(ctx).Where("article_id = ?", articleId).First(&rel).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Series{}, ErrSeriesNotFound
+	}
+	if err != nil {
+		return Series{}, err
+	}
+	return dao.GetById(ctx, rel.SeriesId)
+}
+
+func (dao *GORMSeriesDAO) GetArticles(ctx context.Context, id int64) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).Model(&Article{}).
+		Select("articles.id, articles.title, articles.author_id, articles.status, "+
+			"articles.cover, articles.publish_at, articles.ctime, articles.utime").
+		Joins("JOIN series_articles ON series_articles.article_id = articles.id").
+		Where("series_articles.series_id = ? AND articles.deleted_at = 0", id).
+		Order("series_articles.position ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMSeriesDAO) articleIds(ctx context.Context, id int64) ([]int64, error) {
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&SeriesArticle{}).
+		Where("series_id = ?", id).
+		Order("position ASC").
+		Pluck("article_id", &ids).Error
+	return ids, err
+}
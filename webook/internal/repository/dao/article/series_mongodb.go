@@ -0,0 +1,168 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"github.com/bwmarrin/snowflake"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+var _ SeriesDAO = (*MongoDBSeriesDAO)(nil)
+
+// MongoDBSeriesDAO 章节直接存成专栏里面的数组，索引在 InitCollections 里面创建
+type MongoDBSeriesDAO struct {
+	col *mongo.Collection
+	// artCol 制作库，用来校验章节的文章
+	artCol *mongo.Collection
+	node   *snowflake.Node
+}
+
+func NewMongoDBSeriesDAO(db *mongo.Database, node *snowflake.Node) *MongoDBSeriesDAO {
+	return &MongoDBSeriesDAO{
+		col:    db.Collection("series"),
+		artCol: db.Collection("articles"),
+		node:   node,
+	}
+}
+
+func (m *MongoDBSeriesDAO) Insert(ctx context.Context, s Series) (int64, error) {
+	s.Id = m.node.Generate().Int64()
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	s.ArticleIds = []int64{}
+	_, err := m.col.InsertOne(ctx, s)
+	return s.Id, err
+}
+
+func (m *MongoDBSeriesDAO) Update(ctx context.Context, s Series) error {
+	return m.update(ctx, s.AuthorId, s.Id, bson.D{
+		bson.E{Key: "title", Value: s.Title},
+		bson.E{Key: "description", Value: s.Description},
+	})
+}
+
+func (m *MongoDBSeriesDAO) UpdateStatus(ctx context.Context, author, id int64, status uint8) error {
+	return m.update(ctx, author, id, bson.D{bson.E{Key: "status", Value: status}})
+}
+
+func (m *MongoDBSeriesDAO) SetArticles(ctx context.Context, author, id int64, ids []int64) error {
+	if len(ids) > 0 {
+		// ids 里面有重复的话数量也对不上
+		cnt, err := m.artCol.CountDocuments(ctx, bson.D{
+			bson.E{Key: "id", Value: bson.D{bson.E{Key: "$in", Value: ids}}},
+			bson.E{Key: "author_id", Value: author},
+			notDeleted,
+		})
+		if err != nil {
+			return err
+		}
+		if cnt != int64(len(ids)) {
+			return ErrInvalidSeriesArticle
+		}
+	} else {
+		// 保证存进去的是空数组而不是 null
+		ids = []int64{}
+	}
+	// 已经在别的专栏里面的文章靠 article_ids 上的唯一索引拦下来，
+	// 先查再改的话，两个专栏同时加入同一篇文章都能成功
+	err := m.update(ctx, author, id, bson.D{bson.E{Key: "article_ids", Value: ids}})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrArticleInOtherSeries
+	}
+	return err
+}
+
+func (m *MongoDBSeriesDAO) update(ctx context.Context, author, id int64, sets bson.D) error {
+	sets = append(sets, bson.E{Key: "utime", Value: time.Now().UnixMilli()})
+	res, err := m.col.UpdateOne(ctx, bson.D{
+		bson.E{Key: "id", Value: id},
+		bson.E{Key: "author_id", Value: author},
+	}, bson.D{bson.E{Key: "$set", Value: sets}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (m *MongoDBSeriesDAO) Delete(ctx context.Context, author, id int64) error {
+	res, err := m.col.DeleteOne(ctx, bson.D{
+		bson.E{Key: "id", Value: id},
+		bson.E{Key: "author_id", Value: author},
+	})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount != 1 {
+		return ErrPossibleIncorrectAuthor
+	}
+	return nil
+}
+
+func (m *MongoDBSeriesDAO) GetById(ctx context.Context, id int64) (Series, error) {
+	return m.findOne(ctx, bson.D{bson.E{Key: "id", Value: id}})
+}
+
+func (m *MongoDBSeriesDAO) GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Series, error) {
+	opts := options.Find().
+		SetProjection(bson.D{bson.E{Key: "article_ids", Value: 0}}).
+		SetSort(bson.D{bson.E{Key: "ctime", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	cursor, err := m.col.Find(ctx, bson.D{bson.E{Key: "author_id", Value: author}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []Series
+	err = cursor.All(ctx, &res)
+	return res, err
+}
+
+func (m *MongoDBSeriesDAO) GetByArticle(ctx context.Context, articleId int64) (Series, error) {
+	return m.findOne(ctx, bson.D{bson.E{Key: "article_ids", Value: articleId}})
+}
+
+func (m *MongoDBSeriesDAO) findOne(ctx context.Context, filter bson.D) (Series, error) {
+	var s Series
+	err := m.col.FindOne(ctx, filter).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return s, ErrSeriesNotFound
+	}
+	return s, err
+}
+
+func (m *MongoDBSeriesDAO) GetArticles(ctx context.Context, id int64) ([]Article, error) {
+	s, err := m.GetById(ctx, id)
+	if err != nil || len(s.ArticleIds) == 0 {
+		return nil, err
+	}
+	cursor, err := m.artCol.Find(ctx, bson.D{
+		bson.E{Key: "id", Value: bson.D{bson.E{Key: "$in", Value: s.ArticleIds}}},
+		notDeleted,
+	}, options.Find().SetProjection(bson.D{bson.E{Key: "content", Value: 0}}))
+	if err != nil {
+		return nil, err
+	}
+	var arts []Article
+	if err = cursor.All(ctx, &arts); err != nil {
+		return nil, err
+	}
+	// 按照章节的顺序排好
+	byId := make(map[int64]Article, len(arts))
+	for _, art := range arts {
+		byId[art.Id] = art
+	}
+	res := make([]Article, 0, len(arts))
+	for _, aid := range s.ArticleIds {
+		if art, ok := byId[aid]; ok {
+			res = append(res, art)
+		}
+	}
+	return res, nil
+}
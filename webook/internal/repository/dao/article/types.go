@@ -3,9 +3,27 @@ package article
 import (
 	"context"
 	"errors"
+	"gorm.io/gorm"
 )
 
-var ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+var (
+	ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+	// ErrRevisionNotFound 不管是哪个实现，找不到历史版本都返回这个
+	ErrRevisionNotFound = gorm.ErrRecordNotFound
+	// ErrNotScheduled 文章不存在，不属于这个作者，或者不是定时发表的状态
+	ErrNotScheduled = errors.New("文章不是定时发表状态")
+	// ErrVersionConflict 更新的时候带上的版本号不是最新的，说明在别的地方已经修改过了
+	ErrVersionConflict = errors.New("文章已经被修改过")
+	// ErrNotInRecycleBin 文章不存在，不属于这个作者，没有删除，或者已经超过了可以恢复的时间
+	ErrNotInRecycleBin = errors.New("文章不在回收站里面")
+)
+
+// TagCnt 标签和使用这个标签的已发表文章数量
+type TagCnt struct {
+	Id   int64
+	Name string
+	Cnt  int64
+}
 
 type ArticleDAO interface {
 	Insert(ctx context.Context, art Article) (int64, error)
@@ -13,6 +31,54 @@ type ArticleDAO interface {
 	Sync(ctx context.Context, art Article) (int64, error)
 	SyncStatus(ctx context.Context, author, id int64, status uint8) error
 	GetByAuthor(ctx context.Context, author int64, offset, limit int) ([]Article, error)
+	// GetIdsByAuthor 按照 id 正序查询作者的文章 id，只返回 id 大于 after 的，不包括回收站里面的
+	GetIdsByAuthor(ctx context.Context, author, after int64, limit int) ([]int64, error)
+	// GetById 和 GetPubById 都会带上标签
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
+	// ListPub 按照 utime, id 倒序分页查询已发表的文章
+	// utime 为 0 的时候代表从头开始查询
+	ListPub(ctx context.Context, utime, id int64, limit int) ([]PublishedArticle, error)
+	// GetPubByIds 批量查询已发表的文章，不存在的文章不会出现在返回值里面，也不保证顺序
+	GetPubByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+	// GetPubTitlesByIds 和 GetPubByIds 一样，但是只有 id 和标题
+	GetPubTitlesByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+	// ListPubByTag 和 ListPub 一样的分页方式，只查询带有这个标签的文章
+	ListPubByTag(ctx context.Context, tag string, utime, id int64, limit int) ([]PublishedArticle, error)
+	// ListPubByCategory 和 ListPub 一样的分页方式，只查询这个分类的文章
+	ListPubByCategory(ctx context.Context, category string, utime, id int64, limit int) ([]PublishedArticle, error)
+	// ListPubByAuthor 和 ListPub 一样的分页方式，只查询这个作者的文章
+	ListPubByAuthor(ctx context.Context, author int64, utime, id int64, limit int) ([]PublishedArticle, error)
+	// ScanPub 按照 id 正序遍历已发表的文章，只有 id 和 utime，给站点地图用
+	ScanPub(ctx context.Context, offset, limit int) ([]PublishedArticle, error)
+	// CountPub 已发表的文章数量
+	CountPub(ctx context.Context) (int64, error)
+	// GetPubChange 作者的线上内容最后一次变化的时间，author 为 0 的时候是全站的，没有记录的时候返回 0
+	GetPubChange(ctx context.Context, author int64) (int64, error)
+	// PopularTags 已发表的文章里面使用最多的标签
+	PopularTags(ctx context.Context, limit int) ([]TagCnt, error)
+	// GetRevisions 按照版本号倒序查询作者某篇文章的历史版本，不包含内容
+	GetRevisions(ctx context.Context, author, id int64, offset, limit int) ([]ArticleRevision, error)
+	// UpdateSchedule 修改定时发表的时间，只对定时发表状态的文章生效，否则返回 ErrNotScheduled
+	// publishAt 为 0 代表取消定时发表，文章回到未发表的状态
+	UpdateSchedule(ctx context.Context, author, id, publishAt int64) error
+	// ListDueScheduled 发表时间不晚于 now 的定时发表文章，按照 publish_at, id 正序分页
+	// 只返回排在 (publishAt, id) 后面的文章，两个都是 0 的时候从头开始
+	ListDueScheduled(ctx context.Context, now, publishAt, id int64, limit int) ([]Article, error)
+	// PublishScheduled 发表制作库里面当前的内容，返回发表之后的文章，不带标签
+	// 只有文章还是定时发表的状态并且 publish_at 不晚于 now 才会发表，否则返回 ErrNotScheduled
+	PublishScheduled(ctx context.Context, id, now int64) (Article, error)
+	// GetRevision 找不到的时候返回 ErrRevisionNotFound
+	GetRevision(ctx context.Context, author, id, revision int64) (ArticleRevision, error)
+
+	// SoftDelete 把制作库和线上库的文章一起移到回收站，已经删除过的返回 ErrPossibleIncorrectAuthor
+	SoftDelete(ctx context.Context, author, id, now int64) error
+	// Restore 只能恢复 since 之后删除的文章，否则返回 ErrNotInRecycleBin
+	Restore(ctx context.Context, author, id, since int64) error
+	// ListDeleted 回收站里面 since 之后删除的文章，按照删除时间倒序
+	ListDeleted(ctx context.Context, author, since int64, offset, limit int) ([]Article, error)
+	// ListPurgeable before 之前删除的文章，按照删除时间正序，给清理任务用
+	ListPurgeable(ctx context.Context, before int64, limit int) ([]Article, error)
+	// Purge 彻底删除回收站里面的文章，包括线上库、历史版本和标签，不在回收站里面的文章不会被删除
+	Purge(ctx context.Context, id int64) error
 }
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"webook/internal/domain"
+)
+
+type ArticleStatsDAO interface {
+	// Upsert 覆盖写入，同一天重复刷新的结果是一样的
+	Upsert(ctx context.Context, stats []ArticleDailyStats) error
+	// FindDaily [from, to] 之间每一天的数据，按照日期升序，day 的格式是 2006-01-02
+	FindDaily(ctx context.Context, aid int64, from, to string) ([]ArticleDailyStats, error)
+	// SumAuthorDaily 作者所有文章在 [from, to] 之间每一天阅读数的总和，按照日期升序
+	// 结果里面 Aid 是 0，UV 不能直接相加，所以也是 0
+	SumAuthorDaily(ctx context.Context, uid int64, from, to string) ([]ArticleDailyStats, error)
+	// SumByArticle 作者每一篇文章在 [from, to] 之间阅读数的总和，结果里面 Day 是空的
+	SumByArticle(ctx context.Context, uid int64, from, to string) ([]ArticleDailyStats, error)
+}
+
+type GORMArticleStatsDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMArticleStatsDAO(db *gorm.DB) ArticleStatsDAO {
+	return &GORMArticleStatsDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMArticleStatsDAO) Upsert(ctx context.Context, stats []ArticleDailyStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	for i := range stats {
+		stats[i].Ctime = now
+		stats[i].Utime = now
+	}
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"read_cnt", "uv", "utime"}),
+	}).Create(&stats).Error
+}
+
+func (dao *GORMArticleStatsDAO) FindDaily(ctx context.Context, aid int64, from, to string) ([]ArticleDailyStats, error) {
+	var res []ArticleDailyStats
+	err := dao.db.WithContext(ctx).
+		Where("aid = ? AND day BETWEEN ? AND ?", aid, from, to).
+		Order("day ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleStatsDAO) SumAuthorDaily(ctx context.Context, uid int64, from, to string) ([]ArticleDailyStats, error) {
+	var res []ArticleDailyStats
+	err := dao.byAuthor(ctx, uid, from, to).
+		Select("s.day, SUM(s.read_cnt) AS read_cnt").
+		Group("s.day").
+		Order("s.day ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleStatsDAO) SumByArticle(ctx context.Context, uid int64, from, to string) ([]ArticleDailyStats, error) {
+	var res []ArticleDailyStats
+	err := dao.byAuthor(ctx, uid, from, to).
+		Select("s.aid, SUM(s.read_cnt) AS read_cnt").
+		Group("s.aid").
+		Find(&res).Error
+	return res, err
+}
+
+// byAuthor 统计表里面没有作者，要关联线上库的文章
+// 撤回和删除了的文章不算，和文章排行只展示线上的文章保持一致
+func (dao *GORMArticleStatsDAO) byAuthor(ctx context.Context, uid int64, from, to string) *gorm.DB {
+	return dao.db.WithContext(ctx).Table("article_daily_stats AS s").
+		Joins("JOIN published_articles AS a ON a.id = s.aid").
+		Where("a.author_id = ? AND a.status = ? AND a.deleted_at = 0 AND s.day BETWEEN ? AND ?",
+			uid, domain.ArticleStatusPublished.ToUint8(), from, to)
+}
+
+// ArticleDailyStats 文章每天的阅读数和独立访客，由定时任务从 Redis 刷过来
+type ArticleDailyStats struct {
+	Id  int64  `gorm:"primaryKey,autoIncrement"`
+	Aid int64  `gorm:"uniqueIndex:aid_day"`
+	Day string `gorm:"type:char(10);uniqueIndex:aid_day"`
+	// ReadCnt 去重之后的阅读数
+	ReadCnt int64
+	UV      int64
+	Ctime   int64
+	Utime   int64
+}
@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/sqlx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrWaitingSMSNotFound = gorm.ErrRecordNotFound
+
+const (
+	// AsyncStatusWaiting 等待发送，或者发送失败了等待重试
+	AsyncStatusWaiting = iota
+	// AsyncStatusFailed 超过了重试次数
+	AsyncStatusFailed
+	AsyncStatusSuccess
+)
+
+type AsyncSmsDAO interface {
+	Insert(ctx context.Context, s AsyncSms) error
+	// GetWaitingSMS 抢占一个待发送的请求，抢到之后别的实例在一段时间内都不会再抢到
+	GetWaitingSMS(ctx context.Context) (AsyncSms, error)
+	MarkSuccess(ctx context.Context, id int64) error
+	// MarkFailed 记录一次失败，超过重试次数就不再重试
+	MarkFailed(ctx context.Context, id int64) error
+}
+
+type GORMAsyncSmsDAO struct {
+	db *gorm.DB
+	// preemptTimeout 超过这个时间还没有结果，就认为抢到的实例已经崩溃了，允许别人再抢
+	preemptTimeout time.Duration
+}
+
+func NewGORMAsyncSmsDAO(db *gorm.DB) AsyncSmsDAO {
+	return &GORMAsyncSmsDAO{
+		db:             db,
+		preemptTimeout: time.Minute,
+	}
+}
+
+func (g *GORMAsyncSmsDAO) Insert(ctx context.Context, s AsyncSms) error {
+	now := time.Now()
+	s.Ctime = now.UnixMilli()
+	// 和 MarkFailed 一样把 utime 往前挪，新的请求下一轮就可以发送，而不用等抢占超时
+	s.Utime = now.Add(-g.preemptTimeout).UnixMilli()
+	return g.db.WithContext(ctx).Create(&s).Error
+}
+
+func (g *GORMAsyncSmsDAO) GetWaitingSMS(ctx context.Context) (AsyncSms, error) {
+	var s AsyncSms
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		endTime := now - g.preemptTimeout.Milliseconds()
+		// SELECT ... FOR UPDATE，避免多个实例抢到同一个请求
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND utime <= ?", AsyncStatusWaiting, endTime).
+			First(&s).Error
+		if err != nil {
+			return err
+		}
+		// 更新 utime 就相当于占住了这个请求
+		return tx.Model(&AsyncSms{}).
+			Where("id = ?", s.Id).
+			Updates(map[string]any{
+				"utime": now,
+			}).Error
+	})
+	return s, err
+}
+
+func (g *GORMAsyncSmsDAO) MarkSuccess(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Model(&AsyncSms{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"utime":  time.Now().UnixMilli(),
+			"status": AsyncStatusSuccess,
+		}).Error
+}
+
+func (g *GORMAsyncSmsDAO) MarkFailed(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var s AsyncSms
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, AsyncStatusWaiting).
+			First(&s).Error
+		if err != nil {
+			return err
+		}
+		status := uint8(AsyncStatusWaiting)
+		if s.RetryCnt+1 >= s.RetryMax {
+			status = AsyncStatusFailed
+		}
+		return tx.Model(&AsyncSms{}).
+			Where("id = ?", id).
+			Updates(map[string]any{
+				// 把 utime 往前挪，下一轮就可以立刻重试，而不用等抢占超时
+				"utime":     time.Now().Add(-g.preemptTimeout).UnixMilli(),
+				"retry_cnt": s.RetryCnt + 1,
+				"status":    status,
+			}).Error
+	})
+}
+
+type AsyncSms struct {
+	Id     int64                      `gorm:"primaryKey,autoIncrement"`
+	Config sqlx.JsonColumn[SmsConfig] `gorm:"type:text"`
+	// RetryCnt 已经重试的次数
+	RetryCnt int
+	RetryMax int
+	Status   uint8 `gorm:"index:status_utime"`
+	Ctime    int64
+	Utime    int64 `gorm:"index:status_utime"`
+}
+
+type SmsConfig struct {
+	Biz     string
+	Args    []string
+	Numbers []string
+}
@@ -0,0 +1,104 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var errInvalidKey = errors.New("非法的 key")
+
+// FSStore 存储在本地目录，适合开发环境和单实例部署
+type FSStore struct {
+	root    string
+	baseURL string
+}
+
+func NewFSStore(root, baseURL string) *FSStore {
+	return &FSStore{root: root, baseURL: baseURL}
+}
+
+func (s *FSStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.PutReader(ctx, key, bytes.NewReader(data), contentType)
+}
+
+func (s *FSStore) PutReader(ctx context.Context, key string, r io.ReadSeeker, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免读到写了一半的文件
+	f, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, p)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+func (s *FSStore) Get(ctx context.Context, key string) (Object, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	// 本地文件没有元数据，按照扩展名推断
+	return Object{
+		Data:        data,
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+	}, nil
+}
+
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *FSStore) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+// path 把 key 转成本地路径，不允许跳出 root
+func (s *FSStore) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") {
+		return "", errInvalidKey
+	}
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", errInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean[1:])), nil
+}
@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewFSStore(t.TempDir(), "/images/raw/")
+
+	require.NoError(t, s.Put(ctx, "images/ab/abc.png", []byte("png"), "image/png"))
+	obj, err := s.Get(ctx, "images/ab/abc.png")
+	require.NoError(t, err)
+	assert.Equal(t, Object{Data: []byte("png"), ContentType: "image/png"}, obj)
+	assert.Equal(t, "/images/raw/images/ab/abc.png", s.URL("images/ab/abc.png"))
+
+	// 覆盖写
+	require.NoError(t, s.Put(ctx, "images/ab/abc.png", []byte("png2"), "image/png"))
+	obj, err = s.Get(ctx, "images/ab/abc.png")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("png2"), obj.Data)
+
+	require.NoError(t, s.PutReader(ctx, "exports/1/2.zip", strings.NewReader("zip"), "application/zip"))
+	obj, err = s.Get(ctx, "exports/1/2.zip")
+	require.NoError(t, err)
+	assert.Equal(t, Object{Data: []byte("zip"), ContentType: "application/zip"}, obj)
+
+	require.NoError(t, s.Delete(ctx, "images/ab/abc.png"))
+	_, err = s.Get(ctx, "images/ab/abc.png")
+	assert.Equal(t, ErrNotFound, err)
+	// 删除不存在的对象不算错误
+	assert.NoError(t, s.Delete(ctx, "images/ab/abc.png"))
+}
+
+func TestFSStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s := NewFSStore(t.TempDir(), "/images/raw/")
+	for _, key := range []string{"", "../a.png", "images/../../a.png", "/a.png", "a//b.png", "a\\b.png", "a/"} {
+		t.Run(key, func(t *testing.T) {
+			assert.Equal(t, errInvalidKey, s.Put(ctx, key, []byte("x"), "image/png"))
+			_, err := s.Get(ctx, key)
+			assert.Equal(t, errInvalidKey, err)
+		})
+	}
+}
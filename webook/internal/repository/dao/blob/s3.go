@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"io"
+)
+
+// S3Store 存储在兼容 S3 协议的对象存储上，URL 一般配置成 CDN 的地址
+type S3Store struct {
+	oss     *s3.S3
+	bucket  string
+	baseURL string
+}
+
+func NewS3Store(oss *s3.S3, bucket, baseURL string) *S3Store {
+	return &S3Store{oss: oss, bucket: bucket, baseURL: baseURL}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.PutReader(ctx, key, bytes.NewReader(data), contentType)
+}
+
+func (s *S3Store) PutReader(ctx context.Context, key string, r io.ReadSeeker, contentType string) error {
+	_, err := s.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (Object, error) {
+	res, err := s.oss.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Data: data, ContentType: aws.StringValue(res.ContentType)}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	// S3 删除不存在的对象也是成功的
+	_, err := s.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *S3Store) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
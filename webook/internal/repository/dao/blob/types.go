@@ -0,0 +1,33 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("对象不存在")
+
+// Store 对象存储的抽象，key 是形如 images/ab/xxx.png 的相对路径
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// PutReader 和 Put 一样，给比较大的对象用，不用整个放在内存里面
+	// S3 上传的时候要计算长度和签名，所以需要 io.ReadSeeker
+	PutReader(ctx context.Context, key string, r io.ReadSeeker, contentType string) error
+	// Get 对象不存在的时候返回 ErrNotFound
+	Get(ctx context.Context, key string) (Object, error)
+	// Delete 对象不存在不算错误
+	Delete(ctx context.Context, key string) error
+	// URL 对外访问的地址
+	URL(key string) string
+}
+
+type Object struct {
+	Data        []byte
+	ContentType string
+}
+
+func joinURL(baseURL, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(key, "/")
+}
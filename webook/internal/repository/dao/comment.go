@@ -0,0 +1,157 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type CommentDAO interface {
+	// Insert 写入评论，同时增加资源的评论数，回复还会增加根评论的回复数
+	Insert(ctx context.Context, c Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (Comment, error)
+	// FindRoots 按照时间倒序查询根评论，minId 是上一页最后一条评论的 id，第一页传 0
+	FindRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]Comment, error)
+	// FindHotRoots 按照回复数倒序查询根评论
+	FindHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]Comment, error)
+	// FindReplies 按照时间正序查询根评论下面的回复，maxId 是上一页最后一条回复的 id，第一页传 0
+	FindReplies(ctx context.Context, rid, maxId int64, limit int) ([]Comment, error)
+	// Delete 删除评论，根评论会连着回复一起删除，返回一共删除了多少条
+	Delete(ctx context.Context, c Comment) (int64, error)
+}
+
+type GORMCommentDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMCommentDAO(db *gorm.DB) CommentDAO {
+	return &GORMCommentDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMCommentDAO) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&c).Error; err != nil {
+			return err
+		}
+		if c.RootId > 0 {
+			err := tx.Model(&Comment{}).Where("id = ?", c.RootId).
+				Updates(map[string]any{
+					"reply_cnt": gorm.Expr("`reply_cnt` + 1"),
+					"utime":     now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"comment_cnt": gorm.Expr("`comment_cnt` + 1"),
+				"utime":       now,
+			}),
+		}).Create(&Interactive{
+			Biz:        c.Biz,
+			BizId:      c.BizId,
+			CommentCnt: 1,
+			Ctime:      now,
+			Utime:      now,
+		}).Error
+	})
+	return c.Id, err
+}
+
+func (dao *GORMCommentDAO) FindById(ctx context.Context, id int64) (Comment, error) {
+	var res Comment
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	query := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND root_id = 0", biz, bizId)
+	if minId > 0 {
+		query = query.Where("id < ?", minId)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]Comment, error) {
+	var res []Comment
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND root_id = 0", biz, bizId).
+		Order("reply_cnt DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) FindReplies(ctx context.Context, rid, maxId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := dao.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rid, maxId).
+		Order("id ASC").Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMCommentDAO) Delete(ctx context.Context, c Comment) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Where("id = ?", c.Id).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		// 已经被删除了，例如作者和评论者同时删除
+		if res.RowsAffected == 0 {
+			return ErrDataNotFound
+		}
+		cnt = res.RowsAffected
+		if c.RootId == 0 {
+			res = tx.Where("root_id = ?", c.Id).Delete(&Comment{})
+			if res.Error != nil {
+				return res.Error
+			}
+			cnt += res.RowsAffected
+		} else {
+			err := tx.Model(&Comment{}).
+				Where("id = ? AND reply_cnt > 0", c.RootId).
+				Updates(map[string]any{
+					"reply_cnt": gorm.Expr("`reply_cnt` - 1"),
+					"utime":     now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&Interactive{}).
+			Where("biz = ? AND biz_id = ?", c.Biz, c.BizId).
+			Updates(map[string]any{
+				"comment_cnt": gorm.Expr("GREATEST(`comment_cnt` - ?, 0)", cnt),
+				"utime":       now,
+			}).Error
+	})
+	return cnt, err
+}
+
+type Comment struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"index"`
+	// 按照资源查询根评论
+	Biz    string `gorm:"type:varchar(128);index:biz_type_id_root"`
+	BizId  int64  `gorm:"index:biz_type_id_root"`
+	RootId int64  `gorm:"index:biz_type_id_root;index"`
+	// ParentId 只是用来展示回复的是谁，不需要索引
+	ParentId int64
+	Content  string `gorm:"type:text"`
+	ReplyCnt int64
+	Ctime    int64
+	Utime    int64
+}
@@ -0,0 +1,167 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	FollowRelationStatusInactive uint8 = iota
+	FollowRelationStatusActive
+)
+
+type FollowDAO interface {
+	// CreateFollowRelation 关注，已经关注了的话什么也不做，返回值代表关注关系是否有变化
+	CreateFollowRelation(ctx context.Context, follower, followee int64) (bool, error)
+	// CancelFollowRelation 取消关注，没有关注的话什么也不做，返回值代表关注关系是否有变化
+	CancelFollowRelation(ctx context.Context, follower, followee int64) (bool, error)
+	// FollowerList 某个人的粉丝列表
+	FollowerList(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error)
+	// FolloweeList 某个人关注了哪些人
+	FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error)
+	FollowRelationDetail(ctx context.Context, follower, followee int64) (FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+}
+
+type GORMFollowDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFollowDAO(db *gorm.DB) FollowDAO {
+	return &GORMFollowDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMFollowDAO) CreateFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	return dao.updateRelation(ctx, follower, followee, FollowRelationStatusActive)
+}
+
+func (dao *GORMFollowDAO) CancelFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	return dao.updateRelation(ctx, follower, followee, FollowRelationStatusInactive)
+}
+
+// updateRelation 在同一个事务里面更新关注关系和计数，只有状态真的变化了才更新计数
+func (dao *GORMFollowDAO) updateRelation(ctx context.Context, follower, followee int64, status uint8) (bool, error) {
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		var fr FollowRelation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("follower = ? AND followee = ?", follower, followee).
+			First(&fr).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if status == FollowRelationStatusInactive {
+				return nil
+			}
+			err = tx.Create(&FollowRelation{
+				Follower: follower,
+				Followee: followee,
+				Status:   status,
+				Ctime:    now,
+				Utime:    now,
+			}).Error
+		case err != nil:
+			return err
+		case fr.Status == status:
+			return nil
+		default:
+			err = tx.Model(&FollowRelation{}).
+				Where("id = ?", fr.Id).
+				Updates(map[string]any{
+					"status": status,
+					"utime":  now,
+				}).Error
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+		delta := 1
+		if status == FollowRelationStatusInactive {
+			delta = -1
+		}
+		if err = dao.incrStatics(tx, followee, "followers", delta, now); err != nil {
+			return err
+		}
+		return dao.incrStatics(tx, follower, "followees", delta, now)
+	})
+	return changed, err
+}
+
+func (dao *GORMFollowDAO) incrStatics(tx *gorm.DB, uid int64, field string, delta int, now int64) error {
+	statics := FollowStatics{Uid: uid, Ctime: now, Utime: now}
+	if field == "followers" {
+		statics.Followers = int64(delta)
+	} else {
+		statics.Followees = int64(delta)
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			field:   gorm.Expr("`"+field+"` + ?", delta),
+			"utime": now,
+		}),
+	}).Create(&statics).Error
+}
+
+func (dao *GORMFollowDAO) FollowerList(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("followee = ? AND status = ?", followee, FollowRelationStatusActive).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND status = ?", follower, FollowRelationStatusActive).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FollowRelationDetail(ctx context.Context, follower, followee int64) (FollowRelation, error) {
+	var res FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?", follower, followee, FollowRelationStatusActive).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		First(&res).Error
+	return res, err
+}
+
+// FollowRelation 关注关系
+// 查询粉丝列表的时候用 followee 的索引，查询关注列表的时候用唯一索引的前缀
+type FollowRelation struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	Follower int64 `gorm:"uniqueIndex:follower_followee"`
+	Followee int64 `gorm:"uniqueIndex:follower_followee;index"`
+	// 软删除，取消关注之后再关注只需要更新状态
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
+
+// FollowStatics 关注的计数，和 Interactive 一样每个用户一行
+type FollowStatics struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"uniqueIndex"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type HistoryDAO interface {
+	// Upsert 同一个资源只保留一条记录，重复阅读只更新阅读时间
+	Upsert(ctx context.Context, h ReadHistory) error
+	// List 按照阅读时间倒序
+	List(ctx context.Context, uid int64, offset, limit int) ([]ReadHistory, error)
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	DeleteAll(ctx context.Context, uid int64) error
+	// Trim 只保留最近的 capacity 条记录
+	Trim(ctx context.Context, uid int64, capacity int) error
+}
+
+type GORMHistoryDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMHistoryDAO(db *gorm.DB) HistoryDAO {
+	return &GORMHistoryDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMHistoryDAO) Upsert(ctx context.Context, h ReadHistory) error {
+	now := time.Now().UnixMilli()
+	h.Ctime = now
+	h.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"utime": now,
+		}),
+	}).Create(&h).Error
+}
+
+func (dao *GORMHistoryDAO) List(ctx context.Context, uid int64, offset, limit int) ([]ReadHistory, error) {
+	var res []ReadHistory
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Order("utime DESC").Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMHistoryDAO) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id = ?", uid, biz, bizId).
+		Delete(&ReadHistory{}).Error
+}
+
+func (dao *GORMHistoryDAO) DeleteAll(ctx context.Context, uid int64) error {
+	return dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Delete(&ReadHistory{}).Error
+}
+
+func (dao *GORMHistoryDAO) Trim(ctx context.Context, uid int64, capacity int) error {
+	// 每次最多删除 100 条，正常情况下每次新增一条，只需要删除一条
+	var ids []int64
+	err := dao.db.WithContext(ctx).Model(&ReadHistory{}).
+		Where("uid = ?", uid).
+		Order("utime DESC").Order("id DESC").
+		Offset(capacity).Limit(100).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	return dao.db.WithContext(ctx).
+		Where("id IN ?", ids).
+		Delete(&ReadHistory{}).Error
+}
+
+// ReadHistory 阅读历史
+type ReadHistory struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_id;index:uid_utime"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_id"`
+	Ctime int64
+	Utime int64 `gorm:"index:uid_utime"`
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrImageNotFound = gorm.ErrRecordNotFound
+
+type ImageDAO interface {
+	// Insert 内容相同的图片只保存一份，已经存在的时候返回已有的记录
+	Insert(ctx context.Context, img Image) (Image, error)
+	FindByHash(ctx context.Context, hash string) (Image, error)
+}
+
+type GORMImageDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMImageDAO(db *gorm.DB) ImageDAO {
+	return &GORMImageDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMImageDAO) Insert(ctx context.Context, img Image) (Image, error) {
+	now := time.Now().UnixMilli()
+	img.Ctime = now
+	img.Utime = now
+	// 并发上传同一张图片的时候，以先插入的为准
+	err := dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoNothing: true,
+	}).Create(&img).Error
+	if err != nil {
+		return Image{}, err
+	}
+	return dao.FindByHash(ctx, img.Hash)
+}
+
+func (dao *GORMImageDAO) FindByHash(ctx context.Context, hash string) (Image, error) {
+	var res Image
+	err := dao.db.WithContext(ctx).Where("hash = ?", hash).First(&res).Error
+	return res, err
+}
+
+// Image 上传的图片，按照内容的 sha256 去重
+type Image struct {
+	Id   int64  `gorm:"primaryKey,autoIncrement"`
+	Hash string `gorm:"type:char(64);uniqueIndex"`
+	// Key 在对象存储里面的 key
+	Key         string `gorm:"type:varchar(256)"`
+	ContentType string `gorm:"type:varchar(64)"`
+	Size        int64
+	Width       int
+	Height      int
+	// Uploader 第一次上传的用户
+	Uploader int64 `gorm:"index"`
+	Ctime    int64
+	Utime    int64
+}
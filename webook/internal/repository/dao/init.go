@@ -11,9 +11,29 @@ func InitTable(db *gorm.DB) error {
 		&article.Article{},
 		&article.PublishedArticle{},
 		&article.PublishedArticleV1{},
+		&article.ArticleRevision{},
+		&article.Tag{},
+		&article.ArticleTag{},
+		&article.PublishedArticleTag{},
+		&article.PubChange{},
+		&article.Series{},
+		&article.SeriesArticle{},
 		&Interactive{},
 		&UserLikeBiz{},
 		&Collection{},
 		&UserCollectionBiz{},
+		&AsyncSms{},
+		&ReadHistory{},
+		&FollowRelation{},
+		&FollowStatics{},
+		&Comment{},
+		&Notification{},
+		&NotificationActor{},
+		&Image{},
+		&ArticleTransfer{},
+		&ArticleDailyStats{},
+		&ArticleDailyAnalytics{},
+		&AnalyticsEvent{},
+		&AuthorDailyAnalytics{},
 	) // 若有其他表，则继续往&User{}后添加
 }
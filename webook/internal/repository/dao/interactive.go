@@ -16,6 +16,25 @@ type InteractiveDAO interface {
 	InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error
 	GetCollectionInfo(ctx context.Context, biz string, bizId, uid int64) (UserCollectionBiz, error)
 	BatchIncrReadCnt(ctx context.Context, bizs []string, ids []int64) error
+	// GetByIds 批量查询计数，不存在的资源不会出现在返回值里面
+	GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error)
+	// GetLikeInfos 批量查询用户点赞了哪些资源
+	GetLikeInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserLikeBiz, error)
+	// GetCollectionInfos 批量查询用户收藏了哪些资源
+	GetCollectionInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserCollectionBiz, error)
+
+	// 收藏夹部分，所有的操作都带上了 uid，避免操作别人的收藏夹
+
+	InsertCollection(ctx context.Context, c Collection) (int64, error)
+	UpdateCollectionName(ctx context.Context, id, uid int64, name string) error
+	GetCollections(ctx context.Context, uid int64, offset, limit int) ([]Collection, error)
+	// DeleteCollection 删除收藏夹以及里面的资源，返回被删除的资源，调用者用来更新缓存
+	DeleteCollection(ctx context.Context, id, uid int64) ([]UserCollectionBiz, error)
+	// DeleteCollectionBiz 取消收藏，同时减少收藏数
+	DeleteCollectionBiz(ctx context.Context, biz string, bizId, uid int64) error
+	GetCollectionBizs(ctx context.Context, cid, uid int64, offset, limit int) ([]UserCollectionBiz, error)
+	// MoveCollectionBiz 把资源移动到另外一个收藏夹
+	MoveCollectionBiz(ctx context.Context, biz string, bizId, uid, cid int64) error
 }
 
 type GORMInteractiveDAO struct {
@@ -36,6 +55,14 @@ func (dao *GORMInteractiveDAO) Get(ctx context.Context, biz string, bizId int64)
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetByIds(ctx context.Context, biz string, ids []int64) ([]Interactive, error) {
+	var res []Interactive
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ?", biz, ids).
+		Find(&res).Error
+	return res, err
+}
+
 // IncrReadCnt 是一个插入或者更新语义
 func (dao *GORMInteractiveDAO) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
 	return dao.incrReadCnt(dao.db.WithContext(ctx), biz, bizId)
@@ -76,6 +103,14 @@ func (dao *GORMInteractiveDAO) GetLikeInfo(ctx context.Context, biz string, bizI
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetLikeInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserLikeBiz, error) {
+	var res []UserLikeBiz
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ? AND uid = ? AND status = ?", biz, ids, uid, 1).
+		Find(&res).Error
+	return res, err
+}
+
 func (dao *GORMInteractiveDAO) InsertLikeInfo(ctx context.Context, biz string, bizId, uid int64) error {
 	now := time.Now().UnixMilli()
 	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
@@ -145,12 +180,25 @@ func (dao *GORMInteractiveDAO) GetCollectionInfo(ctx context.Context, biz string
 	return res, err
 }
 
+func (dao *GORMInteractiveDAO) GetCollectionInfos(ctx context.Context, biz string, ids []int64, uid int64) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ? AND uid = ?", biz, ids, uid).
+		Find(&res).Error
+	return res, err
+}
+
 func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserCollectionBiz) error {
 	now := time.Now().UnixMilli()
 	cb.Utime = now
 	cb.Ctime = now
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := dao.db.WithContext(ctx).Create(&cb).Error
+		if cb.Cid > 0 {
+			if err := dao.checkCollectionOwner(tx, cb.Cid, cb.Uid); err != nil {
+				return err
+			}
+		}
+		err := tx.Create(&cb).Error
 		if err != nil {
 			return err
 		}
@@ -169,6 +217,131 @@ func (dao *GORMInteractiveDAO) InsertCollectionBiz(ctx context.Context, cb UserC
 	})
 }
 
+func (dao *GORMInteractiveDAO) InsertCollection(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := dao.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+func (dao *GORMInteractiveDAO) UpdateCollectionName(ctx context.Context, id, uid int64, name string) error {
+	res := dao.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", id, uid).
+		Updates(map[string]any{
+			"name":  name,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDataNotFound
+	}
+	return nil
+}
+
+func (dao *GORMInteractiveDAO) GetCollections(ctx context.Context, uid int64, offset, limit int) ([]Collection, error) {
+	var res []Collection
+	err := dao.db.WithContext(ctx).
+		Where("uid = ?", uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) DeleteCollection(ctx context.Context, id, uid int64) ([]UserCollectionBiz, error) {
+	var items []UserCollectionBiz
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dao.checkCollectionOwner(tx, id, uid); err != nil {
+			return err
+		}
+		err := tx.Where("cid = ? AND uid = ?", id, uid).Find(&items).Error
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err = dao.deleteCollectionBiz(tx, item.Biz, item.BizId, uid); err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", id).Delete(&Collection{}).Error
+	})
+	return items, err
+}
+
+func (dao *GORMInteractiveDAO) DeleteCollectionBiz(ctx context.Context, biz string, bizId, uid int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return dao.deleteCollectionBiz(tx, biz, bizId, uid)
+	})
+}
+
+// deleteCollectionBiz 只有真的删除了收藏记录，才减少收藏数，避免重复取消收藏导致计数错误
+func (dao *GORMInteractiveDAO) deleteCollectionBiz(tx *gorm.DB, biz string, bizId, uid int64) error {
+	res := tx.Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).
+		Delete(&UserCollectionBiz{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDataNotFound
+	}
+	return tx.Model(&Interactive{}).
+		Where("biz = ? AND biz_id = ? AND collect_cnt > 0", biz, bizId).
+		Updates(map[string]any{
+			"collect_cnt": gorm.Expr("`collect_cnt`-1"),
+			"utime":       time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMInteractiveDAO) GetCollectionBizs(ctx context.Context, cid, uid int64, offset, limit int) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).
+		Where("cid = ? AND uid = ?", cid, uid).
+		Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMInteractiveDAO) MoveCollectionBiz(ctx context.Context, biz string, bizId, uid, cid int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if cid > 0 {
+			if err := dao.checkCollectionOwner(tx, cid, uid); err != nil {
+				return err
+			}
+		}
+		res := tx.Model(&UserCollectionBiz{}).
+			Where("biz = ? AND biz_id = ? AND uid = ?", biz, bizId, uid).
+			Updates(map[string]any{
+				"cid":   cid,
+				"utime": time.Now().UnixMilli(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrDataNotFound
+		}
+		return nil
+	})
+}
+
+func (dao *GORMInteractiveDAO) checkCollectionOwner(tx *gorm.DB, cid, uid int64) error {
+	var cnt int64
+	err := tx.Model(&Collection{}).
+		Where("id = ? AND uid = ?", cid, uid).
+		Count(&cnt).Error
+	if err != nil {
+		return err
+	}
+	if cnt == 0 {
+		return ErrDataNotFound
+	}
+	return nil
+}
+
 type Interactive struct {
 	Id int64 `gorm:"primaryKey,autoIncrement"` // 只有一行 对读更友好
 	// 业务标识符 联合索引
@@ -178,6 +351,7 @@ type Interactive struct {
 	ReadCnt    int64
 	LikeCnt    int64
 	CollectCnt int64
+	CommentCnt int64
 	Ctime      int64
 	Utime      int64
 }
@@ -200,7 +374,7 @@ type UserLikeBiz struct {
 type Collection struct {
 	Id    int64  `gorm:"primaryKey,autoIncrement"`
 	Name  string `gorm:"type=varchar(1024)"`
-	Uid   int64  `gorm:""`
+	Uid   int64  `gorm:"index"`
 	Ctime int64
 	Utime int64
 }
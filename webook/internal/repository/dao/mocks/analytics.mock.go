@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/analytics.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/analytics.go -package=daomocks -destination=webook/internal/repository/dao/mocks/analytics.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAnalyticsDAO is a mock of AnalyticsDAO interface.
+type MockAnalyticsDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsDAOMockRecorder
+}
+
+// MockAnalyticsDAOMockRecorder is the mock recorder for MockAnalyticsDAO.
+type MockAnalyticsDAOMockRecorder struct {
+	mock *MockAnalyticsDAO
+}
+
+// NewMockAnalyticsDAO creates a new mock instance.
+func NewMockAnalyticsDAO(ctrl *gomock.Controller) *MockAnalyticsDAO {
+	mock := &MockAnalyticsDAO{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsDAO) EXPECT() *MockAnalyticsDAOMockRecorder {
+	return m.recorder
+}
+
+// FindArticleDaily mocks base method.
+func (m *MockAnalyticsDAO) FindArticleDaily(ctx context.Context, aid int64, from, to string) ([]dao.ArticleDailyAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindArticleDaily", ctx, aid, from, to)
+	ret0, _ := ret[0].([]dao.ArticleDailyAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindArticleDaily indicates an expected call of FindArticleDaily.
+func (mr *MockAnalyticsDAOMockRecorder) FindArticleDaily(ctx, aid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindArticleDaily", reflect.TypeOf((*MockAnalyticsDAO)(nil).FindArticleDaily), ctx, aid, from, to)
+}
+
+// FindAuthorDaily mocks base method.
+func (m *MockAnalyticsDAO) FindAuthorDaily(ctx context.Context, uid int64, from, to string) ([]dao.AuthorDailyAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAuthorDaily", ctx, uid, from, to)
+	ret0, _ := ret[0].([]dao.AuthorDailyAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAuthorDaily indicates an expected call of FindAuthorDaily.
+func (mr *MockAnalyticsDAOMockRecorder) FindAuthorDaily(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAuthorDaily", reflect.TypeOf((*MockAnalyticsDAO)(nil).FindAuthorDaily), ctx, uid, from, to)
+}
+
+// IncrArticle mocks base method.
+func (m *MockAnalyticsDAO) IncrArticle(ctx context.Context, evt dao.AnalyticsEvent, delta dao.ArticleDailyAnalytics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrArticle", ctx, evt, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrArticle indicates an expected call of IncrArticle.
+func (mr *MockAnalyticsDAOMockRecorder) IncrArticle(ctx, evt, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrArticle", reflect.TypeOf((*MockAnalyticsDAO)(nil).IncrArticle), ctx, evt, delta)
+}
+
+// IncrAuthor mocks base method.
+func (m *MockAnalyticsDAO) IncrAuthor(ctx context.Context, evt dao.AnalyticsEvent, delta dao.AuthorDailyAnalytics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrAuthor", ctx, evt, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrAuthor indicates an expected call of IncrAuthor.
+func (mr *MockAnalyticsDAOMockRecorder) IncrAuthor(ctx, evt, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrAuthor", reflect.TypeOf((*MockAnalyticsDAO)(nil).IncrAuthor), ctx, evt, delta)
+}
+
+// SumByArticle mocks base method.
+func (m *MockAnalyticsDAO) SumByArticle(ctx context.Context, uid int64, from, to string) ([]dao.ArticleDailyAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByArticle", ctx, uid, from, to)
+	ret0, _ := ret[0].([]dao.ArticleDailyAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByArticle indicates an expected call of SumByArticle.
+func (mr *MockAnalyticsDAOMockRecorder) SumByArticle(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByArticle", reflect.TypeOf((*MockAnalyticsDAO)(nil).SumByArticle), ctx, uid, from, to)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/article_stats.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/article_stats.go -package=daomocks -destination=webook/internal/repository/dao/mocks/article_stats.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleStatsDAO is a mock of ArticleStatsDAO interface.
+type MockArticleStatsDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleStatsDAOMockRecorder
+}
+
+// MockArticleStatsDAOMockRecorder is the mock recorder for MockArticleStatsDAO.
+type MockArticleStatsDAOMockRecorder struct {
+	mock *MockArticleStatsDAO
+}
+
+// NewMockArticleStatsDAO creates a new mock instance.
+func NewMockArticleStatsDAO(ctrl *gomock.Controller) *MockArticleStatsDAO {
+	mock := &MockArticleStatsDAO{ctrl: ctrl}
+	mock.recorder = &MockArticleStatsDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleStatsDAO) EXPECT() *MockArticleStatsDAOMockRecorder {
+	return m.recorder
+}
+
+// FindDaily mocks base method.
+func (m *MockArticleStatsDAO) FindDaily(ctx context.Context, aid int64, from, to string) ([]dao.ArticleDailyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDaily", ctx, aid, from, to)
+	ret0, _ := ret[0].([]dao.ArticleDailyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDaily indicates an expected call of FindDaily.
+func (mr *MockArticleStatsDAOMockRecorder) FindDaily(ctx, aid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDaily", reflect.TypeOf((*MockArticleStatsDAO)(nil).FindDaily), ctx, aid, from, to)
+}
+
+// SumAuthorDaily mocks base method.
+func (m *MockArticleStatsDAO) SumAuthorDaily(ctx context.Context, uid int64, from, to string) ([]dao.ArticleDailyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumAuthorDaily", ctx, uid, from, to)
+	ret0, _ := ret[0].([]dao.ArticleDailyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumAuthorDaily indicates an expected call of SumAuthorDaily.
+func (mr *MockArticleStatsDAOMockRecorder) SumAuthorDaily(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumAuthorDaily", reflect.TypeOf((*MockArticleStatsDAO)(nil).SumAuthorDaily), ctx, uid, from, to)
+}
+
+// SumByArticle mocks base method.
+func (m *MockArticleStatsDAO) SumByArticle(ctx context.Context, uid int64, from, to string) ([]dao.ArticleDailyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumByArticle", ctx, uid, from, to)
+	ret0, _ := ret[0].([]dao.ArticleDailyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumByArticle indicates an expected call of SumByArticle.
+func (mr *MockArticleStatsDAOMockRecorder) SumByArticle(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumByArticle", reflect.TypeOf((*MockArticleStatsDAO)(nil).SumByArticle), ctx, uid, from, to)
+}
+
+// Upsert mocks base method.
+func (m *MockArticleStatsDAO) Upsert(ctx context.Context, stats []dao.ArticleDailyStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockArticleStatsDAOMockRecorder) Upsert(ctx, stats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockArticleStatsDAO)(nil).Upsert), ctx, stats)
+}
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/comment.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/comment.go -package=daomocks -destination=webook/internal/repository/dao/mocks/comment.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCommentDAO is a mock of CommentDAO interface.
+type MockCommentDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentDAOMockRecorder
+}
+
+// MockCommentDAOMockRecorder is the mock recorder for MockCommentDAO.
+type MockCommentDAOMockRecorder struct {
+	mock *MockCommentDAO
+}
+
+// NewMockCommentDAO creates a new mock instance.
+func NewMockCommentDAO(ctrl *gomock.Controller) *MockCommentDAO {
+	mock := &MockCommentDAO{ctrl: ctrl}
+	mock.recorder = &MockCommentDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentDAO) EXPECT() *MockCommentDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCommentDAO) Delete(ctx context.Context, c dao.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentDAOMockRecorder) Delete(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentDAO)(nil).Delete), ctx, c)
+}
+
+// FindById mocks base method.
+func (m *MockCommentDAO) FindById(ctx context.Context, id int64) (dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockCommentDAOMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockCommentDAO)(nil).FindById), ctx, id)
+}
+
+// FindHotRoots mocks base method.
+func (m *MockCommentDAO) FindHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHotRoots", ctx, biz, bizId, offset, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHotRoots indicates an expected call of FindHotRoots.
+func (mr *MockCommentDAOMockRecorder) FindHotRoots(ctx, biz, bizId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHotRoots", reflect.TypeOf((*MockCommentDAO)(nil).FindHotRoots), ctx, biz, bizId, offset, limit)
+}
+
+// FindReplies mocks base method.
+func (m *MockCommentDAO) FindReplies(ctx context.Context, rid, maxId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReplies", ctx, rid, maxId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReplies indicates an expected call of FindReplies.
+func (mr *MockCommentDAOMockRecorder) FindReplies(ctx, rid, maxId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReplies", reflect.TypeOf((*MockCommentDAO)(nil).FindReplies), ctx, rid, maxId, limit)
+}
+
+// FindRoots mocks base method.
+func (m *MockCommentDAO) FindRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]dao.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoots", ctx, biz, bizId, minId, limit)
+	ret0, _ := ret[0].([]dao.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoots indicates an expected call of FindRoots.
+func (mr *MockCommentDAOMockRecorder) FindRoots(ctx, biz, bizId, minId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoots", reflect.TypeOf((*MockCommentDAO)(nil).FindRoots), ctx, biz, bizId, minId, limit)
+}
+
+// Insert mocks base method.
+func (m *MockCommentDAO) Insert(ctx context.Context, c dao.Comment) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, c)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockCommentDAOMockRecorder) Insert(ctx, c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockCommentDAO)(nil).Insert), ctx, c)
+}
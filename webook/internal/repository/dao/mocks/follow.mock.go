@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/follow.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/follow.go -package=daomocks -destination=webook/internal/repository/dao/mocks/follow.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFollowDAO is a mock of FollowDAO interface.
+type MockFollowDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockFollowDAOMockRecorder
+}
+
+// MockFollowDAOMockRecorder is the mock recorder for MockFollowDAO.
+type MockFollowDAOMockRecorder struct {
+	mock *MockFollowDAO
+}
+
+// NewMockFollowDAO creates a new mock instance.
+func NewMockFollowDAO(ctrl *gomock.Controller) *MockFollowDAO {
+	mock := &MockFollowDAO{ctrl: ctrl}
+	mock.recorder = &MockFollowDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFollowDAO) EXPECT() *MockFollowDAOMockRecorder {
+	return m.recorder
+}
+
+// CancelFollowRelation mocks base method.
+func (m *MockFollowDAO) CancelFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelFollowRelation", ctx, follower, followee)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelFollowRelation indicates an expected call of CancelFollowRelation.
+func (mr *MockFollowDAOMockRecorder) CancelFollowRelation(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelFollowRelation", reflect.TypeOf((*MockFollowDAO)(nil).CancelFollowRelation), ctx, follower, followee)
+}
+
+// CreateFollowRelation mocks base method.
+func (m *MockFollowDAO) CreateFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFollowRelation", ctx, follower, followee)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFollowRelation indicates an expected call of CreateFollowRelation.
+func (mr *MockFollowDAOMockRecorder) CreateFollowRelation(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFollowRelation", reflect.TypeOf((*MockFollowDAO)(nil).CreateFollowRelation), ctx, follower, followee)
+}
+
+// FollowRelationDetail mocks base method.
+func (m *MockFollowDAO) FollowRelationDetail(ctx context.Context, follower, followee int64) (dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowRelationDetail", ctx, follower, followee)
+	ret0, _ := ret[0].(dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowRelationDetail indicates an expected call of FollowRelationDetail.
+func (mr *MockFollowDAOMockRecorder) FollowRelationDetail(ctx, follower, followee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowRelationDetail", reflect.TypeOf((*MockFollowDAO)(nil).FollowRelationDetail), ctx, follower, followee)
+}
+
+// FolloweeList mocks base method.
+func (m *MockFollowDAO) FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FolloweeList", ctx, follower, offset, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FolloweeList indicates an expected call of FolloweeList.
+func (mr *MockFollowDAOMockRecorder) FolloweeList(ctx, follower, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FolloweeList", reflect.TypeOf((*MockFollowDAO)(nil).FolloweeList), ctx, follower, offset, limit)
+}
+
+// FollowerList mocks base method.
+func (m *MockFollowDAO) FollowerList(ctx context.Context, followee int64, offset, limit int) ([]dao.FollowRelation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FollowerList", ctx, followee, offset, limit)
+	ret0, _ := ret[0].([]dao.FollowRelation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FollowerList indicates an expected call of FollowerList.
+func (mr *MockFollowDAOMockRecorder) FollowerList(ctx, followee, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowerList", reflect.TypeOf((*MockFollowDAO)(nil).FollowerList), ctx, followee, offset, limit)
+}
+
+// GetStatics mocks base method.
+func (m *MockFollowDAO) GetStatics(ctx context.Context, uid int64) (dao.FollowStatics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatics", ctx, uid)
+	ret0, _ := ret[0].(dao.FollowStatics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatics indicates an expected call of GetStatics.
+func (mr *MockFollowDAOMockRecorder) GetStatics(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatics", reflect.TypeOf((*MockFollowDAO)(nil).GetStatics), ctx, uid)
+}
@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/history.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/history.go -package=daomocks -destination=webook/internal/repository/dao/mocks/history.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockHistoryDAO is a mock of HistoryDAO interface.
+type MockHistoryDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryDAOMockRecorder
+}
+
+// MockHistoryDAOMockRecorder is the mock recorder for MockHistoryDAO.
+type MockHistoryDAOMockRecorder struct {
+	mock *MockHistoryDAO
+}
+
+// NewMockHistoryDAO creates a new mock instance.
+func NewMockHistoryDAO(ctrl *gomock.Controller) *MockHistoryDAO {
+	mock := &MockHistoryDAO{ctrl: ctrl}
+	mock.recorder = &MockHistoryDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryDAO) EXPECT() *MockHistoryDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockHistoryDAO) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, biz, bizId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockHistoryDAOMockRecorder) Delete(ctx, uid, biz, bizId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHistoryDAO)(nil).Delete), ctx, uid, biz, bizId)
+}
+
+// DeleteAll mocks base method.
+func (m *MockHistoryDAO) DeleteAll(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAll", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAll indicates an expected call of DeleteAll.
+func (mr *MockHistoryDAOMockRecorder) DeleteAll(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockHistoryDAO)(nil).DeleteAll), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockHistoryDAO) List(ctx context.Context, uid int64, offset, limit int) ([]dao.ReadHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]dao.ReadHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockHistoryDAOMockRecorder) List(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockHistoryDAO)(nil).List), ctx, uid, offset, limit)
+}
+
+// Trim mocks base method.
+func (m *MockHistoryDAO) Trim(ctx context.Context, uid int64, capacity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trim", ctx, uid, capacity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Trim indicates an expected call of Trim.
+func (mr *MockHistoryDAOMockRecorder) Trim(ctx, uid, capacity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trim", reflect.TypeOf((*MockHistoryDAO)(nil).Trim), ctx, uid, capacity)
+}
+
+// Upsert mocks base method.
+func (m *MockHistoryDAO) Upsert(ctx context.Context, h dao.ReadHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, h)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockHistoryDAOMockRecorder) Upsert(ctx, h any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockHistoryDAO)(nil).Upsert), ctx, h)
+}
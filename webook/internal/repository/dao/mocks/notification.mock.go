@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/dao/notification.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/dao/notification.go -package=daomocks -destination=webook/internal/repository/dao/mocks/notification.mock.go
+//
+
+// Package daomocks is a generated GoMock package.
+package daomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	dao "webook/internal/repository/dao"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockNotificationDAO is a mock of NotificationDAO interface.
+type MockNotificationDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationDAOMockRecorder
+}
+
+// MockNotificationDAOMockRecorder is the mock recorder for MockNotificationDAO.
+type MockNotificationDAOMockRecorder struct {
+	mock *MockNotificationDAO
+}
+
+// NewMockNotificationDAO creates a new mock instance.
+func NewMockNotificationDAO(ctrl *gomock.Controller) *MockNotificationDAO {
+	mock := &MockNotificationDAO{ctrl: ctrl}
+	mock.recorder = &MockNotificationDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationDAO) EXPECT() *MockNotificationDAOMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockNotificationDAO) CountUnread(ctx context.Context, receiver int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, receiver)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockNotificationDAOMockRecorder) CountUnread(ctx, receiver any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockNotificationDAO)(nil).CountUnread), ctx, receiver)
+}
+
+// List mocks base method.
+func (m *MockNotificationDAO) List(ctx context.Context, receiver int64, offset, limit int) ([]dao.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, receiver, offset, limit)
+	ret0, _ := ret[0].([]dao.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockNotificationDAOMockRecorder) List(ctx, receiver, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockNotificationDAO)(nil).List), ctx, receiver, offset, limit)
+}
+
+// MarkRead mocks base method.
+func (m *MockNotificationDAO) MarkRead(ctx context.Context, receiver int64, ids []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, receiver, ids)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockNotificationDAOMockRecorder) MarkRead(ctx, receiver, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockNotificationDAO)(nil).MarkRead), ctx, receiver, ids)
+}
+
+// Upsert mocks base method.
+func (m *MockNotificationDAO) Upsert(ctx context.Context, n dao.Notification) (dao.Notification, dao.NotificationChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, n)
+	ret0, _ := ret[0].(dao.Notification)
+	ret1, _ := ret[1].(dao.NotificationChange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockNotificationDAOMockRecorder) Upsert(ctx, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockNotificationDAO)(nil).Upsert), ctx, n)
+}
@@ -0,0 +1,175 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/sqlx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	NotificationStatusUnread uint8 = iota
+	NotificationStatusRead
+)
+
+// maxRecentActors 每一条通知最多记录最近的几个人，用于展示
+const maxRecentActors = 3
+
+type NotificationChange uint8
+
+const (
+	// NotificationUnchanged 这个人已经在通知里面了，例如取消点赞之后又点赞
+	NotificationUnchanged NotificationChange = iota
+	// NotificationUpdated 通知有更新，但是原本就是未读的
+	NotificationUpdated
+	// NotificationUnread 多了一条未读的通知
+	NotificationUnread
+)
+
+type NotificationDAO interface {
+	// Upsert 聚合写入通知，同一个接收者、类型、资源和来源的通知只有一条
+	Upsert(ctx context.Context, n Notification) (Notification, NotificationChange, error)
+	// List 按照更新时间倒序
+	List(ctx context.Context, receiver int64, offset, limit int) ([]Notification, error)
+	// MarkRead 标记已读，ids 为空的时候标记全部
+	MarkRead(ctx context.Context, receiver int64, ids []int64) error
+	CountUnread(ctx context.Context, receiver int64) (int64, error)
+}
+
+type GORMNotificationDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMNotificationDAO(db *gorm.DB) NotificationDAO {
+	return &GORMNotificationDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMNotificationDAO) Upsert(ctx context.Context, n Notification) (Notification, NotificationChange, error) {
+	change := NotificationUnchanged
+	actor := n.LastActor
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		var old Notification
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("receiver = ? AND type = ? AND biz = ? AND biz_id = ? AND source_id = ?",
+				n.Receiver, n.Type, n.Biz, n.BizId, n.SourceId).
+			First(&old).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			n.ActorCnt = 1
+			n.RecentActors = sqlx.JsonColumn[[]int64]{Val: []int64{actor}, Valid: true}
+			n.Status = NotificationStatusUnread
+			n.Ctime = now
+			n.Utime = now
+			if err = tx.Create(&n).Error; err != nil {
+				return err
+			}
+			change = NotificationUnread
+			return tx.Create(&NotificationActor{Nid: n.Id, Actor: actor, Ctime: now}).Error
+		case err != nil:
+			return err
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&NotificationActor{Nid: old.Id, Actor: actor, Ctime: now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			n = old
+			return nil
+		}
+		change = NotificationUpdated
+		if old.Status == NotificationStatusRead {
+			change = NotificationUnread
+		}
+		old.ActorCnt++
+		old.LastActor = actor
+		old.RecentActors = sqlx.JsonColumn[[]int64]{Val: recentActors(old.RecentActors.Val, actor), Valid: true}
+		old.Content = n.Content
+		old.Status = NotificationStatusUnread
+		old.Utime = now
+		n = old
+		return tx.Model(&Notification{}).Where("id = ?", old.Id).
+			Updates(map[string]any{
+				"actor_cnt":     gorm.Expr("`actor_cnt` + 1"),
+				"last_actor":    old.LastActor,
+				"recent_actors": old.RecentActors,
+				"content":       old.Content,
+				"status":        old.Status,
+				"utime":         now,
+			}).Error
+	})
+	return n, change, err
+}
+
+func recentActors(actors []int64, actor int64) []int64 {
+	res := make([]int64, 0, maxRecentActors)
+	res = append(res, actor)
+	for _, a := range actors {
+		if len(res) == maxRecentActors {
+			break
+		}
+		if a != actor {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func (dao *GORMNotificationDAO) List(ctx context.Context, receiver int64, offset, limit int) ([]Notification, error) {
+	var res []Notification
+	err := dao.db.WithContext(ctx).
+		Where("receiver = ?", receiver).
+		Order("utime DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMNotificationDAO) MarkRead(ctx context.Context, receiver int64, ids []int64) error {
+	query := dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("receiver = ? AND status = ?", receiver, NotificationStatusUnread)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	// 不更新 utime，避免已读之后改变列表里面的顺序
+	return query.Update("status", NotificationStatusRead).Error
+}
+
+func (dao *GORMNotificationDAO) CountUnread(ctx context.Context, receiver int64) (int64, error) {
+	var res int64
+	err := dao.db.WithContext(ctx).Model(&Notification{}).
+		Where("receiver = ? AND status = ?", receiver, NotificationStatusUnread).
+		Count(&res).Error
+	return res, err
+}
+
+type Notification struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 聚合的维度
+	Receiver int64  `gorm:"uniqueIndex:receiver_type_biz_source;index:receiver_utime"`
+	Type     uint8  `gorm:"uniqueIndex:receiver_type_biz_source"`
+	Biz      string `gorm:"type:varchar(128);uniqueIndex:receiver_type_biz_source"`
+	BizId    int64  `gorm:"uniqueIndex:receiver_type_biz_source"`
+	SourceId int64  `gorm:"uniqueIndex:receiver_type_biz_source"`
+
+	LastActor    int64
+	RecentActors sqlx.JsonColumn[[]int64] `gorm:"type:varchar(512)"`
+	ActorCnt     int64
+	Content      string `gorm:"type:varchar(1024)"`
+	Status       uint8
+	Ctime        int64
+	Utime        int64 `gorm:"index:receiver_utime"`
+}
+
+// NotificationActor 记录聚合通知里面有哪些人，同一个人重复操作不会重复计数
+type NotificationActor struct {
+	Id    int64 `gorm:"primaryKey,autoIncrement"`
+	Nid   int64 `gorm:"uniqueIndex:nid_actor"`
+	Actor int64 `gorm:"uniqueIndex:nid_actor"`
+	Ctime int64
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 内置倒排索引用的分词，没有词典，所以中文按照单字和相邻两个字来切分
+// 英文和数字按照单词切分并且转成小写
+
+type tokenKind uint8
+
+const (
+	tokenWord tokenKind = iota
+	tokenHan
+)
+
+type segment struct {
+	kind  tokenKind
+	runes []rune
+}
+
+// segments 把文本切成连续的单词和连续的汉字，其余的字符都是分隔符
+func segments(text string) []segment {
+	var (
+		res []segment
+		cur *segment
+	)
+	for _, r := range text {
+		var kind tokenKind
+		switch {
+		case unicode.Is(unicode.Han, r):
+			kind = tokenHan
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			kind = tokenWord
+		default:
+			cur = nil
+			continue
+		}
+		if cur == nil || cur.kind != kind {
+			res = append(res, segment{kind: kind})
+			cur = &res[len(res)-1]
+		}
+		cur.runes = append(cur.runes, unicode.ToLower(r))
+	}
+	return res
+}
+
+// indexTerms 索引的时候汉字同时切出单字和相邻两个字，这样单个字也能搜到
+func indexTerms(text string) []string {
+	var res []string
+	for _, seg := range segments(text) {
+		if seg.kind == tokenWord {
+			res = append(res, string(seg.runes))
+			continue
+		}
+		for i := range seg.runes {
+			res = append(res, string(seg.runes[i]))
+			if i+1 < len(seg.runes) {
+				res = append(res, string(seg.runes[i:i+2]))
+			}
+		}
+	}
+	return res
+}
+
+// queryTerms 查询的时候汉字只用相邻两个字，只有一个字的时候才用单字，结果会更准确
+// 返回值是去重了的
+func queryTerms(text string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		if _, ok := seen[term]; ok {
+			return
+		}
+		seen[term] = struct{}{}
+		res = append(res, term)
+	}
+	for _, seg := range segments(text) {
+		if seg.kind == tokenWord || len(seg.runes) == 1 {
+			add(string(seg.runes))
+			continue
+		}
+		for i := 0; i+1 < len(seg.runes); i++ {
+			add(string(seg.runes[i : i+2]))
+		}
+	}
+	return res
+}
+
+// highlight 把 text 里面出现的关键字用 <em> 包起来，没有命中返回 false
+// fragment 大于 0 的时候，只截取第一个关键字附近 fragment 个字符
+func highlight(text string, terms []string, fragment int) (string, bool) {
+	src := []rune(text)
+	lower := make([]rune, len(src))
+	for i, r := range src {
+		lower[i] = unicode.ToLower(r)
+	}
+	type span struct{ start, end int }
+	var spans []span
+	for _, term := range terms {
+		tr := []rune(term)
+		isWord := !unicode.Is(unicode.Han, tr[0])
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if !equalRunes(lower[i:i+len(tr)], tr) {
+				continue
+			}
+			// 英文单词要完整匹配，不然 go 会把 google 也高亮了
+			if isWord && (i > 0 && isWordRune(lower[i-1]) ||
+				i+len(tr) < len(lower) && isWordRune(lower[i+len(tr)])) {
+				continue
+			}
+			spans = append(spans, span{start: i, end: i + len(tr)})
+		}
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	// 合并重叠的部分，例如 数据 和 据库 合并成 数据库
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			last.end = max(last.end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	from, to := 0, len(src)
+	if fragment > 0 && len(src) > fragment {
+		// 关键字前面留一点上下文
+		from = max(0, merged[0].start-fragment/4)
+		to = min(len(src), from+fragment)
+	}
+	var sb strings.Builder
+	pos := from
+	for _, s := range merged {
+		if s.start >= to {
+			break
+		}
+		end := min(s.end, to)
+		sb.WriteString(html.EscapeString(string(src[pos:s.start])))
+		sb.WriteString(highlightPreTag)
+		sb.WriteString(html.EscapeString(string(src[s.start:end])))
+		sb.WriteString(highlightPostTag)
+		pos = end
+	}
+	sb.WriteString(html.EscapeString(string(src[pos:to])))
+	return sb.String(), true
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return !unicode.Is(unicode.Han, r) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
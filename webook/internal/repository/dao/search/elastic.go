@@ -0,0 +1,251 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	articleIndexName = "article_index"
+	userIndexName    = "user_index"
+)
+
+var _ SearchDAO = (*ElasticDAO)(nil)
+
+// ElasticDAO 直接用 Elasticsearch 的 REST 接口
+// 只用到了很少的几个接口，所以没有引入官方的客户端，
+// 本地开发的时候可以用任何兼容这几个接口的服务替换
+type ElasticDAO struct {
+	addr   string
+	client *http.Client
+}
+
+func NewElasticDAO(addr string, client *http.Client) *ElasticDAO {
+	return &ElasticDAO{
+		addr:   strings.TrimRight(addr, "/"),
+		client: client,
+	}
+}
+
+// InitIndexes 创建索引，索引已经存在的时候什么也不做
+func (e *ElasticDAO) InitIndexes(ctx context.Context) error {
+	indexes := map[string]any{
+		articleIndexName: map[string]any{
+			"mappings": map[string]any{
+				"properties": map[string]any{
+					"id":        map[string]any{"type": "long"},
+					"author_id": map[string]any{"type": "long"},
+					"author":    map[string]any{"type": "text"},
+					"title":     map[string]any{"type": "text"},
+					"content":   map[string]any{"type": "text"},
+					"abstract":  map[string]any{"type": "keyword", "index": false},
+					"tags":      map[string]any{"type": "text"},
+					"utime":     map[string]any{"type": "long"},
+				},
+			},
+		},
+		userIndexName: map[string]any{
+			"mappings": map[string]any{
+				"properties": map[string]any{
+					"id":       map[string]any{"type": "long"},
+					"nickname": map[string]any{"type": "text"},
+					"bio":      map[string]any{"type": "text"},
+				},
+			},
+		},
+	}
+	for name, body := range indexes {
+		err := e.do(ctx, http.MethodPut, "/"+name, body, nil)
+		var esErr *elasticError
+		if errors.As(err, &esErr) && esErr.Type == "resource_already_exists_exception" {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ElasticDAO) InputArticle(ctx context.Context, art Article) error {
+	return e.do(ctx, http.MethodPut, docPath(articleIndexName, art.Id), art, nil)
+}
+
+func (e *ElasticDAO) DeleteArticle(ctx context.Context, id int64) error {
+	err := e.do(ctx, http.MethodDelete, docPath(articleIndexName, id), nil, nil)
+	var esErr *elasticError
+	if errors.As(err, &esErr) && esErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (e *ElasticDAO) UpdateAuthor(ctx context.Context, authorId int64, author string) error {
+	body := map[string]any{
+		"query": map[string]any{
+			"term": map[string]any{"author_id": authorId},
+		},
+		"script": map[string]any{
+			"source": "ctx._source.author = params.author",
+			"params": map[string]any{"author": author},
+		},
+	}
+	return e.do(ctx, http.MethodPost,
+		"/"+articleIndexName+"/_update_by_query?conflicts=proceed", body, nil)
+}
+
+func (e *ElasticDAO) InputUser(ctx context.Context, u User) error {
+	return e.do(ctx, http.MethodPut, docPath(userIndexName, u.Id), u, nil)
+}
+
+func (e *ElasticDAO) SearchArticle(ctx context.Context, query string, offset, limit int) (ArticleResult, error) {
+	body := searchBody(query, offset, limit,
+		[]string{"title^3", "tags^2", "author^2", "content"},
+		map[string]any{
+			"title":   map[string]any{"number_of_fragments": 0},
+			"tags":    map[string]any{"number_of_fragments": 0},
+			"author":  map[string]any{"number_of_fragments": 0},
+			"content": map[string]any{"fragment_size": contentFragmentSize, "number_of_fragments": 1},
+		})
+	var resp searchResp[Article]
+	err := e.do(ctx, http.MethodPost, "/"+articleIndexName+"/_search", body, &resp)
+	if err != nil {
+		return ArticleResult{}, err
+	}
+	res := ArticleResult{
+		Total: resp.Hits.Total.Value,
+		Hits:  make([]ArticleHit, 0, len(resp.Hits.Hits)),
+	}
+	for _, hit := range resp.Hits.Hits {
+		res.Hits = append(res.Hits, ArticleHit{Article: hit.Source, Highlight: hit.Highlight})
+	}
+	return res, nil
+}
+
+func (e *ElasticDAO) SearchUser(ctx context.Context, query string, offset, limit int) (UserResult, error) {
+	body := searchBody(query, offset, limit,
+		[]string{"nickname^2", "bio"},
+		map[string]any{
+			"nickname": map[string]any{"number_of_fragments": 0},
+			"bio":      map[string]any{"fragment_size": contentFragmentSize, "number_of_fragments": 1},
+		})
+	var resp searchResp[User]
+	err := e.do(ctx, http.MethodPost, "/"+userIndexName+"/_search", body, &resp)
+	if err != nil {
+		return UserResult{}, err
+	}
+	res := UserResult{
+		Total: resp.Hits.Total.Value,
+		Hits:  make([]UserHit, 0, len(resp.Hits.Hits)),
+	}
+	for _, hit := range resp.Hits.Hits {
+		res.Hits = append(res.Hits, UserHit{User: hit.Source, Highlight: hit.Highlight})
+	}
+	return res, nil
+}
+
+func searchBody(query string, offset, limit int, fields []string, hlFields map[string]any) map[string]any {
+	return map[string]any{
+		"from":             offset,
+		"size":             limit,
+		"track_total_hits": true,
+		"query": map[string]any{
+			"multi_match": map[string]any{
+				"query":    query,
+				"fields":   fields,
+				"operator": "and",
+			},
+		},
+		"sort": []any{
+			"_score",
+			map[string]any{"id": "desc"},
+		},
+		"highlight": map[string]any{
+			"encoder":   "html",
+			"pre_tags":  []string{highlightPreTag},
+			"post_tags": []string{highlightPostTag},
+			"fields":    hlFields,
+		},
+	}
+}
+
+type searchResp[T any] struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Source    T                   `json:"_source"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// elasticError Elasticsearch 返回的错误
+type elasticError struct {
+	Status int
+	Type   string
+	Reason string
+}
+
+func (e *elasticError) Error() string {
+	return fmt.Sprintf("elasticsearch: %d %s %s", e.Status, e.Type, e.Reason)
+}
+
+func docPath(index string, id int64) string {
+	return "/" + index + "/_doc/" + url.PathEscape(strconv.FormatInt(id, 10))
+}
+
+// do body 和 res 为 nil 的时候不发送请求体和不解析响应
+func (e *ElasticDAO) do(ctx context.Context, method, path string, body any, res any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.addr+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		esErr := &elasticError{Status: resp.StatusCode}
+		var errResp struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &errResp) == nil {
+			esErr.Type = errResp.Error.Type
+			esErr.Reason = errResp.Error.Reason
+		}
+		return esErr
+	}
+	if res == nil {
+		return nil
+	}
+	return json.Unmarshal(data, res)
+}
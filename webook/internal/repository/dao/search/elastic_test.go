@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticDAO(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   map[string]any
+	}
+	var reqs []request
+	// 用 httptest 模拟 Elasticsearch
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.RequestURI()}
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > 0 {
+			require.NoError(t, json.Unmarshal(data, &req.body))
+		}
+		reqs = append(reqs, req)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/article_index":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception","reason":"exists"},"status":400}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"result":"not_found"}`))
+		case r.URL.Path == "/article_index/_search":
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":11},"hits":[
+{"_source":{"id":1,"author_id":2,"author":"大明","title":"Go 入门","tags":["后端"]},
+"highlight":{"title":["<em>Go</em> 入门"]}}]}}`))
+		case r.URL.Path == "/user_index/_search":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error":{"type":"search_phase_execution_exception","reason":"boom"},"status":500}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	d := NewElasticDAO(server.URL+"/", server.Client())
+
+	require.NoError(t, d.InitIndexes(ctx))
+	assert.Len(t, reqs, 2)
+
+	require.NoError(t, d.InputArticle(ctx, Article{Id: 1, AuthorId: 2, Title: "Go 入门"}))
+	last := reqs[len(reqs)-1]
+	assert.Equal(t, http.MethodPut, last.method)
+	assert.Equal(t, "/article_index/_doc/1", last.path)
+	assert.Equal(t, "Go 入门", last.body["title"])
+
+	// 文档不存在不算错误
+	require.NoError(t, d.DeleteArticle(ctx, 1))
+
+	require.NoError(t, d.UpdateAuthor(ctx, 2, "小明"))
+	last = reqs[len(reqs)-1]
+	assert.Equal(t, "/article_index/_update_by_query?conflicts=proceed", last.path)
+
+	res, err := d.SearchArticle(ctx, "go", 10, 5)
+	require.NoError(t, err)
+	last = reqs[len(reqs)-1]
+	assert.Equal(t, float64(10), last.body["from"])
+	assert.Equal(t, float64(5), last.body["size"])
+	assert.Equal(t, int64(11), res.Total)
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, Article{Id: 1, AuthorId: 2, Author: "大明", Title: "Go 入门", Tags: []string{"后端"}},
+		res.Hits[0].Article)
+	assert.Equal(t, []string{"<em>Go</em> 入门"}, res.Hits[0].Highlight["title"])
+
+	_, err = d.SearchUser(ctx, "go", 0, 10)
+	var esErr *elasticError
+	require.ErrorAs(t, err, &esErr)
+	assert.Equal(t, http.StatusInternalServerError, esErr.Status)
+	assert.Equal(t, "search_phase_execution_exception", esErr.Type)
+}
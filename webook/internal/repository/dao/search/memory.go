@@ -0,0 +1,237 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+)
+
+var _ SearchDAO = (*MemoryDAO)(nil)
+
+// MemoryDAO 内置的倒排索引，数据都在内存里面
+// 单实例部署或者测试的时候用，多实例部署要用 Elasticsearch
+type MemoryDAO struct {
+	mu        sync.RWMutex
+	articles  map[int64]Article
+	users     map[int64]User
+	artIndex  *invertedIndex
+	userIndex *invertedIndex
+}
+
+func NewMemoryDAO() *MemoryDAO {
+	return &MemoryDAO{
+		articles:  make(map[int64]Article),
+		users:     make(map[int64]User),
+		artIndex:  newInvertedIndex(),
+		userIndex: newInvertedIndex(),
+	}
+}
+
+func (m *MemoryDAO) InputArticle(ctx context.Context, art Article) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.articles[art.Id] = art
+	m.artIndex.add(art.Id, m.articleFields(art))
+	return nil
+}
+
+func (m *MemoryDAO) DeleteArticle(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.articles, id)
+	m.artIndex.remove(id)
+	return nil
+}
+
+func (m *MemoryDAO) UpdateAuthor(ctx context.Context, authorId int64, author string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, art := range m.articles {
+		if art.AuthorId != authorId || art.Author == author {
+			continue
+		}
+		art.Author = author
+		m.articles[id] = art
+		m.artIndex.add(id, m.articleFields(art))
+	}
+	return nil
+}
+
+func (m *MemoryDAO) InputUser(ctx context.Context, u User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[u.Id] = u
+	m.userIndex.add(u.Id, m.userFields(u))
+	return nil
+}
+
+func (m *MemoryDAO) SearchArticle(ctx context.Context, query string, offset, limit int) (ArticleResult, error) {
+	terms := queryTerms(query)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := m.artIndex.search(terms)
+	res := ArticleResult{
+		Total: int64(len(ids)),
+		Hits:  make([]ArticleHit, 0, limit),
+	}
+	for _, id := range page(ids, offset, limit) {
+		art := m.articles[id]
+		hl := make(map[string][]string)
+		highlightField(hl, "title", art.Title, terms, 0)
+		highlightField(hl, "content", art.Content, terms, contentFragmentSize)
+		highlightField(hl, "author", art.Author, terms, 0)
+		for _, tag := range art.Tags {
+			highlightField(hl, "tags", tag, terms, 0)
+		}
+		res.Hits = append(res.Hits, ArticleHit{Article: art, Highlight: hl})
+	}
+	return res, nil
+}
+
+func (m *MemoryDAO) SearchUser(ctx context.Context, query string, offset, limit int) (UserResult, error) {
+	terms := queryTerms(query)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := m.userIndex.search(terms)
+	res := UserResult{
+		Total: int64(len(ids)),
+		Hits:  make([]UserHit, 0, limit),
+	}
+	for _, id := range page(ids, offset, limit) {
+		u := m.users[id]
+		hl := make(map[string][]string)
+		highlightField(hl, "nickname", u.Nickname, terms, 0)
+		highlightField(hl, "bio", u.Bio, terms, contentFragmentSize)
+		res.Hits = append(res.Hits, UserHit{User: u, Highlight: hl})
+	}
+	return res, nil
+}
+
+// articleFields 权重和 Elasticsearch 的实现保持一致
+func (m *MemoryDAO) articleFields(art Article) []field {
+	fields := []field{
+		{text: art.Title, boost: 3},
+		{text: art.Content, boost: 1},
+		{text: art.Author, boost: 2},
+	}
+	for _, tag := range art.Tags {
+		fields = append(fields, field{text: tag, boost: 2})
+	}
+	return fields
+}
+
+func (m *MemoryDAO) userFields(u User) []field {
+	return []field{
+		{text: u.Nickname, boost: 2},
+		{text: u.Bio, boost: 1},
+	}
+}
+
+func highlightField(hl map[string][]string, name, text string, terms []string, fragment int) {
+	if frag, ok := highlight(text, terms, fragment); ok {
+		hl[name] = append(hl[name], frag)
+	}
+}
+
+func page(ids []int64, offset, limit int) []int64 {
+	if offset >= len(ids) {
+		return nil
+	}
+	return ids[offset:min(offset+limit, len(ids))]
+}
+
+type field struct {
+	text  string
+	boost float64
+}
+
+type invertedIndex struct {
+	// postings 词 -> 文档 -> 按照字段权重累加的词频
+	postings map[string]map[int64]float64
+	// terms 文档 -> 词，更新和删除文档的时候用
+	terms map[int64][]string
+}
+
+func newInvertedIndex() *invertedIndex {
+	return &invertedIndex{
+		postings: make(map[string]map[int64]float64),
+		terms:    make(map[int64][]string),
+	}
+}
+
+// add 重复添加同一个文档相当于更新
+func (idx *invertedIndex) add(id int64, fields []field) {
+	idx.remove(id)
+	weights := make(map[string]float64)
+	for _, f := range fields {
+		for _, term := range indexTerms(f.text) {
+			weights[term] += f.boost
+		}
+	}
+	terms := make([]string, 0, len(weights))
+	for term, w := range weights {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int64]float64)
+			idx.postings[term] = docs
+		}
+		docs[id] = w
+		terms = append(terms, term)
+	}
+	idx.terms[id] = terms
+}
+
+func (idx *invertedIndex) remove(id int64) {
+	for _, term := range idx.terms[id] {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.terms, id)
+}
+
+// search 文档要包含所有的词，按照 TF-IDF 的分数倒序，分数一样的时候新的文档在前
+func (idx *invertedIndex) search(terms []string) []int64 {
+	if len(terms) == 0 {
+		return nil
+	}
+	n := float64(len(idx.terms))
+	var scores map[int64]float64
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + n/float64(len(docs)))
+		if scores == nil {
+			scores = make(map[int64]float64, len(docs))
+			for id, w := range docs {
+				scores[id] = w * idf
+			}
+			continue
+		}
+		for id := range scores {
+			w, ok := docs[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += w * idf
+		}
+	}
+	ids := make([]int64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		si, sj := scores[ids[i]], scores[ids[j]]
+		if si != sj {
+			return si > sj
+		}
+		return ids[i] > ids[j]
+	})
+	return ids
+}
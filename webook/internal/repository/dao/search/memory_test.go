@@ -0,0 +1,158 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDAO_SearchArticle(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDAO()
+	require.NoError(t, d.InputArticle(ctx, Article{
+		Id: 1, AuthorId: 10, Author: "大明",
+		Title:   "Go 语言入门",
+		Content: "学习 golang 和数据库",
+		Tags:    []string{"后端"},
+	}))
+	require.NoError(t, d.InputArticle(ctx, Article{
+		Id: 2, AuthorId: 11, Author: "小明",
+		Title:   "数据库索引",
+		Content: "MySQL 的 B+ 树 <索引>",
+		Tags:    []string{"数据库", "MySQL"},
+	}))
+	require.NoError(t, d.InputArticle(ctx, Article{
+		Id: 3, AuthorId: 10, Author: "大明",
+		Title:   "随笔",
+		Content: "今天去了 google",
+	}))
+
+	testCases := []struct {
+		name      string
+		query     string
+		offset    int
+		limit     int
+		wantTotal int64
+		wantIds   []int64
+		wantHl    map[string][]string
+	}{
+		{
+			name:      "标题的权重更高",
+			query:     "数据库",
+			limit:     10,
+			wantTotal: 2,
+			wantIds:   []int64{2, 1},
+			wantHl: map[string][]string{
+				"title": {"<em>数据库</em>索引"},
+				"tags":  {"<em>数据库</em>"},
+			},
+		},
+		{
+			name:      "所有关键字都要命中",
+			query:     "go 数据库",
+			limit:     10,
+			wantTotal: 1,
+			wantIds:   []int64{1},
+			wantHl: map[string][]string{
+				"title":   {"<em>Go</em> 语言入门"},
+				"content": {"学习 golang 和<em>数据库</em>"},
+			},
+		},
+		{
+			name:      "作者昵称",
+			query:     "大明",
+			limit:     1,
+			wantTotal: 2,
+			wantIds:   []int64{3},
+			wantHl: map[string][]string{
+				"author": {"<em>大明</em>"},
+			},
+		},
+		{
+			name:      "分页",
+			query:     "大明",
+			offset:    1,
+			limit:     1,
+			wantTotal: 2,
+			wantIds:   []int64{1},
+		},
+		{
+			name:  "没有命中",
+			query: "java",
+			limit: 10,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := d.SearchArticle(ctx, tc.query, tc.offset, tc.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, res.Total)
+			ids := make([]int64, 0, len(res.Hits))
+			for _, hit := range res.Hits {
+				ids = append(ids, hit.Id)
+			}
+			assert.Equal(t, len(tc.wantIds), len(ids))
+			for i := range tc.wantIds {
+				assert.Equal(t, tc.wantIds[i], ids[i])
+			}
+			if tc.wantHl != nil {
+				assert.Equal(t, tc.wantHl["title"], res.Hits[0].Highlight["title"])
+				assert.Equal(t, tc.wantHl["content"], res.Hits[0].Highlight["content"])
+				assert.Equal(t, tc.wantHl["tags"], res.Hits[0].Highlight["tags"])
+				assert.Equal(t, tc.wantHl["author"], res.Hits[0].Highlight["author"])
+			}
+		})
+	}
+}
+
+func TestMemoryDAO_UpdateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDAO()
+	require.NoError(t, d.InputArticle(ctx, Article{Id: 1, AuthorId: 10, Author: "大明", Title: "第一篇"}))
+	require.NoError(t, d.InputArticle(ctx, Article{Id: 2, AuthorId: 10, Author: "大明", Title: "第二篇"}))
+
+	// 重新索引，旧的标题搜不到了
+	require.NoError(t, d.InputArticle(ctx, Article{Id: 1, AuthorId: 10, Author: "大明", Title: "新标题"}))
+	res, err := d.SearchArticle(ctx, "第一篇", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+
+	require.NoError(t, d.UpdateAuthor(ctx, 10, "小红"))
+	res, err = d.SearchArticle(ctx, "大明", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Total)
+	res, err = d.SearchArticle(ctx, "小红", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Total)
+
+	require.NoError(t, d.DeleteArticle(ctx, 2))
+	require.NoError(t, d.DeleteArticle(ctx, 100))
+	res, err = d.SearchArticle(ctx, "小红", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Total)
+	assert.Equal(t, int64(1), res.Hits[0].Id)
+}
+
+func TestMemoryDAO_SearchUser(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDAO()
+	require.NoError(t, d.InputUser(ctx, User{Id: 1, Nickname: "Tom", Bio: "喜欢写 Go"}))
+	require.NoError(t, d.InputUser(ctx, User{Id: 2, Nickname: "Gopher", Bio: "go go go <3"}))
+
+	res, err := d.SearchUser(ctx, "GO", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Total)
+	// 不会把 Gopher 高亮了
+	assert.Nil(t, res.Hits[0].Highlight["nickname"])
+	assert.Equal(t, []string{"<em>go</em> <em>go</em> <em>go</em> &lt;3"}, res.Hits[0].Highlight["bio"])
+	assert.Equal(t, []string{"喜欢写 <em>Go</em>"}, res.Hits[1].Highlight["bio"])
+}
+
+func TestHighlight_Fragment(t *testing.T) {
+	text := "前面有很多很多的内容然后是关键字后面也有很多很多的内容"
+	frag, ok := highlight(text, queryTerms("关键字"), 12)
+	require.True(t, ok)
+	assert.Equal(t, "然后是<em>关键字</em>后面也有很多", frag)
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+)
+
+// SearchDAO 搜索的存储，有内置的倒排索引和 Elasticsearch 两个实现
+// 写入都是覆盖式的，重复写入同一个 ID 没有副作用
+type SearchDAO interface {
+	InputArticle(ctx context.Context, art Article) error
+	// DeleteArticle 文章不存在的时候什么也不做
+	DeleteArticle(ctx context.Context, id int64) error
+	// UpdateAuthor 作者修改了昵称之后，已经索引了的文章也要跟着修改
+	UpdateAuthor(ctx context.Context, authorId int64, author string) error
+	InputUser(ctx context.Context, u User) error
+	// SearchArticle 在标题、内容、标签和作者昵称里面搜索，文档要包含所有的关键字
+	SearchArticle(ctx context.Context, query string, offset, limit int) (ArticleResult, error)
+	// SearchUser 在昵称和简介里面搜索
+	SearchUser(ctx context.Context, query string, offset, limit int) (UserResult, error)
+}
+
+// Article 只有已经发表的文章才会被索引
+type Article struct {
+	Id       int64  `json:"id"`
+	AuthorId int64  `json:"author_id"`
+	Author   string `json:"author"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	// Abstract 只用来展示，不参与搜索
+	Abstract string   `json:"abstract"`
+	Tags     []string `json:"tags"`
+	Utime    int64    `json:"utime"`
+}
+
+type User struct {
+	Id       int64  `json:"id"`
+	Nickname string `json:"nickname"`
+	Bio      string `json:"bio"`
+}
+
+// ArticleHit Highlight 是字段名到高亮片段的映射
+// 片段已经做过 HTML 转义，关键字用 <em> 包起来，没有命中的字段不会出现
+type ArticleHit struct {
+	Article
+	Highlight map[string][]string
+}
+
+type UserHit struct {
+	User
+	Highlight map[string][]string
+}
+
+type ArticleResult struct {
+	// Total 命中的总数，用来分页
+	Total int64
+	Hits  []ArticleHit
+}
+
+type UserResult struct {
+	Total int64
+	Hits  []UserHit
+}
+
+const (
+	// highlightPreTag 和 highlightPostTag 两个实现保持一致
+	highlightPreTag  = "<em>"
+	highlightPostTag = "</em>"
+	// contentFragmentSize 内容太长，只返回关键字附近的一段
+	contentFragmentSize = 100
+)
@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrTransferNotFound = gorm.ErrRecordNotFound
+	// ErrTransferDuplicate 同一个用户同一种任务已经有一个没有结束的
+	ErrTransferDuplicate = errors.New("已经有没有结束的任务")
+)
+
+// articleTransferStaleMsg 中断了的任务的 Msg
+const articleTransferStaleMsg = "任务中断了，请重新开始"
+
+type ArticleTransferDAO interface {
+	// Insert 先把 since 之前就没有进展的同类任务标记为失败
+	// 同一个用户同一种任务还有没有结束的任务的时候返回 ErrTransferDuplicate
+	Insert(ctx context.Context, t ArticleTransfer, since int64) (ArticleTransfer, error)
+	// UpdateProgress 同时把任务标记为执行中，已经结束的任务不会更新
+	UpdateProgress(ctx context.Context, id int64, total, done, failed int) error
+	Finish(ctx context.Context, id int64, status uint8, done, failed int, msg string) error
+	FindById(ctx context.Context, id int64) (ArticleTransfer, error)
+	// FindUnfinished 用户最近一个还没有结束，并且在 since 之后还有进展的任务
+	FindUnfinished(ctx context.Context, uid int64, kind uint8, since int64) (ArticleTransfer, error)
+	// FailStale 把 before 之前就没有进展的任务标记为失败，返回标记了的数量
+	FailStale(ctx context.Context, before int64) (int64, error)
+	// ListFinished 按照 id 正序查询 before 之前就已经结束了的任务
+	ListFinished(ctx context.Context, before int64, limit int) ([]ArticleTransfer, error)
+	DeleteById(ctx context.Context, id int64) error
+}
+
+type GORMArticleTransferDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMArticleTransferDAO(db *gorm.DB) ArticleTransferDAO {
+	return &GORMArticleTransferDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMArticleTransferDAO) Insert(ctx context.Context, t ArticleTransfer, since int64) (ArticleTransfer, error) {
+	now := time.Now().UnixMilli()
+	t.Ctime = now
+	t.Utime = now
+	t.UnfinishedKey = sql.NullString{String: fmt.Sprintf("%d:%d", t.Uid, t.Kind), Valid: true}
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 中断了的任务不能一直占着位置
+		err := tx.Model(&ArticleTransfer{}).
+			Where("unfinished_key = ? AND utime < ?", t.UnfinishedKey, since).
+			Updates(dao.staleUpdates(now)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&t).Error
+	})
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		const uniqueIndexErrNo uint16 = 1062
+		if mysqlErr.Number == uniqueIndexErrNo {
+			return ArticleTransfer{}, ErrTransferDuplicate
+		}
+	}
+	return t, err
+}
+
+func (dao *GORMArticleTransferDAO) UpdateProgress(ctx context.Context, id int64, total, done, failed int) error {
+	// 已经被当作中断了的任务不能再变回执行中
+	return dao.db.WithContext(ctx).Model(&ArticleTransfer{}).
+		Where("id = ? AND status IN ?", id, unfinishedTransferStatuses).
+		Updates(map[string]any{
+			"status": ArticleTransferStatusRunning,
+			"total":  total,
+			"done":   done,
+			"failed": failed,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMArticleTransferDAO) Finish(ctx context.Context, id int64, status uint8, done, failed int, msg string) error {
+	return dao.db.WithContext(ctx).Model(&ArticleTransfer{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":         status,
+			"done":           done,
+			"failed":         failed,
+			"msg":            msg,
+			"unfinished_key": nil,
+			"utime":          time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMArticleTransferDAO) FindById(ctx context.Context, id int64) (ArticleTransfer, error) {
+	var res ArticleTransfer
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleTransferDAO) FindUnfinished(ctx context.Context, uid int64, kind uint8, since int64) (ArticleTransfer, error) {
+	var res ArticleTransfer
+	err := dao.db.WithContext(ctx).
+		Where("uid = ? AND kind = ? AND status IN ? AND utime >= ?", uid, kind,
+			unfinishedTransferStatuses, since).
+		Order("id DESC").
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleTransferDAO) FailStale(ctx context.Context, before int64) (int64, error) {
+	res := dao.db.WithContext(ctx).Model(&ArticleTransfer{}).
+		Where("status IN ? AND utime < ?", unfinishedTransferStatuses, before).
+		Updates(dao.staleUpdates(time.Now().UnixMilli()))
+	return res.RowsAffected, res.Error
+}
+
+func (dao *GORMArticleTransferDAO) ListFinished(ctx context.Context, before int64, limit int) ([]ArticleTransfer, error) {
+	var res []ArticleTransfer
+	err := dao.db.WithContext(ctx).
+		Where("status IN ? AND utime < ?",
+			[]uint8{ArticleTransferStatusDone, ArticleTransferStatusFailed}, before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleTransferDAO) DeleteById(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&ArticleTransfer{}).Error
+}
+
+// staleUpdates 把中断了的任务标记为失败，同时让出 UnfinishedKey
+func (dao *GORMArticleTransferDAO) staleUpdates(now int64) map[string]any {
+	return map[string]any{
+		"status":         ArticleTransferStatusFailed,
+		"msg":            articleTransferStaleMsg,
+		"unfinished_key": nil,
+		"utime":          now,
+	}
+}
+
+// 和 domain.TransferStatus 保持一致
+const (
+	ArticleTransferStatusPending uint8 = iota + 1
+	ArticleTransferStatusRunning
+	ArticleTransferStatusDone
+	ArticleTransferStatusFailed
+)
+
+var unfinishedTransferStatuses = []uint8{ArticleTransferStatusPending, ArticleTransferStatusRunning}
+
+// ArticleTransfer 导出和导入文章的任务，导出的 zip 放在对象存储里面
+type ArticleTransfer struct {
+	Id     int64 `gorm:"primaryKey,autoIncrement"`
+	Uid    int64 `gorm:"index:idx_uid_kind"`
+	Kind   uint8 `gorm:"index:idx_uid_kind"`
+	Status uint8
+	// UnfinishedKey 没有结束的任务是 uid:kind，结束之后是 NULL
+	// 唯一索引保证同一个用户同一种任务只有一个在进行
+	UnfinishedKey sql.NullString `gorm:"type:varchar(64);unique"`
+	Total         int
+	Done          int
+	Failed        int
+	Msg           string `gorm:"type:varchar(1024)"`
+	Ctime         int64
+	Utime         int64
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+type FollowRepository interface {
+	AddFollowRelation(ctx context.Context, follower, followee int64) error
+	InactiveFollowRelation(ctx context.Context, follower, followee int64) error
+	GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	Followed(ctx context.Context, follower, followee int64) (bool, error)
+	GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type CachedFollowRepository struct {
+	dao   dao.FollowDAO
+	cache cache.FollowCache
+	l     logger.LoggerV1
+}
+
+func NewCachedFollowRepository(dao dao.FollowDAO, cache cache.FollowCache, l logger.LoggerV1) FollowRepository {
+	return &CachedFollowRepository{
+		dao:   dao,
+		cache: cache,
+		l:     l,
+	}
+}
+
+func (c *CachedFollowRepository) AddFollowRelation(ctx context.Context, follower, followee int64) error {
+	changed, err := c.dao.CreateFollowRelation(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	return c.cache.Follow(ctx, follower, followee)
+}
+
+func (c *CachedFollowRepository) InactiveFollowRelation(ctx context.Context, follower, followee int64) error {
+	changed, err := c.dao.CancelFollowRelation(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	return c.cache.CancelFollow(ctx, follower, followee)
+}
+
+func (c *CachedFollowRepository) GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	frs, err := c.dao.FollowerList(ctx, followee, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](frs, c.toDomain), nil
+}
+
+func (c *CachedFollowRepository) GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	frs, err := c.dao.FolloweeList(ctx, follower, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.FollowRelation, domain.FollowRelation](frs, c.toDomain), nil
+}
+
+func (c *CachedFollowRepository) Followed(ctx context.Context, follower, followee int64) (bool, error) {
+	_, err := c.dao.FollowRelationDetail(ctx, follower, followee)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, dao.ErrRecordNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (c *CachedFollowRepository) GetFollowStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := c.cache.StaticsInfo(ctx, uid)
+	if err == nil {
+		return res, nil
+	}
+	statics, err := c.dao.GetStatics(ctx, uid)
+	// 没有记录说明没有关注过别人，也没有被关注过
+	if err != nil && !errors.Is(err, dao.ErrRecordNotFound) {
+		return domain.FollowStatics{}, err
+	}
+	res = domain.FollowStatics{
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+	if er := c.cache.SetStaticsInfo(ctx, uid, res); er != nil {
+		c.l.Error("回写关注计数缓存失败",
+			logger.Int64("uid", uid),
+			logger.Error(er))
+	}
+	return res, nil
+}
+
+func (c *CachedFollowRepository) toDomain(idx int, fr dao.FollowRelation) domain.FollowRelation {
+	return domain.FollowRelation{
+		Follower: fr.Follower,
+		Followee: fr.Followee,
+		Ctime:    time.UnixMilli(fr.Ctime),
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	cachemocks "webook/internal/repository/cache/mocks"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+	"webook/pkg/logger"
+)
+
+func TestCachedFollowRepository_AddFollowRelation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache)
+		wantErr error
+	}{
+		{
+			name: "关注成功，更新缓存",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				d := daomocks.NewMockFollowDAO(ctrl)
+				d.EXPECT().CreateFollowRelation(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "重复关注，不更新缓存",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				d := daomocks.NewMockFollowDAO(ctrl)
+				d.EXPECT().CreateFollowRelation(gomock.Any(), int64(1), int64(2)).Return(false, nil)
+				return d, cachemocks.NewMockFollowCache(ctrl)
+			},
+		},
+		{
+			name: "数据库错误",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				d := daomocks.NewMockFollowDAO(ctrl)
+				d.EXPECT().CreateFollowRelation(gomock.Any(), int64(1), int64(2)).
+					Return(false, errors.New("mock db error"))
+				return d, cachemocks.NewMockFollowCache(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedFollowRepository(d, c, logger.NewNoOpLogger())
+			err := repo.AddFollowRelation(context.Background(), 1, 2)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCachedFollowRepository_GetFollowStatics(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache)
+		want    domain.FollowStatics
+		wantErr error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().StaticsInfo(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{Followers: 3, Followees: 4}, nil)
+				return daomocks.NewMockFollowDAO(ctrl), c
+			},
+			want: domain.FollowStatics{Followers: 3, Followees: 4},
+		},
+		{
+			name: "未命中缓存，查询数据库并回写",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().StaticsInfo(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{}, cache.ErrKeyNotExist)
+				d := daomocks.NewMockFollowDAO(ctrl)
+				d.EXPECT().GetStatics(gomock.Any(), int64(1)).
+					Return(dao.FollowStatics{Uid: 1, Followers: 3, Followees: 4}, nil)
+				c.EXPECT().SetStaticsInfo(gomock.Any(), int64(1),
+					domain.FollowStatics{Followers: 3, Followees: 4}).Return(nil)
+				return d, c
+			},
+			want: domain.FollowStatics{Followers: 3, Followees: 4},
+		},
+		{
+			name: "数据库没有记录，当作零",
+			mock: func(ctrl *gomock.Controller) (dao.FollowDAO, cache.FollowCache) {
+				c := cachemocks.NewMockFollowCache(ctrl)
+				c.EXPECT().StaticsInfo(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{}, cache.ErrKeyNotExist)
+				d := daomocks.NewMockFollowDAO(ctrl)
+				d.EXPECT().GetStatics(gomock.Any(), int64(1)).
+					Return(dao.FollowStatics{}, dao.ErrRecordNotFound)
+				c.EXPECT().SetStaticsInfo(gomock.Any(), int64(1), domain.FollowStatics{}).Return(nil)
+				return d, c
+			},
+			want: domain.FollowStatics{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedFollowRepository(d, c, logger.NewNoOpLogger())
+			res, err := repo.GetFollowStatics(context.Background(), 1)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, res)
+		})
+	}
+}
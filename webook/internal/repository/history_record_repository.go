@@ -2,9 +2,72 @@ package repository
 
 import (
 	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
 	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
 )
 
 type HistoryRecordRepository interface {
 	AddRecord(ctx context.Context, r domain.HistoryRecord) error
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.HistoryRecord, error)
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	Clear(ctx context.Context, uid int64) error
+}
+
+type historyRecordRepository struct {
+	dao dao.HistoryDAO
+	l   logger.LoggerV1
+	// capacity 每个用户最多保留多少条历史记录
+	capacity int
+}
+
+func NewHistoryRecordRepository(dao dao.HistoryDAO, l logger.LoggerV1) HistoryRecordRepository {
+	return &historyRecordRepository{
+		dao:      dao,
+		l:        l,
+		capacity: 1000,
+	}
+}
+
+func (h *historyRecordRepository) AddRecord(ctx context.Context, r domain.HistoryRecord) error {
+	err := h.dao.Upsert(ctx, dao.ReadHistory{
+		Uid:   r.Uid,
+		Biz:   r.Biz,
+		BizId: r.BizId,
+	})
+	if err != nil {
+		return err
+	}
+	// 超出上限的部分删除失败了也没关系，下一次阅读的时候还会再删
+	if er := h.dao.Trim(ctx, r.Uid, h.capacity); er != nil {
+		h.l.Error("清理超出上限的阅读历史失败",
+			logger.Int64("uid", r.Uid),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (h *historyRecordRepository) List(ctx context.Context, uid int64, offset, limit int) ([]domain.HistoryRecord, error) {
+	hs, err := h.dao.List(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.ReadHistory, domain.HistoryRecord](hs, func(idx int, src dao.ReadHistory) domain.HistoryRecord {
+		return domain.HistoryRecord{
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Uid:   src.Uid,
+			Utime: time.UnixMilli(src.Utime),
+		}
+	}), nil
+}
+
+func (h *historyRecordRepository) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return h.dao.Delete(ctx, uid, biz, bizId)
+}
+
+func (h *historyRecordRepository) Clear(ctx context.Context, uid int64) error {
+	return h.dao.DeleteAll(ctx, uid)
 }
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+	"webook/pkg/logger"
+)
+
+func TestHistoryRecordRepository_AddRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) dao.HistoryDAO
+		wantErr error
+	}{
+		{
+			name: "写入并清理超出上限的记录",
+			mock: func(ctrl *gomock.Controller) dao.HistoryDAO {
+				d := daomocks.NewMockHistoryDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), dao.ReadHistory{
+					Uid: 123, Biz: "article", BizId: 1,
+				}).Return(nil)
+				d.EXPECT().Trim(gomock.Any(), int64(123), 1000).Return(nil)
+				return d
+			},
+		},
+		{
+			name: "清理失败不影响写入",
+			mock: func(ctrl *gomock.Controller) dao.HistoryDAO {
+				d := daomocks.NewMockHistoryDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+				d.EXPECT().Trim(gomock.Any(), int64(123), 1000).Return(errors.New("mock db error"))
+				return d
+			},
+		},
+		{
+			name: "写入失败",
+			mock: func(ctrl *gomock.Controller) dao.HistoryDAO {
+				d := daomocks.NewMockHistoryDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return d
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := NewHistoryRecordRepository(tt.mock(ctrl), logger.NewNoOpLogger())
+			err := repo.AddRecord(context.Background(), domain.HistoryRecord{
+				Uid: 123, Biz: "article", BizId: 1,
+			})
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	"webook/internal/repository/dao/blob"
+)
+
+var (
+	ErrImageNotFound       = dao.ErrImageNotFound
+	ErrImageObjectNotFound = blob.ErrNotFound
+)
+
+type ImageRepository interface {
+	FindByHash(ctx context.Context, hash string) (domain.Image, error)
+	// Save 先写对象存储再写元数据，返回的可能是并发上传的同一张图片
+	Save(ctx context.Context, img domain.Image, data []byte) (domain.Image, error)
+	// GetObject 读取图片内容，只有本地存储需要经过我们自己的服务器
+	GetObject(ctx context.Context, key string) ([]byte, string, error)
+	// KeyFromURL 从对外访问的地址反推 key，不是我们存储的地址的时候返回 false
+	KeyFromURL(url string) (string, bool)
+}
+
+type imageRepository struct {
+	dao   dao.ImageDAO
+	store blob.Store
+}
+
+func NewImageRepository(dao dao.ImageDAO, store blob.Store) ImageRepository {
+	return &imageRepository{
+		dao:   dao,
+		store: store,
+	}
+}
+
+func (r *imageRepository) FindByHash(ctx context.Context, hash string) (domain.Image, error) {
+	img, err := r.dao.FindByHash(ctx, hash)
+	if err != nil {
+		return domain.Image{}, err
+	}
+	return r.toDomain(img), nil
+}
+
+func (r *imageRepository) Save(ctx context.Context, img domain.Image, data []byte) (domain.Image, error) {
+	// key 由内容决定，重复写入是幂等的
+	err := r.store.Put(ctx, img.Key, data, img.ContentType)
+	if err != nil {
+		return domain.Image{}, err
+	}
+	res, err := r.dao.Insert(ctx, r.toEntity(img))
+	if err != nil {
+		return domain.Image{}, err
+	}
+	return r.toDomain(res), nil
+}
+
+func (r *imageRepository) GetObject(ctx context.Context, key string) ([]byte, string, error) {
+	obj, err := r.store.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	return obj.Data, obj.ContentType, nil
+}
+
+func (r *imageRepository) KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, r.store.URL(""))
+	return key, ok && key != ""
+}
+
+func (r *imageRepository) toEntity(img domain.Image) dao.Image {
+	return dao.Image{
+		Id:          img.Id,
+		Hash:        img.Hash,
+		Key:         img.Key,
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+		Uploader:    img.Uploader,
+	}
+}
+
+func (r *imageRepository) toDomain(img dao.Image) domain.Image {
+	return domain.Image{
+		Id:          img.Id,
+		Hash:        img.Hash,
+		Key:         img.Key,
+		URL:         r.store.URL(img.Key),
+		ContentType: img.ContentType,
+		Size:        img.Size,
+		Width:       img.Width,
+		Height:      img.Height,
+		Uploader:    img.Uploader,
+		Ctime:       time.UnixMilli(img.Ctime),
+	}
+}
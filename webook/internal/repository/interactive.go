@@ -2,12 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/repository/cache"
 	"webook/internal/repository/dao"
 	"webook/pkg/logger"
 )
 
+// ErrCollectionNotFound 收藏夹或者收藏记录不存在，或者不属于这个用户
+var ErrCollectionNotFound = dao.ErrDataNotFound
+
 type InteractiveRepository interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
 	// BatchIncrReadCnt 这里调用者要保证 bizs 和 bizIds 长度一样
@@ -18,6 +24,22 @@ type InteractiveRepository interface {
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
 	Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
+	// GetByIds 批量查询计数，返回值里面每一个 bizId 都有对应的计数，不存在的资源计数都是 0
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
+	// LikedByIds 批量查询用户是否点赞，只返回点赞了的 bizId
+	LikedByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]bool, error)
+	// CollectedByIds 批量查询用户是否收藏，只返回收藏了的 bizId
+	CollectedByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]bool, error)
+
+	AddCollection(ctx context.Context, c domain.Collection) (int64, error)
+	// UpdateCollection 目前只能修改名字
+	UpdateCollection(ctx context.Context, c domain.Collection) error
+	Collections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error)
+	// DeleteCollection 删除收藏夹，里面的资源也会被取消收藏
+	DeleteCollection(ctx context.Context, id, uid int64) error
+	DeleteCollectionItem(ctx context.Context, biz string, bizId, uid int64) error
+	CollectionItems(ctx context.Context, cid, uid int64, offset, limit int) ([]domain.CollectionItem, error)
+	MoveCollectionItem(ctx context.Context, biz string, bizId, uid, cid int64) error
 }
 
 type CachedReadCntRepository struct {
@@ -36,10 +58,10 @@ func NewCachedInteractiveRepository(dao dao.InteractiveDAO, cache cache.Interact
 
 func (c *CachedReadCntRepository) Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
 	_, err := c.dao.GetLikeInfo(ctx, biz, id, uid)
-	switch err {
-	case nil:
+	switch {
+	case err == nil:
 		return true, nil
-	case dao.ErrDataNotFound:
+	case errors.Is(err, dao.ErrRecordNotFound):
 		return false, nil
 	default:
 		return false, err
@@ -48,10 +70,10 @@ func (c *CachedReadCntRepository) Liked(ctx context.Context, biz string, id int6
 
 func (c *CachedReadCntRepository) Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
 	_, err := c.dao.GetCollectionInfo(ctx, biz, id, uid)
-	switch err {
-	case nil:
+	switch {
+	case err == nil:
 		return true, nil
-	case dao.ErrDataNotFound:
+	case errors.Is(err, dao.ErrRecordNotFound):
 		return false, nil
 	default:
 		return false, err
@@ -110,7 +132,8 @@ func (c *CachedReadCntRepository) Get(ctx context.Context, biz string, bizId int
 		return intr, nil
 	}
 	ie, err := c.dao.Get(ctx, biz, bizId)
-	if err == nil {
+	// 没有记录说明还没有人阅读过，计数都是 0
+	if err != nil && !errors.Is(err, dao.ErrRecordNotFound) {
 		return domain.Interactive{}, err
 	}
 	res := c.toDomain(ie)
@@ -126,11 +149,159 @@ func (c *CachedReadCntRepository) Get(ctx context.Context, biz string, bizId int
 	return res, nil
 }
 
+func (c *CachedReadCntRepository) GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	res, err := c.cache.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		// 缓存出问题了，全部从数据库里面查
+		c.l.Error("批量查询缓存失败",
+			logger.String("biz", biz),
+			logger.Error(err))
+		res = make(map[int64]domain.Interactive, len(bizIds))
+	}
+	missed := make([]int64, 0, len(bizIds))
+	for _, bizId := range bizIds {
+		if _, ok := res[bizId]; !ok {
+			missed = append(missed, bizId)
+		}
+	}
+	if len(missed) == 0 {
+		return res, nil
+	}
+	intrs, err := c.dao.GetByIds(ctx, biz, missed)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[int64]domain.Interactive, len(intrs))
+	for _, intr := range intrs {
+		found[intr.BizId] = c.toDomain(intr)
+	}
+	for _, bizId := range missed {
+		// 数据库里面也没有的，计数就是 0
+		res[bizId] = found[bizId]
+	}
+	go func() {
+		for bizId, intr := range found {
+			if er := c.cache.Set(ctx, biz, bizId, intr); er != nil {
+				c.l.Error("回写缓存失败",
+					logger.Int64("bizId", bizId),
+					logger.String("biz", biz),
+					logger.Error(er))
+			}
+		}
+	}()
+	return res, nil
+}
+
+func (c *CachedReadCntRepository) LikedByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]bool, error) {
+	likes, err := c.dao.GetLikeInfos(ctx, biz, bizIds, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(likes))
+	for _, like := range likes {
+		res[like.BizId] = true
+	}
+	return res, nil
+}
+
+func (c *CachedReadCntRepository) CollectedByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]bool, error) {
+	cbs, err := c.dao.GetCollectionInfos(ctx, biz, bizIds, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(cbs))
+	for _, cb := range cbs {
+		res[cb.BizId] = true
+	}
+	return res, nil
+}
+
+func (c *CachedReadCntRepository) AddCollection(ctx context.Context, col domain.Collection) (int64, error) {
+	return c.dao.InsertCollection(ctx, dao.Collection{
+		Name: col.Name,
+		Uid:  col.Uid,
+	})
+}
+
+func (c *CachedReadCntRepository) UpdateCollection(ctx context.Context, col domain.Collection) error {
+	return c.dao.UpdateCollectionName(ctx, col.Id, col.Uid, col.Name)
+}
+
+func (c *CachedReadCntRepository) Collections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error) {
+	cols, err := c.dao.GetCollections(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Collection, domain.Collection](cols, func(idx int, src dao.Collection) domain.Collection {
+		return domain.Collection{
+			Id:    src.Id,
+			Uid:   src.Uid,
+			Name:  src.Name,
+			Ctime: time.UnixMilli(src.Ctime),
+			Utime: time.UnixMilli(src.Utime),
+		}
+	}), nil
+}
+
+func (c *CachedReadCntRepository) DeleteCollection(ctx context.Context, id, uid int64) error {
+	items, err := c.dao.DeleteCollection(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		// 数据库已经提交了，缓存更新失败只能等缓存过期
+		if er := c.cache.DecrCollectCntIfPresent(ctx, item.Biz, item.BizId); er != nil {
+			c.l.Error("更新收藏数缓存失败",
+				logger.String("biz", item.Biz),
+				logger.Int64("bizId", item.BizId),
+				logger.Error(er))
+		}
+	}
+	return nil
+}
+
+func (c *CachedReadCntRepository) DeleteCollectionItem(ctx context.Context, biz string, bizId, uid int64) error {
+	err := c.dao.DeleteCollectionBiz(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+	// 和 DeleteCollection 一样，数据库已经提交了，缓存更新失败只能等缓存过期
+	if er := c.cache.DecrCollectCntIfPresent(ctx, biz, bizId); er != nil {
+		c.l.Error("更新收藏数缓存失败",
+			logger.String("biz", biz),
+			logger.Int64("bizId", bizId),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (c *CachedReadCntRepository) CollectionItems(ctx context.Context, cid, uid int64, offset, limit int) ([]domain.CollectionItem, error) {
+	cbs, err := c.dao.GetCollectionBizs(ctx, cid, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.UserCollectionBiz, domain.CollectionItem](cbs, func(idx int, src dao.UserCollectionBiz) domain.CollectionItem {
+		return domain.CollectionItem{
+			Cid:   src.Cid,
+			Biz:   src.Biz,
+			BizId: src.BizId,
+			Uid:   src.Uid,
+			Ctime: time.UnixMilli(src.Ctime),
+		}
+	}), nil
+}
+
+func (c *CachedReadCntRepository) MoveCollectionItem(ctx context.Context, biz string, bizId, uid, cid int64) error {
+	return c.dao.MoveCollectionBiz(ctx, biz, bizId, uid, cid)
+}
+
 // 最简原则：1. 接收器永远用指针 2. 输入输出都用结构体
 func (c *CachedReadCntRepository) toDomain(intr dao.Interactive) domain.Interactive {
 	return domain.Interactive{
 		LikeCnt:    intr.LikeCnt,
 		CollectCnt: intr.CollectCnt,
 		ReadCnt:    intr.ReadCnt,
+		CommentCnt: intr.CommentCnt,
+		Utime:      time.UnixMilli(intr.Utime),
 	}
 }
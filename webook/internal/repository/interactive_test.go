@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	cachemocks "webook/internal/repository/cache/mocks"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+	"webook/pkg/logger"
+)
+
+func TestCachedReadCntRepository_GetByIds(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		bizIds  []int64
+		wantRes map[int64]domain.Interactive
+		wantErr error
+	}{
+		{
+			name: "全部命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
+					Return(map[int64]domain.Interactive{
+						1: {ReadCnt: 1},
+						2: {ReadCnt: 2},
+					}, nil)
+				return daomocks.NewMockInteractiveDAO(ctrl), c
+			},
+			bizIds: []int64{1, 2},
+			wantRes: map[int64]domain.Interactive{
+				1: {ReadCnt: 1},
+				2: {ReadCnt: 2},
+			},
+		},
+		{
+			name: "部分命中缓存，剩下的查数据库并回写",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2, 3}).
+					Return(map[int64]domain.Interactive{
+						1: {ReadCnt: 1},
+					}, nil)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				// 3 在数据库里面也没有
+				d.EXPECT().GetByIds(gomock.Any(), "article", []int64{2, 3}).
+					Return([]dao.Interactive{
+						{BizId: 2, Biz: "article", ReadCnt: 2, LikeCnt: 1, Utime: now.UnixMilli()},
+					}, nil)
+				c.EXPECT().Set(gomock.Any(), "article", int64(2),
+					domain.Interactive{ReadCnt: 2, LikeCnt: 1, Utime: now}).Return(nil)
+				return d, c
+			},
+			bizIds: []int64{1, 2, 3},
+			wantRes: map[int64]domain.Interactive{
+				1: {ReadCnt: 1},
+				2: {ReadCnt: 2, LikeCnt: 1, Utime: now},
+				3: {},
+			},
+		},
+		{
+			name: "缓存出错，查数据库",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}).
+					Return(nil, errors.New("mock redis error"))
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}).
+					Return([]dao.Interactive{
+						{BizId: 1, Biz: "article", CollectCnt: 3, Utime: now.UnixMilli()},
+					}, nil)
+				c.EXPECT().Set(gomock.Any(), "article", int64(1),
+					domain.Interactive{CollectCnt: 3, Utime: now}).Return(nil)
+				return d, c
+			},
+			bizIds: []int64{1},
+			wantRes: map[int64]domain.Interactive{
+				1: {CollectCnt: 3, Utime: now},
+			},
+		},
+		{
+			name: "数据库出错",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}).
+					Return(map[int64]domain.Interactive{}, nil)
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}).
+					Return(nil, errors.New("mock db error"))
+				return d, c
+			},
+			bizIds:  []int64{1},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c, logger.NewNoOpLogger())
+			res, err := repo.GetByIds(context.Background(), "article", tt.bizIds)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantRes, res)
+			// 等待异步回写缓存
+			time.Sleep(time.Millisecond * 100)
+		})
+	}
+}
+
+func TestCachedReadCntRepository_DeleteCollectionItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache)
+		wantErr error
+	}{
+		{
+			name: "取消收藏，同时减少缓存里面的收藏数",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().DeleteCollectionBiz(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().DecrCollectCntIfPresent(gomock.Any(), "article", int64(1)).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "缓存更新失败，数据库已经删除了，不返回错误",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().DeleteCollectionBiz(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				c := cachemocks.NewMockInteractiveCache(ctrl)
+				c.EXPECT().DecrCollectCntIfPresent(gomock.Any(), "article", int64(1)).
+					Return(errors.New("mock redis error"))
+				return d, c
+			},
+		},
+		{
+			name: "没有收藏过，不更新缓存",
+			mock: func(ctrl *gomock.Controller) (dao.InteractiveDAO, cache.InteractiveCache) {
+				d := daomocks.NewMockInteractiveDAO(ctrl)
+				d.EXPECT().DeleteCollectionBiz(gomock.Any(), "article", int64(1), int64(123)).
+					Return(dao.ErrDataNotFound)
+				return d, cachemocks.NewMockInteractiveCache(ctrl)
+			},
+			wantErr: ErrCollectionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedInteractiveRepository(d, c, logger.NewNoOpLogger())
+			err := repo.DeleteCollectionItem(context.Background(), "article", 1, 123)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCachedReadCntRepository_DeleteCollection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := daomocks.NewMockInteractiveDAO(ctrl)
+	d.EXPECT().DeleteCollection(gomock.Any(), int64(10), int64(123)).
+		Return([]dao.UserCollectionBiz{
+			{Cid: 10, Biz: "article", BizId: 1, Uid: 123},
+			{Cid: 10, Biz: "article", BizId: 2, Uid: 123},
+		}, nil)
+	c := cachemocks.NewMockInteractiveCache(ctrl)
+	c.EXPECT().DecrCollectCntIfPresent(gomock.Any(), "article", int64(1)).Return(nil)
+	// 缓存失败不影响结果
+	c.EXPECT().DecrCollectCntIfPresent(gomock.Any(), "article", int64(2)).
+		Return(errors.New("mock redis error"))
+	repo := NewCachedInteractiveRepository(d, c, logger.NewNoOpLogger())
+	err := repo.DeleteCollection(context.Background(), 10, 123)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/analytics.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/analytics.go -package=repomocks -destination=webook/internal/repository/mocks/analytics.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAnalyticsRepository is a mock of AnalyticsRepository interface.
+type MockAnalyticsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsRepositoryMockRecorder
+}
+
+// MockAnalyticsRepositoryMockRecorder is the mock recorder for MockAnalyticsRepository.
+type MockAnalyticsRepositoryMockRecorder struct {
+	mock *MockAnalyticsRepository
+}
+
+// NewMockAnalyticsRepository creates a new mock instance.
+func NewMockAnalyticsRepository(ctrl *gomock.Controller) *MockAnalyticsRepository {
+	mock := &MockAnalyticsRepository{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsRepository) EXPECT() *MockAnalyticsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ArticleDaily mocks base method.
+func (m *MockAnalyticsRepository) ArticleDaily(ctx context.Context, aid int64, from, to time.Time) ([]domain.AnalyticsPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArticleDaily", ctx, aid, from, to)
+	ret0, _ := ret[0].([]domain.AnalyticsPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArticleDaily indicates an expected call of ArticleDaily.
+func (mr *MockAnalyticsRepositoryMockRecorder) ArticleDaily(ctx, aid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArticleDaily", reflect.TypeOf((*MockAnalyticsRepository)(nil).ArticleDaily), ctx, aid, from, to)
+}
+
+// ArticleTotals mocks base method.
+func (m *MockAnalyticsRepository) ArticleTotals(ctx context.Context, uid int64, from, to time.Time) ([]domain.ArticleAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArticleTotals", ctx, uid, from, to)
+	ret0, _ := ret[0].([]domain.ArticleAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArticleTotals indicates an expected call of ArticleTotals.
+func (mr *MockAnalyticsRepositoryMockRecorder) ArticleTotals(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArticleTotals", reflect.TypeOf((*MockAnalyticsRepository)(nil).ArticleTotals), ctx, uid, from, to)
+}
+
+// AuthorDaily mocks base method.
+func (m *MockAnalyticsRepository) AuthorDaily(ctx context.Context, uid int64, from, to time.Time) ([]domain.AnalyticsPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorDaily", ctx, uid, from, to)
+	ret0, _ := ret[0].([]domain.AnalyticsPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorDaily indicates an expected call of AuthorDaily.
+func (mr *MockAnalyticsRepositoryMockRecorder) AuthorDaily(ctx, uid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorDaily", reflect.TypeOf((*MockAnalyticsRepository)(nil).AuthorDaily), ctx, uid, from, to)
+}
+
+// IncrArticle mocks base method.
+func (m *MockAnalyticsRepository) IncrArticle(ctx context.Context, evt domain.AnalyticsEvent, aid, author int64, t time.Time, delta domain.AnalyticsMetrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrArticle", ctx, evt, aid, author, t, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrArticle indicates an expected call of IncrArticle.
+func (mr *MockAnalyticsRepositoryMockRecorder) IncrArticle(ctx, evt, aid, author, t, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrArticle", reflect.TypeOf((*MockAnalyticsRepository)(nil).IncrArticle), ctx, evt, aid, author, t, delta)
+}
+
+// IncrAuthor mocks base method.
+func (m *MockAnalyticsRepository) IncrAuthor(ctx context.Context, evt domain.AnalyticsEvent, uid int64, t time.Time, delta domain.AnalyticsMetrics) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrAuthor", ctx, evt, uid, t, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrAuthor indicates an expected call of IncrAuthor.
+func (mr *MockAnalyticsRepositoryMockRecorder) IncrAuthor(ctx, evt, uid, t, delta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrAuthor", reflect.TypeOf((*MockAnalyticsRepository)(nil).IncrAuthor), ctx, evt, uid, t, delta)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/article_stats.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/article_stats.go -package=repomocks -destination=webook/internal/repository/mocks/article_stats.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleStatsRepository is a mock of ArticleStatsRepository interface.
+type MockArticleStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleStatsRepositoryMockRecorder
+}
+
+// MockArticleStatsRepositoryMockRecorder is the mock recorder for MockArticleStatsRepository.
+type MockArticleStatsRepositoryMockRecorder struct {
+	mock *MockArticleStatsRepository
+}
+
+// NewMockArticleStatsRepository creates a new mock instance.
+func NewMockArticleStatsRepository(ctrl *gomock.Controller) *MockArticleStatsRepository {
+	mock := &MockArticleStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleStatsRepository) EXPECT() *MockArticleStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DailyViews mocks base method.
+func (m *MockArticleStatsRepository) DailyViews(ctx context.Context, aid int64, from, to time.Time) ([]domain.ArticleDailyViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DailyViews", ctx, aid, from, to)
+	ret0, _ := ret[0].([]domain.ArticleDailyViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DailyViews indicates an expected call of DailyViews.
+func (mr *MockArticleStatsRepositoryMockRecorder) DailyViews(ctx, aid, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DailyViews", reflect.TypeOf((*MockArticleStatsRepository)(nil).DailyViews), ctx, aid, from, to)
+}
+
+// Flush mocks base method.
+func (m *MockArticleStatsRepository) Flush(ctx context.Context, day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx, day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockArticleStatsRepositoryMockRecorder) Flush(ctx, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockArticleStatsRepository)(nil).Flush), ctx, day)
+}
+
+// RecordRead mocks base method.
+func (m *MockArticleStatsRepository) RecordRead(ctx context.Context, aid int64, reader string, t time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRead", ctx, aid, reader, t)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordRead indicates an expected call of RecordRead.
+func (mr *MockArticleStatsRepositoryMockRecorder) RecordRead(ctx, aid, reader, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRead", reflect.TypeOf((*MockArticleStatsRepository)(nil).RecordRead), ctx, aid, reader, t)
+}
@@ -0,0 +1,189 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/repository/transfer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/repository/transfer.go -package=repomocks -destination=webook/internal/repository/mocks/transfer.mock.go
+//
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleTransferRepository is a mock of ArticleTransferRepository interface.
+type MockArticleTransferRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleTransferRepositoryMockRecorder
+}
+
+// MockArticleTransferRepositoryMockRecorder is the mock recorder for MockArticleTransferRepository.
+type MockArticleTransferRepositoryMockRecorder struct {
+	mock *MockArticleTransferRepository
+}
+
+// NewMockArticleTransferRepository creates a new mock instance.
+func NewMockArticleTransferRepository(ctrl *gomock.Controller) *MockArticleTransferRepository {
+	mock := &MockArticleTransferRepository{ctrl: ctrl}
+	mock.recorder = &MockArticleTransferRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleTransferRepository) EXPECT() *MockArticleTransferRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockArticleTransferRepository) Create(ctx context.Context, t domain.ArticleTransfer, since time.Time) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, t, since)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockArticleTransferRepositoryMockRecorder) Create(ctx, t, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleTransferRepository)(nil).Create), ctx, t, since)
+}
+
+// FailStale mocks base method.
+func (m *MockArticleTransferRepository) FailStale(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStale", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStale indicates an expected call of FailStale.
+func (mr *MockArticleTransferRepositoryMockRecorder) FailStale(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStale", reflect.TypeOf((*MockArticleTransferRepository)(nil).FailStale), ctx, before)
+}
+
+// FindById mocks base method.
+func (m *MockArticleTransferRepository) FindById(ctx context.Context, id int64) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockArticleTransferRepositoryMockRecorder) FindById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockArticleTransferRepository)(nil).FindById), ctx, id)
+}
+
+// FindUnfinished mocks base method.
+func (m *MockArticleTransferRepository) FindUnfinished(ctx context.Context, uid int64, kind domain.TransferKind, since time.Time) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnfinished", ctx, uid, kind, since)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnfinished indicates an expected call of FindUnfinished.
+func (mr *MockArticleTransferRepositoryMockRecorder) FindUnfinished(ctx, uid, kind, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnfinished", reflect.TypeOf((*MockArticleTransferRepository)(nil).FindUnfinished), ctx, uid, kind, since)
+}
+
+// Finish mocks base method.
+func (m *MockArticleTransferRepository) Finish(ctx context.Context, t domain.ArticleTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockArticleTransferRepositoryMockRecorder) Finish(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockArticleTransferRepository)(nil).Finish), ctx, t)
+}
+
+// GetExport mocks base method.
+func (m *MockArticleTransferRepository) GetExport(ctx context.Context, t domain.ArticleTransfer) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", ctx, t)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockArticleTransferRepositoryMockRecorder) GetExport(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockArticleTransferRepository)(nil).GetExport), ctx, t)
+}
+
+// ListFinished mocks base method.
+func (m *MockArticleTransferRepository) ListFinished(ctx context.Context, before time.Time, limit int) ([]domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFinished", ctx, before, limit)
+	ret0, _ := ret[0].([]domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFinished indicates an expected call of ListFinished.
+func (mr *MockArticleTransferRepositoryMockRecorder) ListFinished(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFinished", reflect.TypeOf((*MockArticleTransferRepository)(nil).ListFinished), ctx, before, limit)
+}
+
+// Purge mocks base method.
+func (m *MockArticleTransferRepository) Purge(ctx context.Context, t domain.ArticleTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockArticleTransferRepositoryMockRecorder) Purge(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockArticleTransferRepository)(nil).Purge), ctx, t)
+}
+
+// SaveExport mocks base method.
+func (m *MockArticleTransferRepository) SaveExport(ctx context.Context, t domain.ArticleTransfer, r io.ReadSeeker) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExport", ctx, t, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveExport indicates an expected call of SaveExport.
+func (mr *MockArticleTransferRepositoryMockRecorder) SaveExport(ctx, t, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExport", reflect.TypeOf((*MockArticleTransferRepository)(nil).SaveExport), ctx, t, r)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockArticleTransferRepository) UpdateProgress(ctx context.Context, t domain.ArticleTransfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockArticleTransferRepositoryMockRecorder) UpdateProgress(ctx, t any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockArticleTransferRepository)(nil).UpdateProgress), ctx, t)
+}
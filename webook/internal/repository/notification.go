@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/ecodeclub/ekit/sqlx"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+type NotificationRepository interface {
+	// Add 写入一条通知，n.LastActor 是触发通知的人，会和已有的通知聚合在一起
+	Add(ctx context.Context, n domain.Notification) error
+	List(ctx context.Context, receiver int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCnt(ctx context.Context, receiver int64) (int64, error)
+	MarkRead(ctx context.Context, receiver int64, ids []int64) error
+	// Subscribe 订阅所有实例上产生的新通知
+	Subscribe(ctx context.Context) (<-chan domain.Notification, error)
+}
+
+type CachedNotificationRepository struct {
+	dao   dao.NotificationDAO
+	cache cache.NotificationCache
+	l     logger.LoggerV1
+}
+
+func NewCachedNotificationRepository(dao dao.NotificationDAO, cache cache.NotificationCache, l logger.LoggerV1) NotificationRepository {
+	return &CachedNotificationRepository{
+		dao:   dao,
+		cache: cache,
+		l:     l,
+	}
+}
+
+func (c *CachedNotificationRepository) Add(ctx context.Context, n domain.Notification) error {
+	entity, change, err := c.dao.Upsert(ctx, c.toEntity(n))
+	if err != nil || change == dao.NotificationUnchanged {
+		return err
+	}
+	if change == dao.NotificationUnread {
+		if er := c.cache.IncrUnreadCntIfPresent(ctx, n.Receiver); er != nil {
+			c.l.Error("更新未读数缓存失败",
+				logger.Int64("uid", n.Receiver),
+				logger.Error(er))
+		}
+	}
+	// 推送失败不影响收件箱，用户刷新一下就能看到
+	if er := c.cache.Publish(ctx, c.toDomain(0, entity)); er != nil {
+		c.l.Error("推送通知失败",
+			logger.Int64("uid", n.Receiver),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (c *CachedNotificationRepository) List(ctx context.Context, receiver int64, offset, limit int) ([]domain.Notification, error) {
+	ns, err := c.dao.List(ctx, receiver, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.Notification, domain.Notification](ns, c.toDomain), nil
+}
+
+func (c *CachedNotificationRepository) UnreadCnt(ctx context.Context, receiver int64) (int64, error) {
+	cnt, err := c.cache.UnreadCnt(ctx, receiver)
+	if err == nil {
+		return cnt, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		c.l.Error("查询未读数缓存失败",
+			logger.Int64("uid", receiver),
+			logger.Error(err))
+	}
+	cnt, err = c.dao.CountUnread(ctx, receiver)
+	if err != nil {
+		return 0, err
+	}
+	if er := c.cache.SetUnreadCnt(ctx, receiver, cnt); er != nil {
+		c.l.Error("回写未读数缓存失败",
+			logger.Int64("uid", receiver),
+			logger.Error(er))
+	}
+	return cnt, nil
+}
+
+func (c *CachedNotificationRepository) MarkRead(ctx context.Context, receiver int64, ids []int64) error {
+	if err := c.dao.MarkRead(ctx, receiver, ids); err != nil {
+		return err
+	}
+	// 标记部分已读的时候不好计算减少了多少，直接删除缓存
+	return c.cache.DelUnreadCnt(ctx, receiver)
+}
+
+func (c *CachedNotificationRepository) Subscribe(ctx context.Context) (<-chan domain.Notification, error) {
+	return c.cache.Subscribe(ctx)
+}
+
+func (c *CachedNotificationRepository) toEntity(n domain.Notification) dao.Notification {
+	return dao.Notification{
+		Id:        n.Id,
+		Receiver:  n.Receiver,
+		Type:      uint8(n.Type),
+		Biz:       n.Biz,
+		BizId:     n.BizId,
+		SourceId:  n.SourceId,
+		LastActor: n.LastActor,
+		RecentActors: sqlx.JsonColumn[[]int64]{
+			Val:   n.RecentActors,
+			Valid: len(n.RecentActors) > 0,
+		},
+		ActorCnt: n.ActorCnt,
+		Content:  n.Content,
+	}
+}
+
+func (c *CachedNotificationRepository) toDomain(idx int, n dao.Notification) domain.Notification {
+	return domain.Notification{
+		Id:           n.Id,
+		Receiver:     n.Receiver,
+		Type:         domain.NotificationType(n.Type),
+		Biz:          n.Biz,
+		BizId:        n.BizId,
+		SourceId:     n.SourceId,
+		LastActor:    n.LastActor,
+		RecentActors: n.RecentActors.Val,
+		ActorCnt:     n.ActorCnt,
+		Content:      n.Content,
+		Read:         n.Status == dao.NotificationStatusRead,
+		Ctime:        time.UnixMilli(n.Ctime),
+		Utime:        time.UnixMilli(n.Utime),
+	}
+}
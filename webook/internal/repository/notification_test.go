@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	cachemocks "webook/internal/repository/cache/mocks"
+	"webook/internal/repository/dao"
+	daomocks "webook/internal/repository/dao/mocks"
+	"webook/pkg/logger"
+)
+
+func TestCachedNotificationRepository_Add(t *testing.T) {
+	n := domain.Notification{
+		Receiver:  2,
+		Type:      domain.NotificationTypeLike,
+		Biz:       "article",
+		BizId:     1,
+		LastActor: 3,
+	}
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache)
+		wantErr error
+	}{
+		{
+			name: "新的未读通知，增加未读数并推送",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+					Return(dao.Notification{Id: 1, Receiver: 2, ActorCnt: 1}, dao.NotificationUnread, nil)
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().IncrUnreadCntIfPresent(gomock.Any(), int64(2)).Return(nil)
+				c.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "聚合到未读的通知里面，只推送",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+					Return(dao.Notification{Id: 1, Receiver: 2, ActorCnt: 2}, dao.NotificationUpdated, nil)
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil)
+				return d, c
+			},
+		},
+		{
+			name: "同一个人重复操作，什么也不做",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+					Return(dao.Notification{Id: 1}, dao.NotificationUnchanged, nil)
+				return d, cachemocks.NewMockNotificationCache(ctrl)
+			},
+		},
+		{
+			name: "推送失败不影响结果",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+					Return(dao.Notification{Id: 1, Receiver: 2}, dao.NotificationUpdated, nil)
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(errors.New("mock redis error"))
+				return d, c
+			},
+		},
+		{
+			name: "数据库错误",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+					Return(dao.Notification{}, dao.NotificationUnchanged, errors.New("mock db error"))
+				return d, cachemocks.NewMockNotificationCache(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedNotificationRepository(d, c, logger.NewNoOpLogger())
+			err := repo.Add(context.Background(), n)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestCachedNotificationRepository_UnreadCnt(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache)
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "命中缓存",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().UnreadCnt(gomock.Any(), int64(2)).Return(int64(5), nil)
+				return daomocks.NewMockNotificationDAO(ctrl), c
+			},
+			wantCnt: 5,
+		},
+		{
+			name: "未命中缓存，查询数据库并回写",
+			mock: func(ctrl *gomock.Controller) (dao.NotificationDAO, cache.NotificationCache) {
+				c := cachemocks.NewMockNotificationCache(ctrl)
+				c.EXPECT().UnreadCnt(gomock.Any(), int64(2)).Return(int64(0), cache.ErrKeyNotExist)
+				d := daomocks.NewMockNotificationDAO(ctrl)
+				d.EXPECT().CountUnread(gomock.Any(), int64(2)).Return(int64(3), nil)
+				c.EXPECT().SetUnreadCnt(gomock.Any(), int64(2), int64(3)).Return(nil)
+				return d, c
+			},
+			wantCnt: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d, c := tt.mock(ctrl)
+			repo := NewCachedNotificationRepository(d, c, logger.NewNoOpLogger())
+			cnt, err := repo.UnreadCnt(context.Background(), 2)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCnt, cnt)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/pkg/logger"
+)
+
+type RankingRepository interface {
+	ReplaceTopN(ctx context.Context, arts []domain.Article) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+	// Remove 从热榜里面去掉一篇文章，比如说文章被删除了
+	// 其它实例的本地缓存只能等过期，或者等下一次计算热榜
+	Remove(ctx context.Context, id int64) error
+}
+
+// CachedRankingRepository 热榜只存在于缓存里面
+// 查询顺序是 本地缓存 -> Redis -> 过期的本地缓存
+type CachedRankingRepository struct {
+	redis *cache.RankingRedisCache
+	local *cache.RankingLocalCache
+	l     logger.LoggerV1
+}
+
+func NewCachedRankingRepository(redis *cache.RankingRedisCache,
+	local *cache.RankingLocalCache, l logger.LoggerV1) RankingRepository {
+	return &CachedRankingRepository{
+		redis: redis,
+		local: local,
+		l:     l,
+	}
+}
+
+func (repo *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	// 本地缓存不会失败，先更新本地缓存
+	_ = repo.local.Set(ctx, arts)
+	return repo.redis.Set(ctx, arts)
+}
+
+func (repo *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := repo.local.Get(ctx)
+	if err == nil {
+		return arts, nil
+	}
+	arts, err = repo.redis.Get(ctx)
+	if err == nil {
+		_ = repo.local.Set(ctx, arts)
+		return arts, nil
+	}
+	repo.l.Error("从 Redis 中查询热榜失败，尝试使用本地缓存", logger.Error(err))
+	return repo.local.ForceGet(ctx)
+}
+
+func (repo *CachedRankingRepository) Remove(ctx context.Context, id int64) error {
+	_ = repo.local.Remove(ctx, id)
+	return repo.redis.Remove(ctx, id)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao/search"
+)
+
+type SearchRepository interface {
+	// InputArticle 索引已经发表的文章，重复索引相当于更新
+	InputArticle(ctx context.Context, art domain.SearchArticle) error
+	DeleteArticle(ctx context.Context, id int64) error
+	// InputUser 索引用户，同时更新这个用户已经被索引了的文章的作者昵称
+	InputUser(ctx context.Context, u domain.SearchUser) error
+	SearchArticle(ctx context.Context, query string, offset, limit int) (domain.SearchArticleResult, error)
+	SearchUser(ctx context.Context, query string, offset, limit int) (domain.SearchUserResult, error)
+}
+
+type searchRepository struct {
+	dao search.SearchDAO
+}
+
+func NewSearchRepository(dao search.SearchDAO) SearchRepository {
+	return &searchRepository{
+		dao: dao,
+	}
+}
+
+func (s *searchRepository) InputArticle(ctx context.Context, art domain.SearchArticle) error {
+	return s.dao.InputArticle(ctx, search.Article{
+		Id:       art.Id,
+		AuthorId: art.AuthorId,
+		Author:   art.Author,
+		Title:    art.Title,
+		Content:  art.Content,
+		Abstract: art.Abstract,
+		Tags:     art.Tags,
+		Utime:    art.Utime.UnixMilli(),
+	})
+}
+
+func (s *searchRepository) DeleteArticle(ctx context.Context, id int64) error {
+	return s.dao.DeleteArticle(ctx, id)
+}
+
+func (s *searchRepository) InputUser(ctx context.Context, u domain.SearchUser) error {
+	err := s.dao.InputUser(ctx, search.User{
+		Id:       u.Id,
+		Nickname: u.Nickname,
+		Bio:      u.Bio,
+	})
+	if err != nil {
+		return err
+	}
+	return s.dao.UpdateAuthor(ctx, u.Id, u.Nickname)
+}
+
+func (s *searchRepository) SearchArticle(ctx context.Context, query string, offset, limit int) (domain.SearchArticleResult, error) {
+	res, err := s.dao.SearchArticle(ctx, query, offset, limit)
+	if err != nil {
+		return domain.SearchArticleResult{}, err
+	}
+	return domain.SearchArticleResult{
+		Total: res.Total,
+		Articles: slice.Map(res.Hits, func(idx int, src search.ArticleHit) domain.SearchArticle {
+			return domain.SearchArticle{
+				Id:        src.Id,
+				AuthorId:  src.AuthorId,
+				Author:    src.Author,
+				Title:     src.Title,
+				Content:   src.Content,
+				Abstract:  src.Abstract,
+				Tags:      src.Tags,
+				Utime:     time.UnixMilli(src.Utime),
+				Highlight: src.Highlight,
+			}
+		}),
+	}, nil
+}
+
+func (s *searchRepository) SearchUser(ctx context.Context, query string, offset, limit int) (domain.SearchUserResult, error) {
+	res, err := s.dao.SearchUser(ctx, query, offset, limit)
+	if err != nil {
+		return domain.SearchUserResult{}, err
+	}
+	return domain.SearchUserResult{
+		Total: res.Total,
+		Users: slice.Map(res.Hits, func(idx int, src search.UserHit) domain.SearchUser {
+			return domain.SearchUser{
+				Id:        src.Id,
+				Nickname:  src.Nickname,
+				Bio:       src.Bio,
+				Highlight: src.Highlight,
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"io"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+	"webook/internal/repository/dao/blob"
+)
+
+var (
+	ErrTransferNotFound = dao.ErrTransferNotFound
+	// ErrTransferDuplicate 同一个用户同一种任务已经有一个没有结束的
+	ErrTransferDuplicate = dao.ErrTransferDuplicate
+	// ErrExportNotFound 导出的文件不存在，一般是被清理掉了
+	ErrExportNotFound = blob.ErrNotFound
+)
+
+type ArticleTransferRepository interface {
+	// Create since 之前就没有进展的同类任务当作已经中断了，标记为失败
+	// 还有没有结束的同类任务的时候返回 ErrTransferDuplicate
+	Create(ctx context.Context, t domain.ArticleTransfer, since time.Time) (domain.ArticleTransfer, error)
+	UpdateProgress(ctx context.Context, t domain.ArticleTransfer) error
+	// Finish 记录最终的状态、进度和 Msg
+	Finish(ctx context.Context, t domain.ArticleTransfer) error
+	FindById(ctx context.Context, id int64) (domain.ArticleTransfer, error)
+	// FindUnfinished 最近一个没有结束的任务，since 之后都没有进展的任务当作已经中断了
+	FindUnfinished(ctx context.Context, uid int64, kind domain.TransferKind, since time.Time) (domain.ArticleTransfer, error)
+	// FailStale 把 before 之前就没有进展的任务标记为失败，返回标记了的数量
+	FailStale(ctx context.Context, before time.Time) (int64, error)
+	// ListFinished before 之前就已经结束了的任务，给清理任务用
+	ListFinished(ctx context.Context, before time.Time, limit int) ([]domain.ArticleTransfer, error)
+	// Purge 删除任务，导出任务还会删除导出的 zip
+	Purge(ctx context.Context, t domain.ArticleTransfer) error
+	// SaveExport 保存导出的 zip
+	SaveExport(ctx context.Context, t domain.ArticleTransfer, r io.ReadSeeker) error
+	GetExport(ctx context.Context, t domain.ArticleTransfer) ([]byte, error)
+}
+
+type articleTransferRepository struct {
+	dao   dao.ArticleTransferDAO
+	store blob.Store
+}
+
+func NewArticleTransferRepository(dao dao.ArticleTransferDAO, store blob.Store) ArticleTransferRepository {
+	return &articleTransferRepository{
+		dao:   dao,
+		store: store,
+	}
+}
+
+func (r *articleTransferRepository) Create(ctx context.Context, t domain.ArticleTransfer,
+	since time.Time) (domain.ArticleTransfer, error) {
+	res, err := r.dao.Insert(ctx, r.toEntity(t), since.UnixMilli())
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	return r.toDomain(res), nil
+}
+
+func (r *articleTransferRepository) UpdateProgress(ctx context.Context, t domain.ArticleTransfer) error {
+	return r.dao.UpdateProgress(ctx, t.Id, t.Total, t.Done, t.Failed)
+}
+
+func (r *articleTransferRepository) Finish(ctx context.Context, t domain.ArticleTransfer) error {
+	return r.dao.Finish(ctx, t.Id, uint8(t.Status), t.Done, t.Failed, t.Msg)
+}
+
+func (r *articleTransferRepository) FindById(ctx context.Context, id int64) (domain.ArticleTransfer, error) {
+	res, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	return r.toDomain(res), nil
+}
+
+func (r *articleTransferRepository) FindUnfinished(ctx context.Context, uid int64,
+	kind domain.TransferKind, since time.Time) (domain.ArticleTransfer, error) {
+	res, err := r.dao.FindUnfinished(ctx, uid, uint8(kind), since.UnixMilli())
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	return r.toDomain(res), nil
+}
+
+func (r *articleTransferRepository) FailStale(ctx context.Context, before time.Time) (int64, error) {
+	return r.dao.FailStale(ctx, before.UnixMilli())
+}
+
+func (r *articleTransferRepository) ListFinished(ctx context.Context, before time.Time,
+	limit int) ([]domain.ArticleTransfer, error) {
+	res, err := r.dao.ListFinished(ctx, before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.ArticleTransfer) domain.ArticleTransfer {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *articleTransferRepository) Purge(ctx context.Context, t domain.ArticleTransfer) error {
+	// 先删文件，删除失败的话下一次还能找到这个任务
+	if t.Kind == domain.TransferKindExport {
+		if err := r.store.Delete(ctx, r.exportKey(t)); err != nil {
+			return err
+		}
+	}
+	return r.dao.DeleteById(ctx, t.Id)
+}
+
+func (r *articleTransferRepository) SaveExport(ctx context.Context, t domain.ArticleTransfer, rd io.ReadSeeker) error {
+	return r.store.PutReader(ctx, r.exportKey(t), rd, "application/zip")
+}
+
+func (r *articleTransferRepository) GetExport(ctx context.Context, t domain.ArticleTransfer) ([]byte, error) {
+	obj, err := r.store.Get(ctx, r.exportKey(t))
+	if err != nil {
+		return nil, err
+	}
+	return obj.Data, nil
+}
+
+// exportKey 导出的文件只能通过下载接口拿到，不暴露对象存储的地址
+func (r *articleTransferRepository) exportKey(t domain.ArticleTransfer) string {
+	return fmt.Sprintf("exports/%d/%d.zip", t.Uid, t.Id)
+}
+
+func (r *articleTransferRepository) toEntity(t domain.ArticleTransfer) dao.ArticleTransfer {
+	return dao.ArticleTransfer{
+		Id:     t.Id,
+		Uid:    t.Uid,
+		Kind:   uint8(t.Kind),
+		Status: uint8(t.Status),
+		Total:  t.Total,
+		Done:   t.Done,
+		Failed: t.Failed,
+		Msg:    t.Msg,
+	}
+}
+
+func (r *articleTransferRepository) toDomain(t dao.ArticleTransfer) domain.ArticleTransfer {
+	return domain.ArticleTransfer{
+		Id:     t.Id,
+		Uid:    t.Uid,
+		Kind:   domain.TransferKind(t.Kind),
+		Status: domain.TransferStatus(t.Status),
+		Total:  t.Total,
+		Done:   t.Done,
+		Failed: t.Failed,
+		Msg:    t.Msg,
+		Ctime:  time.UnixMilli(t.Ctime),
+		Utime:  time.UnixMilli(t.Utime),
+	}
+}
@@ -64,7 +64,8 @@ func (r *CachedUserRepository) FindById(c context.Context, uid int64) (domain.Us
 	}
 	uu, err := r.dao.FindByUserId(c, uid)
 	if err != nil {
-		return domain.User{}, fmt.Errorf("id can not be found")
+		// 带上原始的错误，调用者要区分找不到和别的错误
+		return domain.User{}, fmt.Errorf("id can not be found: %w", err)
 	}
 	u = r.entityToDomain(uu)
 	// _ = r.cache.Set(c, u)
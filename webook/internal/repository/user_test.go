@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -114,7 +115,7 @@ func TestCachedUserRepository_FindById(t *testing.T) {
 
 			c:       context.Background(),
 			id:      12,
-			wantErr: errors.New("id can not be found"),
+			wantErr: fmt.Errorf("id can not be found: %w", dao.ErrUserNotFound),
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/repository/article"
+)
+
+// MaxTopArticles 文章排行最多返回多少篇
+const MaxTopArticles = 50
+
+var ErrInvalidGranularity = errors.New("不支持的时间粒度")
+
+// MaxAnalyticsPeriods 不同粒度最多查询最近多少个区间，不支持的粒度返回 0
+func MaxAnalyticsPeriods(g domain.AnalyticsGranularity) int {
+	switch g {
+	case domain.AnalyticsGranularityDay:
+		return MaxStatsDays
+	case domain.AnalyticsGranularityWeek:
+		return 26
+	case domain.AnalyticsGranularityMonth:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// AnalyticsService 作者后台的数据分析
+type AnalyticsService interface {
+	// Series 最近 periods 个区间的数据，包括还没有过完的当前区间
+	// aid 为 0 的时候是作者所有文章的汇总，加上新增的粉丝，否则是作者的某一篇文章
+	// 按照时间升序，没有数据的区间也有一条全是 0 的数据，方便前端画图
+	Series(ctx context.Context, uid, aid int64, g domain.AnalyticsGranularity, periods int) ([]domain.AnalyticsPoint, error)
+	// TopArticles 作者最近 days 天里面 metric 最高的 limit 篇文章，已经撤回或者删除的文章不算
+	TopArticles(ctx context.Context, uid int64, days int, metric domain.AnalyticsMetric, limit int) ([]domain.ArticleAnalytics, error)
+}
+
+type analyticsService struct {
+	repo    repository.AnalyticsRepository
+	artRepo article.ArticleRepository
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, artRepo article.ArticleRepository) AnalyticsService {
+	return &analyticsService{
+		repo:    repo,
+		artRepo: artRepo,
+	}
+}
+
+func (svc *analyticsService) Series(ctx context.Context, uid, aid int64,
+	g domain.AnalyticsGranularity, periods int) ([]domain.AnalyticsPoint, error) {
+	maxPeriods := MaxAnalyticsPeriods(g)
+	if maxPeriods == 0 {
+		return nil, ErrInvalidGranularity
+	}
+	periods = min(max(periods, 1), maxPeriods)
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := g.Add(g.Start(to), 1-periods)
+
+	var (
+		daily []domain.AnalyticsPoint
+		err   error
+	)
+	if aid > 0 {
+		art, er := svc.artRepo.GetById(ctx, aid)
+		if er != nil {
+			return nil, er
+		}
+		if art.Author.Id != uid {
+			return nil, ErrPossibleIncorrectAuthor
+		}
+		daily, err = svc.repo.ArticleDaily(ctx, aid, from, to)
+	} else {
+		daily, err = svc.repo.AuthorDaily(ctx, uid, from, to)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.AnalyticsPoint, 0, periods)
+	for start := from; !start.After(to); start = g.Add(start, 1) {
+		res = append(res, domain.AnalyticsPoint{Start: start})
+	}
+	// daily 和 res 都是升序的，一趟就能归到对应的区间里面
+	i := 0
+	for _, p := range daily {
+		for i+1 < len(res) && !p.Start.Before(res[i+1].Start) {
+			i++
+		}
+		if p.Start.Before(res[i].Start) {
+			continue
+		}
+		res[i].Metrics = res[i].Metrics.Add(p.Metrics)
+	}
+	return res, nil
+}
+
+func (svc *analyticsService) TopArticles(ctx context.Context, uid int64, days int,
+	metric domain.AnalyticsMetric, limit int) ([]domain.ArticleAnalytics, error) {
+	days = min(max(days, 1), MaxStatsDays)
+	limit = min(max(limit, 1), MaxTopArticles)
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, 1-days)
+	totals, err := svc.repo.ArticleTotals(ctx, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// 一样多的时候新文章在前面
+	slices.SortFunc(totals, func(a, b domain.ArticleAnalytics) int {
+		if c := cmp.Compare(metric.Of(b.Metrics), metric.Of(a.Metrics)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Article.Id, a.Article.Id)
+	})
+	res := make([]domain.ArticleAnalytics, 0, min(limit, len(totals)))
+	// 撤回或者删除了的文章查不到标题，会被跳过，所以每次按照还差的数量查一批，直到凑够为止
+	for len(totals) > 0 && len(res) < limit {
+		batch := totals[:min(limit-len(res), len(totals))]
+		totals = totals[len(batch):]
+		ids := make([]int64, 0, len(batch))
+		metrics := make(map[int64]domain.AnalyticsMetrics, len(batch))
+		for _, t := range batch {
+			ids = append(ids, t.Article.Id)
+			metrics[t.Article.Id] = t.Metrics
+		}
+		arts, err := svc.artRepo.ListPubTitlesByIds(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			res = append(res, domain.ArticleAnalytics{
+				Article: art,
+				Metrics: metrics[art.Id],
+			})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	artrepomocks "webook/internal/repository/article/mocks"
+	repomocks "webook/internal/repository/mocks"
+)
+
+func Test_analyticsService_Series(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	thisWeek := domain.AnalyticsGranularityWeek.Start(today)
+	lastWeek := thisWeek.AddDate(0, 0, -7)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+	repo := repomocks.NewMockAnalyticsRepository(ctrl)
+	svc := NewAnalyticsService(repo, artRepo)
+
+	// 作者的汇总按周归并，没有数据的周补 0
+	repo.EXPECT().AuthorDaily(gomock.Any(), int64(123), lastWeek.AddDate(0, 0, -7), today).
+		Return([]domain.AnalyticsPoint{
+			{Start: lastWeek, Metrics: domain.AnalyticsMetrics{ReadCnt: 5, FollowerCnt: 1}},
+			{Start: lastWeek.AddDate(0, 0, 6), Metrics: domain.AnalyticsMetrics{ReadCnt: 2, LikeCnt: 3}},
+			{Start: today, Metrics: domain.AnalyticsMetrics{CommentCnt: 4}},
+		}, nil)
+	res, err := svc.Series(context.Background(), 123, 0, domain.AnalyticsGranularityWeek, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AnalyticsPoint{
+		{Start: lastWeek.AddDate(0, 0, -7)},
+		{Start: lastWeek, Metrics: domain.AnalyticsMetrics{ReadCnt: 7, LikeCnt: 3, FollowerCnt: 1}},
+		{Start: thisWeek, Metrics: domain.AnalyticsMetrics{CommentCnt: 4}},
+	}, res)
+
+	// 单篇文章按月归并
+	thisMonth := domain.AnalyticsGranularityMonth.Start(today)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	artRepo.EXPECT().GetById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}}, nil).Times(2)
+	repo.EXPECT().ArticleDaily(gomock.Any(), int64(1), lastMonth, today).
+		Return([]domain.AnalyticsPoint{
+			{Start: lastMonth.AddDate(0, 0, 3), Metrics: domain.AnalyticsMetrics{ReadCnt: 10, CollectCnt: 1}},
+		}, nil)
+	res, err = svc.Series(context.Background(), 123, 1, domain.AnalyticsGranularityMonth, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AnalyticsPoint{
+		{Start: lastMonth, Metrics: domain.AnalyticsMetrics{ReadCnt: 10, CollectCnt: 1}},
+		{Start: thisMonth},
+	}, res)
+
+	// 只能看自己的文章
+	_, err = svc.Series(context.Background(), 234, 1, domain.AnalyticsGranularityDay, 7)
+	assert.Equal(t, ErrPossibleIncorrectAuthor, err)
+
+	_, err = svc.Series(context.Background(), 123, 0, domain.AnalyticsGranularityUnknown, 7)
+	assert.Equal(t, ErrInvalidGranularity, err)
+}
+
+func Test_analyticsService_TopArticles(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+	repo := repomocks.NewMockAnalyticsRepository(ctrl)
+	repo.EXPECT().ArticleTotals(gomock.Any(), int64(123), today.AddDate(0, 0, -6), today).
+		Return([]domain.ArticleAnalytics{
+			{Article: domain.Article{Id: 1}, Metrics: domain.AnalyticsMetrics{ReadCnt: 5, LikeCnt: 1}},
+			{Article: domain.Article{Id: 2}, Metrics: domain.AnalyticsMetrics{ReadCnt: 9}},
+			{Article: domain.Article{Id: 3}, Metrics: domain.AnalyticsMetrics{ReadCnt: 9}},
+			{Article: domain.Article{Id: 4}, Metrics: domain.AnalyticsMetrics{ReadCnt: 1}},
+		}, nil)
+	// 阅读数一样的时候新文章在前面，3 已经撤回了，要再查一批补上
+	artRepo.EXPECT().ListPubTitlesByIds(gomock.Any(), []int64{3, 2}).
+		Return([]domain.Article{{Id: 2, Title: "标题2"}}, nil)
+	artRepo.EXPECT().ListPubTitlesByIds(gomock.Any(), []int64{1}).
+		Return([]domain.Article{{Id: 1, Title: "标题1"}}, nil)
+	svc := NewAnalyticsService(repo, artRepo)
+
+	res, err := svc.TopArticles(context.Background(), 123, 7, domain.AnalyticsMetricRead, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ArticleAnalytics{
+		{Article: domain.Article{Id: 2, Title: "标题2"}, Metrics: domain.AnalyticsMetrics{ReadCnt: 9}},
+		{Article: domain.Article{Id: 1, Title: "标题1"}, Metrics: domain.AnalyticsMetrics{ReadCnt: 5, LikeCnt: 1}},
+	}, res)
+}
@@ -2,26 +2,110 @@ package service
 
 import (
 	"context"
+	"errors"
+	"golang.org/x/sync/errgroup"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
 	"webook/internal/domain"
 	events "webook/internal/events/article"
+	"webook/internal/events/search"
+	"webook/internal/repository"
 	"webook/internal/repository/article"
+	"webook/pkg/linediff"
 	"webook/pkg/logger"
 )
 
+var (
+	ErrRevisionNotFound = article.ErrRevisionNotFound
+	ErrNotScheduled     = article.ErrNotScheduled
+	// ErrVersionConflict 保存的时候带上的版本号已经过期了
+	ErrVersionConflict = article.ErrVersionConflict
+	// ErrNotInRecycleBin 文章不在回收站里面，或者已经过了可以恢复的时间
+	ErrNotInRecycleBin = article.ErrNotInRecycleBin
+	// ErrPossibleIncorrectAuthor 文章不存在，不属于这个作者，或者已经删除了
+	ErrPossibleIncorrectAuthor = article.ErrPossibleIncorrectAuthor
+	// ErrInvalidPublishTime 定时发表的时间必须在未来
+	ErrInvalidPublishTime = errors.New("定时发表的时间不对")
+	// ErrInvalidTags 标签太多，或者标签、分类太长
+	ErrInvalidTags = errors.New("标签或者分类不合法")
+	// ErrInvalidCover 封面必须是 http(s) 或者站内的地址
+	ErrInvalidCover = errors.New("封面不合法")
+	// ErrContentTooLong 内容超过了 maxContentLen
+	ErrContentTooLong = errors.New("文章内容太长")
+	// ErrRevisionRequired 修改已有的文章必须带上编辑的时候拿到的版本号
+	ErrRevisionRequired = errors.New("缺少版本号")
+)
+
+const (
+	maxTagCnt      = 5
+	maxTagLength   = 20
+	maxCategoryLen = 20
+	maxCoverLen    = 512
+	// maxContentLen 按字节计算，保存的时候要同步渲染一遍生成摘要
+	maxContentLen = 256 << 10
+)
+
 type ArticleService interface {
+	// Save art.Id 不为 0 的时候必须带上 art.Revision，没有的话返回 ErrRevisionRequired，
+	// 已经被修改过的话返回 ErrVersionConflict
+	// 保存成功之后的版本号就是 art.Revision + 1，Publish 和 Schedule 也一样
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Publish(ctx context.Context, art domain.Article) (int64, error)
 	Withdraw(ctx context.Context, uid, id int64) error
 	PublishV1(ctx context.Context, art domain.Article) (int64, error)
+	// Schedule 保存草稿，并且在 publishAt 的时候自动发表
+	Schedule(ctx context.Context, art domain.Article, publishAt time.Time) (int64, error)
+	// Reschedule 只修改定时发表的时间
+	Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error
+	// CancelSchedule 取消定时发表，文章回到草稿的状态
+	CancelSchedule(ctx context.Context, uid, id int64) error
+	// PublishDue 分批发表所有到期的定时发表文章，limit 是每一批的数量，返回成功发表的数量，给定时任务用
+	// 一篇失败不影响后面的文章，返回的是最后一个错误
+	PublishDue(ctx context.Context, now time.Time, limit int) (int, error)
 	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	// ListIds 按照 id 正序返回作者排在 after 后面的文章 id，翻页的时候不会因为文章更新而漏掉
+	ListIds(ctx context.Context, uid, after int64, limit int) ([]int64, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
+	// ListRevisions 历史版本列表，不包含内容
+	ListRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, uid, id, revision int64) (domain.ArticleRevision, error)
+	// DiffRevisions 按行比较两个版本的内容，内容太多的时候返回 linediff.ErrTooLarge
+	DiffRevisions(ctx context.Context, uid, id, from, to int64) ([]linediff.Line, error)
+	// RestoreRevision 把某个版本恢复成草稿，这本身也会产生一个新的版本
+	RestoreRevision(ctx context.Context, uid, id, revision int64) error
+	// LiveRevision 线上正在展示的版本号，从来没有发表过的时候返回 0
+	LiveRevision(ctx context.Context, uid, id int64) (int64, error)
+	// Delete 移到回收站，读者和作者的列表里面都看不到了
+	Delete(ctx context.Context, uid, id int64) error
+	// Restore 从回收站恢复，原来是发表状态的话读者又可以看到了
+	Restore(ctx context.Context, uid, id int64) error
+	// ListDeleted 回收站里面还可以恢复的文章
+	ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+	// PurgeExpired 彻底删除在回收站里面超过保留时间的文章，返回这一批找到的文章数量，给定时任务用
+	PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error)
 
 	// 剩下的这个是给读者用的服务，暂时放到这里
 
 	// GetPublishedById 查找已经发表的
 	// 正常来说在微服务架构下，读者服务和创作者服务会是两个独立的服务
 	// 单体应用下可以混在一起，毕竟现在也没几个方法
-	GetPublishedById(ctx context.Context, id, uid int64) (domain.Article, error)
+	// 返回的文章会带上渲染之后的内容
+	// fingerprint 是没有登录的读者的指纹，用来给阅读数去重
+	GetPublishedById(ctx context.Context, id, uid int64, fingerprint string) (domain.Article, error)
+	// ListPub 读者侧的文章列表，按照更新时间倒序，基于游标分页
+	ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ListPubByIds 批量查询已发表的文章，用于收藏夹之类的列表页，不会产生阅读事件
+	ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPubTitlesByIds 和 ListPubByIds 一样，但是只有 Id 和 Title
+	ListPubTitlesByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	// ListPubByTag 和 ListPub 一样，只查询带有某个标签的文章
+	ListPubByTag(ctx context.Context, tag string, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// ListPubByCategory 和 ListPub 一样，只查询某个分类的文章
+	ListPubByCategory(ctx context.Context, category string, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	// PopularTags 已发表的文章里面使用最多的标签
+	PopularTags(ctx context.Context, limit int) ([]domain.Tag, error)
 }
 
 type articleService struct {
@@ -33,16 +117,23 @@ type articleService struct {
 	// 1 和 2 是互斥的，不会同时存在
 	repo   article.ArticleRepository
 	logger logger.LoggerV1
+	// rankingRepo 删除文章之后要从热榜里面去掉
+	rankingRepo repository.RankingRepository
 
 	// 搞个异步的
 	producer events.Producer
+	// searchProducer 发表和撤回之后通知搜索更新索引
+	searchProducer search.Producer
 }
 
-func NewArticleService(repo article.ArticleRepository, l logger.LoggerV1, producer events.Producer) ArticleService {
+func NewArticleService(repo article.ArticleRepository, rankingRepo repository.RankingRepository,
+	l logger.LoggerV1, producer events.Producer, searchProducer search.Producer) ArticleService {
 	return &articleService{
-		repo:     repo,
-		logger:   l,
-		producer: producer,
+		repo:           repo,
+		rankingRepo:    rankingRepo,
+		logger:         l,
+		producer:       producer,
+		searchProducer: searchProducer,
 	}
 }
 
@@ -56,10 +147,18 @@ func NewArticleServiceV1(authorRepo article.ArticleAuthorRepository, readerRepo
 }
 
 func (svc *articleService) Withdraw(ctx context.Context, uid, id int64) error {
-	return svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusPrivate)
+	err := svc.repo.SyncStatus(ctx, uid, id, domain.ArticleStatusPrivate)
+	if err != nil {
+		return err
+	}
+	svc.syncSearch(domain.Article{Id: id, Status: domain.ArticleStatusPrivate})
+	return nil
 }
 
 func (svc *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	if err := svc.normalize(&art); err != nil {
+		return 0, err
+	}
 	// 设置为未发表
 	art.Status = domain.ArticleStatusUnpublished
 	if art.Id > 0 {
@@ -70,8 +169,214 @@ func (svc *articleService) Save(ctx context.Context, art domain.Article) (int64,
 }
 
 func (svc *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	if err := svc.normalize(&art); err != nil {
+		return 0, err
+	}
 	art.Status = domain.ArticleStatusPublished
-	return svc.repo.Sync(ctx, art)
+	id, err := svc.repo.Sync(ctx, art)
+	if err != nil {
+		return 0, err
+	}
+	art.Id = id
+	svc.syncSearch(art)
+	return id, nil
+}
+
+// syncSearch 搜索的索引晚一点更新也没关系，所以异步发送，失败了只记录日志
+func (svc *articleService) syncSearch(art domain.Article) {
+	evt := search.ArticleEvent{
+		Id:       art.Id,
+		AuthorId: art.Author.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		Abstract: art.Abstract,
+		Tags:     art.Tags,
+		Status:   art.Status.ToUint8(),
+		Utime:    time.Now().UnixMilli(),
+	}
+	go func() {
+		if err := svc.searchProducer.ProduceArticleEvent(evt); err != nil {
+			svc.logger.Error("发送搜索同步事件失败",
+				logger.Int64("aid", evt.Id), logger.Error(err))
+		}
+	}()
+}
+
+func (svc *articleService) Schedule(ctx context.Context, art domain.Article, publishAt time.Time) (int64, error) {
+	if !publishAt.After(time.Now()) {
+		return 0, ErrInvalidPublishTime
+	}
+	if err := svc.normalize(&art); err != nil {
+		return 0, err
+	}
+	art.Status = domain.ArticleStatusScheduled
+	art.PublishAt = publishAt
+	if art.Id > 0 {
+		err := svc.update(ctx, art)
+		return art.Id, err
+	}
+	return svc.create(ctx, art)
+}
+
+// normalize 去掉标签和分类首尾的空白，去掉空的和重复的标签，重复不区分大小写，校验版本号、封面和内容长度，
+// 顺便生成摘要
+// Tags 为 nil 的时候保持 nil，代表不修改标签
+func (svc *articleService) normalize(art *domain.Article) error {
+	if art.Id > 0 && art.Revision <= 0 {
+		return ErrRevisionRequired
+	}
+	if len(art.Content) > maxContentLen {
+		return ErrContentTooLong
+	}
+	art.Abstract = article.Abstract(art.Content)
+	art.Category = strings.TrimSpace(art.Category)
+	if utf8.RuneCountInString(art.Category) > maxCategoryLen {
+		return ErrInvalidTags
+	}
+	art.Cover = strings.TrimSpace(art.Cover)
+	if !validCover(art.Cover) {
+		return ErrInvalidCover
+	}
+	if art.Tags == nil {
+		return nil
+	}
+	tags := make([]string, 0, len(art.Tags))
+	seen := make(map[string]struct{}, len(art.Tags))
+	for _, tag := range art.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return ErrInvalidTags
+		}
+		// 标签不区分大小写，保留第一次出现的写法
+		key := strings.ToLower(tag)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagCnt {
+		return ErrInvalidTags
+	}
+	art.Tags = tags
+	return nil
+}
+
+// validCover 封面会直接作为图片地址返回给前端，不允许 javascript: 之类的协议
+func validCover(cover string) bool {
+	if cover == "" {
+		return true
+	}
+	if len(cover) > maxCoverLen || strings.ContainsAny(cover, " \t\r\n\"'<>\\") {
+		return false
+	}
+	// 以 // 开头的是省略了协议的外部地址
+	if strings.HasPrefix(cover, "/") && !strings.HasPrefix(cover, "//") {
+		return true
+	}
+	u, err := url.Parse(cover)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (svc *articleService) Reschedule(ctx context.Context, uid, id int64, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return ErrInvalidPublishTime
+	}
+	return svc.repo.UpdateSchedule(ctx, uid, id, publishAt)
+}
+
+func (svc *articleService) CancelSchedule(ctx context.Context, uid, id int64) error {
+	return svc.repo.UpdateSchedule(ctx, uid, id, time.Time{})
+}
+
+func (svc *articleService) PublishDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	var (
+		cnt     int
+		lastErr error
+		// 失败的文章还是定时发表的状态，所以要往后翻页，不然每次都查到同一批
+		after domain.Article
+	)
+	for {
+		arts, err := svc.repo.ListDueScheduled(ctx, now, after, limit)
+		if err != nil {
+			return cnt, err
+		}
+		for _, art := range arts {
+			// 发表的是制作库里面当前的内容，不是查出来的这一份
+			pub, err := svc.repo.PublishScheduled(ctx, art.Id, now)
+			switch {
+			case errors.Is(err, ErrNotScheduled):
+				// 查出来之后作者取消或者修改了定时发表
+			case err != nil:
+				// 一篇失败不影响别的文章，下一次定时任务会再次尝试
+				svc.logger.Error("定时发表文章失败",
+					logger.Int64("aid", art.Id), logger.Error(err))
+				lastErr = err
+			default:
+				cnt++
+				svc.syncSearch(pub)
+			}
+		}
+		if len(arts) < limit {
+			return cnt, lastErr
+		}
+		after = arts[len(arts)-1]
+	}
+}
+
+func (svc *articleService) Delete(ctx context.Context, uid, id int64) error {
+	err := svc.repo.SoftDelete(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	if er := svc.rankingRepo.Remove(ctx, id); er != nil {
+		svc.logger.Error("从热榜里面去掉文章失败",
+			logger.Int64("aid", id), logger.Error(er))
+	}
+	svc.syncSearch(domain.Article{Id: id, Status: domain.ArticleStatusPrivate})
+	return nil
+}
+
+func (svc *articleService) Restore(ctx context.Context, uid, id int64) error {
+	err := svc.repo.Restore(ctx, uid, id, time.Now().Add(-domain.RecycleBinRetention))
+	if err != nil {
+		return err
+	}
+	art, err := svc.repo.GetById(ctx, id)
+	if err != nil {
+		// 恢复已经成功了，搜索的索引等下一次发表再更新
+		svc.logger.Error("恢复文章之后查询文章失败",
+			logger.Int64("aid", id), logger.Error(err))
+		return nil
+	}
+	if art.Published() {
+		svc.syncSearch(art)
+	}
+	return nil
+}
+
+func (svc *articleService) ListDeleted(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error) {
+	return svc.repo.ListDeleted(ctx, uid, time.Now().Add(-domain.RecycleBinRetention), offset, limit)
+}
+
+func (svc *articleService) PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	arts, err := svc.repo.ListPurgeable(ctx, now.Add(-domain.RecycleBinRetention), limit)
+	if err != nil {
+		return 0, err
+	}
+	var lastErr error
+	for _, art := range arts {
+		if err = svc.repo.Purge(ctx, art.Id); err != nil {
+			// 一篇失败不影响别的文章，下一次定时任务会再次尝试
+			svc.logger.Error("清理回收站的文章失败",
+				logger.Int64("aid", art.Id), logger.Error(err))
+			lastErr = err
+		}
+	}
+	return len(arts), lastErr
 }
 
 // PublishV1 基于使用两种 repository 的写法
@@ -118,13 +423,18 @@ func (svc *articleService) update(ctx context.Context, art domain.Article) error
 	return svc.repo.Update(ctx, art)
 }
 
-func (svc *articleService) GetPublishedById(ctx context.Context, id, uid int64) (domain.Article, error) {
+func (svc *articleService) GetPublishedById(ctx context.Context, id, uid int64, fingerprint string) (domain.Article, error) {
 	res, err := svc.repo.GetPublishedById(ctx, id)
+	if err == nil {
+		rendered := svc.repo.Render(ctx, res)
+		res.Rendered = &rendered
+	}
 	go func() {
 		if err == nil {
 			er := svc.producer.ProduceReadEvent(events.ReadEvent{
-				Aid: id,
-				Uid: uid,
+				Aid:         id,
+				Uid:         uid,
+				Fingerprint: fingerprint,
 			})
 			if er != nil {
 				svc.logger.Error("发送消息失败",
@@ -141,6 +451,92 @@ func (svc *articleService) List(ctx context.Context, uid int64, offset, limit in
 	return svc.repo.List(ctx, uid, offset, limit)
 }
 
+func (svc *articleService) ListIds(ctx context.Context, uid, after int64, limit int) ([]int64, error) {
+	return svc.repo.ListIds(ctx, uid, after, limit)
+}
+
 func (svc *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	return svc.repo.GetById(ctx, id)
 }
+
+func (svc *articleService) ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	return svc.repo.ListPub(ctx, utime, id, limit)
+}
+
+func (svc *articleService) ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	return svc.repo.ListPubByIds(ctx, ids)
+}
+
+func (svc *articleService) ListPubTitlesByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	return svc.repo.ListPubTitlesByIds(ctx, ids)
+}
+
+func (svc *articleService) ListRevisions(ctx context.Context, uid, id int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	return svc.repo.GetRevisions(ctx, uid, id, offset, limit)
+}
+
+func (svc *articleService) GetRevision(ctx context.Context, uid, id, revision int64) (domain.ArticleRevision, error) {
+	return svc.repo.GetRevision(ctx, uid, id, revision)
+}
+
+func (svc *articleService) DiffRevisions(ctx context.Context, uid, id, from, to int64) ([]linediff.Line, error) {
+	var (
+		eg             errgroup.Group
+		fromRev, toRev domain.ArticleRevision
+	)
+	eg.Go(func() error {
+		var err error
+		fromRev, err = svc.repo.GetRevision(ctx, uid, id, from)
+		return err
+	})
+	eg.Go(func() error {
+		var err error
+		toRev, err = svc.repo.GetRevision(ctx, uid, id, to)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return linediff.Diff(fromRev.Content, toRev.Content)
+}
+
+func (svc *articleService) RestoreRevision(ctx context.Context, uid, id, revision int64) error {
+	rev, err := svc.repo.GetRevision(ctx, uid, id, revision)
+	if err != nil {
+		return err
+	}
+	// 版本里面没有分类和封面，沿用当前草稿的；Tags 为 nil 不会修改标签
+	cur, err := svc.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	// 恢复只是覆盖草稿，要重新发表才会影响线上
+	_, err = svc.Save(ctx, domain.Article{
+		Id:       id,
+		Revision: cur.Revision,
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Category: cur.Category,
+		Cover:    cur.Cover,
+		Author: domain.Author{
+			Id: uid,
+		},
+	})
+	return err
+}
+
+func (svc *articleService) LiveRevision(ctx context.Context, uid, id int64) (int64, error) {
+	return svc.repo.GetLiveRevision(ctx, uid, id)
+}
+
+func (svc *articleService) ListPubByTag(ctx context.Context, tag string, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	return svc.repo.ListPubByTag(ctx, strings.TrimSpace(tag), utime, id, limit)
+}
+
+func (svc *articleService) ListPubByCategory(ctx context.Context, category string, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	return svc.repo.ListPubByCategory(ctx, strings.TrimSpace(category), utime, id, limit)
+}
+
+func (svc *articleService) PopularTags(ctx context.Context, limit int) ([]domain.Tag, error) {
+	return svc.repo.PopularTags(ctx, limit)
+}
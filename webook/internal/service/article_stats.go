@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/repository/article"
+)
+
+// MaxStatsDays 最多查询最近多少天的阅读数据
+const MaxStatsDays = 90
+
+type ArticleStatsService interface {
+	// Views 作者的文章最近 days 天每一天的阅读数据，包括今天
+	// 按照日期升序，没有阅读的日子也有一条全是 0 的数据，方便前端画图
+	Views(ctx context.Context, uid, aid int64, days int) ([]domain.ArticleDailyViews, error)
+	// Flush 把缓存里面 day 这一天的统计写到数据库，给定时任务用
+	Flush(ctx context.Context, day time.Time) (int, error)
+}
+
+type articleStatsService struct {
+	repo    repository.ArticleStatsRepository
+	artRepo article.ArticleRepository
+}
+
+func NewArticleStatsService(repo repository.ArticleStatsRepository, artRepo article.ArticleRepository) ArticleStatsService {
+	return &articleStatsService{
+		repo:    repo,
+		artRepo: artRepo,
+	}
+}
+
+func (svc *articleStatsService) Views(ctx context.Context, uid, aid int64, days int) ([]domain.ArticleDailyViews, error) {
+	art, err := svc.artRepo.GetById(ctx, aid)
+	if err != nil {
+		return nil, err
+	}
+	if art.Author.Id != uid {
+		return nil, ErrPossibleIncorrectAuthor
+	}
+	days = min(max(days, 1), MaxStatsDays)
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, 1-days)
+	views, err := svc.repo.DailyViews(ctx, aid, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]domain.ArticleDailyViews, len(views))
+	for _, v := range views {
+		byDay[v.Day.Format(time.DateOnly)] = v
+	}
+	res := make([]domain.ArticleDailyViews, 0, days)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		v, ok := byDay[day.Format(time.DateOnly)]
+		if !ok {
+			v = domain.ArticleDailyViews{Day: day}
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (svc *articleStatsService) Flush(ctx context.Context, day time.Time) (int, error) {
+	return svc.repo.Flush(ctx, day)
+}
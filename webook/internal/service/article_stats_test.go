@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	artrepomocks "webook/internal/repository/article/mocks"
+	repomocks "webook/internal/repository/mocks"
+)
+
+func Test_articleStatsService_Views(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := today.AddDate(0, 0, -2)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artRepo := artrepomocks.NewMockArticleRepository(ctrl)
+	repo := repomocks.NewMockArticleStatsRepository(ctrl)
+	artRepo.EXPECT().GetById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}}, nil).Times(2)
+	repo.EXPECT().DailyViews(gomock.Any(), int64(1), from, today).
+		Return([]domain.ArticleDailyViews{{Day: from, ReadCnt: 5, UV: 3}}, nil)
+	svc := NewArticleStatsService(repo, artRepo)
+
+	// 没有阅读的日子补 0
+	res, err := svc.Views(context.Background(), 123, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ArticleDailyViews{
+		{Day: from, ReadCnt: 5, UV: 3},
+		{Day: from.AddDate(0, 0, 1)},
+		{Day: today},
+	}, res)
+
+	// 只能看自己的文章
+	_, err = svc.Views(context.Background(), 234, 1, 3)
+	assert.Equal(t, ErrPossibleIncorrectAuthor, err)
+}
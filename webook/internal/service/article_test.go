@@ -5,10 +5,17 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 	"webook/internal/domain"
+	"webook/internal/events/search"
+	evtmocks "webook/internal/events/search/mocks"
 	"webook/internal/repository/article"
 	artrepomocks "webook/internal/repository/article/mocks"
+	repomocks "webook/internal/repository/mocks"
+	"webook/pkg/linediff"
 	"webook/pkg/logger"
 )
 
@@ -42,7 +49,7 @@ func Test_articleService_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(1), nil)
+				}).Return(nil)
 				return author, reader
 			},
 			art: domain.Article{
@@ -77,7 +84,7 @@ func Test_articleService_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(2), nil)
+				}).Return(nil)
 				return author, reader
 			},
 			art: domain.Article{
@@ -141,7 +148,7 @@ func Test_articleService_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(0), errors.New("mock db error"))
+				}).Return(errors.New("mock db error"))
 				reader.EXPECT().Save(gomock.Any(), domain.Article{
 					// 确保使用了制作库 ID
 					Id:      2,
@@ -150,7 +157,7 @@ func Test_articleService_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(2), nil)
+				}).Return(nil)
 				return author, reader
 			},
 			art: domain.Article{
@@ -186,7 +193,7 @@ func Test_articleService_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Times(3).Return(int64(0), errors.New("mock db error"))
+				}).Times(3).Return(errors.New("mock db error"))
 				return author, reader
 			},
 			art: domain.Article{
@@ -207,10 +214,406 @@ func Test_articleService_Publish(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			author, reader := tc.mock(ctrl)
-			svc := NewArticleServiceV1(author, reader, &logger.NoOpLogger{})
+			svc := NewArticleServiceV1(author, reader, &logger.NoOpLogger{}, nil)
 			id, err := svc.PublishV1(context.Background(), tc.art)
 			assert.Equal(t, tc.wantErr, err)
 			assert.Equal(t, tc.wantId, id)
 		})
 	}
 }
+
+func Test_articleService_RestoreRevision(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+
+		uid      int64
+		id       int64
+		revision int64
+
+		wantErr error
+	}{
+		{
+			name: "恢复成功",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(2), int64(3)).
+					Return(domain.ArticleRevision{
+						ArticleId: 2,
+						Revision:  3,
+						AuthorId:  123,
+						Title:     "旧的标题",
+						Content:   "旧的内容",
+						Status:    domain.ArticleStatusPublished,
+					}, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(2)).
+					Return(domain.Article{
+						Id:       2,
+						Revision: 5,
+						Title:    "新的标题",
+						Category: "后端",
+						Tags:     []string{"Go"},
+					}, nil)
+				// 恢复之后是草稿，而不是版本当时的状态
+				// 分类沿用当前的，标签不修改
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:       2,
+					Revision: 5,
+					Title:    "旧的标题",
+					Content:  "旧的内容",
+					Abstract: "旧的内容",
+					Category: "后端",
+					Author: domain.Author{
+						Id: 123,
+					},
+					Status: domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				return repo
+			},
+			uid:      123,
+			id:       2,
+			revision: 3,
+		},
+		{
+			name: "版本不存在",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(2), int64(3)).
+					Return(domain.ArticleRevision{}, ErrRevisionNotFound)
+				return repo
+			},
+			uid:      123,
+			id:       2,
+			revision: 3,
+			wantErr:  ErrRevisionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), nil, &logger.NoOpLogger{}, nil, nil)
+			err := svc.RestoreRevision(context.Background(), tc.uid, tc.id, tc.revision)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_DiffRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockArticleRepository(ctrl)
+	repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(2), int64(1)).
+		Return(domain.ArticleRevision{Revision: 1, Content: "a\nb"}, nil)
+	repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(2), int64(2)).
+		Return(domain.ArticleRevision{Revision: 2, Content: "a\nc"}, nil)
+	svc := NewArticleService(repo, nil, &logger.NoOpLogger{}, nil, nil)
+	lines, err := svc.DiffRevisions(context.Background(), 123, 2, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []linediff.Line{
+		{Op: linediff.OpEqual, OldNo: 1, NewNo: 1, Text: "a"},
+		{Op: linediff.OpDelete, OldNo: 2, Text: "b"},
+		{Op: linediff.OpInsert, NewNo: 2, Text: "c"},
+	}, lines)
+}
+
+func Test_articleService_PublishDue(t *testing.T) {
+	now := time.Now()
+	var wg sync.WaitGroup
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (article.ArticleRepository, search.Producer)
+
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "全部发表成功",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, search.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				due := []domain.Article{
+					{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled, PublishAt: now},
+					{Id: 2, Author: domain.Author{Id: 234}, Status: domain.ArticleStatusScheduled, PublishAt: now},
+				}
+				repo.EXPECT().ListDueScheduled(gomock.Any(), now, domain.Article{}, 2).Return(due, nil)
+				repo.EXPECT().PublishScheduled(gomock.Any(), int64(1), now).
+					Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished}, nil)
+				repo.EXPECT().PublishScheduled(gomock.Any(), int64(2), now).
+					Return(domain.Article{Id: 2, Author: domain.Author{Id: 234}, Status: domain.ArticleStatusPublished}, nil)
+				// 一批是满的，还要再查一次
+				repo.EXPECT().ListDueScheduled(gomock.Any(), now, due[1], 2).Return(nil, nil)
+				producer := evtmocks.NewMockProducer(ctrl)
+				expectSearchEvent(producer, &wg, searchEvent(1, 123), nil)
+				// 搜索同步失败不影响发表
+				expectSearchEvent(producer, &wg, searchEvent(2, 234), errors.New("mock kafka error"))
+				return repo, producer
+			},
+			wantCnt: 2,
+		},
+		{
+			name: "部分失败，翻到下一批",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, search.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				due := []domain.Article{
+					{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled, PublishAt: now},
+					{Id: 2, Author: domain.Author{Id: 234}, Status: domain.ArticleStatusScheduled, PublishAt: now},
+				}
+				repo.EXPECT().ListDueScheduled(gomock.Any(), now, domain.Article{}, 2).Return(due, nil)
+				// 第一篇失败了，第二篇照样要处理
+				repo.EXPECT().PublishScheduled(gomock.Any(), int64(1), now).
+					Return(domain.Article{}, errors.New("mock db error"))
+				// 查出来之后作者取消了定时发表
+				repo.EXPECT().PublishScheduled(gomock.Any(), int64(2), now).
+					Return(domain.Article{}, ErrNotScheduled)
+				// 失败的那一篇还在，要从这一批的最后一篇往后查
+				repo.EXPECT().ListDueScheduled(gomock.Any(), now, due[1], 2).
+					Return([]domain.Article{
+						{Id: 3, Author: domain.Author{Id: 345}, Status: domain.ArticleStatusScheduled, PublishAt: now},
+					}, nil)
+				repo.EXPECT().PublishScheduled(gomock.Any(), int64(3), now).
+					Return(domain.Article{Id: 3, Author: domain.Author{Id: 345}, Status: domain.ArticleStatusPublished}, nil)
+				// 只有发表成功的才会同步到搜索
+				producer := evtmocks.NewMockProducer(ctrl)
+				expectSearchEvent(producer, &wg, searchEvent(3, 345), nil)
+				return repo, producer
+			},
+			wantCnt: 1,
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) (article.ArticleRepository, search.Producer) {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().ListDueScheduled(gomock.Any(), now, domain.Article{}, 2).
+					Return(nil, errors.New("mock db error"))
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, producer := tc.mock(ctrl)
+			svc := NewArticleService(repo, nil, &logger.NoOpLogger{}, nil, producer)
+			cnt, err := svc.PublishDue(context.Background(), now, 2)
+			wg.Wait()
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
+
+// expectSearchEvent 搜索同步是异步发送的，wg.Wait 之后才能确定 mock 已经被调用
+func expectSearchEvent(producer *evtmocks.MockProducer, wg *sync.WaitGroup, m gomock.Matcher, err error) {
+	wg.Add(1)
+	producer.EXPECT().ProduceArticleEvent(m).DoAndReturn(func(evt search.ArticleEvent) error {
+		defer wg.Done()
+		return err
+	})
+}
+
+// searchEvent 只比较 ID、作者和状态，Utime 是发送的时候才生成的
+func searchEvent(id, authorId int64) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		evt, ok := x.(search.ArticleEvent)
+		return ok && evt.Id == id && evt.AuthorId == authorId &&
+			evt.Status == domain.ArticleStatusPublished.ToUint8()
+	})
+}
+
+func Test_articleService_Withdraw(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockArticleRepository(ctrl)
+	producer := evtmocks.NewMockProducer(ctrl)
+	svc := NewArticleService(repo, nil, &logger.NoOpLogger{}, nil, producer)
+
+	var wg sync.WaitGroup
+	repo.EXPECT().SyncStatus(gomock.Any(), int64(123), int64(1), domain.ArticleStatusPrivate).Return(nil)
+	expectSearchEvent(producer, &wg, gomock.Cond(func(x any) bool {
+		evt := x.(search.ArticleEvent)
+		return evt.Id == 1 && evt.Status == domain.ArticleStatusPrivate.ToUint8()
+	}), nil)
+	assert.NoError(t, svc.Withdraw(context.Background(), 123, 1))
+	wg.Wait()
+
+	// 撤回失败不需要同步
+	repo.EXPECT().SyncStatus(gomock.Any(), int64(123), int64(2), domain.ArticleStatusPrivate).
+		Return(errors.New("mock db error"))
+	assert.Error(t, svc.Withdraw(context.Background(), 123, 2))
+}
+
+func Test_articleService_Schedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockArticleRepository(ctrl)
+	svc := NewArticleService(repo, nil, &logger.NoOpLogger{}, nil, nil)
+	// 过去的时间直接拒绝，不会访问 repository
+	_, err := svc.Schedule(context.Background(), domain.Article{Id: 1}, time.Now().Add(-time.Minute))
+	assert.Equal(t, ErrInvalidPublishTime, err)
+
+	publishAt := time.Now().Add(time.Hour)
+	repo.EXPECT().Update(gomock.Any(), domain.Article{
+		Id:        1,
+		Revision:  1,
+		Title:     "我的标题",
+		Author:    domain.Author{Id: 123},
+		Status:    domain.ArticleStatusScheduled,
+		PublishAt: publishAt,
+	}).Return(nil)
+	id, err := svc.Schedule(context.Background(), domain.Article{
+		Id:       1,
+		Revision: 1,
+		Title:    "我的标题",
+		Author:   domain.Author{Id: 123},
+	}, publishAt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
+
+func Test_articleService_SaveTags(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleRepository
+
+		art domain.Article
+
+		wantErr error
+	}{
+		{
+			name: "去掉空白和重复的标签",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:       1,
+					Revision: 1,
+					Category: "后端",
+					Tags:     []string{"Go", "MySQL"},
+					Status:   domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				return repo
+			},
+			art: domain.Article{
+				Id:       1,
+				Revision: 1,
+				Category: " 后端 ",
+				// 只是大小写不同也算重复，保留第一次出现的写法
+				Tags: []string{" Go", "", "MySQL", "Go ", "go", "mysql"},
+			},
+		},
+		{
+			name: "不传标签",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				repo := artrepomocks.NewMockArticleRepository(ctrl)
+				// nil 要原样传下去，代表不修改标签
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:       1,
+					Revision: 1,
+					Status:   domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				return repo
+			},
+			art: domain.Article{Id: 1, Revision: 1},
+		},
+		{
+			name: "标签太多",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Id:       1,
+				Revision: 1,
+				Tags:     []string{"a", "b", "c", "d", "e", "f"},
+			},
+			wantErr: ErrInvalidTags,
+		},
+		{
+			name: "标签太长",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Id:       1,
+				Revision: 1,
+				Tags:     []string{"一二三四五六七八九十一二三四五六七八九十一"},
+			},
+			wantErr: ErrInvalidTags,
+		},
+		{
+			name: "内容太长",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art: domain.Article{
+				Id:       1,
+				Revision: 1,
+				Content:  strings.Repeat("a", maxContentLen+1),
+			},
+			wantErr: ErrContentTooLong,
+		},
+		{
+			name: "修改的时候没有版本号",
+			mock: func(ctrl *gomock.Controller) article.ArticleRepository {
+				return artrepomocks.NewMockArticleRepository(ctrl)
+			},
+			art:     domain.Article{Id: 1},
+			wantErr: ErrRevisionRequired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), nil, &logger.NoOpLogger{}, nil, nil)
+			_, err := svc.Save(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleService_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockArticleRepository(ctrl)
+	rankingRepo := repomocks.NewMockRankingRepository(ctrl)
+	producer := evtmocks.NewMockProducer(ctrl)
+	svc := NewArticleService(repo, rankingRepo, &logger.NoOpLogger{}, nil, producer)
+
+	// 删除之后热榜和搜索里面也要下线，热榜失败了不影响删除
+	var wg sync.WaitGroup
+	repo.EXPECT().SoftDelete(gomock.Any(), int64(123), int64(1)).Return(nil)
+	rankingRepo.EXPECT().Remove(gomock.Any(), int64(1)).Return(errors.New("mock redis error"))
+	expectSearchEvent(producer, &wg, gomock.Cond(func(x any) bool {
+		evt := x.(search.ArticleEvent)
+		return evt.Id == 1 && evt.Status == domain.ArticleStatusPrivate.ToUint8()
+	}), nil)
+	assert.NoError(t, svc.Delete(context.Background(), 123, 1))
+	wg.Wait()
+
+	// 不是自己的文章
+	repo.EXPECT().SoftDelete(gomock.Any(), int64(123), int64(2)).Return(article.ErrPossibleIncorrectAuthor)
+	assert.ErrorIs(t, svc.Delete(context.Background(), 123, 2), ErrPossibleIncorrectAuthor)
+}
+
+func Test_articleService_PurgeExpired(t *testing.T) {
+	now := time.Now()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockArticleRepository(ctrl)
+	svc := NewArticleService(repo, nil, &logger.NoOpLogger{}, nil, nil)
+
+	dbErr := errors.New("mock db error")
+	repo.EXPECT().ListPurgeable(gomock.Any(), now.Add(-domain.RecycleBinRetention), 10).
+		Return([]domain.Article{{Id: 1}, {Id: 2}}, nil)
+	// 第一篇失败了，第二篇照样要清理
+	repo.EXPECT().Purge(gomock.Any(), int64(1)).Return(dbErr)
+	repo.EXPECT().Purge(gomock.Any(), int64(2)).Return(nil)
+	cnt, err := svc.PurgeExpired(context.Background(), now, 10)
+	assert.ErrorIs(t, err, dbErr)
+	assert.Equal(t, 2, cnt)
+}
@@ -9,8 +9,6 @@ import (
 	"webook/internal/service/sms"
 )
 
-const codeTplId = "1877556"
-
 type CodeService interface {
 	Send(c context.Context, biz string, phone string) error
 	Verify(c context.Context, biz string, phone string, inputCode string) (bool, error)
@@ -35,7 +33,8 @@ func (cs *SMSCodeService) Send(c context.Context, biz string, phone string) erro
 	if err != nil {
 		return fmt.Errorf("error store code. %w\n", err)
 	}
-	err = cs.sms.Send(c, codeTplId, []string{code}, phone)
+	// 具体用哪个模板由 sms 的模板注册中心决定
+	err = cs.sms.Send(c, biz, []string{code}, phone)
 	if err != nil {
 		// redis有验证码，但没发送成功。不能删掉此验证码，因为err有可能是超时问题。
 		// 可以重试，在初始化时传入重试的smsSvc
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/errgroup"
+	"webook/internal/domain"
+	"webook/internal/events/notification"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+// previewReplyCnt 根评论下面预先加载多少条回复，更多的回复要单独分页查询
+const previewReplyCnt = 3
+
+// notificationContentLength 通知里面只带上评论的前面一部分
+const notificationContentLength = 100
+
+var (
+	ErrCommentNotFound = repository.ErrCommentNotFound
+	// ErrCommentBizNotFound 被评论的资源不存在，或者不支持评论
+	ErrCommentBizNotFound = errors.New("被评论的资源不存在")
+	// ErrCommentNoPermission 只有评论者自己和资源的作者可以删除评论
+	ErrCommentNoPermission = errors.New("没有权限删除评论")
+)
+
+type CommentService interface {
+	// Comment 发表评论，ParentId 大于 0 代表回复某一条评论
+	Comment(ctx context.Context, c domain.Comment) (int64, error)
+	// ListRoots 按照时间倒序查询根评论，minId 为上一页最后一条评论的 id
+	ListRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]domain.Comment, error)
+	// ListHotRoots 按照热度查询根评论，热度会变化，所以只能用 offset 分页
+	ListHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]domain.Comment, error)
+	// ListReplies 按照时间正序查询根评论下面的回复，maxId 为上一页最后一条回复的 id
+	ListReplies(ctx context.Context, rid, maxId int64, limit int) ([]domain.Comment, error)
+	// Delete 评论者可以删除自己的评论，资源的作者可以删除资源下面的任何评论
+	Delete(ctx context.Context, id, uid int64) error
+}
+
+type commentService struct {
+	repo     repository.CommentRepository
+	artSvc   ArticleService
+	producer notification.Producer
+	l        logger.LoggerV1
+}
+
+func NewCommentService(repo repository.CommentRepository, artSvc ArticleService,
+	producer notification.Producer, l logger.LoggerV1) CommentService {
+	return &commentService{
+		repo:     repo,
+		artSvc:   artSvc,
+		producer: producer,
+		l:        l,
+	}
+}
+
+func (s *commentService) Comment(ctx context.Context, c domain.Comment) (int64, error) {
+	evt := notification.Event{
+		Type:    domain.NotificationTypeComment,
+		Actor:   c.Uid,
+		Biz:     c.Biz,
+		BizId:   c.BizId,
+		Content: c.Content,
+	}
+	if cs := []rune(c.Content); len(cs) > notificationContentLength {
+		evt.Content = string(cs[:notificationContentLength])
+	}
+	if c.ParentId > 0 {
+		parent, err := s.repo.FindById(ctx, c.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		// 回复的评论要和资源对得上
+		if parent.Biz != c.Biz || parent.BizId != c.BizId {
+			return 0, ErrCommentNotFound
+		}
+		c.RootId = parent.RootId
+		if parent.IsRoot() {
+			c.RootId = parent.Id
+		}
+		// 回复通知被回复的人，而不是资源的作者
+		evt.Type = domain.NotificationTypeReply
+		evt.Receiver = parent.Uid
+	} else {
+		c.RootId = 0
+		owner, err := s.bizOwner(ctx, c.Biz, c.BizId)
+		if err != nil {
+			return 0, err
+		}
+		evt.Receiver = owner
+	}
+	id, err := s.repo.CreateComment(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+	evt.SourceId = id
+	produceNotification(s.producer, s.l, evt)
+	return id, nil
+}
+
+func (s *commentService) ListRoots(ctx context.Context, biz string, bizId, minId int64, limit int) ([]domain.Comment, error) {
+	roots, err := s.repo.FindRoots(ctx, biz, bizId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return roots, s.loadReplies(ctx, roots)
+}
+
+func (s *commentService) ListHotRoots(ctx context.Context, biz string, bizId int64, offset, limit int) ([]domain.Comment, error) {
+	roots, err := s.repo.FindHotRoots(ctx, biz, bizId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return roots, s.loadReplies(ctx, roots)
+}
+
+// loadReplies 并发加载每一条根评论的前几条回复
+func (s *commentService) loadReplies(ctx context.Context, roots []domain.Comment) error {
+	var eg errgroup.Group
+	for i := range roots {
+		if roots[i].ReplyCnt == 0 {
+			continue
+		}
+		eg.Go(func() error {
+			replies, err := s.repo.FindReplies(ctx, roots[i].Id, 0, previewReplyCnt)
+			roots[i].Replies = replies
+			return err
+		})
+	}
+	return eg.Wait()
+}
+
+func (s *commentService) ListReplies(ctx context.Context, rid, maxId int64, limit int) ([]domain.Comment, error) {
+	return s.repo.FindReplies(ctx, rid, maxId, limit)
+}
+
+func (s *commentService) Delete(ctx context.Context, id, uid int64) error {
+	c, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Uid != uid {
+		owner, err := s.bizOwner(ctx, c.Biz, c.BizId)
+		// 资源已经不在了，也就没有作者可以管理评论了
+		if errors.Is(err, ErrCommentBizNotFound) {
+			return ErrCommentNoPermission
+		}
+		if err != nil {
+			return err
+		}
+		if owner != uid {
+			return ErrCommentNoPermission
+		}
+	}
+	return s.repo.DeleteComment(ctx, c)
+}
+
+// bizOwner 查询被评论的资源属于谁，目前只有文章可以评论
+func (s *commentService) bizOwner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	if biz != "article" {
+		return 0, ErrCommentBizNotFound
+	}
+	arts, err := s.artSvc.ListPubByIds(ctx, []int64{bizId})
+	if err != nil {
+		return 0, err
+	}
+	if len(arts) == 0 {
+		return 0, ErrCommentBizNotFound
+	}
+	return arts[0].Author.Id, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/events/notification"
+	evtmocks "webook/internal/events/notification/mocks"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+	svcmocks "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func TestCommentService_Comment(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService)
+		c    domain.Comment
+
+		wantId  int64
+		wantErr error
+		// wantEvt 为 nil 代表不会发送通知
+		wantEvt *notification.Event
+	}{
+		{
+			name: "发表根评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 2}}}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Uid: 3, Biz: "article", BizId: 1, Content: "hello",
+				}).Return(int64(10), nil)
+				return repo, artSvc
+			},
+			c:      domain.Comment{Uid: 3, Biz: "article", BizId: 1, Content: "hello"},
+			wantId: 10,
+			wantEvt: &notification.Event{
+				Type: domain.NotificationTypeComment, Actor: 3, Biz: "article", BizId: 1,
+				Receiver: 2, SourceId: 10, Content: "hello",
+			},
+		},
+		{
+			name: "文章不存在",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).
+					Return([]domain.Article{}, nil)
+				return repomocks.NewMockCommentRepository(ctrl), artSvc
+			},
+			c:       domain.Comment{Uid: 3, Biz: "article", BizId: 1, Content: "hello"},
+			wantErr: ErrCommentBizNotFound,
+		},
+		{
+			name: "不支持评论的资源",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				return repomocks.NewMockCommentRepository(ctrl), svcmocks.NewMockArticleService(ctrl)
+			},
+			c:       domain.Comment{Uid: 3, Biz: "unknown", BizId: 1, Content: "hello"},
+			wantErr: ErrCommentBizNotFound,
+		},
+		{
+			name: "回复回复，挂在同一个根评论下面",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Comment{
+					Id: 11, Uid: 4, Biz: "article", BizId: 1, RootId: 10, ParentId: 10,
+				}, nil)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Uid: 3, Biz: "article", BizId: 1, RootId: 10, ParentId: 11, Content: "hello",
+				}).Return(int64(12), nil)
+				return repo, svcmocks.NewMockArticleService(ctrl)
+			},
+			c:      domain.Comment{Uid: 3, Biz: "article", BizId: 1, ParentId: 11, Content: "hello"},
+			wantId: 12,
+			wantEvt: &notification.Event{
+				Type: domain.NotificationTypeReply, Actor: 3, Biz: "article", BizId: 1,
+				Receiver: 4, SourceId: 12, Content: "hello",
+			},
+		},
+		{
+			name: "回复的评论不属于这篇文章",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Comment{
+					Id: 11, Biz: "article", BizId: 2,
+				}, nil)
+				return repo, svcmocks.NewMockArticleService(ctrl)
+			},
+			c:       domain.Comment{Uid: 3, Biz: "article", BizId: 1, ParentId: 11, Content: "hello"},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artSvc := tc.mock(ctrl)
+			evts := make(chan notification.Event, 1)
+			producer := evtmocks.NewMockProducer(ctrl)
+			producer.EXPECT().ProduceNotificationEvent(gomock.Any()).
+				DoAndReturn(func(evt notification.Event) error {
+					evts <- evt
+					return nil
+				}).AnyTimes()
+			svc := NewCommentService(repo, artSvc, producer, &logger.NoOpLogger{})
+			id, err := svc.Comment(context.Background(), tc.c)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			if tc.wantEvt == nil {
+				return
+			}
+			// 通知是异步发送的
+			select {
+			case evt := <-evts:
+				assert.Equal(t, *tc.wantEvt, evt)
+			case <-time.After(time.Second):
+				t.Fatal("没有发送通知")
+			}
+		})
+	}
+}
+
+func TestCommentService_Delete(t *testing.T) {
+	cmt := domain.Comment{Id: 10, Uid: 3, Biz: "article", BizId: 1}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService)
+		uid  int64
+
+		wantErr error
+	}{
+		{
+			name: "删除自己的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cmt).Return(nil)
+				return repo, svcmocks.NewMockArticleService(ctrl)
+			},
+			uid: 3,
+		},
+		{
+			name: "作者删除文章下面的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cmt).Return(nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 2}}}, nil)
+				return repo, artSvc
+			},
+			uid: 2,
+		},
+		{
+			name: "删除别人的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).
+					Return([]domain.Article{{Id: 1, Author: domain.Author{Id: 2}}}, nil)
+				return repo, artSvc
+			},
+			uid:     4,
+			wantErr: ErrCommentNoPermission,
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, ArticleService) {
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPubByIds(gomock.Any(), []int64{1}).
+					Return(nil, errors.New("mock db error"))
+				return repo, artSvc
+			},
+			uid:     4,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artSvc := tc.mock(ctrl)
+			svc := NewCommentService(repo, artSvc, evtmocks.NewMockProducer(ctrl), &logger.NoOpLogger{})
+			err := svc.Delete(context.Background(), 10, tc.uid)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
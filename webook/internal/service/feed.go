@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/repository/article"
+)
+
+// ErrFeedAuthorNotFound 订阅的作者不存在
+var ErrFeedAuthorNotFound = errors.New("作者不存在")
+
+const (
+	// FeedItemLimit 订阅源里面最多的文章数量
+	FeedItemLimit = 20
+	// SitemapPageSize 每一页站点地图的文章数量，协议规定不能超过 50000
+	SitemapPageSize = 10000
+)
+
+// FeedService 订阅源和站点地图，都只包含已经发表的文章
+type FeedService interface {
+	// AuthorFeed 作者最新发表的 FeedItemLimit 篇文章
+	AuthorFeed(ctx context.Context, uid int64) (domain.Feed, error)
+	// SitemapIndex 站点地图的页数，以及线上内容最后一次变化的时间
+	SitemapIndex(ctx context.Context) (int, time.Time, error)
+	// SitemapPage 第 page 页的站点地图，page 从 1 开始，超出范围的时候返回空切片
+	// 返回的时间和 SitemapIndex 一样，撤回或者删除文章之后后面的页都会移动，所以不能只看这一页的文章
+	SitemapPage(ctx context.Context, page int) ([]domain.SitemapEntry, time.Time, error)
+}
+
+type feedService struct {
+	repo     article.ArticleRepository
+	userRepo repository.UserRepository
+}
+
+func NewFeedService(repo article.ArticleRepository, userRepo repository.UserRepository) FeedService {
+	return &feedService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (svc *feedService) AuthorFeed(ctx context.Context, uid int64) (domain.Feed, error) {
+	user, err := svc.userRepo.FindById(ctx, uid)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		return domain.Feed{}, ErrFeedAuthorNotFound
+	case err != nil:
+		return domain.Feed{}, err
+	}
+	arts, err := svc.repo.ListPubByAuthor(ctx, uid, time.Time{}, 0, FeedItemLimit)
+	if err != nil {
+		return domain.Feed{}, err
+	}
+	feed := domain.Feed{
+		Author: domain.Author{Id: user.Id, Name: user.NickName},
+		Items:  make([]domain.FeedItem, 0, len(arts)),
+	}
+	for _, art := range arts {
+		// 摘要和详情页用的是同一份渲染结果，有缓存
+		rendered := svc.repo.Render(ctx, art)
+		feed.Items = append(feed.Items, domain.FeedItem{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: rendered.Abstract,
+			Category: art.Category,
+			Ctime:    art.Ctime,
+			Utime:    art.Utime,
+		})
+	}
+	feed.Updated, err = svc.lastModified(ctx, uid, arts)
+	if err != nil {
+		return domain.Feed{}, err
+	}
+	return feed, nil
+}
+
+// lastModified 线上内容最后一次变化的时间
+// 不能只看剩下的文章里面最新的，撤回或者删除之后会变回更早的时间，客户端就会一直拿到 304
+// arts 按照 utime 倒序，在开始记录变化之前发表的文章只能用它们自己的 utime
+func (svc *feedService) lastModified(ctx context.Context, uid int64, arts []domain.Article) (time.Time, error) {
+	changed, err := svc.repo.PubChangedAt(ctx, uid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(arts) > 0 && arts[0].Utime.After(changed) {
+		return arts[0].Utime, nil
+	}
+	return changed, nil
+}
+
+func (svc *feedService) SitemapIndex(ctx context.Context) (int, time.Time, error) {
+	cnt, err := svc.repo.CountPub(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	lastMod, err := svc.sitemapLastModified(ctx)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	// 没有文章的时候也有一页空的站点地图
+	pages := max(int((cnt+SitemapPageSize-1)/SitemapPageSize), 1)
+	return pages, lastMod, nil
+}
+
+func (svc *feedService) SitemapPage(ctx context.Context, page int) ([]domain.SitemapEntry, time.Time, error) {
+	if page < 1 {
+		return []domain.SitemapEntry{}, time.Time{}, nil
+	}
+	arts, err := svc.repo.ScanPub(ctx, (page-1)*SitemapPageSize, SitemapPageSize)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	lastMod, err := svc.sitemapLastModified(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	res := make([]domain.SitemapEntry, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, domain.SitemapEntry{ArticleId: art.Id, Utime: art.Utime})
+	}
+	return res, lastMod, nil
+}
+
+func (svc *feedService) sitemapLastModified(ctx context.Context) (time.Time, error) {
+	latest, err := svc.repo.ListPub(ctx, time.Time{}, 0, 1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return svc.lastModified(ctx, 0, latest)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	artrepomocks "webook/internal/repository/article/mocks"
+	repomocks "webook/internal/repository/mocks"
+)
+
+func Test_feedService_AuthorFeed(t *testing.T) {
+	testCases := []struct {
+		name    string
+		findErr error
+		wantErr error
+	}{
+		{
+			name:    "作者不存在",
+			findErr: fmt.Errorf("id can not be found: %w", repository.ErrUserNotFound),
+			wantErr: ErrFeedAuthorNotFound,
+		},
+		{
+			// 数据库出错不能当成作者不存在，否则订阅源会被客户端当成已经失效
+			name:    "查询作者失败",
+			findErr: errors.New("mock db error"),
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := repomocks.NewMockUserRepository(ctrl)
+			userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{}, tc.findErr)
+			svc := NewFeedService(artrepomocks.NewMockArticleRepository(ctrl), userRepo)
+			_, err := svc.AuthorFeed(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_feedService_LastModified(t *testing.T) {
+	utime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name    string
+		arts    []domain.Article
+		changed time.Time
+		want    time.Time
+	}{
+		{
+			// 撤回或者删除了最新的文章，剩下的文章都更早
+			name:    "撤回之后",
+			arts:    []domain.Article{{Id: 1, Utime: utime}},
+			changed: utime.Add(time.Hour),
+			want:    utime.Add(time.Hour),
+		},
+		{
+			// 开始记录变化之前发表的文章
+			name: "没有变化的记录",
+			arts: []domain.Article{{Id: 1, Utime: utime}},
+			want: utime,
+		},
+		{
+			name:    "文章都撤回了",
+			changed: utime,
+			want:    utime,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := repomocks.NewMockUserRepository(ctrl)
+			userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{Id: 123}, nil)
+			repo := artrepomocks.NewMockArticleRepository(ctrl)
+			repo.EXPECT().ListPubByAuthor(gomock.Any(), int64(123), time.Time{}, int64(0), FeedItemLimit).
+				Return(tc.arts, nil)
+			repo.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 1).Return(tc.arts, nil)
+			repo.EXPECT().Render(gomock.Any(), gomock.Any()).Return(domain.RenderedContent{}).AnyTimes()
+			repo.EXPECT().PubChangedAt(gomock.Any(), int64(123)).Return(tc.changed, nil)
+			repo.EXPECT().PubChangedAt(gomock.Any(), int64(0)).Return(tc.changed, nil)
+			repo.EXPECT().CountPub(gomock.Any()).Return(int64(len(tc.arts)), nil)
+			svc := NewFeedService(repo, userRepo)
+
+			feed, err := svc.AuthorFeed(context.Background(), 123)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, feed.Updated)
+			_, lastMod, err := svc.SitemapIndex(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, lastMod)
+		})
+	}
+}
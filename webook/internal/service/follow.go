@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/internal/domain"
+	"webook/internal/events/notification"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrFolloweeNotFound = errors.New("要关注的用户不存在")
+)
+
+type FollowService interface {
+	Follow(ctx context.Context, follower, followee int64) error
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	// GetFollowers 粉丝列表
+	GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	// GetFollowees 关注列表
+	GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	// GetFollowStatics 查询 uid 的关注数据，viewer 大于 0 的时候会补充 viewer 有没有关注 uid
+	GetFollowStatics(ctx context.Context, uid, viewer int64) (domain.FollowStatics, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepository
+	producer notification.Producer
+	l        logger.LoggerV1
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepository,
+	producer notification.Producer, l logger.LoggerV1) FollowService {
+	return &followService{
+		repo:     repo,
+		userRepo: userRepo,
+		producer: producer,
+		l:        l,
+	}
+}
+
+func (f *followService) Follow(ctx context.Context, follower, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	_, err := f.userRepo.FindById(ctx, followee)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrFolloweeNotFound
+	case err != nil:
+		return err
+	}
+	err = f.repo.AddFollowRelation(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+	// 关注的通知都聚合在被关注的人身上
+	produceNotification(f.producer, f.l, notification.Event{
+		Type:     domain.NotificationTypeFollow,
+		Actor:    follower,
+		Biz:      "user",
+		BizId:    followee,
+		Receiver: followee,
+	})
+	return nil
+}
+
+func (f *followService) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return f.repo.InactiveFollowRelation(ctx, follower, followee)
+}
+
+func (f *followService) GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return f.repo.GetFollowers(ctx, followee, offset, limit)
+}
+
+func (f *followService) GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return f.repo.GetFollowees(ctx, follower, offset, limit)
+}
+
+func (f *followService) GetFollowStatics(ctx context.Context, uid, viewer int64) (domain.FollowStatics, error) {
+	res, err := f.repo.GetFollowStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if viewer <= 0 || viewer == uid {
+		return res, nil
+	}
+	res.Followed, err = f.repo.Followed(ctx, viewer, uid)
+	return res, err
+}
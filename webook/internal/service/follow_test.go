@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/events/notification"
+	evtmocks "webook/internal/events/notification/mocks"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_followService_Follow(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, notification.Producer)
+		followee int64
+		wantErr  error
+	}{
+		{
+			name: "关注成功",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, notification.Producer) {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				repo.EXPECT().AddFollowRelation(gomock.Any(), int64(1), int64(2)).Return(nil)
+				// 通知是异步发送的
+				producer.EXPECT().ProduceNotificationEvent(gomock.Any()).Return(nil).AnyTimes()
+				return repo, userRepo, producer
+			},
+			followee: 2,
+		},
+		{
+			name: "不能关注自己",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, notification.Producer) {
+				return repomocks.NewMockFollowRepository(ctrl), repomocks.NewMockUserRepository(ctrl),
+					evtmocks.NewMockProducer(ctrl)
+			},
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "用户不存在",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, notification.Producer) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(3)).Return(domain.User{}, repository.ErrUserNotFound)
+				return repomocks.NewMockFollowRepository(ctrl), userRepo, evtmocks.NewMockProducer(ctrl)
+			},
+			followee: 3,
+			wantErr:  ErrFolloweeNotFound,
+		},
+		{
+			name: "查询用户失败",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository, notification.Producer) {
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{}, errors.New("mock db error"))
+				return repomocks.NewMockFollowRepository(ctrl), userRepo, evtmocks.NewMockProducer(ctrl)
+			},
+			followee: 2,
+			wantErr:  errors.New("mock db error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo, producer := tt.mock(ctrl)
+			svc := NewFollowService(repo, userRepo, producer, logger.NewNoOpLogger())
+			err := svc.Follow(context.Background(), 1, tt.followee)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+type HistoryService interface {
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.HistoryRecord, error)
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	// Clear 清空用户的阅读历史
+	Clear(ctx context.Context, uid int64) error
+}
+
+type historyService struct {
+	repo repository.HistoryRecordRepository
+}
+
+func NewHistoryService(repo repository.HistoryRecordRepository) HistoryService {
+	return &historyService{
+		repo: repo,
+	}
+}
+
+func (h *historyService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.HistoryRecord, error) {
+	return h.repo.List(ctx, uid, offset, limit)
+}
+
+func (h *historyService) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return h.repo.Delete(ctx, uid, biz, bizId)
+}
+
+func (h *historyService) Clear(ctx context.Context, uid int64) error {
+	return h.repo.Clear(ctx, uid)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+	"webook/internal/domain"
+	"webook/internal/repository"
+
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrImageTooLarge       = errors.New("图片太大")
+	ErrInvalidImage        = errors.New("不支持的图片")
+	ErrImageObjectNotFound = repository.ErrImageObjectNotFound
+)
+
+const (
+	// imageKeyPrefix 图片的 key 都以它开头，同一个对象存储里面还有别的文件
+	imageKeyPrefix = "images/"
+	maxImageSize   = 5 << 20
+	maxCoverSize   = 2 << 20
+	// maxImageSide 防止解码的时候占用过多内存
+	maxImageSide   = 10000
+	minCoverWidth  = 200
+	minCoverHeight = 100
+)
+
+// imageExts 允许上传的类型，值是存储时候的扩展名
+var imageExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ImageService interface {
+	// Upload 校验图片并保存，内容相同的图片直接返回已有的记录
+	Upload(ctx context.Context, uid int64, data []byte, usage domain.ImageUsage) (domain.Image, error)
+	// GetObject 返回图片内容和类型
+	GetObject(ctx context.Context, key string) ([]byte, string, error)
+	// KeyFromURL 文章里面的图片地址对应的 key，外部图片返回 false
+	KeyFromURL(url string) (string, bool)
+}
+
+type imageService struct {
+	repo repository.ImageRepository
+}
+
+func NewImageService(repo repository.ImageRepository) ImageService {
+	return &imageService{
+		repo: repo,
+	}
+}
+
+func (svc *imageService) Upload(ctx context.Context, uid int64, data []byte, usage domain.ImageUsage) (domain.Image, error) {
+	limit := maxImageSize
+	if usage == domain.ImageUsageCover {
+		limit = maxCoverSize
+	}
+	if len(data) > limit {
+		return domain.Image{}, ErrImageTooLarge
+	}
+	// 不相信客户端声明的类型，按照内容判断
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExts[contentType]
+	if !ok {
+		return domain.Image{}, ErrInvalidImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.Image{}, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 ||
+		cfg.Width > maxImageSide || cfg.Height > maxImageSide {
+		return domain.Image{}, ErrInvalidImage
+	}
+	if usage == domain.ImageUsageCover &&
+		(cfg.Width < minCoverWidth || cfg.Height < minCoverHeight) {
+		return domain.Image{}, ErrInvalidImage
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	img, err := svc.repo.FindByHash(ctx, hash)
+	if err == nil {
+		return img, nil
+	}
+	if !errors.Is(err, repository.ErrImageNotFound) {
+		return domain.Image{}, err
+	}
+	return svc.repo.Save(ctx, domain.Image{
+		Hash: hash,
+		// 用前两位做一级目录，避免单个目录下文件太多
+		Key:         fmt.Sprintf("%s%s/%s%s", imageKeyPrefix, hash[:2], hash, ext),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Uploader:    uid,
+	}, data)
+}
+
+func (svc *imageService) GetObject(ctx context.Context, key string) ([]byte, string, error) {
+	// 导出的文章之类的文件不能通过图片的接口访问
+	if !svc.isImageKey(key) {
+		return nil, "", ErrImageObjectNotFound
+	}
+	return svc.repo.GetObject(ctx, key)
+}
+
+func (svc *imageService) KeyFromURL(url string) (string, bool) {
+	key, ok := svc.repo.KeyFromURL(url)
+	if !ok || !svc.isImageKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+func (svc *imageService) isImageKey(key string) bool {
+	return strings.HasPrefix(key, imageKeyPrefix) && !strings.Contains(key, "..")
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+)
+
+func TestImageService_Upload(t *testing.T) {
+	small := encodePNG(t, 10, 20)
+	cover := encodePNG(t, 400, 200)
+	sum := sha256.Sum256(small)
+	hash := hex.EncodeToString(sum[:])
+	dbErr := errors.New("mock db error")
+
+	testCases := []struct {
+		name  string
+		mock  func(ctrl *gomock.Controller) repository.ImageRepository
+		data  []byte
+		usage domain.ImageUsage
+
+		wantImg domain.Image
+		wantErr error
+	}{
+		{
+			name: "上传新图片",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				repo := repomocks.NewMockImageRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).
+					Return(domain.Image{}, repository.ErrImageNotFound)
+				repo.EXPECT().Save(gomock.Any(), domain.Image{
+					Hash:        hash,
+					Key:         "images/" + hash[:2] + "/" + hash + ".png",
+					ContentType: "image/png",
+					Size:        int64(len(small)),
+					Width:       10,
+					Height:      20,
+					Uploader:    123,
+				}, small).Return(domain.Image{Id: 1, URL: "/images/raw/x.png"}, nil)
+				return repo
+			},
+			data:    small,
+			usage:   domain.ImageUsageContent,
+			wantImg: domain.Image{Id: 1, URL: "/images/raw/x.png"},
+		},
+		{
+			name: "内容相同的图片直接返回",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				repo := repomocks.NewMockImageRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).
+					Return(domain.Image{Id: 2, Uploader: 456}, nil)
+				return repo
+			},
+			data:    small,
+			usage:   domain.ImageUsageContent,
+			wantImg: domain.Image{Id: 2, Uploader: 456},
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				repo := repomocks.NewMockImageRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), hash).
+					Return(domain.Image{}, dbErr)
+				return repo
+			},
+			data:    small,
+			usage:   domain.ImageUsageContent,
+			wantErr: dbErr,
+		},
+		{
+			name: "封面",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				repo := repomocks.NewMockImageRepository(ctrl)
+				repo.EXPECT().FindByHash(gomock.Any(), gomock.Any()).
+					Return(domain.Image{Id: 3}, nil)
+				return repo
+			},
+			data:    cover,
+			usage:   domain.ImageUsageCover,
+			wantImg: domain.Image{Id: 3},
+		},
+		{
+			name: "封面太小",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				return repomocks.NewMockImageRepository(ctrl)
+			},
+			data:    small,
+			usage:   domain.ImageUsageCover,
+			wantErr: ErrInvalidImage,
+		},
+		{
+			name: "封面太大",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				return repomocks.NewMockImageRepository(ctrl)
+			},
+			data:    append(bytes.Clone(cover), make([]byte, maxCoverSize)...),
+			usage:   domain.ImageUsageCover,
+			wantErr: ErrImageTooLarge,
+		},
+		{
+			name: "不是图片",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				return repomocks.NewMockImageRepository(ctrl)
+			},
+			data:    []byte("<svg onload=alert(1)></svg>"),
+			usage:   domain.ImageUsageContent,
+			wantErr: ErrInvalidImage,
+		},
+		{
+			name: "类型对但是内容损坏",
+			mock: func(ctrl *gomock.Controller) repository.ImageRepository {
+				return repomocks.NewMockImageRepository(ctrl)
+			},
+			data:    small[:20],
+			usage:   domain.ImageUsageContent,
+			wantErr: ErrInvalidImage,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewImageService(tc.mock(ctrl))
+			img, err := svc.Upload(context.Background(), 123, tc.data, tc.usage)
+			// 解码失败的时候会带上具体的原因
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantImg, img)
+		})
+	}
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestValidCover(t *testing.T) {
+	for cover, want := range map[string]bool{
+		"":                              true,
+		"/images/raw/images/ab/x.png":   true,
+		"https://cdn.example.com/x.png": true,
+		"http://cdn.example.com/x.png":  true,
+		"//evil.com/x.png":              false,
+		"javascript:alert(1)":           false,
+		"data:image/png;base64,xx":      false,
+		"https:///x.png":                false,
+		"/x.png\" onerror=\"alert(1)":   false,
+		"images/x.png":                  false,
+	} {
+		assert.Equal(t, want, validCover(cover), cover)
+	}
+}
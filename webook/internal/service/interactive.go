@@ -4,10 +4,13 @@ import (
 	"context"
 	"golang.org/x/sync/errgroup"
 	"webook/internal/domain"
+	"webook/internal/events/notification"
 	"webook/internal/repository"
 	"webook/pkg/logger"
 )
 
+var ErrCollectionNotFound = repository.ErrCollectionNotFound
+
 type InteractiveService interface {
 	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
 	// Like 点赞
@@ -17,11 +20,27 @@ type InteractiveService interface {
 	// Collect 收藏
 	Collect(ctx context.Context, biz string, bizId, cid, uid int64) error
 	Get(ctx context.Context, biz string, bizId, uid int64) (domain.Interactive, error)
+	// GetByIds 批量查询，用于列表页，uid 大于 0 的时候会补充用户是否点赞或者收藏
+	GetByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]domain.Interactive, error)
+
+	// 收藏夹管理，cid 为 0 代表默认收藏夹
+
+	CreateCollection(ctx context.Context, uid int64, name string) (int64, error)
+	RenameCollection(ctx context.Context, id, uid int64, name string) error
+	ListCollections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error)
+	// DeleteCollection 删除收藏夹，里面的资源都会被取消收藏
+	DeleteCollection(ctx context.Context, id, uid int64) error
+	// CancelCollect 取消收藏
+	CancelCollect(ctx context.Context, biz string, bizId, uid int64) error
+	ListCollectionItems(ctx context.Context, cid, uid int64, offset, limit int) ([]domain.CollectionItem, error)
+	// MoveCollectionItem 把资源移动到收藏夹 cid
+	MoveCollectionItem(ctx context.Context, biz string, bizId, uid, cid int64) error
 }
 
 type interactiveService struct {
-	repo repository.InteractiveRepository
-	l    logger.LoggerV1
+	repo     repository.InteractiveRepository
+	producer notification.Producer
+	l        logger.LoggerV1
 }
 
 func (i *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
@@ -58,8 +77,59 @@ func (i *interactiveService) Get(ctx context.Context, biz string, bizId, uid int
 	return intr, err
 }
 
+func (i *interactiveService) GetByIds(ctx context.Context, biz string, bizIds []int64, uid int64) (map[int64]domain.Interactive, error) {
+	if len(bizIds) == 0 {
+		return map[int64]domain.Interactive{}, nil
+	}
+	intrs, err := i.repo.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	if uid <= 0 {
+		return intrs, nil
+	}
+	var (
+		eg        errgroup.Group
+		liked     map[int64]bool
+		collected map[int64]bool
+	)
+	eg.Go(func() error {
+		var er error
+		liked, er = i.repo.LikedByIds(ctx, biz, bizIds, uid)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		collected, er = i.repo.CollectedByIds(ctx, biz, bizIds, uid)
+		return er
+	})
+	if err = eg.Wait(); err != nil {
+		// 和 Get 一样，这个查询失败只需要记录日志就可以
+		i.l.Error("批量查询用户是否点赞的信息失败",
+			logger.String("biz", biz),
+			logger.Int64("uid", uid),
+			logger.Error(err))
+	}
+	for bizId, intr := range intrs {
+		intr.Liked = liked[bizId]
+		intr.Collected = collected[bizId]
+		intrs[bizId] = intr
+	}
+	return intrs, nil
+}
+
 func (i *interactiveService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
-	return i.repo.IncrLike(ctx, biz, bizId, uid)
+	err := i.repo.IncrLike(ctx, biz, bizId, uid)
+	if err != nil {
+		return err
+	}
+	produceNotification(i.producer, i.l, notification.Event{
+		Type:  domain.NotificationTypeLike,
+		Actor: uid,
+		Biz:   biz,
+		BizId: bizId,
+	})
+	return nil
 }
 
 func (i *interactiveService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
@@ -69,13 +139,60 @@ func (i *interactiveService) CancelLike(ctx context.Context, biz string, bizId i
 // Collect 收藏
 func (i *interactiveService) Collect(ctx context.Context,
 	biz string, bizId, cid, uid int64) error {
-	return i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
+	err := i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
+	if err != nil {
+		return err
+	}
+	produceNotification(i.producer, i.l, notification.Event{
+		Type:  domain.NotificationTypeCollect,
+		Actor: uid,
+		Biz:   biz,
+		BizId: bizId,
+	})
+	return nil
+}
+
+func (i *interactiveService) CreateCollection(ctx context.Context, uid int64, name string) (int64, error) {
+	return i.repo.AddCollection(ctx, domain.Collection{
+		Uid:  uid,
+		Name: name,
+	})
+}
+
+func (i *interactiveService) RenameCollection(ctx context.Context, id, uid int64, name string) error {
+	return i.repo.UpdateCollection(ctx, domain.Collection{
+		Id:   id,
+		Uid:  uid,
+		Name: name,
+	})
+}
+
+func (i *interactiveService) ListCollections(ctx context.Context, uid int64, offset, limit int) ([]domain.Collection, error) {
+	return i.repo.Collections(ctx, uid, offset, limit)
+}
+
+func (i *interactiveService) DeleteCollection(ctx context.Context, id, uid int64) error {
+	return i.repo.DeleteCollection(ctx, id, uid)
+}
+
+func (i *interactiveService) CancelCollect(ctx context.Context, biz string, bizId, uid int64) error {
+	return i.repo.DeleteCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (i *interactiveService) ListCollectionItems(ctx context.Context, cid, uid int64, offset, limit int) ([]domain.CollectionItem, error) {
+	return i.repo.CollectionItems(ctx, cid, uid, offset, limit)
+}
+
+func (i *interactiveService) MoveCollectionItem(ctx context.Context, biz string, bizId, uid, cid int64) error {
+	return i.repo.MoveCollectionItem(ctx, biz, bizId, uid, cid)
 }
 
 func NewInteractiveService(repo repository.InteractiveRepository,
+	producer notification.Producer,
 	l logger.LoggerV1) InteractiveService {
 	return &interactiveService{
-		repo: repo,
-		l:    l,
+		repo:     repo,
+		producer: producer,
+		l:        l,
 	}
 }
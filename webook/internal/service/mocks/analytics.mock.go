@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/service/analytics.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/service/analytics.go -package=svcmocks -destination=webook/internal/service/mocks/analytics.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAnalyticsService is a mock of AnalyticsService interface.
+type MockAnalyticsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnalyticsServiceMockRecorder
+}
+
+// MockAnalyticsServiceMockRecorder is the mock recorder for MockAnalyticsService.
+type MockAnalyticsServiceMockRecorder struct {
+	mock *MockAnalyticsService
+}
+
+// NewMockAnalyticsService creates a new mock instance.
+func NewMockAnalyticsService(ctrl *gomock.Controller) *MockAnalyticsService {
+	mock := &MockAnalyticsService{ctrl: ctrl}
+	mock.recorder = &MockAnalyticsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAnalyticsService) EXPECT() *MockAnalyticsServiceMockRecorder {
+	return m.recorder
+}
+
+// Series mocks base method.
+func (m *MockAnalyticsService) Series(ctx context.Context, uid, aid int64, g domain.AnalyticsGranularity, periods int) ([]domain.AnalyticsPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Series", ctx, uid, aid, g, periods)
+	ret0, _ := ret[0].([]domain.AnalyticsPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Series indicates an expected call of Series.
+func (mr *MockAnalyticsServiceMockRecorder) Series(ctx, uid, aid, g, periods any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Series", reflect.TypeOf((*MockAnalyticsService)(nil).Series), ctx, uid, aid, g, periods)
+}
+
+// TopArticles mocks base method.
+func (m *MockAnalyticsService) TopArticles(ctx context.Context, uid int64, days int, metric domain.AnalyticsMetric, limit int) ([]domain.ArticleAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopArticles", ctx, uid, days, metric, limit)
+	ret0, _ := ret[0].([]domain.ArticleAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopArticles indicates an expected call of TopArticles.
+func (mr *MockAnalyticsServiceMockRecorder) TopArticles(ctx, uid, days, metric, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopArticles", reflect.TypeOf((*MockAnalyticsService)(nil).TopArticles), ctx, uid, days, metric, limit)
+}
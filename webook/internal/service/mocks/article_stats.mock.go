@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/service/article_stats.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/service/article_stats.go -package=svcmocks -destination=webook/internal/service/mocks/article_stats.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleStatsService is a mock of ArticleStatsService interface.
+type MockArticleStatsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleStatsServiceMockRecorder
+}
+
+// MockArticleStatsServiceMockRecorder is the mock recorder for MockArticleStatsService.
+type MockArticleStatsServiceMockRecorder struct {
+	mock *MockArticleStatsService
+}
+
+// NewMockArticleStatsService creates a new mock instance.
+func NewMockArticleStatsService(ctrl *gomock.Controller) *MockArticleStatsService {
+	mock := &MockArticleStatsService{ctrl: ctrl}
+	mock.recorder = &MockArticleStatsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleStatsService) EXPECT() *MockArticleStatsServiceMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockArticleStatsService) Flush(ctx context.Context, day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", ctx, day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockArticleStatsServiceMockRecorder) Flush(ctx, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockArticleStatsService)(nil).Flush), ctx, day)
+}
+
+// Views mocks base method.
+func (m *MockArticleStatsService) Views(ctx context.Context, uid, aid int64, days int) ([]domain.ArticleDailyViews, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Views", ctx, uid, aid, days)
+	ret0, _ := ret[0].([]domain.ArticleDailyViews)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Views indicates an expected call of Views.
+func (mr *MockArticleStatsServiceMockRecorder) Views(ctx, uid, aid, days any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Views", reflect.TypeOf((*MockArticleStatsService)(nil).Views), ctx, uid, aid, days)
+}
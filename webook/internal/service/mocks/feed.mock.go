@@ -0,0 +1,89 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/service/feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/service/feed.go -package=svcmocks -destination=webook/internal/service/mocks/feed.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedService is a mock of FeedService interface.
+type MockFeedService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedServiceMockRecorder
+}
+
+// MockFeedServiceMockRecorder is the mock recorder for MockFeedService.
+type MockFeedServiceMockRecorder struct {
+	mock *MockFeedService
+}
+
+// NewMockFeedService creates a new mock instance.
+func NewMockFeedService(ctrl *gomock.Controller) *MockFeedService {
+	mock := &MockFeedService{ctrl: ctrl}
+	mock.recorder = &MockFeedServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedService) EXPECT() *MockFeedServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthorFeed mocks base method.
+func (m *MockFeedService) AuthorFeed(ctx context.Context, uid int64) (domain.Feed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorFeed", ctx, uid)
+	ret0, _ := ret[0].(domain.Feed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorFeed indicates an expected call of AuthorFeed.
+func (mr *MockFeedServiceMockRecorder) AuthorFeed(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorFeed", reflect.TypeOf((*MockFeedService)(nil).AuthorFeed), ctx, uid)
+}
+
+// SitemapIndex mocks base method.
+func (m *MockFeedService) SitemapIndex(ctx context.Context) (int, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SitemapIndex", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SitemapIndex indicates an expected call of SitemapIndex.
+func (mr *MockFeedServiceMockRecorder) SitemapIndex(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SitemapIndex", reflect.TypeOf((*MockFeedService)(nil).SitemapIndex), ctx)
+}
+
+// SitemapPage mocks base method.
+func (m *MockFeedService) SitemapPage(ctx context.Context, page int) ([]domain.SitemapEntry, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SitemapPage", ctx, page)
+	ret0, _ := ret[0].([]domain.SitemapEntry)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SitemapPage indicates an expected call of SitemapPage.
+func (mr *MockFeedServiceMockRecorder) SitemapPage(ctx, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SitemapPage", reflect.TypeOf((*MockFeedService)(nil).SitemapPage), ctx, page)
+}
@@ -0,0 +1,132 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webook/internal/service/transfer.go
+//
+// Generated by this command:
+//
+//	mockgen -source=webook/internal/service/transfer.go -package=svcmocks -destination=webook/internal/service/mocks/transfer.mock.go
+//
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	domain "webook/internal/domain"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleTransferService is a mock of ArticleTransferService interface.
+type MockArticleTransferService struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleTransferServiceMockRecorder
+}
+
+// MockArticleTransferServiceMockRecorder is the mock recorder for MockArticleTransferService.
+type MockArticleTransferServiceMockRecorder struct {
+	mock *MockArticleTransferService
+}
+
+// NewMockArticleTransferService creates a new mock instance.
+func NewMockArticleTransferService(ctrl *gomock.Controller) *MockArticleTransferService {
+	mock := &MockArticleTransferService{ctrl: ctrl}
+	mock.recorder = &MockArticleTransferServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleTransferService) EXPECT() *MockArticleTransferServiceMockRecorder {
+	return m.recorder
+}
+
+// Download mocks base method.
+func (m *MockArticleTransferService) Download(ctx context.Context, uid, id int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", ctx, uid, id)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockArticleTransferServiceMockRecorder) Download(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockArticleTransferService)(nil).Download), ctx, uid, id)
+}
+
+// FailStale mocks base method.
+func (m *MockArticleTransferService) FailStale(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailStale", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailStale indicates an expected call of FailStale.
+func (mr *MockArticleTransferServiceMockRecorder) FailStale(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStale", reflect.TypeOf((*MockArticleTransferService)(nil).FailStale), ctx, now)
+}
+
+// Get mocks base method.
+func (m *MockArticleTransferService) Get(ctx context.Context, uid, id int64) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, uid, id)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockArticleTransferServiceMockRecorder) Get(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockArticleTransferService)(nil).Get), ctx, uid, id)
+}
+
+// PurgeExpired mocks base method.
+func (m *MockArticleTransferService) PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", ctx, now, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockArticleTransferServiceMockRecorder) PurgeExpired(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockArticleTransferService)(nil).PurgeExpired), ctx, now, limit)
+}
+
+// StartExport mocks base method.
+func (m *MockArticleTransferService) StartExport(ctx context.Context, uid int64) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartExport", ctx, uid)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartExport indicates an expected call of StartExport.
+func (mr *MockArticleTransferServiceMockRecorder) StartExport(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartExport", reflect.TypeOf((*MockArticleTransferService)(nil).StartExport), ctx, uid)
+}
+
+// StartImport mocks base method.
+func (m *MockArticleTransferService) StartImport(ctx context.Context, uid int64, filename string, data []byte) (domain.ArticleTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartImport", ctx, uid, filename, data)
+	ret0, _ := ret[0].(domain.ArticleTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartImport indicates an expected call of StartImport.
+func (mr *MockArticleTransferServiceMockRecorder) StartImport(ctx, uid, filename, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImport", reflect.TypeOf((*MockArticleTransferService)(nil).StartImport), ctx, uid, filename, data)
+}
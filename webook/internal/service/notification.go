@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/events/notification"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+type NotificationService interface {
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error)
+	UnreadCnt(ctx context.Context, uid int64) (int64, error)
+	// MarkRead ids 为空的时候标记全部已读
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	// Subscribe 订阅推送给 uid 的新通知，不再需要的时候要调用返回的 cancel
+	// 推送是尽力而为的，消费得太慢的话会丢掉一部分通知
+	Subscribe(uid int64) (<-chan domain.Notification, func())
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+	l    logger.LoggerV1
+
+	once sync.Once
+	mu   sync.RWMutex
+	subs map[int64]map[chan domain.Notification]struct{}
+}
+
+func NewNotificationService(repo repository.NotificationRepository, l logger.LoggerV1) NotificationService {
+	return &notificationService{
+		repo: repo,
+		l:    l,
+		subs: make(map[int64]map[chan domain.Notification]struct{}),
+	}
+}
+
+func (s *notificationService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Notification, error) {
+	return s.repo.List(ctx, uid, offset, limit)
+}
+
+func (s *notificationService) UnreadCnt(ctx context.Context, uid int64) (int64, error) {
+	return s.repo.UnreadCnt(ctx, uid)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	return s.repo.MarkRead(ctx, uid, ids)
+}
+
+func (s *notificationService) Subscribe(uid int64) (<-chan domain.Notification, func()) {
+	// 第一次有人订阅的时候才开始监听
+	s.once.Do(func() {
+		go s.dispatchLoop()
+	})
+	ch := make(chan domain.Notification, 16)
+	s.mu.Lock()
+	if s.subs[uid] == nil {
+		s.subs[uid] = make(map[chan domain.Notification]struct{})
+	}
+	s.subs[uid][ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs[uid], ch)
+		if len(s.subs[uid]) == 0 {
+			delete(s.subs, uid)
+		}
+	}
+}
+
+// dispatchLoop 订阅所有的新通知，转发给连接在这个实例上的用户
+func (s *notificationService) dispatchLoop() {
+	for {
+		ns, err := s.repo.Subscribe(context.Background())
+		if err != nil {
+			s.l.Error("订阅通知失败，稍后重试", logger.Error(err))
+			time.Sleep(time.Second)
+			continue
+		}
+		for n := range ns {
+			s.dispatch(n)
+		}
+		s.l.Warn("通知订阅断开，重新订阅")
+	}
+}
+
+func (s *notificationService) dispatch(n domain.Notification) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for ch := range s.subs[n.Receiver] {
+		select {
+		case ch <- n:
+		default:
+			// 客户端太慢了，直接丢弃，它可以通过列表接口拿到
+		}
+	}
+}
+
+// produceNotification 异步发送通知事件，失败了只记录日志
+func produceNotification(p notification.Producer, l logger.LoggerV1, evt notification.Event) {
+	go func() {
+		if err := p.ProduceNotificationEvent(evt); err != nil {
+			l.Error("发送通知事件失败",
+				logger.Int64("actor", evt.Actor),
+				logger.String("biz", evt.Biz),
+				logger.Int64("bizId", evt.BizId),
+				logger.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ecodeclub/ekit/queue"
+	"github.com/ecodeclub/ekit/slice"
+	"math"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+type RankingService interface {
+	// TopN 计算热榜，并且保存起来
+	TopN(ctx context.Context) error
+	// GetTopN 查询计算好的热榜
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+type BatchRankingService struct {
+	artSvc  ArticleService
+	intrSvc InteractiveService
+	userSvc UserService
+	repo    repository.RankingRepository
+	l       logger.LoggerV1
+
+	batchSize int
+	n         int
+	// window 只计算这段时间内更新过的文章
+	window    time.Duration
+	scoreFunc func(intr domain.Interactive) float64
+	biz       string
+}
+
+func NewBatchRankingService(artSvc ArticleService, intrSvc InteractiveService, userSvc UserService,
+	repo repository.RankingRepository, l logger.LoggerV1) RankingService {
+	return &BatchRankingService{
+		artSvc:    artSvc,
+		intrSvc:   intrSvc,
+		userSvc:   userSvc,
+		repo:      repo,
+		l:         l,
+		batchSize: 100,
+		n:         100,
+		window:    time.Hour * 24 * 7,
+		scoreFunc: gravityScore,
+		biz:       "article",
+	}
+}
+
+// gravityScore 类似于 Hacker News 的重力衰减算法
+// 互动越多分数越高，越久没有互动分数下降得越快
+func gravityScore(intr domain.Interactive) float64 {
+	const (
+		readWeight    = 1.0
+		likeWeight    = 3.0
+		collectWeight = 5.0
+		gravity       = 1.5
+	)
+	votes := float64(intr.ReadCnt)*readWeight +
+		float64(intr.LikeCnt)*likeWeight +
+		float64(intr.CollectCnt)*collectWeight
+	hours := time.Since(intr.Utime).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return votes / math.Pow(hours+2, gravity)
+}
+
+func (svc *BatchRankingService) TopN(ctx context.Context) error {
+	arts, err := svc.topN(ctx)
+	if err != nil {
+		return err
+	}
+	svc.fillAuthors(ctx, arts)
+	return svc.repo.ReplaceTopN(ctx, arts)
+}
+
+// fillAuthors 线上库的文章只有作者 ID，热榜要展示作者的名字
+// 查不到的作者只是不展示名字，不影响热榜
+func (svc *BatchRankingService) fillAuthors(ctx context.Context, arts []domain.Article) {
+	names := make(map[int64]string, len(arts))
+	for i := range arts {
+		uid := arts[i].Author.Id
+		name, ok := names[uid]
+		if !ok {
+			u, err := svc.userSvc.Profile(ctx, uid)
+			if err != nil {
+				svc.l.Error("热榜查询作者失败", logger.Int64("uid", uid), logger.Error(err))
+			}
+			name = u.NickName
+			names[uid] = name
+		}
+		arts[i].Author.Name = name
+	}
+}
+
+func (svc *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	return svc.repo.GetTopN(ctx)
+}
+
+func (svc *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	type Score struct {
+		art   domain.Article
+		score float64
+	}
+	// 小顶堆，堆顶是分数最低的
+	topN := queue.NewConcurrentPriorityQueue[Score](svc.n,
+		func(src Score, dst Score) int {
+			switch {
+			case src.score > dst.score:
+				return 1
+			case src.score == dst.score:
+				return 0
+			default:
+				return -1
+			}
+		})
+
+	start := time.Now().Add(-svc.window)
+	var (
+		utime time.Time
+		id    int64
+	)
+	for {
+		arts, err := svc.artSvc.ListPub(ctx, utime, id, svc.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		ids := slice.Map[domain.Article, int64](arts, func(idx int, src domain.Article) int64 {
+			return src.Id
+		})
+		intrs, err := svc.intrSvc.GetByIds(ctx, svc.biz, ids, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			// 热榜不需要内容
+			art.Content = ""
+			ele := Score{
+				art:   art,
+				score: svc.scoreFunc(intrs[art.Id]),
+			}
+			err = topN.Enqueue(ele)
+			if errors.Is(err, queue.ErrOutOfCapacity) {
+				// 满了，和堆顶比较，留下分数高的
+				minEle, _ := topN.Dequeue()
+				if minEle.score < ele.score {
+					_ = topN.Enqueue(ele)
+				} else {
+					_ = topN.Enqueue(minEle)
+				}
+			}
+		}
+		// 没有下一批了，或者剩下的都是很久以前的文章了
+		if len(arts) < svc.batchSize || arts[len(arts)-1].Utime.Before(start) {
+			break
+		}
+		last := arts[len(arts)-1]
+		utime, id = last.Utime, last.Id
+	}
+
+	res := make([]domain.Article, topN.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		ele, _ := topN.Dequeue()
+		res[i] = ele.art
+	}
+	return res, nil
+}
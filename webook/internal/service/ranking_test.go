@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+	svcmocks "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func TestBatchRankingService_TopN(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (ArticleService, InteractiveService, UserService, repository.RankingRepository)
+
+		wantErr error
+	}{
+		{
+			name: "分批计算成功",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService, UserService, repository.RankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						{Id: 4, Utime: now, Author: domain.Author{Id: 1}},
+						{Id: 3, Utime: now, Author: domain.Author{Id: 1}},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{4, 3}, int64(0)).
+					Return(map[int64]domain.Interactive{
+						4: {LikeCnt: 1},
+						3: {LikeCnt: 3},
+					}, nil)
+				artSvc.EXPECT().ListPub(gomock.Any(), now, int64(3), 2).
+					Return([]domain.Article{
+						{Id: 2, Utime: now, Author: domain.Author{Id: 2}},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{2}, int64(0)).
+					Return(map[int64]domain.Interactive{
+						2: {LikeCnt: 2},
+					}, nil)
+				// 同一个作者只查一次，查不到的作者不影响热榜
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(1)).
+					Return(domain.User{Id: 1, NickName: "作者1"}, nil)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(2)).
+					Return(domain.User{}, errors.New("mock db error"))
+				repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{
+					{Id: 3, Utime: now, Author: domain.Author{Id: 1, Name: "作者1"}},
+					{Id: 2, Utime: now, Author: domain.Author{Id: 2}},
+				}).Return(nil)
+				return artSvc, intrSvc, userSvc, repo
+			},
+		},
+		{
+			name: "超出时间窗口就不再继续",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService, UserService, repository.RankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				repo := repomocks.NewMockRankingRepository(ctrl)
+				old := now.Add(-time.Hour * 24 * 30)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						{Id: 4, Utime: now},
+						{Id: 3, Utime: old},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{4, 3}, int64(0)).
+					Return(map[int64]domain.Interactive{
+						4: {LikeCnt: 1},
+						3: {LikeCnt: 3},
+					}, nil)
+				userSvc := svcmocks.NewMockUserService(ctrl)
+				userSvc.EXPECT().Profile(gomock.Any(), int64(0)).Return(domain.User{}, nil)
+				repo.EXPECT().ReplaceTopN(gomock.Any(), []domain.Article{
+					{Id: 3, Utime: old},
+					{Id: 4, Utime: now},
+				}).Return(nil)
+				return artSvc, intrSvc, userSvc, repo
+			},
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService, UserService, repository.RankingRepository) {
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return(nil, errors.New("mock db error"))
+				return artSvc, svcmocks.NewMockInteractiveService(ctrl),
+					svcmocks.NewMockUserService(ctrl), repomocks.NewMockRankingRepository(ctrl)
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artSvc, intrSvc, userSvc, repo := tc.mock(ctrl)
+			svc := NewBatchRankingService(artSvc, intrSvc, userSvc, repo, &logger.NoOpLogger{}).(*BatchRankingService)
+			svc.batchSize = 2
+			svc.n = 2
+			// 简化分数计算，方便验证
+			svc.scoreFunc = func(intr domain.Interactive) float64 {
+				return float64(intr.LikeCnt)
+			}
+			err := svc.TopN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
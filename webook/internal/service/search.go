@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+type SearchService interface {
+	SearchArticle(ctx context.Context, query string, offset, limit int) (domain.SearchArticleResult, error)
+	SearchUser(ctx context.Context, query string, offset, limit int) (domain.SearchUserResult, error)
+}
+
+type searchService struct {
+	repo repository.SearchRepository
+}
+
+func NewSearchService(repo repository.SearchRepository) SearchService {
+	return &searchService{
+		repo: repo,
+	}
+}
+
+func (s *searchService) SearchArticle(ctx context.Context, query string, offset, limit int) (domain.SearchArticleResult, error) {
+	return s.repo.SearchArticle(ctx, query, offset, limit)
+}
+
+func (s *searchService) SearchUser(ctx context.Context, query string, offset, limit int) (domain.SearchUserResult, error) {
+	return s.repo.SearchUser(ctx, query, offset, limit)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/repository/article"
+	"webook/pkg/logger"
+)
+
+var (
+	ErrSeriesNotFound = article.ErrSeriesNotFound
+	// ErrInvalidSeriesArticle 章节太多，或者有不存在、已经删除、不属于作者的文章
+	ErrInvalidSeriesArticle = article.ErrInvalidSeriesArticle
+	ErrArticleInOtherSeries = article.ErrArticleInOtherSeries
+	// ErrInvalidSeries 标题为空，或者标题、简介太长
+	ErrInvalidSeries = errors.New("专栏的标题或者简介不合法")
+)
+
+const (
+	maxSeriesTitleLen = 64
+	maxSeriesDescLen  = 512
+	// maxSeriesArticles 一个专栏最多的章节数量
+	maxSeriesArticles = 200
+)
+
+type SeriesService interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// Update 只修改标题和简介
+	Update(ctx context.Context, s domain.Series) error
+	// Delete 只删除专栏，章节的文章保持原样
+	Delete(ctx context.Context, uid, id int64) error
+	// SetArticles 整体替换章节，添加、移除和调整顺序都是用它
+	SetArticles(ctx context.Context, uid, id int64, ids []int64) error
+	// Publish 发表所有还没有发表的章节，全部成功之后专栏才会变成发表状态
+	Publish(ctx context.Context, uid, id int64) error
+	// Withdraw 专栏和所有已经发表的章节都变成仅自己可见
+	Withdraw(ctx context.Context, uid, id int64) error
+	List(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, error)
+	// Detail 作者视角的专栏，带上所有没有删除的章节
+	Detail(ctx context.Context, uid, id int64) (domain.Series, error)
+
+	// GetPublished 读者视角的专栏，只带上已经发表的章节，专栏没有发表的时候返回 ErrSeriesNotFound
+	GetPublished(ctx context.Context, id int64) (domain.Series, error)
+	// Nav 文章在专栏里面的上一篇和下一篇，文章不在已经发表的专栏里面的时候返回 ErrSeriesNotFound
+	Nav(ctx context.Context, articleId int64) (domain.SeriesNav, error)
+}
+
+type seriesService struct {
+	repo article.SeriesRepository
+	// artSvc 发表和撤回章节要走正常的流程，比如同步搜索
+	artSvc ArticleService
+	l      logger.LoggerV1
+}
+
+func NewSeriesService(repo article.SeriesRepository, artSvc ArticleService, l logger.LoggerV1) SeriesService {
+	return &seriesService{
+		repo:   repo,
+		artSvc: artSvc,
+		l:      l,
+	}
+}
+
+func (svc *seriesService) Create(ctx context.Context, s domain.Series) (int64, error) {
+	if err := svc.normalize(&s); err != nil {
+		return 0, err
+	}
+	s.Status = domain.SeriesStatusUnpublished
+	return svc.repo.Create(ctx, s)
+}
+
+func (svc *seriesService) Update(ctx context.Context, s domain.Series) error {
+	if err := svc.normalize(&s); err != nil {
+		return err
+	}
+	return svc.repo.Update(ctx, s)
+}
+
+func (svc *seriesService) normalize(s *domain.Series) error {
+	s.Title = strings.TrimSpace(s.Title)
+	s.Description = strings.TrimSpace(s.Description)
+	if s.Title == "" || utf8.RuneCountInString(s.Title) > maxSeriesTitleLen ||
+		utf8.RuneCountInString(s.Description) > maxSeriesDescLen {
+		return ErrInvalidSeries
+	}
+	return nil
+}
+
+func (svc *seriesService) Delete(ctx context.Context, uid, id int64) error {
+	return svc.repo.Delete(ctx, uid, id)
+}
+
+func (svc *seriesService) SetArticles(ctx context.Context, uid, id int64, ids []int64) error {
+	if len(ids) > maxSeriesArticles {
+		return ErrInvalidSeriesArticle
+	}
+	return svc.repo.SetArticles(ctx, uid, id, ids)
+}
+
+func (svc *seriesService) Publish(ctx context.Context, uid, id int64) error {
+	arts, err := svc.chapters(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, ch := range arts {
+		if ch.Published() {
+			continue
+		}
+		// 章节列表里面没有内容，发表要用完整的草稿
+		art, err := svc.artSvc.GetById(ctx, ch.Id)
+		if err == nil {
+			_, err = svc.artSvc.Publish(ctx, art)
+		}
+		if err != nil {
+			// 一篇失败不影响别的章节，重试的时候已经发表的会被跳过
+			svc.l.Error("发表专栏的章节失败",
+				logger.Int64("sid", id),
+				logger.Int64("aid", ch.Id),
+				logger.Error(err))
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return svc.repo.UpdateStatus(ctx, uid, id, domain.SeriesStatusPublished)
+}
+
+func (svc *seriesService) Withdraw(ctx context.Context, uid, id int64) error {
+	arts, err := svc.chapters(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	// 先撤回专栏，读者马上就看不到专栏页了
+	err = svc.repo.UpdateStatus(ctx, uid, id, domain.SeriesStatusUnpublished)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, ch := range arts {
+		if !ch.Published() {
+			continue
+		}
+		if err = svc.artSvc.Withdraw(ctx, uid, ch.Id); err != nil {
+			svc.l.Error("撤回专栏的章节失败",
+				logger.Int64("sid", id),
+				logger.Int64("aid", ch.Id),
+				logger.Error(err))
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// chapters 校验专栏是不是这个作者的，并且返回所有的章节
+func (svc *seriesService) chapters(ctx context.Context, uid, id int64) ([]domain.Article, error) {
+	s, err := svc.repo.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Author.Id != uid {
+		return nil, ErrPossibleIncorrectAuthor
+	}
+	return svc.repo.GetArticles(ctx, id)
+}
+
+func (svc *seriesService) List(ctx context.Context, uid int64, offset, limit int) ([]domain.Series, error) {
+	return svc.repo.List(ctx, uid, offset, limit)
+}
+
+func (svc *seriesService) Detail(ctx context.Context, uid, id int64) (domain.Series, error) {
+	s, err := svc.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	if s.Author.Id != uid {
+		return domain.Series{}, ErrPossibleIncorrectAuthor
+	}
+	s.Articles, err = svc.repo.GetArticles(ctx, id)
+	return s, err
+}
+
+func (svc *seriesService) GetPublished(ctx context.Context, id int64) (domain.Series, error) {
+	s, err := svc.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	if !s.Published() {
+		return domain.Series{}, ErrSeriesNotFound
+	}
+	s.Articles, err = svc.artSvc.ListPubByIds(ctx, s.ArticleIds)
+	return s, err
+}
+
+func (svc *seriesService) Nav(ctx context.Context, articleId int64) (domain.SeriesNav, error) {
+	s, err := svc.repo.GetByArticle(ctx, articleId)
+	if err != nil {
+		return domain.SeriesNav{}, err
+	}
+	if !s.Published() {
+		return domain.SeriesNav{}, ErrSeriesNotFound
+	}
+	// 只在已经发表的章节之间跳转，导航只需要标题
+	arts, err := svc.artSvc.ListPubTitlesByIds(ctx, s.ArticleIds)
+	if err != nil {
+		return domain.SeriesNav{}, err
+	}
+	nav := domain.SeriesNav{Series: s}
+	for i := range arts {
+		if arts[i].Id != articleId {
+			continue
+		}
+		if i > 0 {
+			nav.Prev = &arts[i-1]
+		}
+		if i < len(arts)-1 {
+			nav.Next = &arts[i+1]
+		}
+		break
+	}
+	return nav, nil
+}
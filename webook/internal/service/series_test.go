@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository/article"
+	artrepomocks "webook/internal/repository/article/mocks"
+	svcmocks "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_seriesService_Publish(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (article.SeriesRepository, ArticleService)
+
+		wantErr error
+	}{
+		{
+			name: "只发表还没有发表的章节",
+			mock: func(ctrl *gomock.Controller) (article.SeriesRepository, ArticleService) {
+				repo := artrepomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Series{Id: 1, Author: domain.Author{Id: 123}}, nil)
+				repo.EXPECT().GetArticles(gomock.Any(), int64(1)).Return([]domain.Article{
+					{Id: 11, Status: domain.ArticleStatusPublished},
+					{Id: 12, Status: domain.ArticleStatusUnpublished},
+				}, nil)
+				draft := domain.Article{Id: 12, Title: "第二章", Content: "内容", Revision: 3,
+					Author: domain.Author{Id: 123}, Status: domain.ArticleStatusUnpublished}
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetById(gomock.Any(), int64(12)).Return(draft, nil)
+				artSvc.EXPECT().Publish(gomock.Any(), draft).Return(int64(12), nil)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(123), int64(1), domain.SeriesStatusPublished).
+					Return(nil)
+				return repo, artSvc
+			},
+		},
+		{
+			name: "有章节发表失败，专栏保持未发表",
+			mock: func(ctrl *gomock.Controller) (article.SeriesRepository, ArticleService) {
+				repo := artrepomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Series{Id: 1, Author: domain.Author{Id: 123}}, nil)
+				repo.EXPECT().GetArticles(gomock.Any(), int64(1)).Return([]domain.Article{
+					{Id: 11, Status: domain.ArticleStatusUnpublished},
+					{Id: 12, Status: domain.ArticleStatusPrivate},
+				}, nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetById(gomock.Any(), int64(11)).Return(domain.Article{Id: 11}, nil)
+				artSvc.EXPECT().Publish(gomock.Any(), domain.Article{Id: 11}).
+					Return(int64(0), errors.New("mock db error"))
+				// 第一篇失败了，第二篇照样要发表
+				artSvc.EXPECT().GetById(gomock.Any(), int64(12)).Return(domain.Article{Id: 12}, nil)
+				artSvc.EXPECT().Publish(gomock.Any(), domain.Article{Id: 12}).Return(int64(12), nil)
+				return repo, artSvc
+			},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "不是自己的专栏",
+			mock: func(ctrl *gomock.Controller) (article.SeriesRepository, ArticleService) {
+				repo := artrepomocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Series{Id: 1, Author: domain.Author{Id: 234}}, nil)
+				return repo, svcmocks.NewMockArticleService(ctrl)
+			},
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artSvc := tc.mock(ctrl)
+			svc := NewSeriesService(repo, artSvc, &logger.NoOpLogger{})
+			err := svc.Publish(context.Background(), 123, 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_seriesService_Nav(t *testing.T) {
+	series := domain.Series{Id: 1, Title: "教程", Status: domain.SeriesStatusPublished,
+		ArticleIds: []int64{11, 12, 13, 14}}
+	// 12 还没有发表，所以 13 的上一篇是 11
+	pubs := []domain.Article{{Id: 11}, {Id: 13}, {Id: 14}}
+	testCases := []struct {
+		name      string
+		articleId int64
+		series    domain.Series
+
+		wantPrev int64
+		wantNext int64
+	}{
+		{
+			name:      "跳过没有发表的章节",
+			articleId: 13,
+			series:    series,
+			wantPrev:  11,
+			wantNext:  14,
+		},
+		{
+			name:      "第一篇",
+			articleId: 11,
+			series:    series,
+			wantNext:  13,
+		},
+		{
+			name:      "最后一篇",
+			articleId: 14,
+			series:    series,
+			wantPrev:  13,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := artrepomocks.NewMockSeriesRepository(ctrl)
+			repo.EXPECT().GetByArticle(gomock.Any(), tc.articleId).Return(tc.series, nil)
+			artSvc := svcmocks.NewMockArticleService(ctrl)
+			artSvc.EXPECT().ListPubTitlesByIds(gomock.Any(), tc.series.ArticleIds).Return(pubs, nil)
+			svc := NewSeriesService(repo, artSvc, &logger.NoOpLogger{})
+
+			nav, err := svc.Nav(context.Background(), tc.articleId)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.series.Id, nav.Series.Id)
+			var prev, next int64
+			if nav.Prev != nil {
+				prev = nav.Prev.Id
+			}
+			if nav.Next != nil {
+				next = nav.Next.Id
+			}
+			assert.Equal(t, tc.wantPrev, prev)
+			assert.Equal(t, tc.wantNext, next)
+		})
+	}
+
+	// 没有发表的专栏不展示导航
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := artrepomocks.NewMockSeriesRepository(ctrl)
+	repo.EXPECT().GetByArticle(gomock.Any(), int64(11)).
+		Return(domain.Series{Id: 1, Status: domain.SeriesStatusUnpublished}, nil)
+	svc := NewSeriesService(repo, svcmocks.NewMockArticleService(ctrl), &logger.NoOpLogger{})
+	_, err := svc.Nav(context.Background(), 11)
+	assert.Equal(t, ErrSeriesNotFound, err)
+}
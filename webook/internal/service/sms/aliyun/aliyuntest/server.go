@@ -0,0 +1,85 @@
+// Package aliyuntest 提供一个本地的阿里云短信接口替身，用于测试
+package aliyuntest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"webook/internal/service/sms/aliyun"
+)
+
+// Message 替身收到的短信
+type Message struct {
+	PhoneNumbers  string
+	SignName      string
+	TemplateCode  string
+	TemplateParam string
+}
+
+// Server 校验签名，记录收到的短信，并且可以指定返回的错误码
+type Server struct {
+	*httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	messages []Message
+	code     string
+}
+
+func NewServer(secret string) *Server {
+	s := &Server{secret: secret, code: "OK"}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// SetCode 之后的请求都返回这个错误码，"OK" 代表成功
+func (s *Server) SetCode(code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.code = code
+}
+
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("Action") != "SendSms" {
+		s.write(w, http.StatusBadRequest, "InvalidAction.NotFound")
+		return
+	}
+	if aliyun.Sign(r.Method, query, s.secret) != query.Get("Signature") {
+		s.write(w, http.StatusBadRequest, "SignatureDoesNotMatch")
+		return
+	}
+	s.mu.Lock()
+	code := s.code
+	if code == "OK" {
+		s.messages = append(s.messages, Message{
+			PhoneNumbers:  query.Get("PhoneNumbers"),
+			SignName:      query.Get("SignName"),
+			TemplateCode:  query.Get("TemplateCode"),
+			TemplateParam: query.Get("TemplateParam"),
+		})
+	}
+	s.mu.Unlock()
+	if code == "OK" {
+		s.write(w, http.StatusOK, code)
+		return
+	}
+	s.write(w, http.StatusBadRequest, code)
+}
+
+func (s *Server) write(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Code":      code,
+		"Message":   code,
+		"RequestId": "mock-request-id",
+	})
+}
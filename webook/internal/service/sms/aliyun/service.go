@@ -0,0 +1,148 @@
+package aliyun
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"webook/internal/service/sms"
+)
+
+// Provider 在模板注册中心里面的名字
+const Provider = "aliyun"
+
+// DefaultEndpoint 阿里云短信服务的地址，测试的时候可以换成本地的替身
+const DefaultEndpoint = "https://dysmsapi.aliyuncs.com"
+
+// 这些错误码代表被限流了，短信没有发出去，稍后可以重试
+var temporaryCodes = map[string]struct{}{
+	"isv.BUSINESS_LIMIT_CONTROL": {},
+	"Throttling.User":            {},
+	"Throttling.Api":             {},
+}
+
+// Service 直接调用阿里云短信的 RPC 风格 HTTP 接口，不依赖阿里云的 SDK
+type Service struct {
+	endpoint        string
+	accessKeyId     string
+	accessKeySecret string
+	client          *http.Client
+	tpls            *sms.TemplateRegistry
+
+	now   func() time.Time
+	nonce func() string
+}
+
+func NewService(endpoint, accessKeyId, accessKeySecret string,
+	client *http.Client, tpls *sms.TemplateRegistry) *Service {
+	return &Service{
+		endpoint:        endpoint,
+		accessKeyId:     accessKeyId,
+		accessKeySecret: accessKeySecret,
+		client:          client,
+		tpls:            tpls,
+		now:             time.Now,
+		nonce: func() string {
+			return uuid.New().String()
+		},
+	}
+}
+
+type sendResp struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	BizId     string `json:"BizId"`
+	RequestId string `json:"RequestId"`
+}
+
+func (s *Service) Send(c context.Context, biz string, args []string, numbers ...string) error {
+	tpl, err := s.tpls.Get(biz, Provider)
+	if err != nil {
+		return err
+	}
+	if len(tpl.ParamNames) != len(args) {
+		return fmt.Errorf("模板参数个数不匹配，biz: %s, 需要 %d 个，实际 %d 个",
+			biz, len(tpl.ParamNames), len(args))
+	}
+	params := make(map[string]string, len(args))
+	for i, name := range tpl.ParamNames {
+		params[name] = args[i]
+	}
+	tplParam, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("Action", "SendSms")
+	query.Set("Version", "2017-05-25")
+	query.Set("Format", "JSON")
+	query.Set("AccessKeyId", s.accessKeyId)
+	query.Set("SignatureMethod", "HMAC-SHA1")
+	query.Set("SignatureVersion", "1.0")
+	query.Set("SignatureNonce", s.nonce())
+	query.Set("Timestamp", s.now().UTC().Format("2006-01-02T15:04:05Z"))
+	query.Set("PhoneNumbers", strings.Join(numbers, ","))
+	query.Set("SignName", tpl.Signature)
+	query.Set("TemplateCode", tpl.Id)
+	query.Set("TemplateParam", string(tplParam))
+	query.Set("Signature", Sign(http.MethodGet, query, s.accessKeySecret))
+
+	req, err := http.NewRequestWithContext(c, http.MethodGet,
+		s.endpoint+"/?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// 网络错误和超时，和腾讯云一样可以重试
+		return fmt.Errorf("%w, %w", sms.ErrTemporary, err)
+	}
+	defer resp.Body.Close()
+	var res sendResp
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("解析阿里云短信响应失败，http status: %d, %w", resp.StatusCode, err)
+	}
+	if res.Code == "OK" {
+		return nil
+	}
+	if _, ok := temporaryCodes[res.Code]; ok {
+		return fmt.Errorf("%w, code: %s, message: %s", sms.ErrTemporary, res.Code, res.Message)
+	}
+	return fmt.Errorf("send message failed, code:%s, message:%s", res.Code, res.Message)
+}
+
+// Sign 阿里云 RPC 接口的签名算法，query 里面不能包含 Signature
+func Sign(method string, query url.Values, secret string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if k == "Signature" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(query.Get(k)))
+	}
+	stringToSign := method + "&" + percentEncode("/") + "&" +
+		percentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func percentEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
@@ -0,0 +1,92 @@
+package aliyun_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/aliyun"
+	"webook/internal/service/sms/aliyun/aliyuntest"
+)
+
+func TestService_Send(t *testing.T) {
+	tpls := sms.NewTemplateRegistry().
+		Register("login", aliyun.Provider, sms.Template{
+			Id:         "SMS_123",
+			Signature:  "webook",
+			ParamNames: []string{"code"},
+		})
+	testCases := []struct {
+		name   string
+		biz    string
+		args   []string
+		secret string
+		code   string
+
+		wantErr     error
+		wantMessage []aliyuntest.Message
+	}{
+		{
+			name:   "发送成功",
+			biz:    "login",
+			args:   []string{"123456"},
+			secret: "secret",
+			code:   "OK",
+			wantMessage: []aliyuntest.Message{
+				{
+					PhoneNumbers:  "13800000000,13900000000",
+					SignName:      "webook",
+					TemplateCode:  "SMS_123",
+					TemplateParam: `{"code":"123456"}`,
+				},
+			},
+		},
+		{
+			name:    "模板不存在",
+			biz:     "unknown",
+			args:    []string{"123456"},
+			secret:  "secret",
+			code:    "OK",
+			wantErr: sms.ErrTemplateNotFound,
+		},
+		{
+			name:    "被限流了，可以重试",
+			biz:     "login",
+			args:    []string{"123456"},
+			secret:  "secret",
+			code:    "isv.BUSINESS_LIMIT_CONTROL",
+			wantErr: sms.ErrTemporary,
+		},
+		{
+			name:    "签名错误",
+			biz:     "login",
+			args:    []string{"123456"},
+			secret:  "wrong",
+			code:    "OK",
+			wantErr: errors.New("send message failed, code:SignatureDoesNotMatch, message:SignatureDoesNotMatch"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := aliyuntest.NewServer("secret")
+			defer server.Close()
+			server.SetCode(tc.code)
+			svc := aliyun.NewService(server.URL, "id", tc.secret, http.DefaultClient, tpls)
+			err := svc.Send(context.Background(), tc.biz, tc.args, "13800000000", "13900000000")
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				if !errors.Is(err, tc.wantErr) {
+					assert.Equal(t, tc.wantErr.Error(), err.Error())
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMessage, server.Messages())
+		})
+	}
+}
@@ -1,46 +1,135 @@
 package async
 
-//type SMSService struct {
-//	svc  sms.Service
-//	repo repository.SMSAysncReqRepository
-//}
-//
-//func NewSMSService() *SMSService {
-//	return &SMSService{}
-//}
-//
-//func (s *SMSService) StartAsync() {
-//	go func() {
-//		reqs := s.svc.查找没发出去的请求()
-//		for _, req := range reqs {
-//			// 在这发送 并且控制重试
-//			s.svc.Send(, req.biz, req.args, req.numbers...)
-//		}
-//	}()
-//}
-//
-//func (s *SMSService) Send(c context.Context, biz string, args []string, numbers ...string) error {
-//	// 首先是正常路径
-//	err := s.svc.Send(c, biz, args, numbers...)
-//	if err != nil {
-//		// 判定是不是崩溃了,需判断错误率什么的
-//
-//		if 崩溃了 {
-//			s.repo.Store()
-//		}
-//	}
-//	return nil
-//}
-
-//func (s *SMSService) needAsync() bool {
-//	// 这边是你要设计的，各种判定要不要触发异步的方案
-//	// 1. 基于响应时间的，平均响应时间
-//	// 1.1 使用绝对阈值，比如说直接发送的时候，（连续一段时间，或者连续N个请求）响应时间超了
-//	// 1.2 变化趋势，比如说当前一秒钟内的所有请求的响应时间比上一秒增长了 X%，就转异步
-//	// 2. 基于错误率：一段时间内，收到 err 的请求比率大于 X%，转异步
-//
-//	// 什么时候退出异步？
-//	// 1. 进入异步 N 分钟后
-//	// 2. 保留 1% 的流量（或者更少），继续同步发送，判定响应时间/错误率
-//	return true
-//}
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/internal/service/sms"
+	"webook/pkg/logger"
+)
+
+// Service 服务商出问题的时候，把请求存到数据库里面，由后台的 goroutine 异步发送
+type Service struct {
+	svc  sms.Service
+	repo repository.AsyncSmsRepository
+	l    logger.LoggerV1
+
+	window *slidingWindow
+	// minSamples 样本太少的时候不做判断，避免一两个失败就转异步
+	minSamples int
+	// errRateThreshold 错误率超过这个值就转异步
+	errRateThreshold float64
+	// rtThreshold 平均响应时间超过这个值就转异步
+	rtThreshold time.Duration
+	// probeRatio 异步状态下，依旧有这个比例的请求同步发送，用来探测服务商是否恢复了
+	probeRatio float64
+	retryMax   int
+	random     func() float64
+}
+
+func NewService(svc sms.Service, repo repository.AsyncSmsRepository, l logger.LoggerV1) *Service {
+	return &Service{
+		svc:              svc,
+		repo:             repo,
+		l:                l,
+		window:           newSlidingWindow(time.Minute, 1000),
+		minSamples:       10,
+		errRateThreshold: 0.3,
+		rtThreshold:      time.Second,
+		probeRatio:       0.01,
+		retryMax:         3,
+		random:           rand.Float64,
+	}
+}
+
+func (s *Service) Send(c context.Context, biz string, args []string, numbers ...string) error {
+	if s.needAsync() && s.random() >= s.probeRatio {
+		return s.store(c, biz, args, numbers)
+	}
+	start := time.Now()
+	err := s.svc.Send(c, biz, args, numbers...)
+	s.window.add(sample{at: start, rt: time.Since(start), failed: err != nil})
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	if s.needAsync() {
+		// 服务商已经出问题了，这个请求也交给异步重试
+		if er := s.store(c, biz, args, numbers); er != nil {
+			s.l.Error("短信转异步失败", logger.String("biz", biz), logger.Error(er))
+			return err
+		}
+		return nil
+	}
+	return err
+}
+
+func (s *Service) store(c context.Context, biz string, args []string, numbers []string) error {
+	return s.repo.Add(c, domain.AsyncSms{
+		Biz:      biz,
+		Args:     args,
+		Numbers:  numbers,
+		RetryMax: s.retryMax,
+	})
+}
+
+// needAsync 最近一段时间内的错误率或者平均响应时间超过阈值，就转异步
+// 不需要专门的退出机制，异步状态下的探测请求成功了，窗口里面的指标自然会降下来
+func (s *Service) needAsync() bool {
+	cnt, errRate, avgRT := s.window.stat(time.Now())
+	if cnt < s.minSamples {
+		return false
+	}
+	return errRate >= s.errRateThreshold || avgRT >= s.rtThreshold
+}
+
+// StartAsyncCycle 不断地抢占待发送的请求并发送，直到 ctx 被取消
+func (s *Service) StartAsyncCycle(ctx context.Context) {
+	for {
+		err := s.AsyncSend(ctx)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrWaitingSMSNotFound) {
+			s.l.Error("抢占异步发送短信的请求失败", logger.Error(err))
+		}
+		// 没有请求，或者数据库出问题了，都歇一会
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// AsyncSend 抢占并发送一个请求
+func (s *Service) AsyncSend(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	pctx, cancel := context.WithTimeout(ctx, time.Second)
+	as, err := s.repo.PreemptWaitingSMS(pctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	sctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	err = s.svc.Send(sctx, as.Biz, as.Args, as.Numbers...)
+	cancel()
+	if err != nil {
+		s.l.Error("异步发送短信失败",
+			logger.Int64("id", as.Id),
+			logger.Error(err))
+	}
+	rctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	err = s.repo.ReportScheduleResult(rctx, as.Id, err == nil)
+	if err != nil {
+		s.l.Error("记录异步发送短信的结果失败",
+			logger.Int64("id", as.Id),
+			logger.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+	"webook/internal/service/sms"
+	smsmocks "webook/internal/service/sms/mocks"
+	"webook/pkg/logger"
+)
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository)
+		// 预先放进窗口里面的样本
+		samples []sample
+		random  float64
+
+		wantErr error
+	}{
+		{
+			name: "服务商正常，同步发送",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").Return(nil)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			random: 0.5,
+		},
+		{
+			name: "样本不足，失败了也不转异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").
+					Return(errors.New("mock sms error"))
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			random:  0.5,
+			wantErr: errors.New("mock sms error"),
+		},
+		{
+			name: "错误率过高，转异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), domain.AsyncSms{
+					Biz:      "login",
+					Args:     []string{"123"},
+					Numbers:  []string{"138"},
+					RetryMax: 3,
+				}).Return(nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			samples: failedSamples(10),
+			random:  0.5,
+		},
+		{
+			name: "响应时间过长，转异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			samples: slowSamples(10),
+			random:  0.5,
+		},
+		{
+			name: "异步状态下的探测请求，依旧同步发送",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").Return(nil)
+				return svc, repomocks.NewMockAsyncSmsRepository(ctrl)
+			},
+			samples: failedSamples(10),
+			random:  0.001,
+		},
+		{
+			name: "探测请求失败，转存异步",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").
+					Return(errors.New("mock sms error"))
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil)
+				return svc, repo
+			},
+			samples: failedSamples(10),
+			random:  0.001,
+		},
+		{
+			name: "转异步失败",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errors.New("mock db error"))
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			samples: failedSamples(10),
+			random:  0.5,
+			wantErr: errors.New("mock db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, &logger.NoOpLogger{})
+			s.random = func() float64 {
+				return tc.random
+			}
+			for _, sp := range tc.samples {
+				s.window.add(sp)
+			}
+			err := s.Send(context.Background(), "login", []string{"123"}, "138")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestService_AsyncSend(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository)
+
+		wantErr error
+	}{
+		{
+			name: "发送成功",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any()).Return(domain.AsyncSms{
+					Id:      1,
+					Biz:     "login",
+					Args:    []string{"123"},
+					Numbers: []string{"138"},
+				}, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").Return(nil)
+				repo.EXPECT().ReportScheduleResult(gomock.Any(), int64(1), true).Return(nil)
+				return svc, repo
+			},
+		},
+		{
+			name: "发送失败，记录下来等待重试",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any()).Return(domain.AsyncSms{
+					Id:      1,
+					Biz:     "login",
+					Args:    []string{"123"},
+					Numbers: []string{"138"},
+				}, nil)
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "login", []string{"123"}, "138").
+					Return(errors.New("mock sms error"))
+				repo.EXPECT().ReportScheduleResult(gomock.Any(), int64(1), false).Return(nil)
+				return svc, repo
+			},
+		},
+		{
+			name: "没有待发送的请求",
+			mock: func(ctrl *gomock.Controller) (sms.Service, repository.AsyncSmsRepository) {
+				repo := repomocks.NewMockAsyncSmsRepository(ctrl)
+				repo.EXPECT().PreemptWaitingSMS(gomock.Any()).
+					Return(domain.AsyncSms{}, repository.ErrWaitingSMSNotFound)
+				return smsmocks.NewMockService(ctrl), repo
+			},
+			wantErr: repository.ErrWaitingSMSNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, repo := tc.mock(ctrl)
+			s := NewService(svc, repo, &logger.NoOpLogger{})
+			err := s.AsyncSend(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func failedSamples(n int) []sample {
+	res := make([]sample, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, sample{at: time.Now(), rt: time.Millisecond, failed: true})
+	}
+	return res
+}
+
+func slowSamples(n int) []sample {
+	res := make([]sample, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, sample{at: time.Now(), rt: time.Second * 2})
+	}
+	return res
+}
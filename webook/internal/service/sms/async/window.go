@@ -0,0 +1,64 @@
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+type sample struct {
+	at     time.Time
+	rt     time.Duration
+	failed bool
+}
+
+// slidingWindow 记录最近 window 时间内的同步发送结果
+// 用环形数组存，最多保留 capacity 个样本，超出的时候覆盖最老的
+type slidingWindow struct {
+	mu      sync.Mutex
+	samples []sample
+	// head 下一个写入的位置
+	head   int
+	size   int
+	window time.Duration
+}
+
+func newSlidingWindow(window time.Duration, capacity int) *slidingWindow {
+	return &slidingWindow{
+		samples: make([]sample, capacity),
+		window:  window,
+	}
+}
+
+func (w *slidingWindow) add(s sample) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.samples[w.head] = s
+	w.head = (w.head + 1) % len(w.samples)
+	if w.size < len(w.samples) {
+		w.size++
+	}
+}
+
+// stat 返回窗口内的样本数、错误率和平均响应时间
+func (w *slidingWindow) stat(now time.Time) (cnt int, errRate float64, avgRT time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	start := now.Add(-w.window)
+	var failed int
+	var total time.Duration
+	for i := 0; i < w.size; i++ {
+		s := w.samples[i]
+		if s.at.Before(start) {
+			continue
+		}
+		cnt++
+		total += s.rt
+		if s.failed {
+			failed++
+		}
+	}
+	if cnt == 0 {
+		return 0, 0, 0
+	}
+	return cnt, float64(failed) / float64(cnt), total / time.Duration(cnt)
+}
@@ -0,0 +1,42 @@
+package failover
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/aliyun"
+	"webook/internal/service/sms/aliyun/aliyuntest"
+	"webook/internal/service/sms/memory"
+)
+
+func TestFailoverService_Send(t *testing.T) {
+	server := aliyuntest.NewServer("secret")
+	defer server.Close()
+	tpls := sms.NewTemplateRegistry().
+		Register("login", aliyun.Provider, sms.Template{
+			Id:         "SMS_123",
+			Signature:  "webook",
+			ParamNames: []string{"code"},
+		})
+	backup := memory.NewService()
+	svc := NewFailoverService([]sms.Service{
+		aliyun.NewService(server.URL, "id", "secret", http.DefaultClient, tpls),
+		backup,
+	})
+
+	err := svc.Send(context.Background(), "login", []string{"123456"}, "13800000000")
+	assert.NoError(t, err)
+	assert.Len(t, server.Messages(), 1)
+	assert.Equal(t, 0, backup.Calls())
+
+	// 阿里云出问题了，切换到下一个服务商
+	server.SetCode("isp.SYSTEM_ERROR")
+	err = svc.Send(context.Background(), "login", []string{"123456"}, "13800000000")
+	assert.NoError(t, err)
+	assert.Len(t, server.Messages(), 1)
+	assert.Equal(t, 1, backup.Calls())
+}
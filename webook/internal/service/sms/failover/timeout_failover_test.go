@@ -0,0 +1,44 @@
+package failover
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/memory"
+)
+
+func TestTimeoutFailoverService_Send(t *testing.T) {
+	// 第一个服务商一直超时，第二个正常
+	slow := memory.NewFaultyService(time.Second, nil, 0)
+	normal := memory.NewService()
+	svc := NewTimeoutFailoverService([]sms.Service{slow, normal}, 2)
+
+	send := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		return svc.Send(ctx, "tpl", []string{"123"}, "138")
+	}
+	// 连续超时两次之后才会切换
+	assert.Equal(t, context.DeadlineExceeded, send())
+	assert.Equal(t, context.DeadlineExceeded, send())
+	assert.NoError(t, send())
+	assert.NoError(t, send())
+	assert.Equal(t, 2, slow.Calls())
+	assert.Equal(t, 2, normal.Calls())
+}
+
+func TestTimeoutFailoverService_SendNotTimeout(t *testing.T) {
+	// 非超时的错误不计入连续超时的次数
+	faulty := memory.NewFaultyService(0, sms.ErrTemporary, -1)
+	normal := memory.NewService()
+	svc := NewTimeoutFailoverService([]sms.Service{faulty, normal}, 1)
+	for i := 0; i < 3; i++ {
+		err := svc.Send(context.Background(), "tpl", []string{"123"}, "138")
+		assert.Equal(t, sms.ErrTemporary, err)
+	}
+	assert.Equal(t, 0, normal.Calls())
+}
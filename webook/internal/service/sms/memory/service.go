@@ -3,17 +3,50 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 )
 
 type Service struct {
+	// delay 模拟服务商的响应时间
+	delay time.Duration
+	// 前 failTimes 次调用返回 err，小于 0 代表一直返回 err
+	err       error
+	failTimes int32
+	calls     int32
 }
 
 func NewService() *Service {
 	return &Service{}
 }
 
+// NewFaultyService 模拟响应慢或者出错的服务商，用于测试各种装饰器
+func NewFaultyService(delay time.Duration, err error, failTimes int32) *Service {
+	return &Service{
+		delay:     delay,
+		err:       err,
+		failTimes: failTimes,
+	}
+}
+
 // Send 模拟发短信的过程 为了测试
-func (s Service) Send(c context.Context, tplId string, args []string, numbers ...string) error {
+func (s *Service) Send(c context.Context, tplId string, args []string, numbers ...string) error {
+	calls := atomic.AddInt32(&s.calls, 1)
+	if s.delay > 0 {
+		select {
+		case <-c.Done():
+			return c.Err()
+		case <-time.After(s.delay):
+		}
+	}
+	if s.err != nil && (s.failTimes < 0 || calls <= s.failTimes) {
+		return s.err
+	}
 	fmt.Println(args)
 	return nil
 }
+
+// Calls 被调用的次数
+func (s *Service) Calls() int {
+	return int(atomic.LoadInt32(&s.calls))
+}
@@ -2,25 +2,43 @@ package retryable
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"github.com/ecodeclub/ekit/retry"
+	"time"
 	"webook/internal/service/sms"
 )
 
 type Service struct {
-	svc      sms.Service
-	retryMax int
+	svc sms.Service
+	// newStrategy 重试策略是有状态的，所以每次发送都要创建一个新的
+	newStrategy func() retry.Strategy
 }
 
-// Send 重试机制
-func (s Service) Send(c context.Context, biz string, args []string, numbers ...string) error {
-	err := s.svc.Send(c, biz, args, numbers...)
-	cnt := 1
-	for err != nil && cnt < s.retryMax {
-		err = s.svc.Send(c, biz, args, numbers...)
-		if err == nil {
-			return nil
+func NewService(svc sms.Service, newStrategy func() retry.Strategy) sms.Service {
+	return &Service{
+		svc:         svc,
+		newStrategy: newStrategy,
+	}
+}
+
+// Send 重试机制，只重试 sms.ErrTemporary
+func (s *Service) Send(c context.Context, biz string, args []string, numbers ...string) error {
+	strategy := s.newStrategy()
+	for {
+		err := s.svc.Send(c, biz, args, numbers...)
+		if err == nil || !errors.Is(err, sms.ErrTemporary) {
+			return err
+		}
+		interval, ok := strategy.Next()
+		if !ok {
+			return err
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-c.Done():
+			timer.Stop()
+			return c.Err()
+		case <-timer.C:
 		}
-		cnt++
 	}
-	return fmt.Errorf("retry failed")
 }
@@ -0,0 +1,75 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ecodeclub/ekit/retry"
+	"github.com/stretchr/testify/assert"
+
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/memory"
+)
+
+func TestService_Send(t *testing.T) {
+	temporaryErr := fmt.Errorf("被限流了 %w", sms.ErrTemporary)
+	testCases := []struct {
+		name string
+		svc  *memory.Service
+
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "一次成功",
+			svc:       memory.NewService(),
+			wantCalls: 1,
+		},
+		{
+			name:      "重试之后成功",
+			svc:       memory.NewFaultyService(0, temporaryErr, 2),
+			wantCalls: 3,
+		},
+		{
+			name:      "超过重试次数",
+			svc:       memory.NewFaultyService(0, temporaryErr, -1),
+			wantErr:   temporaryErr,
+			wantCalls: 4,
+		},
+		{
+			name:      "不可以重试的错误",
+			svc:       memory.NewFaultyService(0, errors.New("模板不存在"), -1),
+			wantErr:   errors.New("模板不存在"),
+			wantCalls: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(tc.svc, func() retry.Strategy {
+				s, err := retry.NewFixedIntervalRetryStrategy(time.Millisecond, 3)
+				assert.NoError(t, err)
+				return s
+			})
+			err := svc.Send(context.Background(), "tpl", []string{"123"}, "138")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCalls, tc.svc.Calls())
+		})
+	}
+}
+
+func TestService_SendCanceled(t *testing.T) {
+	m := memory.NewFaultyService(0, sms.ErrTemporary, -1)
+	svc := NewService(m, func() retry.Strategy {
+		s, _ := retry.NewFixedIntervalRetryStrategy(time.Hour, 3)
+		return s
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	// 等待重试的时候超时了，要立刻返回
+	err := svc.Send(ctx, "tpl", []string{"123"}, "138")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, m.Calls())
+}
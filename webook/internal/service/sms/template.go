@@ -0,0 +1,49 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTemplateNotFound = errors.New("短信模板不存在")
+
+// Template 某个服务商上的短信模板
+type Template struct {
+	Id        string
+	Signature string
+	// ParamNames 按顺序对应 Send 的 args，腾讯云是按位置传参的，不需要
+	// 阿里云的模板参数是 JSON 对象，需要参数名
+	ParamNames []string
+}
+
+// TemplateRegistry 维护业务到各个服务商模板的映射
+// 业务方只需要知道自己的 biz，例如 "login"，切换服务商的时候不需要改业务代码
+// 只在初始化的时候注册，之后只读，所以不需要加锁
+type TemplateRegistry struct {
+	// biz => provider => template
+	tpls map[string]map[string]Template
+}
+
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{
+		tpls: make(map[string]map[string]Template),
+	}
+}
+
+func (r *TemplateRegistry) Register(biz, provider string, tpl Template) *TemplateRegistry {
+	providers, ok := r.tpls[biz]
+	if !ok {
+		providers = make(map[string]Template)
+		r.tpls[biz] = providers
+	}
+	providers[provider] = tpl
+	return r
+}
+
+func (r *TemplateRegistry) Get(biz, provider string) (Template, error) {
+	tpl, ok := r.tpls[biz][provider]
+	if !ok {
+		return Template{}, fmt.Errorf("%w, biz: %s, provider: %s", ErrTemplateNotFound, biz, provider)
+	}
+	return tpl, nil
+}
@@ -2,34 +2,56 @@ package tencent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
+	"webook/internal/service/sms"
 
 	"github.com/ecodeclub/ekit"
 	"github.com/ecodeclub/ekit/slice"
-	sms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	tcerr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	tsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
 )
 
+// Provider 在模板注册中心里面的名字
+const Provider = "tencent"
+
+// temporaryCodes 可以稍后重试的错误码
+// SDK 把网络错误和超时也包装成了 ClientError.NetworkError，只能按照错误码来判断
+var temporaryCodes = map[string]struct{}{
+	"ClientError.NetworkError":             {},
+	"RequestLimitExceeded":                 {},
+	"InternalError":                        {},
+	"InternalError.Timeout":                {},
+	"InternalError.SendAndRecvFail":        {},
+	"LimitExceeded.DeliveryFrequencyLimit": {},
+}
+
 type Service struct {
-	appId     *string // 取指针 是因为腾讯云SMS的设计要求
-	signature *string
-	client    *sms.Client
+	appId  *string // 取指针 是因为腾讯云SMS的设计要求
+	client *tsms.Client
+	tpls   *sms.TemplateRegistry
 }
 
-func NewService(client *sms.Client, appId string, signature string) *Service {
+func NewService(client *tsms.Client, appId string, tpls *sms.TemplateRegistry) *Service {
 	return &Service{
-		client:    client,
-		appId:     ekit.ToPtr[string](appId),
-		signature: ekit.ToPtr[string](signature),
+		client: client,
+		appId:  ekit.ToPtr[string](appId),
+		tpls:   tpls,
 	}
 }
 
-// Send biz 代表的是tplId
+// Send biz 是业务，模板 ID 和签名从注册中心里面取
 func (s *Service) Send(c context.Context, biz string, args []string, numbers ...string) error {
-	req := sms.NewSendSmsRequest()
+	tpl, err := s.tpls.Get(biz, Provider)
+	if err != nil {
+		return err
+	}
+	req := tsms.NewSendSmsRequest()
+	req.SetContext(c)
 	req.SmsSdkAppId = s.appId
-	req.SignName = s.signature
-	req.TemplateId = ekit.ToPtr[string](biz)
+	req.SignName = ekit.ToPtr[string](tpl.Signature)
+	req.TemplateId = ekit.ToPtr[string](tpl.Id)
 	req.PhoneNumberSet = s.toStringPtrSlice(numbers)
 	req.TemplateParamSet = s.toStringPtrSlice(args)
 	resp, err := s.client.SendSms(req)
@@ -37,18 +59,39 @@ func (s *Service) Send(c context.Context, biz string, args []string, numbers ...
 		zap.Any("req", req),
 		zap.Any("resp", resp))
 	if err != nil {
-		return fmt.Errorf("error.%w\n", err)
+		var sdkErr *tcerr.TencentCloudSDKError
+		if errors.As(err, &sdkErr) && isTemporary(sdkErr.Code) {
+			return fmt.Errorf("%w, %w", sms.ErrTemporary, err)
+		}
+		return fmt.Errorf("调用腾讯短信服务失败 %w", err)
 	}
 	for _, status := range resp.Response.SendStatusSet {
-		if status.Code == nil || *(status.Code) == "Ok" {
-			return fmt.Errorf("send message failed, code:%s, message:%s", *status.Code, *status.Message)
+		if status.Code == nil || *(status.Code) != "Ok" {
+			code := s.deref(status.Code)
+			if isTemporary(code) {
+				return fmt.Errorf("%w, code: %s, message: %s", sms.ErrTemporary, code, s.deref(status.Message))
+			}
+			return fmt.Errorf("send message failed, code:%s, message:%s",
+				code, s.deref(status.Message))
 		}
 	}
 	return nil
 }
 
+func isTemporary(code string) bool {
+	_, ok := temporaryCodes[code]
+	return ok
+}
+
 func (s *Service) toStringPtrSlice(src []string) []*string {
 	return slice.Map[string, *string](src, func(idx int, src string) *string {
 		return &src
 	})
 }
+
+func (s *Service) deref(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
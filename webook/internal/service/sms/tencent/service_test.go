@@ -0,0 +1,96 @@
+package tencent_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+
+	"webook/internal/service/sms"
+	"webook/internal/service/sms/tencent"
+)
+
+func TestService_Send(t *testing.T) {
+	tpls := sms.NewTemplateRegistry().
+		Register("login", tencent.Provider, sms.Template{
+			Id:         "123",
+			Signature:  "webook",
+			ParamNames: []string{"code"},
+		})
+	testCases := []struct {
+		name string
+		// resp 服务端返回的内容，为空的时候直接关掉服务端，模拟网络错误
+		resp string
+
+		wantErr error
+	}{
+		{
+			name: "发送成功",
+			resp: `{"Response":{"SendStatusSet":[{"Code":"Ok","Message":"send success"}],"RequestId":"1"}}`,
+		},
+		{
+			name:    "网络错误，可以重试",
+			wantErr: sms.ErrTemporary,
+		},
+		{
+			name:    "被限流了，可以重试",
+			resp:    `{"Response":{"Error":{"Code":"RequestLimitExceeded","Message":"limit"},"RequestId":"1"}}`,
+			wantErr: sms.ErrTemporary,
+		},
+		{
+			name:    "服务商内部超时，可以重试",
+			resp:    `{"Response":{"Error":{"Code":"InternalError.Timeout","Message":"timeout"},"RequestId":"1"}}`,
+			wantErr: sms.ErrTemporary,
+		},
+		{
+			name:    "号码命中了频率限制，可以重试",
+			resp:    `{"Response":{"SendStatusSet":[{"Code":"LimitExceeded.DeliveryFrequencyLimit","Message":"limit"}],"RequestId":"1"}}`,
+			wantErr: sms.ErrTemporary,
+		},
+		{
+			name:    "签名错误",
+			resp:    `{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"signature"},"RequestId":"1"}}`,
+			wantErr: errors.New("调用腾讯短信服务失败 [TencentCloudSDKError] Code=AuthFailure.SignatureFailure, Message=signature, RequestId=1"),
+		},
+		{
+			name:    "号码不对",
+			resp:    `{"Response":{"SendStatusSet":[{"Code":"InvalidParameterValue.IncorrectPhoneNumber","Message":"phone"}],"RequestId":"1"}}`,
+			wantErr: errors.New("send message failed, code:InvalidParameterValue.IncorrectPhoneNumber, message:phone"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.resp))
+			}))
+			defer server.Close()
+			prof := profile.NewClientProfile()
+			prof.HttpProfile.Scheme = "HTTP"
+			prof.HttpProfile.Endpoint = strings.TrimPrefix(server.URL, "http://")
+			client, err := tsms.NewClient(common.NewCredential("id", "key"), "ap-guangzhou", prof)
+			require.NoError(t, err)
+			if tc.resp == "" {
+				server.Close()
+			}
+			svc := tencent.NewService(client, "app", tpls)
+			err = svc.Send(context.Background(), "login", []string{"123456"}, "+8613800000000")
+			if tc.wantErr != nil {
+				require.Error(t, err)
+				if !errors.Is(err, tc.wantErr) {
+					assert.Equal(t, tc.wantErr.Error(), err.Error())
+				}
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -1,6 +1,14 @@
 package sms
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrTemporary 这次没有发出去，并且稍后可以重试，例如被服务商限流了、网络错误或者超时
+// 各个服务商的实现要把这一类错误包装成 ErrTemporary，装饰器只会重试这一类错误
+// 超时的时候短信可能已经发出去了，重试会让用户偶尔收到两条，但是总比一条都收不到好
+var ErrTemporary = errors.New("短信服务暂时不可用")
 
 // Service 发送短信的抽象
 // 目前你可以理解为，这是一个为了适配不同的短信供应商的抽象
@@ -0,0 +1,670 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/frontmatter"
+	"webook/pkg/logger"
+)
+
+var (
+	ErrTransferNotFound = repository.ErrTransferNotFound
+	// ErrExportNotFound 导出的文件已经被清理掉了
+	ErrExportNotFound = repository.ErrExportNotFound
+	// ErrTransferNotReady 导出还没有完成，或者已经失败了
+	ErrTransferNotReady = errors.New("导出还没有完成")
+	// ErrTransferBusy 上一次导入还没有结束
+	ErrTransferBusy = errors.New("已经有正在进行的导入")
+	// ErrInvalidImportFile 不是 zip 或者 Markdown 文件，文件太大，或者文章太多
+	ErrInvalidImportFile = errors.New("导入的文件不对")
+)
+
+const (
+	// MaxImportSize 上传的 zip 或者 Markdown 文件的大小
+	MaxImportSize = 20 << 20
+	// maxImportEntries zip 里面最多的文件数量，包括图片
+	maxImportEntries = 2000
+	// maxImportArticles 一次最多导入的文章数量
+	maxImportArticles = 500
+	// maxImportArticleSize 解压之后单篇文章的大小，图片的大小由 ImageService 限制
+	maxImportArticleSize = 1 << 20
+	maxImportTitleLen    = 256
+	// syncImportArticles 文章不多的时候直接导入完再返回，不用再查询进度
+	syncImportArticles = 5
+
+	exportPageSize = 50
+	// maxExportArticles 防止异常数据导致导出一直进行下去
+	maxExportArticles = 10000
+
+	// transferTimeout 后台任务最长的执行时间
+	transferTimeout = 30 * time.Minute
+	// transferStaleAfter 超过这么久没有进展的任务当作已经中断了，比如执行的时候重启了
+	transferStaleAfter = 10 * time.Minute
+	// maxTransferMsgLen 和数据库的字段长度保持一致，按字节计算
+	maxTransferMsgLen = 1024
+)
+
+// imageLinkPattern Markdown 里面的图片，第一个分组是图片的地址
+var imageLinkPattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^\s)>]+)>?`)
+
+var articleStatusNames = map[domain.ArticleStatus]string{
+	domain.ArticleStatusUnpublished: "draft",
+	domain.ArticleStatusPublished:   "published",
+	domain.ArticleStatusPrivate:     "private",
+	domain.ArticleStatusScheduled:   "scheduled",
+}
+
+// ArticleTransferService 导出和导入作者的文章
+// 导出的 zip 里面每篇文章是 articles/<id>.md，线上的版本和草稿不一样的时候还有 articles/<id>.published.md，
+// 文章里面我们自己存储的图片放在 images 下面，文章里面的地址改成相对路径
+type ArticleTransferService interface {
+	// StartExport 在后台导出所有的文章，已经有正在进行的导出的时候返回那个任务
+	StartExport(ctx context.Context, uid int64) (domain.ArticleTransfer, error)
+	// StartImport 检查文件之后开始导入，文章全部保存成草稿
+	// 文章不多的时候直接导入完再返回，否则在后台导入，通过 Get 查询进度
+	StartImport(ctx context.Context, uid int64, filename string, data []byte) (domain.ArticleTransfer, error)
+	Get(ctx context.Context, uid, id int64) (domain.ArticleTransfer, error)
+	// Download 导出的 zip
+	Download(ctx context.Context, uid, id int64) ([]byte, error)
+	// FailStale 把很久没有进展的任务标记为失败，一般是执行的时候重启了，给定时任务用
+	FailStale(ctx context.Context, now time.Time) (int64, error)
+	// PurgeExpired 删除结束超过 domain.TransferRetention 的任务和导出的文件，返回这一批找到的任务数量，给定时任务用
+	PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type articleTransferService struct {
+	repo     repository.ArticleTransferRepository
+	artSvc   ArticleService
+	imageSvc ImageService
+	l        logger.LoggerV1
+}
+
+func NewArticleTransferService(repo repository.ArticleTransferRepository, artSvc ArticleService,
+	imageSvc ImageService, l logger.LoggerV1) ArticleTransferService {
+	return &articleTransferService{
+		repo:     repo,
+		artSvc:   artSvc,
+		imageSvc: imageSvc,
+		l:        l,
+	}
+}
+
+func (svc *articleTransferService) StartExport(ctx context.Context, uid int64) (domain.ArticleTransfer, error) {
+	since := time.Now().Add(-transferStaleAfter)
+	t, err := svc.repo.Create(ctx, domain.ArticleTransfer{
+		Uid:    uid,
+		Kind:   domain.TransferKindExport,
+		Status: domain.TransferStatusPending,
+	}, since)
+	if errors.Is(err, repository.ErrTransferDuplicate) {
+		return svc.repo.FindUnfinished(ctx, uid, domain.TransferKindExport, since)
+	}
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	go func(t domain.ArticleTransfer) {
+		ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+		defer cancel()
+		svc.finish(ctx, &t, svc.export(ctx, &t))
+	}(t)
+	return t, nil
+}
+
+func (svc *articleTransferService) StartImport(ctx context.Context, uid int64,
+	filename string, data []byte) (domain.ArticleTransfer, error) {
+	if len(data) > MaxImportSize {
+		return domain.ArticleTransfer{}, ErrInvalidImportFile
+	}
+	b, err := newImportBundle(filename, data)
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	t, err := svc.repo.Create(ctx, domain.ArticleTransfer{
+		Uid:    uid,
+		Kind:   domain.TransferKindImport,
+		Status: domain.TransferStatusPending,
+		Total:  len(b.articles),
+	}, time.Now().Add(-transferStaleAfter))
+	if errors.Is(err, repository.ErrTransferDuplicate) {
+		return domain.ArticleTransfer{}, ErrTransferBusy
+	}
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	if len(b.articles) <= syncImportArticles {
+		svc.finish(ctx, &t, svc.importArticles(ctx, &t, b))
+		return t, nil
+	}
+	go func(t domain.ArticleTransfer) {
+		ctx, cancel := context.WithTimeout(context.Background(), transferTimeout)
+		defer cancel()
+		svc.finish(ctx, &t, svc.importArticles(ctx, &t, b))
+	}(t)
+	return t, nil
+}
+
+func (svc *articleTransferService) Get(ctx context.Context, uid, id int64) (domain.ArticleTransfer, error) {
+	t, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.ArticleTransfer{}, err
+	}
+	if t.Uid != uid {
+		return domain.ArticleTransfer{}, ErrTransferNotFound
+	}
+	return t, nil
+}
+
+func (svc *articleTransferService) Download(ctx context.Context, uid, id int64) ([]byte, error) {
+	t, err := svc.Get(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind != domain.TransferKindExport || t.Status != domain.TransferStatusDone {
+		return nil, ErrTransferNotReady
+	}
+	return svc.repo.GetExport(ctx, t)
+}
+
+func (svc *articleTransferService) FailStale(ctx context.Context, now time.Time) (int64, error) {
+	return svc.repo.FailStale(ctx, now.Add(-transferStaleAfter))
+}
+
+func (svc *articleTransferService) PurgeExpired(ctx context.Context, now time.Time, limit int) (int, error) {
+	ts, err := svc.repo.ListFinished(ctx, now.Add(-domain.TransferRetention), limit)
+	if err != nil {
+		return 0, err
+	}
+	var lastErr error
+	for _, t := range ts {
+		if err = svc.repo.Purge(ctx, t); err != nil {
+			// 一个失败不影响别的任务，下一次定时任务会再次尝试
+			svc.l.Error("清理导出导入的任务失败",
+				logger.Int64("tid", t.Id), logger.Error(err))
+			lastErr = err
+		}
+	}
+	return len(ts), lastErr
+}
+
+// finish 记录任务的最终状态，err 是整个任务失败的原因，单篇文章失败不算
+func (svc *articleTransferService) finish(ctx context.Context, t *domain.ArticleTransfer, err error) {
+	t.Status = domain.TransferStatusDone
+	if err != nil {
+		svc.l.Error("导出导入文章失败", logger.Int64("tid", t.Id),
+			logger.Int64("uid", t.Uid), logger.Error(err))
+		t.Status = domain.TransferStatusFailed
+		t.Msg = truncateMsg(err.Error())
+	}
+	if er := svc.repo.Finish(ctx, *t); er != nil {
+		svc.l.Error("记录导出导入的结果失败", logger.Int64("tid", t.Id), logger.Error(er))
+	}
+}
+
+// progress 进度只是给用户看的，失败了不影响任务本身
+func (svc *articleTransferService) progress(ctx context.Context, t *domain.ArticleTransfer) {
+	t.Status = domain.TransferStatusRunning
+	if err := svc.repo.UpdateProgress(ctx, *t); err != nil {
+		svc.l.Warn("更新导出导入的进度失败", logger.Int64("tid", t.Id), logger.Error(err))
+	}
+}
+
+func (svc *articleTransferService) export(ctx context.Context, t *domain.ArticleTransfer) error {
+	ids, err := svc.exportIds(ctx, t.Uid)
+	if err != nil {
+		return err
+	}
+	t.Total = len(ids)
+	svc.progress(ctx, t)
+
+	// 文章和图片可能很多，先写到临时文件里面，不放在内存
+	f, err := os.CreateTemp("", "webook-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	w := &exportWriter{zw: zip.NewWriter(f), images: map[string]bool{}}
+	var failures []string
+	for _, id := range ids {
+		if err = svc.exportArticle(ctx, w, t.Uid, id); err != nil {
+			// 单篇文章失败了不影响别的文章
+			svc.l.Warn("导出文章失败", logger.Int64("tid", t.Id),
+				logger.Int64("aid", id), logger.Error(err))
+			t.Failed++
+			failures = append(failures, fmt.Sprintf("%d: %s", id, err.Error()))
+		} else {
+			t.Done++
+		}
+		svc.progress(ctx, t)
+	}
+	if err = w.zw.Close(); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err = svc.repo.SaveExport(ctx, *t, f); err != nil {
+		return err
+	}
+	t.Msg = truncateMsg(strings.Join(failures, "; "))
+	return nil
+}
+
+// exportIds 先拿到所有文章的 ID，导出的过程中新建的文章不会包含进去
+// 按照 id 翻页，翻页的过程中有文章更新也不会漏掉或者重复
+func (svc *articleTransferService) exportIds(ctx context.Context, uid int64) ([]int64, error) {
+	var ids []int64
+	var after int64
+	for len(ids) < maxExportArticles {
+		page, err := svc.artSvc.ListIds(ctx, uid, after, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+		after = page[len(page)-1]
+	}
+	return ids, nil
+}
+
+func (svc *articleTransferService) exportArticle(ctx context.Context, w *exportWriter, uid, id int64) error {
+	// 列表里面只有摘要，要重新查一次完整的内容
+	art, err := svc.artSvc.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if art.Author.Id != uid {
+		return ErrPossibleIncorrectAuthor
+	}
+	live, err := svc.artSvc.LiveRevision(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	var pub domain.ArticleRevision
+	// 线上的版本和草稿一样的时候不用再导出一份
+	if live > 0 && live != art.Revision {
+		pub, err = svc.artSvc.GetRevision(ctx, uid, id, live)
+		if err != nil {
+			return err
+		}
+	}
+
+	meta := articleMeta{
+		Title:     art.Title,
+		Id:        art.Id,
+		Status:    articleStatusNames[art.Status],
+		Revision:  art.Revision,
+		Category:  art.Category,
+		Tags:      art.Tags,
+		Cover:     svc.exportImage(ctx, w, art.Cover),
+		PublishAt: art.PublishAt,
+		Created:   art.Ctime,
+		Updated:   art.Utime,
+	}
+	content := replaceImageLinks(art.Content, func(link string) string {
+		return svc.exportImage(ctx, w, link)
+	})
+	if err = w.writeMarkdown(fmt.Sprintf("articles/%d.md", id), meta, content); err != nil {
+		return err
+	}
+	if pub.Revision == 0 {
+		return nil
+	}
+	meta.Title = pub.Title
+	meta.Status = articleStatusNames[domain.ArticleStatusPublished]
+	meta.Revision = pub.Revision
+	meta.Updated = pub.Ctime
+	// 历史版本只记录了标题和内容，分类、标签和封面用的是草稿的
+	content = replaceImageLinks(pub.Content, func(link string) string {
+		return svc.exportImage(ctx, w, link)
+	})
+	return w.writeMarkdown(fmt.Sprintf("articles/%d.published.md", id), meta, content)
+}
+
+// exportImage 把我们自己存储的图片放进 zip，返回文章里面应该使用的地址
+// 外部的图片和读取失败的图片保持原来的地址
+func (svc *articleTransferService) exportImage(ctx context.Context, w *exportWriter, link string) string {
+	key, ok := svc.imageSvc.KeyFromURL(link)
+	if !ok {
+		return link
+	}
+	if !w.images[key] {
+		data, _, err := svc.imageSvc.GetObject(ctx, key)
+		if err != nil {
+			svc.l.Warn("导出图片失败", logger.String("key", key), logger.Error(err))
+			return link
+		}
+		if err = w.write(key, data); err != nil {
+			svc.l.Warn("导出图片失败", logger.String("key", key), logger.Error(err))
+			return link
+		}
+		w.images[key] = true
+	}
+	// 文章在 articles 下面，图片的 key 以 images/ 开头
+	return "../" + key
+}
+
+func (svc *articleTransferService) importArticles(ctx context.Context,
+	t *domain.ArticleTransfer, b *importBundle) error {
+	svc.progress(ctx, t)
+	uploaded := map[importImageKey]string{}
+	var failures []string
+	for _, f := range b.articles {
+		if err := svc.importArticle(ctx, t.Uid, b, f, uploaded); err != nil {
+			svc.l.Warn("导入文章失败", logger.Int64("tid", t.Id),
+				logger.String("file", f.name), logger.Error(err))
+			t.Failed++
+			failures = append(failures, fmt.Sprintf("%s: %s", f.name, err.Error()))
+		} else {
+			t.Done++
+		}
+		svc.progress(ctx, t)
+	}
+	t.Msg = truncateMsg(strings.Join(failures, "; "))
+	return nil
+}
+
+func (svc *articleTransferService) importArticle(ctx context.Context, uid int64, b *importBundle,
+	f importFile, uploaded map[importImageKey]string) error {
+	data, err := f.read()
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(data) {
+		return ErrInvalidImportFile
+	}
+	var meta importMeta
+	body, err := frontmatter.Unmarshal(data, &meta)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+	dir := path.Dir(f.name)
+	body = replaceImageLinks(body, func(link string) string {
+		return svc.importImage(ctx, uid, b, dir, link, domain.ImageUsageContent, uploaded)
+	})
+	cover := strings.TrimSpace(meta.Cover)
+	if relativeLink(cover) {
+		cover = svc.importImage(ctx, uid, b, dir, cover, domain.ImageUsageCover, uploaded)
+		// 找不到的相对路径不是合法的封面，直接去掉
+		if relativeLink(cover) {
+			cover = ""
+		}
+	}
+	_, err = svc.artSvc.Save(ctx, domain.Article{
+		Title:    importTitle(meta.Title, body, f.name),
+		Content:  body,
+		Author:   domain.Author{Id: uid},
+		Category: meta.Category,
+		Tags:     meta.Tags,
+		Cover:    cover,
+	})
+	return err
+}
+
+// importImage 上传 zip 里面的图片，返回上传之后的地址
+// 不是相对路径，或者找不到、上传失败的图片保持原来的地址
+func (svc *articleTransferService) importImage(ctx context.Context, uid int64, b *importBundle, dir, link string,
+	usage domain.ImageUsage, uploaded map[importImageKey]string) string {
+	if !relativeLink(link) {
+		return link
+	}
+	p, err := url.PathUnescape(link)
+	if err != nil {
+		return link
+	}
+	p = path.Join(dir, p)
+	// 同一张图片不同的用途限制不一样，要分开上传
+	key := importImageKey{path: p, usage: usage}
+	if res, ok := uploaded[key]; ok {
+		return res
+	}
+	zf, ok := b.files[p]
+	if !ok {
+		return link
+	}
+	data, err := readZipFile(zf, maxImageSize)
+	if err == nil {
+		var img domain.Image
+		img, err = svc.imageSvc.Upload(ctx, uid, data, usage)
+		if err == nil {
+			uploaded[key] = img.URL
+			return img.URL
+		}
+	}
+	svc.l.Warn("导入图片失败", logger.Int64("uid", uid),
+		logger.String("file", p), logger.Error(err))
+	// 失败了也记下来，避免同一张图片反复尝试
+	uploaded[key] = link
+	return link
+}
+
+// articleMeta 导出的 Markdown 文件的 front matter
+type articleMeta struct {
+	Title     string    `yaml:"title"`
+	Id        int64     `yaml:"id,omitempty"`
+	Status    string    `yaml:"status,omitempty"`
+	Revision  int64     `yaml:"revision,omitempty"`
+	Category  string    `yaml:"category,omitempty"`
+	Tags      []string  `yaml:"tags,omitempty"`
+	Cover     string    `yaml:"cover,omitempty"`
+	PublishAt time.Time `yaml:"publish_at,omitempty"`
+	Created   time.Time `yaml:"created,omitempty"`
+	Updated   time.Time `yaml:"updated,omitempty"`
+}
+
+// importMeta 导入的时候只关心这几个字段，别的平台导出的文件里面其它字段都忽略掉
+type importMeta struct {
+	Title    string   `yaml:"title"`
+	Category string   `yaml:"category"`
+	Tags     []string `yaml:"tags"`
+	Cover    string   `yaml:"cover"`
+}
+
+type exportWriter struct {
+	zw *zip.Writer
+	// images 已经写进 zip 的图片
+	images map[string]bool
+}
+
+func (w *exportWriter) writeMarkdown(name string, meta articleMeta, content string) error {
+	data, err := frontmatter.Marshal(meta, content)
+	if err != nil {
+		return err
+	}
+	return w.write(name, data)
+}
+
+func (w *exportWriter) write(name string, data []byte) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+type importFile struct {
+	name string
+	read func() ([]byte, error)
+}
+
+type importImageKey struct {
+	path  string
+	usage domain.ImageUsage
+}
+
+// importBundle 要导入的文章，以及 zip 里面可以被文章引用的文件
+type importBundle struct {
+	articles []importFile
+	// files 只有 zip 才有，key 是清理过的路径
+	files map[string]*zip.File
+}
+
+func newImportBundle(filename string, data []byte) (*importBundle, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".md", ".markdown":
+		return &importBundle{
+			articles: []importFile{{
+				name: path.Base(filename),
+				read: func() ([]byte, error) {
+					if len(data) > maxImportArticleSize {
+						return nil, ErrInvalidImportFile
+					}
+					return data, nil
+				},
+			}},
+		}, nil
+	case ".zip":
+		return newZipImportBundle(data)
+	default:
+		return nil, ErrInvalidImportFile
+	}
+}
+
+func newZipImportBundle(data []byte) (*importBundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+	}
+	if len(zr.File) > maxImportEntries {
+		return nil, ErrInvalidImportFile
+	}
+	b := &importBundle{files: make(map[string]*zip.File, len(zr.File))}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		name := path.Clean(strings.ReplaceAll(zf.Name, "\\", "/"))
+		// macOS 打包的时候会带上这些
+		if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		b.files[name] = zf
+		if !importable(name) {
+			continue
+		}
+		b.articles = append(b.articles, importFile{
+			name: name,
+			read: func() ([]byte, error) {
+				return readZipFile(zf, maxImportArticleSize)
+			},
+		})
+	}
+	if len(b.articles) == 0 || len(b.articles) > maxImportArticles {
+		return nil, ErrInvalidImportFile
+	}
+	// 按照文件名的顺序导入，同一个文件重新导入的结果是一样的
+	sort.Slice(b.articles, func(i, j int) bool {
+		return b.articles[i].name < b.articles[j].name
+	})
+	return b, nil
+}
+
+// importable 线上版本是我们自己导出的文件里面的副本，只导入草稿
+func importable(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".published.md") {
+		return false
+	}
+	return strings.HasSuffix(lower, ".md") || strings.HasSuffix(lower, ".markdown")
+}
+
+// readZipFile 不相信 zip 里面记录的大小，读的时候也要限制
+func readZipFile(zf *zip.File, limit int) ([]byte, error) {
+	if zf.UncompressedSize64 > uint64(limit) {
+		return nil, ErrInvalidImportFile
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, ErrInvalidImportFile
+	}
+	return data, nil
+}
+
+// importTitle 依次使用 front matter 的标题、第一个一级标题和文件名
+func importTitle(title, body, filename string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		for _, line := range strings.Split(body, "\n") {
+			if h, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+				title = strings.TrimSpace(h)
+				break
+			}
+		}
+	}
+	if title == "" {
+		base := path.Base(filename)
+		title = strings.TrimSuffix(base, path.Ext(base))
+	}
+	if utf8.RuneCountInString(title) > maxImportTitleLen {
+		title = string([]rune(title)[:maxImportTitleLen])
+	}
+	return title
+}
+
+// relativeLink 指向导入文件里面其它文件的链接
+func relativeLink(link string) bool {
+	if link == "" || strings.HasPrefix(link, "/") || strings.HasPrefix(link, "#") {
+		return false
+	}
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// replaceImageLinks 用 fn 的结果替换 Markdown 里面所有图片的地址
+func replaceImageLinks(content string, fn func(link string) string) string {
+	matches := imageLinkPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+	var sb strings.Builder
+	sb.Grow(len(content))
+	last := 0
+	for _, m := range matches {
+		start, end := m[2], m[3]
+		sb.WriteString(content[last:start])
+		sb.WriteString(fn(content[start:end]))
+		last = end
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// truncateMsg 截断的时候不能把一个字符切成两半
+func truncateMsg(msg string) string {
+	if len(msg) <= maxTransferMsgLen {
+		return msg
+	}
+	const ellipsis = "..."
+	end := maxTransferMsgLen - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(msg[end]) {
+		end--
+	}
+	return msg[:end] + ellipsis
+}
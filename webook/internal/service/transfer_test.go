@@ -0,0 +1,283 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repomocks "webook/internal/repository/mocks"
+	svcmocks "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_articleTransferService_StartImport(t *testing.T) {
+	pic, cover := []byte("pic"), []byte("cover")
+	data := zipFiles(t, map[string]string{
+		"blog/a.md": "---\ntitle: 第一篇\ncategory: Go\ntags: [并发]\ncover: img/cover.png\n---\n" +
+			"![图](img/pic.png) ![外部](https://example.com/x.png) ![不存在](img/none.png)",
+		"blog/b.md":           "# 第二篇\n正文",
+		"blog/b.published.md": "线上的版本不导入",
+		"blog/img/pic.png":    string(pic),
+		"blog/img/cover.png":  string(cover),
+		"__MACOSX/blog/a.md":  "",
+	})
+
+	testCases := []struct {
+		name     string
+		filename string
+		data     []byte
+		mock     func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService)
+
+		wantTransfer domain.ArticleTransfer
+		wantErr      error
+	}{
+		{
+			name:     "zip 里面的图片上传之后替换地址",
+			filename: "blog.zip",
+			data:     data,
+			mock: func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService) {
+				repo := repomocks.NewMockArticleTransferRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.ArticleTransfer{Uid: 123, Kind: domain.TransferKindImport,
+					Status: domain.TransferStatusPending, Total: 2}, gomock.Any()).
+					Return(domain.ArticleTransfer{Id: 1, Uid: 123, Kind: domain.TransferKindImport, Total: 2}, nil)
+				repo.EXPECT().UpdateProgress(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+				imageSvc := svcmocks.NewMockImageService(ctrl)
+				imageSvc.EXPECT().Upload(gomock.Any(), int64(123), pic, domain.ImageUsageContent).
+					Return(domain.Image{URL: "/images/raw/images/aa/pic.png"}, nil)
+				imageSvc.EXPECT().Upload(gomock.Any(), int64(123), cover, domain.ImageUsageCover).
+					Return(domain.Image{URL: "/images/raw/images/bb/cover.png"}, nil)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().Save(gomock.Any(), domain.Article{
+					Title: "第一篇",
+					Content: "![图](/images/raw/images/aa/pic.png) ![外部](https://example.com/x.png) " +
+						"![不存在](img/none.png)",
+					Author:   domain.Author{Id: 123},
+					Category: "Go",
+					Tags:     []string{"并发"},
+					Cover:    "/images/raw/images/bb/cover.png",
+				}).Return(int64(11), nil)
+				// 没有 front matter 的时候用第一个标题
+				artSvc.EXPECT().Save(gomock.Any(), domain.Article{
+					Title:   "第二篇",
+					Content: "# 第二篇\n正文",
+					Author:  domain.Author{Id: 123},
+				}).Return(int64(0), ErrInvalidTags)
+				repo.EXPECT().Finish(gomock.Any(), domain.ArticleTransfer{Id: 1, Uid: 123,
+					Kind: domain.TransferKindImport, Status: domain.TransferStatusDone,
+					Total: 2, Done: 1, Failed: 1, Msg: "blog/b.md: 标签或者分类不合法"}).Return(nil)
+				return repo, artSvc, imageSvc
+			},
+			wantTransfer: domain.ArticleTransfer{Id: 1, Uid: 123, Kind: domain.TransferKindImport,
+				Status: domain.TransferStatusDone, Total: 2, Done: 1, Failed: 1,
+				Msg: "blog/b.md: 标签或者分类不合法"},
+		},
+		{
+			name:     "单个 Markdown 文件用文件名做标题",
+			filename: "笔记.md",
+			data:     []byte("![图](img/pic.png)"),
+			mock: func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService) {
+				repo := repomocks.NewMockArticleTransferRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.ArticleTransfer{Id: 2, Uid: 123, Kind: domain.TransferKindImport, Total: 1}, nil)
+				repo.EXPECT().UpdateProgress(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				artSvc := svcmocks.NewMockArticleService(ctrl)
+				artSvc.EXPECT().Save(gomock.Any(), domain.Article{
+					Title:   "笔记",
+					Content: "![图](img/pic.png)",
+					Author:  domain.Author{Id: 123},
+				}).Return(int64(12), nil)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any()).Return(nil)
+				return repo, artSvc, svcmocks.NewMockImageService(ctrl)
+			},
+			wantTransfer: domain.ArticleTransfer{Id: 2, Uid: 123, Kind: domain.TransferKindImport,
+				Status: domain.TransferStatusDone, Total: 1, Done: 1},
+		},
+		{
+			name:     "不支持的文件",
+			filename: "blog.docx",
+			data:     []byte("docx"),
+			mock: func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService) {
+				return repomocks.NewMockArticleTransferRepository(ctrl),
+					svcmocks.NewMockArticleService(ctrl), svcmocks.NewMockImageService(ctrl)
+			},
+			wantErr: ErrInvalidImportFile,
+		},
+		{
+			name:     "zip 里面没有 Markdown",
+			filename: "blog.zip",
+			data:     zipFiles(t, map[string]string{"img/pic.png": "pic"}),
+			mock: func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService) {
+				return repomocks.NewMockArticleTransferRepository(ctrl),
+					svcmocks.NewMockArticleService(ctrl), svcmocks.NewMockImageService(ctrl)
+			},
+			wantErr: ErrInvalidImportFile,
+		},
+		{
+			name:     "上一次导入还没有结束",
+			filename: "blog.zip",
+			data:     data,
+			mock: func(ctrl *gomock.Controller) (repository.ArticleTransferRepository, ArticleService, ImageService) {
+				repo := repomocks.NewMockArticleTransferRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.ArticleTransfer{}, repository.ErrTransferDuplicate)
+				return repo, svcmocks.NewMockArticleService(ctrl), svcmocks.NewMockImageService(ctrl)
+			},
+			wantErr: ErrTransferBusy,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artSvc, imageSvc := tc.mock(ctrl)
+			svc := NewArticleTransferService(repo, artSvc, imageSvc, &logger.NoOpLogger{})
+			res, err := svc.StartImport(context.Background(), 123, tc.filename, tc.data)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.wantTransfer, res)
+		})
+	}
+}
+
+func Test_articleTransferService_StartExport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleTransferRepository(ctrl)
+	artSvc := svcmocks.NewMockArticleService(ctrl)
+	imageSvc := svcmocks.NewMockImageService(ctrl)
+
+	ctime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(domain.ArticleTransfer{Id: 1, Uid: 123, Kind: domain.TransferKindExport}, nil)
+	artSvc.EXPECT().ListIds(gomock.Any(), int64(123), int64(0), exportPageSize).
+		Return([]int64{11, 12}, nil)
+	repo.EXPECT().UpdateProgress(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+	artSvc.EXPECT().GetById(gomock.Any(), int64(11)).Return(domain.Article{
+		Id: 11, Title: "草稿", Content: "![图](https://cdn.webook.com/images/aa/pic.png) ![外部](https://example.com/x.png)",
+		Author: domain.Author{Id: 123}, Status: domain.ArticleStatusPublished, Revision: 3, Tags: []string{"Go"},
+		Cover: "https://cdn.webook.com/images/aa/pic.png", Ctime: ctime, Utime: ctime,
+	}, nil)
+	artSvc.EXPECT().LiveRevision(gomock.Any(), int64(123), int64(11)).Return(int64(2), nil)
+	artSvc.EXPECT().GetRevision(gomock.Any(), int64(123), int64(11), int64(2)).
+		Return(domain.ArticleRevision{Revision: 2, Title: "线上", Content: "线上的内容", Ctime: ctime}, nil)
+	imageSvc.EXPECT().KeyFromURL("https://cdn.webook.com/images/aa/pic.png").
+		Return("images/aa/pic.png", true).Times(2)
+	imageSvc.EXPECT().KeyFromURL("https://example.com/x.png").Return("", false)
+	// 同一张图片只导出一次
+	imageSvc.EXPECT().GetObject(gomock.Any(), "images/aa/pic.png").Return([]byte("pic"), "image/png", nil)
+	// 不是自己的文章
+	artSvc.EXPECT().GetById(gomock.Any(), int64(12)).Return(domain.Article{Id: 12, Author: domain.Author{Id: 234}}, nil)
+
+	var exported []byte
+	repo.EXPECT().SaveExport(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, t domain.ArticleTransfer, r io.ReadSeeker) error {
+			var err error
+			exported, err = io.ReadAll(r)
+			return err
+		})
+	finished := make(chan domain.ArticleTransfer, 1)
+	repo.EXPECT().Finish(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, t domain.ArticleTransfer) error {
+			finished <- t
+			return nil
+		})
+
+	svc := NewArticleTransferService(repo, artSvc, imageSvc, &logger.NoOpLogger{})
+	res, err := svc.StartExport(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Id)
+
+	var task domain.ArticleTransfer
+	select {
+	case task = <-finished:
+	case <-time.After(time.Second * 5):
+		t.Fatal("导出没有结束")
+	}
+	assert.Equal(t, domain.TransferStatusDone, task.Status)
+	assert.Equal(t, 2, task.Total)
+	assert.Equal(t, 1, task.Done)
+	assert.Equal(t, 1, task.Failed)
+
+	files := unzipFiles(t, exported)
+	assert.Equal(t, map[string]string{
+		"articles/11.md": "---\ntitle: 草稿\nid: 11\nstatus: published\nrevision: 3\ntags:\n    - Go\n" +
+			"cover: ../images/aa/pic.png\ncreated: 2024-05-01T08:00:00Z\nupdated: 2024-05-01T08:00:00Z\n---\n" +
+			"![图](../images/aa/pic.png) ![外部](https://example.com/x.png)",
+		"articles/11.published.md": "---\ntitle: 线上\nid: 11\nstatus: published\nrevision: 2\ntags:\n    - Go\n" +
+			"cover: ../images/aa/pic.png\ncreated: 2024-05-01T08:00:00Z\nupdated: 2024-05-01T08:00:00Z\n---\n" +
+			"线上的内容",
+		"images/aa/pic.png": "pic",
+	}, files)
+}
+
+func Test_articleTransferService_StartExport_Unfinished(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleTransferRepository(ctrl)
+	// 已经有正在进行的导出的时候返回那个任务，不会再启动一个
+	repo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(domain.ArticleTransfer{}, repository.ErrTransferDuplicate)
+	repo.EXPECT().FindUnfinished(gomock.Any(), int64(123), domain.TransferKindExport, gomock.Any()).
+		Return(domain.ArticleTransfer{Id: 1, Uid: 123, Kind: domain.TransferKindExport,
+			Status: domain.TransferStatusRunning}, nil)
+	svc := NewArticleTransferService(repo, svcmocks.NewMockArticleService(ctrl),
+		svcmocks.NewMockImageService(ctrl), &logger.NoOpLogger{})
+	res, err := svc.StartExport(context.Background(), 123)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Id)
+}
+
+func Test_articleTransferService_PurgeExpired(t *testing.T) {
+	now := time.Now()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockArticleTransferRepository(ctrl)
+	svc := NewArticleTransferService(repo, nil, nil, &logger.NoOpLogger{})
+
+	blobErr := errors.New("mock blob error")
+	ts := []domain.ArticleTransfer{{Id: 1, Kind: domain.TransferKindExport}, {Id: 2, Kind: domain.TransferKindImport}}
+	repo.EXPECT().ListFinished(gomock.Any(), now.Add(-domain.TransferRetention), 10).Return(ts, nil)
+	// 第一个失败了，第二个照样要清理
+	repo.EXPECT().Purge(gomock.Any(), ts[0]).Return(blobErr)
+	repo.EXPECT().Purge(gomock.Any(), ts[1]).Return(nil)
+	cnt, err := svc.PurgeExpired(context.Background(), now, 10)
+	assert.ErrorIs(t, err, blobErr)
+	assert.Equal(t, 2, cnt)
+}
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func unzipFiles(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	res := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		res[f.Name] = string(content)
+	}
+	return res
+}
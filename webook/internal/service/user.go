@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"webook/internal/events/search"
 	"webook/pkg/logger"
 
 	"golang.org/x/crypto/bcrypt"
@@ -35,12 +36,15 @@ type UserService interface {
 type userService struct {
 	repo repository.UserRepository
 	l    logger.LoggerV1
+	// searchProducer 注册和修改资料之后通知搜索更新索引
+	searchProducer search.Producer
 }
 
-func NewUserService(repo repository.UserRepository, l logger.LoggerV1) UserService {
+func NewUserService(repo repository.UserRepository, l logger.LoggerV1, searchProducer search.Producer) UserService {
 	return &userService{
-		repo: repo,
-		l:    l,
+		repo:           repo,
+		l:              l,
+		searchProducer: searchProducer,
 	}
 }
 
@@ -51,7 +55,17 @@ func (svc *userService) SignUp(c context.Context, u domain.User) error {
 		return err
 	}
 	u.Password = string(hash)
-	return svc.repo.Create(c, u)
+	if err = svc.repo.Create(c, u); err != nil {
+		return err
+	}
+	// Create 拿不到 ID，只能再查一次，查不到的话只是暂时搜索不到这个用户
+	nu, err := svc.repo.FindByEmail(c, u.Email)
+	if err != nil {
+		svc.l.Error("注册之后查询用户失败", logger.Error(err))
+		return nil
+	}
+	svc.syncSearch(nu.Id)
+	return nil
 }
 
 func (svc *userService) FindOrCreate(c context.Context, phone string) (domain.User, error) {
@@ -77,9 +91,14 @@ func (svc *userService) FindOrCreate(c context.Context, phone string) (domain.Us
 	if err != nil && !errors.Is(err, repository.ErrUserDuplicate) {
 		return u, fmt.Errorf("create user by phone failed. %w\n", err)
 	}
+	created := err == nil
 
 	// 这里会遇到主从延迟的问题
-	return svc.repo.FindByPhone(c, phone)
+	u, err = svc.repo.FindByPhone(c, phone)
+	if err == nil && created {
+		svc.syncSearch(u.Id)
+	}
+	return u, err
 }
 
 func (svc *userService) FindOrCreateByWechat(c context.Context, info domain.WechatInfo) (domain.User, error) {
@@ -95,9 +114,14 @@ func (svc *userService) FindOrCreateByWechat(c context.Context, info domain.Wech
 	if err != nil && !errors.Is(err, repository.ErrUserDuplicate) {
 		return u, fmt.Errorf("create user by phone failed. %w\n", err)
 	}
+	created := err == nil
 
 	// 这里会遇到主从延迟的问题
-	return svc.repo.FindByWechat(c, info.OpenID)
+	u, err = svc.repo.FindByWechat(c, info.OpenID)
+	if err == nil && created {
+		svc.syncSearch(u.Id)
+	}
+	return u, err
 }
 
 func (svc *userService) Login(c context.Context, email, password string) (domain.User, error) {
@@ -120,7 +144,11 @@ func (svc *userService) Login(c context.Context, email, password string) (domain
 }
 
 func (svc *userService) Edit(c context.Context, uid int64, u domain.User) error {
-	return svc.repo.Update(c, u)
+	if err := svc.repo.Update(c, u); err != nil {
+		return err
+	}
+	svc.syncSearch(u.Id)
+	return nil
 }
 
 func (svc *userService) Profile(c context.Context, uid int64) (domain.User, error) {
@@ -143,5 +171,19 @@ func (svc *userService) UpdateNonSensitiveInfo(c context.Context, u domain.User)
 	u.Email = ""
 	u.Phone = ""
 	u.Password = ""
-	return svc.repo.Update(c, u)
+	if err := svc.repo.Update(c, u); err != nil {
+		return err
+	}
+	svc.syncSearch(u.Id)
+	return nil
+}
+
+// syncSearch 搜索的索引晚一点更新也没关系，所以异步发送，失败了只记录日志
+func (svc *userService) syncSearch(uid int64) {
+	go func() {
+		if err := svc.searchProducer.ProduceUserEvent(search.UserEvent{Id: uid}); err != nil {
+			svc.l.Error("发送搜索同步事件失败",
+				logger.Int64("uid", uid), logger.Error(err))
+		}
+	}()
 }
@@ -12,6 +12,8 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"webook/internal/domain"
+	"webook/internal/events/search"
+	evtmocks "webook/internal/events/search/mocks"
 	"webook/internal/repository"
 	repomocks "webook/internal/repository/mocks"
 )
@@ -94,7 +96,7 @@ func Test_userService_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			svc := NewUserService(tt.mock(ctrl), tt.l)
+			svc := NewUserService(tt.mock(ctrl), tt.l, nil)
 			u, err := svc.Login(tt.c, tt.email, tt.password)
 			assert.Equal(t, tt.wantErr, err)
 			assert.Equal(t, tt.wantUser, u)
@@ -102,6 +104,36 @@ func Test_userService_Login(t *testing.T) {
 	}
 }
 
+func Test_userService_SignUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repomocks.NewMockUserRepository(ctrl)
+	producer := evtmocks.NewMockProducer(ctrl)
+	svc := NewUserService(repo, logger.NewNoOpLogger(), producer)
+
+	// 注册之后要通知搜索建立索引，事件是异步发送的
+	evts := make(chan search.UserEvent, 1)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+	producer.EXPECT().ProduceUserEvent(gomock.Any()).DoAndReturn(func(evt search.UserEvent) error {
+		evts <- evt
+		return nil
+	})
+	err := svc.SignUp(context.Background(), domain.User{Email: "123@qq.com", Password: "hello@world123"})
+	assert.Equal(t, nil, err)
+	select {
+	case evt := <-evts:
+		assert.Equal(t, search.UserEvent{Id: 1}, evt)
+	case <-time.After(time.Second):
+		t.Fatal("没有发送搜索同步事件")
+	}
+
+	// 邮箱冲突不会发送事件
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrUserDuplicate)
+	err = svc.SignUp(context.Background(), domain.User{Email: "123@qq.com", Password: "hello@world123"})
+	assert.Equal(t, ErrUserDuplicate, err)
+}
+
 func TestEncrypted(t *testing.T) {
 	res, err := bcrypt.GenerateFromPassword([]byte("hello@world123"), bcrypt.DefaultCost)
 	if err == nil {
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*AnalyticsHandler)(nil)
+
+const defaultTopArticles = 10
+
+var (
+	analyticsGranularities = map[string]domain.AnalyticsGranularity{
+		"":      domain.AnalyticsGranularityDay,
+		"day":   domain.AnalyticsGranularityDay,
+		"week":  domain.AnalyticsGranularityWeek,
+		"month": domain.AnalyticsGranularityMonth,
+	}
+	// defaultAnalyticsPeriods 不传 periods 的时候查最近多少个区间
+	defaultAnalyticsPeriods = map[domain.AnalyticsGranularity]int{
+		domain.AnalyticsGranularityDay:   defaultStatsDays,
+		domain.AnalyticsGranularityWeek:  12,
+		domain.AnalyticsGranularityMonth: 6,
+	}
+	analyticsMetrics = map[string]domain.AnalyticsMetric{
+		"":        domain.AnalyticsMetricRead,
+		"read":    domain.AnalyticsMetricRead,
+		"like":    domain.AnalyticsMetricLike,
+		"collect": domain.AnalyticsMetricCollect,
+		"comment": domain.AnalyticsMetricComment,
+	}
+)
+
+// AnalyticsHandler 作者后台的数据分析
+type AnalyticsHandler struct {
+	svc service.AnalyticsService
+}
+
+func NewAnalyticsHandler(svc service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		svc: svc,
+	}
+}
+
+func (h *AnalyticsHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/analytics")
+	g.POST("/series", ginx.WrapReqAndToken[AnalyticsSeriesReq, jwt.UserClaims](h.Series))
+	g.POST("/top", ginx.WrapReqAndToken[AnalyticsTopReq, jwt.UserClaims](h.Top))
+}
+
+func (h *AnalyticsHandler) Series(ctx *gin.Context, req AnalyticsSeriesReq, uc jwt.UserClaims) (ginx.Result, error) {
+	g, ok := analyticsGranularities[req.Granularity]
+	if !ok {
+		return Result{Code: 4, Msg: "时间粒度只能是 day、week 或者 month"}, nil
+	}
+	periods := req.Periods
+	if periods <= 0 {
+		periods = defaultAnalyticsPeriods[g]
+	}
+	if maxPeriods := service.MaxAnalyticsPeriods(g); periods > maxPeriods {
+		return Result{Code: 4, Msg: fmt.Sprintf("最多查询最近 %d 个区间", maxPeriods)}, nil
+	}
+	points, err := h.svc.Series(ctx, uc.Uid, req.Id, g, periods)
+	switch {
+	case err == nil:
+		return Result{
+			Data: slice.Map(points, func(idx int, src domain.AnalyticsPoint) AnalyticsPointVO {
+				return AnalyticsPointVO{
+					Start:              src.Start.Format(time.DateOnly),
+					AnalyticsMetricsVO: newAnalyticsMetricsVO(src.Metrics),
+				}
+			}),
+		}, nil
+	case errors.Is(err, service.ErrPossibleIncorrectAuthor):
+		return Result{Code: 4, Msg: "文章不存在"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *AnalyticsHandler) Top(ctx *gin.Context, req AnalyticsTopReq, uc jwt.UserClaims) (ginx.Result, error) {
+	metric, ok := analyticsMetrics[req.Metric]
+	if !ok {
+		return Result{Code: 4, Msg: "排序指标只能是 read、like、collect 或者 comment"}, nil
+	}
+	days := req.Days
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	if days > service.MaxStatsDays {
+		return Result{Code: 4, Msg: "最多查询最近 90 天"}, nil
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultTopArticles
+	}
+	limit = min(limit, service.MaxTopArticles)
+	arts, err := h.svc.TopArticles(ctx, uc.Uid, days, metric, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: slice.Map(arts, func(idx int, src domain.ArticleAnalytics) ArticleAnalyticsVO {
+			return ArticleAnalyticsVO{
+				Id:                 src.Article.Id,
+				Title:              src.Article.Title,
+				AnalyticsMetricsVO: newAnalyticsMetricsVO(src.Metrics),
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package web
+
+import "webook/internal/domain"
+
+// AnalyticsSeriesReq Id 为 0 的时候是作者自己的汇总
+// Granularity 是 day、week 或者 month，默认是 day；Periods 为 0 的时候用各个粒度的默认值
+type AnalyticsSeriesReq struct {
+	Id          int64  `json:"id"`
+	Granularity string `json:"granularity"`
+	Periods     int    `json:"periods"`
+}
+
+// AnalyticsTopReq Metric 是 read、like、collect 或者 comment，默认是 read
+// Days 为 0 的时候默认最近 30 天，Limit 为 0 的时候默认 10 篇
+type AnalyticsTopReq struct {
+	Days   int    `json:"days"`
+	Metric string `json:"metric"`
+	Limit  int    `json:"limit"`
+}
+
+type AnalyticsMetricsVO struct {
+	ReadCnt    int64 `json:"readCnt"`
+	LikeCnt    int64 `json:"likeCnt"`
+	CollectCnt int64 `json:"collectCnt"`
+	CommentCnt int64 `json:"commentCnt"`
+	// FollowerCnt 新增的粉丝，只有作者自己的汇总里面有
+	FollowerCnt int64 `json:"followerCnt"`
+}
+
+type AnalyticsPointVO struct {
+	// Start 区间的第一天，格式是 2006-01-02
+	Start string `json:"start"`
+	AnalyticsMetricsVO
+}
+
+type ArticleAnalyticsVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+	AnalyticsMetricsVO
+}
+
+func newAnalyticsMetricsVO(m domain.AnalyticsMetrics) AnalyticsMetricsVO {
+	return AnalyticsMetricsVO{
+		ReadCnt:     m.ReadCnt,
+		LikeCnt:     m.LikeCnt,
+		CollectCnt:  m.CollectCnt,
+		CommentCnt:  m.CommentCnt,
+		FollowerCnt: m.FollowerCnt,
+	}
+}
@@ -1,6 +1,9 @@
 package web
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-gonic/gin"
@@ -12,24 +15,31 @@ import (
 	"webook/internal/service"
 	"webook/internal/web/jwt"
 	"webook/pkg/ginx"
+	"webook/pkg/linediff"
 	"webook/pkg/logger"
 )
 
 var _ handler = (*ArticleHandler)(nil)
 
 type ArticleHandler struct {
-	svc     service.ArticleService
-	l       logger.LoggerV1
-	intrSvc service.InteractiveService
-	biz     string
+	svc       service.ArticleService
+	l         logger.LoggerV1
+	intrSvc   service.InteractiveService
+	followSvc service.FollowService
+	// seriesSvc 文章详情里面的专栏导航
+	seriesSvc service.SeriesService
+	biz       string
 }
 
-func NewArticleHandler(svc service.ArticleService, l logger.LoggerV1, intrSvc service.InteractiveService) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService, l logger.LoggerV1, intrSvc service.InteractiveService,
+	followSvc service.FollowService, seriesSvc service.SeriesService) *ArticleHandler {
 	return &ArticleHandler{
-		svc:     svc,
-		l:       l,
-		biz:     "article",
-		intrSvc: intrSvc,
+		svc:       svc,
+		l:         l,
+		biz:       "article",
+		intrSvc:   intrSvc,
+		followSvc: followSvc,
+		seriesSvc: seriesSvc,
 	}
 }
 
@@ -40,10 +50,31 @@ func (a *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	g.POST("/withdraw", a.Withdraw) // 仅自己可见
 	g.POST("/list", ginx.WrapReqAndToken[Page, jwt.UserClaims](a.List))
 	g.GET("/detail/:id", ginx.WrapToken[jwt.UserClaims](a.Detail))
+	g.POST("/delete", ginx.WrapReqAndToken[ArticleIdReq, jwt.UserClaims](a.Delete))
+
+	// 回收站
+	rec := g.Group("/recycle")
+	rec.POST("/list", ginx.WrapReqAndToken[Page, jwt.UserClaims](a.RecycleBin))
+	rec.POST("/restore", ginx.WrapReqAndToken[ArticleIdReq, jwt.UserClaims](a.Restore))
+
+	sch := g.Group("/schedule")
+	sch.POST("", ginx.WrapReqAndToken[ScheduleReq, jwt.UserClaims](a.Schedule))
+	sch.POST("/update", ginx.WrapReqAndToken[RescheduleReq, jwt.UserClaims](a.Reschedule))
+	sch.POST("/cancel", ginx.WrapReqAndToken[CancelScheduleReq, jwt.UserClaims](a.CancelSchedule))
+
+	rev := g.Group("/revisions")
+	rev.POST("/list", ginx.WrapReqAndToken[RevisionListReq, jwt.UserClaims](a.Revisions))
+	rev.POST("/detail", ginx.WrapReqAndToken[RevisionReq, jwt.UserClaims](a.RevisionDetail))
+	rev.POST("/diff", ginx.WrapReqAndToken[RevisionDiffReq, jwt.UserClaims](a.RevisionDiff))
+	rev.POST("/restore", ginx.WrapReqAndToken[RevisionReq, jwt.UserClaims](a.RestoreRevision))
 
 	pub := g.Group("/pub")
-	//pub.GET("/pub", a.PubList)
-	pub.GET("/:id", ginx.WrapToken(a.PubDetail))
+	pub.GET("/list", ginx.WrapReqAndToken[PubListReq, jwt.UserClaims](a.PubList))
+	pub.GET("/tag", ginx.WrapReqAndToken[PubTagListReq, jwt.UserClaims](a.PubListByTag))
+	pub.GET("/category", ginx.WrapReqAndToken[PubCategoryListReq, jwt.UserClaims](a.PubListByCategory))
+	pub.GET("/tags/popular", ginx.WrapReq[PopularTagsReq](a.l, a.PopularTags))
+	// 没有登录也可以看，阅读数按照读者的指纹去重
+	pub.GET("/:id", ginx.WrapOptionalToken[jwt.UserClaims](a.PubDetail))
 	pub.POST("/like", ginx.WrapReqAndToken[LikeReq, jwt.UserClaims](a.Like))
 	pub.POST("/collect", ginx.WrapReqAndToken[CollectReq](a.Collect))
 }
@@ -65,9 +96,48 @@ func (a *ArticleHandler) Edit(c *gin.Context) {
 		a.l.Error("未发现用户的 session 信息")
 		return
 	}
-	// 检测输入，跳过这一步
-	// 调用 svc 的代码
-	id, err := a.svc.Save(c, req.toDomain(claims.Uid))
+	art, err := a.toDomain(c, req, claims.Uid)
+	if err != nil {
+		c.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("保存帖子查询原来的草稿失败", logger.Error(err))
+		return
+	}
+	id, err := a.svc.Save(c, art)
+	if errors.Is(err, service.ErrInvalidTags) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签或者分类不合法",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCover) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "封面不合法",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrRevisionRequired) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "缺少版本号，请刷新之后再编辑",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrContentTooLong) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章内容太长",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		c.JSON(http.StatusOK, a.versionConflict(c, claims.Uid, req.Id))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -100,7 +170,48 @@ func (a *ArticleHandler) Publish(c *gin.Context) {
 		return
 	}
 
-	id, err := a.svc.Publish(c, req.toDomain(claims.Uid))
+	art, err := a.toDomain(c, req, claims.Uid)
+	if err != nil {
+		c.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		a.l.Error("发表帖子查询原来的草稿失败", logger.Error(err))
+		return
+	}
+	id, err := a.svc.Publish(c, art)
+	if errors.Is(err, service.ErrInvalidTags) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签或者分类不合法",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCover) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "封面不合法",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrRevisionRequired) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "缺少版本号，请刷新之后再编辑",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrContentTooLong) {
+		c.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章内容太长",
+		})
+		return
+	}
+	if errors.Is(err, service.ErrVersionConflict) {
+		c.JSON(http.StatusOK, a.versionConflict(c, claims.Uid, req.Id))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -146,6 +257,104 @@ func (a *ArticleHandler) Withdraw(ctx *gin.Context) {
 
 }
 
+func (a *ArticleHandler) Schedule(ctx *gin.Context, req ScheduleReq, uc jwt.UserClaims) (ginx.Result, error) {
+	art, err := a.toDomain(ctx, req.ArticleReq, uc.Uid)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("定时发表查询原来的草稿失败 %w", err)
+	}
+	id, err := a.svc.Schedule(ctx, art, time.UnixMilli(req.PublishAt))
+	switch {
+	case errors.Is(err, service.ErrInvalidPublishTime):
+		return Result{Code: 4, Msg: "发表时间必须在未来"}, nil
+	case errors.Is(err, service.ErrInvalidTags):
+		return Result{Code: 4, Msg: "标签或者分类不合法"}, nil
+	case errors.Is(err, service.ErrInvalidCover):
+		return Result{Code: 4, Msg: "封面不合法"}, nil
+	case errors.Is(err, service.ErrRevisionRequired):
+		return Result{Code: 4, Msg: "缺少版本号，请刷新之后再编辑"}, nil
+	case errors.Is(err, service.ErrContentTooLong):
+		return Result{Code: 4, Msg: "文章内容太长"}, nil
+	case errors.Is(err, service.ErrVersionConflict):
+		return a.versionConflict(ctx, uc.Uid, req.Id), nil
+	case err != nil:
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("定时发表失败 %w", err)
+	}
+	return Result{Msg: "OK", Data: id}, nil
+}
+
+func (a *ArticleHandler) Reschedule(ctx *gin.Context, req RescheduleReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := a.svc.Reschedule(ctx, uc.Uid, req.Id, time.UnixMilli(req.PublishAt))
+	switch {
+	case errors.Is(err, service.ErrInvalidPublishTime):
+		return Result{Code: 4, Msg: "发表时间必须在未来"}, nil
+	case errors.Is(err, service.ErrNotScheduled):
+		return Result{Code: 4, Msg: "文章不是定时发表状态"}, nil
+	case err != nil:
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("修改定时发表时间失败 %w", err)
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (a *ArticleHandler) CancelSchedule(ctx *gin.Context, req CancelScheduleReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := a.svc.CancelSchedule(ctx, uc.Uid, req.Id)
+	if errors.Is(err, service.ErrNotScheduled) {
+		return Result{Code: 4, Msg: "文章不是定时发表状态"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("取消定时发表失败 %w", err)
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+// Delete 移到回收站，在保留时间内可以恢复
+func (a *ArticleHandler) Delete(ctx *gin.Context, req ArticleIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := a.svc.Delete(ctx, uc.Uid, req.Id)
+	if errors.Is(err, service.ErrPossibleIncorrectAuthor) {
+		return Result{Code: 4, Msg: "文章不存在"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("删除文章失败 %w", err)
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (a *ArticleHandler) Restore(ctx *gin.Context, req ArticleIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := a.svc.Restore(ctx, uc.Uid, req.Id)
+	if errors.Is(err, service.ErrNotInRecycleBin) {
+		return Result{Code: 4, Msg: "文章不在回收站里面"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("恢复文章失败 %w", err)
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (a *ArticleHandler) RecycleBin(ctx *gin.Context, req Page, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > maxRecycleBinLimit {
+		limit = maxRecycleBinLimit
+	}
+	arts, err := a.svc.ListDeleted(ctx, uc.Uid, max(req.Offset, 0), limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("查询回收站失败 %w", err)
+	}
+	return Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:        src.Id,
+				Title:     src.Title,
+				Abstract:  src.Abstract,
+				Status:    src.Status.ToUint8(),
+				Cover:     src.Cover,
+				DeletedAt: src.DeletedAt.Format(time.DateTime),
+				PurgeAt:   src.PurgeAt().Format(time.DateTime),
+				Ctime:     src.Ctime.Format(time.DateTime),
+				Utime:     src.Utime.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
 func (a *ArticleHandler) List(ctx *gin.Context, req Page, uc jwt.UserClaims) (ginx.Result, error) {
 	res, err := a.svc.List(ctx, uc.Uid, req.Offset, req.Limit)
 	if err != nil {
@@ -154,10 +363,12 @@ func (a *ArticleHandler) List(ctx *gin.Context, req Page, uc jwt.UserClaims) (gi
 	return ginx.Result{
 		Data: slice.Map[domain.Article, ArticleVO](res, func(idx int, src domain.Article) ArticleVO {
 			return ArticleVO{
-				Id:       src.Id,
-				Title:    src.Title,
-				Abstract: src.Abstract(),
-				Status:   src.Status.ToUint8(),
+				Id:        src.Id,
+				Title:     src.Title,
+				Abstract:  src.Abstract,
+				Status:    src.Status.ToUint8(),
+				Cover:     src.Cover,
+				PublishAt: formatPublishAt(src.PublishAt),
 				// 这个列表请求，不需要返回内容
 				//Content: src.Content,
 				// 这个是创作者看自己的文章列表，也不需要这个字段
@@ -186,18 +397,141 @@ func (a *ArticleHandler) Detail(ctx *gin.Context, usr jwt.UserClaims) (ginx.Resu
 	}
 
 	return Result{
-		Data: ArticleVO{
-			Id:       art.Id,
-			Title:    art.Title,
-			Abstract: art.Abstract(),
-			Status:   art.Status.ToUint8(),
-			Ctime:    art.Ctime.Format(time.DateTime),
-			Utime:    art.Utime.Format(time.DateTime),
+		Data: newDraftVO(art),
+	}, nil
+}
+
+// toDomain 修改已有的文章的时候，没有传的字段保留草稿里面原来的值
+func (a *ArticleHandler) toDomain(ctx *gin.Context, req ArticleReq, uid int64) (domain.Article, error) {
+	art := req.toDomain(uid)
+	if req.Id == 0 || (req.Category != nil && req.Cover != nil) {
+		return art, nil
+	}
+	cur, err := a.svc.GetById(ctx, req.Id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	// 别人的文章什么也不保留，后面更新的时候会失败
+	if cur.Author.Id == uid {
+		if req.Category == nil {
+			art.Category = cur.Category
+		}
+		if req.Cover == nil {
+			art.Cover = cur.Cover
+		}
+	}
+	return art, nil
+}
+
+// versionConflict 草稿已经在别的地方被修改过，把最新的草稿返回给前端，让作者自己合并
+func (a *ArticleHandler) versionConflict(ctx *gin.Context, uid, id int64) Result {
+	res := Result{Code: codeVersionConflict, Msg: "文章已经在别的地方被修改过"}
+	art, err := a.svc.GetById(ctx, id)
+	if err != nil || art.Author.Id != uid {
+		a.l.Error("版本冲突之后查询最新的草稿失败",
+			logger.Int64("aid", id), logger.Int64("uid", uid), logger.Error(err))
+		return res
+	}
+	res.Data = newDraftVO(art)
+	return res
+}
+
+func (a *ArticleHandler) Revisions(ctx *gin.Context, req RevisionListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > maxRevisionLimit {
+		limit = maxRevisionLimit
+	}
+	var (
+		eg   errgroup.Group
+		revs []domain.ArticleRevision
+		live int64
+	)
+	eg.Go(func() error {
+		var er error
+		revs, er = a.svc.ListRevisions(ctx, uc.Uid, req.Id, req.Offset, limit)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		live, er = a.svc.LiveRevision(ctx, uc.Uid, req.Id)
+		return er
+	})
+	if err := eg.Wait(); err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("查询文章历史版本失败 %w", err)
+	}
+	return Result{
+		Data: RevisionListVO{
+			Live: live,
+			List: slice.Map[domain.ArticleRevision, RevisionVO](revs,
+				func(idx int, src domain.ArticleRevision) RevisionVO {
+					return a.toRevisionVO(src, live)
+				}),
 		},
 	}, nil
 }
 
-func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc ginx.UserClaims) (Result, error) {
+func (a *ArticleHandler) RevisionDetail(ctx *gin.Context, req RevisionReq, uc jwt.UserClaims) (ginx.Result, error) {
+	rev, err := a.svc.GetRevision(ctx, uc.Uid, req.Id, req.Revision)
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		return Result{Code: 4, Msg: "版本不存在"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	live, err := a.svc.LiveRevision(ctx, uc.Uid, req.Id)
+	if err != nil {
+		// 不影响查看版本内容
+		a.l.Error("查询线上版本失败", logger.Int64("aid", req.Id), logger.Error(err))
+	}
+	return Result{Data: a.toRevisionVO(rev, live)}, nil
+}
+
+func (a *ArticleHandler) RevisionDiff(ctx *gin.Context, req RevisionDiffReq, uc jwt.UserClaims) (ginx.Result, error) {
+	lines, err := a.svc.DiffRevisions(ctx, uc.Uid, req.Id, req.From, req.To)
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		return Result{Code: 4, Msg: "版本不存在"}, nil
+	}
+	if errors.Is(err, linediff.ErrTooLarge) {
+		return Result{Code: 4, Msg: "内容太多，无法比较"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: slice.Map[linediff.Line, DiffLineVO](lines, func(idx int, src linediff.Line) DiffLineVO {
+			return DiffLineVO{
+				Op:    src.Op.String(),
+				OldNo: src.OldNo,
+				NewNo: src.NewNo,
+				Text:  src.Text,
+			}
+		}),
+	}, nil
+}
+
+func (a *ArticleHandler) RestoreRevision(ctx *gin.Context, req RevisionReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := a.svc.RestoreRevision(ctx, uc.Uid, req.Id, req.Revision)
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		return Result{Code: 4, Msg: "版本不存在"}, nil
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (a *ArticleHandler) toRevisionVO(rev domain.ArticleRevision, live int64) RevisionVO {
+	return RevisionVO{
+		Revision: rev.Revision,
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Status:   rev.Status.ToUint8(),
+		Live:     rev.Revision == live,
+		Ctime:    rev.Ctime.Format(time.DateTime),
+	}
+}
+
+func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc jwt.UserClaims) (Result, error) {
 	idstr := ctx.Param("id")
 	id, err := strconv.ParseInt(idstr, 10, 64)
 	if err != nil {
@@ -213,10 +547,11 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc ginx.UserClaims) (Result
 		eg   errgroup.Group
 		art  domain.Article
 		intr domain.Interactive
+		nav  *SeriesNavVO
 	)
 	eg.Go(func() error {
 		var er error
-		art, er = a.svc.GetPublishedById(ctx, id, uc.Uid)
+		art, er = a.svc.GetPublishedById(ctx, id, uc.Uid, readerFingerprint(ctx))
 		return er
 	})
 
@@ -226,6 +561,12 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc ginx.UserClaims) (Result
 		return er
 	})
 
+	// 专栏导航不是必须的，查询失败也不影响看文章
+	eg.Go(func() error {
+		nav = a.seriesNav(ctx, id)
+		return nil
+	})
+
 	err = eg.Wait()
 
 	if err != nil {
@@ -235,6 +576,22 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc ginx.UserClaims) (Result
 		}, fmt.Errorf("获取文章信息失败 %w", err)
 	}
 
+	// 作者的粉丝数要等拿到了文章才知道作者是谁
+	statics, err := a.followSvc.GetFollowStatics(ctx, art.Author.Id, uc.Uid)
+	if err != nil {
+		a.l.Error("查询作者的关注数据失败",
+			logger.Int64("author", art.Author.Id),
+			logger.Error(err))
+	}
+	art.Author.Followers = statics.Followers
+	art.Author.Followed = statics.Followed
+
+	// service 已经渲染过了，这里只是兜底，没有渲染结果的时候至少还有摘要
+	rendered := art.Rendered
+	if rendered == nil {
+		rendered = &domain.RenderedContent{Abstract: art.Abstract}
+	}
+
 	// 直接异步操作，在确定我们获取到了数据之后再来操作
 	//go func() {
 	//	err = a.intrSvc.IncrReadCnt(ctx, a.biz, art.Id)
@@ -245,21 +602,160 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context, uc ginx.UserClaims) (Result
 
 	return Result{
 		Data: ArticleVO{
-			Id:      art.Id,
-			Title:   art.Title,
-			Status:  art.Status.ToUint8(),
-			Content: art.Content,
+			Id:       art.Id,
+			Title:    art.Title,
+			Status:   art.Status.ToUint8(),
+			Abstract: rendered.Abstract,
+			Content:  art.Content,
+			HTML:     rendered.HTML,
+			TOC: slice.Map[domain.TOCItem, TOCItemVO](rendered.TOC, func(idx int, src domain.TOCItem) TOCItemVO {
+				return TOCItemVO{Level: src.Level, Text: src.Text, Anchor: src.Anchor}
+			}),
+			WordCnt:        rendered.WordCnt,
+			ReadingMinutes: rendered.ReadingMinutes,
+			Category:       art.Category,
+			Tags:           art.Tags,
+			Cover:          art.Cover,
 			// 要把作者信息带出去
-			Author:     art.Author.Name,
+			Author:          art.Author.Name,
+			AuthorId:        art.Author.Id,
+			AuthorFollowers: art.Author.Followers,
+			AuthorFollowed:  art.Author.Followed,
+			Ctime:           art.Ctime.Format(time.DateTime),
+			Utime:           art.Utime.Format(time.DateTime),
+			ReadCnt:         intr.ReadCnt,
+			CollectCnt:      intr.CollectCnt,
+			LikeCnt:         intr.LikeCnt,
+			CommentCnt:      intr.CommentCnt,
+			Liked:           intr.Liked,
+			Collected:       intr.Collected,
+			Series:          nav,
+		},
+	}, nil
+}
+
+// readerFingerprint 没有登录的读者用 IP 和 User-Agent 区分，只用来给阅读数去重
+func readerFingerprint(ctx *gin.Context) string {
+	sum := sha256.Sum256([]byte(ctx.ClientIP() + "|" + ctx.Request.UserAgent()))
+	return hex.EncodeToString(sum[:16])
+}
+
+// seriesNav 文章不在已经发表的专栏里面的时候返回 nil
+func (a *ArticleHandler) seriesNav(ctx *gin.Context, id int64) *SeriesNavVO {
+	res, err := a.seriesSvc.Nav(ctx, id)
+	if err != nil {
+		if !errors.Is(err, service.ErrSeriesNotFound) {
+			a.l.Error("查询文章所在的专栏失败", logger.Int64("aid", id), logger.Error(err))
+		}
+		return nil
+	}
+	toNav := func(art *domain.Article) *ArticleNavVO {
+		if art == nil {
+			return nil
+		}
+		return &ArticleNavVO{Id: art.Id, Title: art.Title}
+	}
+	return &SeriesNavVO{
+		Id:    res.Series.Id,
+		Title: res.Series.Title,
+		Prev:  toNav(res.Prev),
+		Next:  toNav(res.Next),
+	}
+}
+
+func (a *ArticleHandler) PubList(ctx *gin.Context, req PubListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	utime, id, err := req.parseCursor()
+	if err != nil {
+		return Result{Code: 4, Msg: "参数错误"}, fmt.Errorf("游标 %s 不正确, %w", req.Cursor, err)
+	}
+	limit := req.limit()
+	arts, err := a.svc.ListPub(ctx, utime, id, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("查询已发表文章列表失败 %w", err)
+	}
+	return Result{Data: a.toPubListVO(ctx, arts, limit, uc.Uid)}, nil
+}
+
+func (a *ArticleHandler) PubListByTag(ctx *gin.Context, req PubTagListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	utime, id, err := req.parseCursor()
+	if err != nil || req.Tag == "" {
+		return Result{Code: 4, Msg: "参数错误"}, fmt.Errorf("标签 %s 或者游标 %s 不正确, %w", req.Tag, req.Cursor, err)
+	}
+	limit := req.limit()
+	arts, err := a.svc.ListPubByTag(ctx, req.Tag, utime, id, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("按照标签查询文章列表失败 %w", err)
+	}
+	return Result{Data: a.toPubListVO(ctx, arts, limit, uc.Uid)}, nil
+}
+
+func (a *ArticleHandler) PubListByCategory(ctx *gin.Context, req PubCategoryListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	utime, id, err := req.parseCursor()
+	if err != nil || req.Category == "" {
+		return Result{Code: 4, Msg: "参数错误"}, fmt.Errorf("分类 %s 或者游标 %s 不正确, %w", req.Category, req.Cursor, err)
+	}
+	limit := req.limit()
+	arts, err := a.svc.ListPubByCategory(ctx, req.Category, utime, id, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("按照分类查询文章列表失败 %w", err)
+	}
+	return Result{Data: a.toPubListVO(ctx, arts, limit, uc.Uid)}, nil
+}
+
+func (a *ArticleHandler) PopularTags(ctx *gin.Context, req PopularTagsReq) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPopularTags
+	}
+	tags, err := a.svc.PopularTags(ctx, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("查询热门标签失败 %w", err)
+	}
+	return Result{
+		Data: slice.Map[domain.Tag, TagVO](tags, func(idx int, src domain.Tag) TagVO {
+			return TagVO{Name: src.Name, Cnt: src.Cnt}
+		}),
+	}, nil
+}
+
+// toPubListVO 读者侧的各种列表都需要带上计数和下一页的游标
+func (a *ArticleHandler) toPubListVO(ctx *gin.Context, arts []domain.Article, limit int, uid int64) PubListVO {
+	ids := slice.Map[domain.Article, int64](arts, func(idx int, src domain.Article) int64 {
+		return src.Id
+	})
+	intrs, err := a.intrSvc.GetByIds(ctx, a.biz, ids, uid)
+	if err != nil {
+		// 计数查询失败不影响列表本身的展示
+		a.l.Error("批量查询文章计数失败", logger.Error(err))
+	}
+
+	res := PubListVO{
+		List: make([]ArticleVO, 0, len(arts)),
+	}
+	for _, art := range arts {
+		intr := intrs[art.Id]
+		res.List = append(res.List, ArticleVO{
+			Id:         art.Id,
+			Title:      art.Title,
+			Abstract:   art.Abstract,
+			Status:     art.Status.ToUint8(),
+			Cover:      art.Cover,
 			Ctime:      art.Ctime.Format(time.DateTime),
 			Utime:      art.Utime.Format(time.DateTime),
 			ReadCnt:    intr.ReadCnt,
 			CollectCnt: intr.CollectCnt,
 			LikeCnt:    intr.LikeCnt,
+			CommentCnt: intr.CommentCnt,
 			Liked:      intr.Liked,
 			Collected:  intr.Collected,
-		},
-	}, nil
+		})
+	}
+	// 不满一页说明没有更多数据了
+	if len(arts) == limit {
+		last := arts[len(arts)-1]
+		res.Cursor = encodePubCursor(last.Utime, last.Id)
+	}
+	return res
 }
 
 func (a *ArticleHandler) Like(ctx *gin.Context, req LikeReq, uc jwt.UserClaims) (ginx.Result, error) {
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*ArticleStatsHandler)(nil)
+
+const defaultStatsDays = 30
+
+// ArticleStatsHandler 作者查看自己文章的阅读数据
+type ArticleStatsHandler struct {
+	svc service.ArticleStatsService
+}
+
+func NewArticleStatsHandler(svc service.ArticleStatsService) *ArticleStatsHandler {
+	return &ArticleStatsHandler{
+		svc: svc,
+	}
+}
+
+func (h *ArticleStatsHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/stats")
+	g.POST("/views", ginx.WrapReqAndToken[ArticleViewsReq, jwt.UserClaims](h.Views))
+}
+
+func (h *ArticleStatsHandler) Views(ctx *gin.Context, req ArticleViewsReq, uc jwt.UserClaims) (ginx.Result, error) {
+	days := req.Days
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	if days > service.MaxStatsDays {
+		return Result{Code: 4, Msg: "最多查询最近 90 天"}, nil
+	}
+	views, err := h.svc.Views(ctx, uc.Uid, req.Id, days)
+	switch {
+	case err == nil:
+		return Result{
+			Data: slice.Map(views, func(idx int, src domain.ArticleDailyViews) ArticleDailyViewsVO {
+				return ArticleDailyViewsVO{
+					Day:     src.Day.Format(time.DateOnly),
+					ReadCnt: src.ReadCnt,
+					UV:      src.UV,
+				}
+			}),
+		}, nil
+	case errors.Is(err, service.ErrPossibleIncorrectAuthor):
+		return Result{Code: 4, Msg: "文章不存在"}, err
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
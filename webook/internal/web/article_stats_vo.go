@@ -0,0 +1,14 @@
+package web
+
+// ArticleViewsReq Days 为 0 的时候默认最近 30 天
+type ArticleViewsReq struct {
+	Id   int64 `json:"id"`
+	Days int   `json:"days"`
+}
+
+type ArticleDailyViewsVO struct {
+	// Day 格式是 2006-01-02
+	Day     string `json:"day"`
+	ReadCnt int64  `json:"readCnt"`
+	UV      int64  `json:"uv"`
+}
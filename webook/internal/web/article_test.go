@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/service"
 	svcmocks "webook/internal/service/mocks"
@@ -91,7 +92,7 @@ func TestArticleHandler_Publish(t *testing.T) {
 				})
 			})
 			// 用不上 codeSvc
-			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{})
+			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{}, nil, nil, nil)
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost,
@@ -112,3 +113,488 @@ func TestArticleHandler_Publish(t *testing.T) {
 		})
 	}
 }
+
+func TestArticleHandler_EditVersionConflict(t *testing.T) {
+	utime := time.UnixMilli(1700000000000)
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.ArticleService
+
+		wantCode int
+		wantMsg  string
+		wantData *ArticleVO
+	}{
+		{
+			name: "返回最新的草稿",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:       1,
+					Title:    "我的标题",
+					Content:  "我的内容",
+					Category: "后端",
+					Revision: 3,
+					Author:   domain.Author{Id: 123},
+				}).Return(int64(0), service.ErrVersionConflict)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:       1,
+					Title:    "另一个标签页的标题",
+					Content:  "另一个标签页的内容",
+					Abstract: "另一个标签页的内容",
+					Revision: 4,
+					Author:   domain.Author{Id: 123},
+					Ctime:    utime,
+					Utime:    utime,
+				}, nil)
+				return svc
+			},
+			wantCode: codeVersionConflict,
+			wantMsg:  "文章已经在别的地方被修改过",
+			wantData: &ArticleVO{
+				Id:       1,
+				Title:    "另一个标签页的标题",
+				Abstract: "另一个标签页的内容",
+				Content:  "另一个标签页的内容",
+				Revision: 4,
+				Ctime:    utime.Format(time.DateTime),
+				Utime:    utime.Format(time.DateTime),
+			},
+		},
+		{
+			name: "查询最新的草稿失败",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), gomock.Any()).
+					Return(int64(0), service.ErrVersionConflict)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, errors.New("mock db error"))
+				return svc
+			},
+			wantCode: codeVersionConflict,
+			wantMsg:  "文章已经在别的地方被修改过",
+		}, {
+			name: "缺少版本号",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), gomock.Any()).
+					Return(int64(0), service.ErrRevisionRequired)
+				return svc
+			},
+			wantCode: 4,
+			wantMsg:  "缺少版本号，请刷新之后再编辑",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{
+					Uid: 123,
+				})
+			})
+			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{}, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/articles/edit",
+				bytes.NewBufferString(`{"id":1,"revision":3,"title":"我的标题","content":"我的内容","category":"后端","cover":""}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var webRes struct {
+				Code int        `json:"code"`
+				Msg  string     `json:"msg"`
+				Data *ArticleVO `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&webRes))
+			assert.Equal(t, tc.wantCode, webRes.Code)
+			assert.Equal(t, tc.wantMsg, webRes.Msg)
+			assert.Equal(t, tc.wantData, webRes.Data)
+		})
+	}
+}
+
+func TestArticleHandler_EditCategory(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.ArticleService
+
+		reqBody string
+
+		wantRes Result
+	}{
+		{
+			name: "没有传分类和封面，保留原来的",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:       1,
+					Category: "后端",
+					Cover:    "https://example.com/cover.png",
+					Author:   domain.Author{Id: 123},
+				}, nil)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:       1,
+					Title:    "我的标题",
+					Content:  "我的内容",
+					Category: "后端",
+					Cover:    "https://example.com/cover.png",
+					Author:   domain.Author{Id: 123},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody: `{"id":1,"title":"我的标题","content":"我的内容"}`,
+			wantRes: Result{Msg: "OK", Data: float64(1)},
+		},
+		{
+			name: "传了分类，没有传封面，只保留原来的封面",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:       1,
+					Category: "后端",
+					Cover:    "https://example.com/cover.png",
+					Author:   domain.Author{Id: 123},
+				}, nil)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:       1,
+					Title:    "我的标题",
+					Content:  "我的内容",
+					Category: "前端",
+					Cover:    "https://example.com/cover.png",
+					Author:   domain.Author{Id: 123},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody: `{"id":1,"title":"我的标题","content":"我的内容","category":"前端"}`,
+			wantRes: Result{Msg: "OK", Data: float64(1)},
+		},
+		{
+			name: "传了空的分类和封面，清掉原来的",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "我的标题",
+					Content: "我的内容",
+					Author:  domain.Author{Id: 123},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody: `{"id":1,"title":"我的标题","content":"我的内容","category":"","cover":""}`,
+			wantRes: Result{Msg: "OK", Data: float64(1)},
+		},
+		{
+			name: "查询原来的草稿失败",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, errors.New("mock db error"))
+				return svc
+			},
+			reqBody: `{"id":1,"title":"我的标题","content":"我的内容"}`,
+			wantRes: Result{Code: 5, Msg: "系统错误"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{
+					Uid: 123,
+				})
+			})
+			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{}, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/articles/edit",
+				bytes.NewBufferString(tc.reqBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var webRes Result
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&webRes))
+			assert.Equal(t, tc.wantRes, webRes)
+		})
+	}
+}
+
+func TestArticleHandler_PubList(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService)
+
+		query string
+
+		wantCode int
+		wantRes  pubListResult
+	}{
+		{
+			name: "第一页，还有下一页",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						{Id: 3, Title: "标题3", Content: "内容3", Abstract: "内容3", Ctime: now, Utime: now},
+						{Id: 2, Title: "标题2", Content: "内容2", Abstract: "内容2", Ctime: now, Utime: now},
+					}, nil)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{3, 2}, int64(123)).
+					Return(map[int64]domain.Interactive{
+						3: {ReadCnt: 3, LikeCnt: 2, CollectCnt: 1, Liked: true},
+						2: {ReadCnt: 1},
+					}, nil)
+				return svc, intrSvc
+			},
+			query:    "limit=2",
+			wantCode: http.StatusOK,
+			wantRes: pubListResult{
+				Data: PubListVO{
+					List: []ArticleVO{
+						{Id: 3, Title: "标题3", Abstract: "内容3",
+							Ctime: now.Format(time.DateTime), Utime: now.Format(time.DateTime),
+							ReadCnt: 3, LikeCnt: 2, CollectCnt: 1, Liked: true},
+						{Id: 2, Title: "标题2", Abstract: "内容2",
+							Ctime: now.Format(time.DateTime), Utime: now.Format(time.DateTime),
+							ReadCnt: 1},
+					},
+					Cursor: encodePubCursor(now, 2),
+				},
+			},
+		},
+		{
+			name: "最后一页，计数查询失败",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPub(gomock.Any(), now, int64(2), 2).
+					Return([]domain.Article{
+						{Id: 1, Title: "标题1", Content: "内容1", Abstract: "内容1", Ctime: now, Utime: now},
+					}, nil)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}, int64(123)).
+					Return(nil, errors.New("mock db error"))
+				return svc, intrSvc
+			},
+			query:    "limit=2&cursor=" + encodePubCursor(now, 2),
+			wantCode: http.StatusOK,
+			wantRes: pubListResult{
+				Data: PubListVO{
+					List: []ArticleVO{
+						{Id: 1, Title: "标题1", Abstract: "内容1",
+							Ctime: now.Format(time.DateTime), Utime: now.Format(time.DateTime)},
+					},
+				},
+			},
+		},
+		{
+			name: "游标不对",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				return svcmocks.NewMockArticleService(ctrl), svcmocks.NewMockInteractiveService(ctrl)
+			},
+			query:    "cursor=abc",
+			wantCode: http.StatusOK,
+			wantRes: pubListResult{
+				Code: 4,
+				Msg:  "参数错误",
+			},
+		},
+		{
+			name: "查询列表失败",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), defaultPubListLimit).
+					Return(nil, errors.New("mock db error"))
+				return svc, svcmocks.NewMockInteractiveService(ctrl)
+			},
+			wantCode: http.StatusOK,
+			wantRes: pubListResult{
+				Code: 5,
+				Msg:  "系统错误",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", ijwt.UserClaims{
+					Uid: 123,
+				})
+			})
+			svc, intrSvc := tc.mock(ctrl)
+			h := NewArticleHandler(svc, &logger.NoOpLogger{}, intrSvc, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet,
+				"/articles/pub/list?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if resp.Code != 200 {
+				return
+			}
+			var webRes pubListResult
+			err = json.NewDecoder(resp.Body).Decode(&webRes)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantRes, webRes)
+		})
+	}
+}
+
+type pubListResult struct {
+	Code int       `json:"code"`
+	Msg  string    `json:"msg"`
+	Data PubListVO `json:"data"`
+}
+
+func TestArticleHandler_Restore(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.ArticleService
+
+		wantCode int
+		wantMsg  string
+	}{
+		{
+			name: "恢复成功",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).Return(nil)
+				return svc
+			},
+			wantMsg: "OK",
+		},
+		{
+			name: "已经过了保留时间",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).
+					Return(service.ErrNotInRecycleBin)
+				return svc
+			},
+			wantCode: 4,
+			wantMsg:  "文章不在回收站里面",
+		},
+		{
+			name: "系统错误",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Restore(gomock.Any(), int64(123), int64(1)).
+					Return(errors.New("mock db error"))
+				return svc
+			},
+			wantCode: 5,
+			wantMsg:  "系统错误",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("claims", &ijwt.UserClaims{
+					Uid: 123,
+				})
+			})
+			h := NewArticleHandler(tc.mock(ctrl), &logger.NoOpLogger{}, nil, nil, nil)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodPost, "/articles/recycle/restore",
+				bytes.NewBufferString(`{"id":1}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var webRes Result
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&webRes))
+			assert.Equal(t, tc.wantCode, webRes.Code)
+			assert.Equal(t, tc.wantMsg, webRes.Msg)
+		})
+	}
+}
+
+func TestArticleHandler_PubDetail(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims *ijwt.UserClaims
+		uid    int64
+		// fingerprint 没有登录的时候必须带上指纹，不然阅读数没法去重
+		fingerprint gomock.Matcher
+	}{
+		{
+			name:        "登录了",
+			claims:      &ijwt.UserClaims{Uid: 123},
+			uid:         123,
+			fingerprint: gomock.Any(),
+		},
+		{
+			name:        "没有登录",
+			uid:         0,
+			fingerprint: gomock.Not(""),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := svcmocks.NewMockArticleService(ctrl)
+			svc.EXPECT().GetPublishedById(gomock.Any(), int64(1), tc.uid, tc.fingerprint).
+				Return(domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 234},
+					Rendered: &domain.RenderedContent{HTML: "<p>内容</p>"}}, nil)
+			intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+			intrSvc.EXPECT().Get(gomock.Any(), "article", int64(1), tc.uid).
+				Return(domain.Interactive{ReadCnt: 10}, nil)
+			followSvc := svcmocks.NewMockFollowService(ctrl)
+			followSvc.EXPECT().GetFollowStatics(gomock.Any(), int64(234), tc.uid).
+				Return(domain.FollowStatics{Followers: 5}, nil)
+			seriesSvc := svcmocks.NewMockSeriesService(ctrl)
+			seriesSvc.EXPECT().Nav(gomock.Any(), int64(1)).Return(domain.SeriesNav{}, service.ErrSeriesNotFound)
+
+			server := gin.Default()
+			if tc.claims != nil {
+				server.Use(func(ctx *gin.Context) {
+					ctx.Set("claims", tc.claims)
+				})
+			}
+			h := NewArticleHandler(svc, &logger.NoOpLogger{}, intrSvc, followSvc, seriesSvc)
+			h.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, "/articles/pub/1", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var webRes struct {
+				Code int       `json:"code"`
+				Data ArticleVO `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&webRes))
+			assert.Equal(t, 0, webRes.Code)
+			assert.Equal(t, int64(1), webRes.Data.Id)
+			assert.Equal(t, "<p>内容</p>", webRes.Data.HTML)
+			assert.Equal(t, int64(10), webRes.Data.ReadCnt)
+		})
+	}
+}
@@ -1,6 +1,24 @@
 package web
 
-import "webook/internal/domain"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"webook/internal/domain"
+)
+
+const (
+	defaultPubListLimit = 20
+	maxPubListLimit     = 100
+	maxRevisionLimit    = 50
+	maxRecycleBinLimit  = 50
+	defaultPopularTags  = 20
+
+	// codeVersionConflict 保存草稿的时候版本号过期了，Data 里面是最新的草稿
+	codeVersionConflict = 409
+)
 
 // VO view object, 即对标前端
 
@@ -10,16 +28,128 @@ type ArticleVO struct {
 	Abstract string `json:"abstract"`
 	Content  string `json:"content"`
 	Status   uint8  `json:"status"`
-	Author   string `json:"author"`
-	Ctime    string `json:"ctime"`
-	Utime    string `json:"utime"`
+	Revision int64  `json:"revision,omitempty"`
+	// Category 和 Tags 只在详情里面有
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Cover 封面图片的 URL
+	Cover string `json:"cover,omitempty"`
+	// HTML 之类的渲染结果只在读者查看详情的时候才有，Content 还是原始的 Markdown
+	HTML           string      `json:"html,omitempty"`
+	TOC            []TOCItemVO `json:"toc,omitempty"`
+	WordCnt        int         `json:"wordCnt,omitempty"`
+	ReadingMinutes int         `json:"readingMinutes,omitempty"`
+	// PublishAt 定时发表的时间，只有定时发表的文章才有
+	PublishAt string `json:"publishAt,omitempty"`
+	// DeletedAt 和 PurgeAt 只有回收站里面的文章才有，过了 PurgeAt 就不能恢复了
+	DeletedAt string `json:"deletedAt,omitempty"`
+	PurgeAt   string `json:"purgeAt,omitempty"`
+	Author    string `json:"author"`
+	Ctime     string `json:"ctime"`
+	Utime     string `json:"utime"`
 	// 点赞之类的信息
 	LikeCnt    int64 `json:"likeCnt"`
 	CollectCnt int64 `json:"collectCnt"`
 	ReadCnt    int64 `json:"readCnt"`
+	CommentCnt int64 `json:"commentCnt"`
 	// 个人是否点赞的信息
 	Liked     bool `json:"liked"`
 	Collected bool `json:"collected"`
+	// 作者的关注数据只在文章详情里面有
+	AuthorId        int64 `json:"authorId,omitempty"`
+	AuthorFollowers int64 `json:"authorFollowers"`
+	AuthorFollowed  bool  `json:"authorFollowed"`
+	// Series 文章所在的专栏，只在读者查看详情的时候才有
+	Series *SeriesNavVO `json:"series,omitempty"`
+}
+
+// newDraftVO 作者查看自己的草稿，带上编辑需要的全部内容
+func newDraftVO(art domain.Article) ArticleVO {
+	return ArticleVO{
+		Id:        art.Id,
+		Title:     art.Title,
+		Abstract:  art.Abstract,
+		Content:   art.Content,
+		Status:    art.Status.ToUint8(),
+		Revision:  art.Revision,
+		Category:  art.Category,
+		Tags:      art.Tags,
+		Cover:     art.Cover,
+		PublishAt: formatPublishAt(art.PublishAt),
+		Ctime:     art.Ctime.Format(time.DateTime),
+		Utime:     art.Utime.Format(time.DateTime),
+	}
+}
+
+type TOCItemVO struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// PubListReq 读者侧的列表请求
+// Cursor 是上一页返回的游标，第一页不需要传
+type PubListReq struct {
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int    `form:"limit" json:"limit"`
+}
+
+func (req PubListReq) parseCursor() (time.Time, int64, error) {
+	if req.Cursor == "" {
+		return time.Time{}, 0, nil
+	}
+	segs := strings.Split(req.Cursor, "_")
+	if len(segs) != 2 {
+		return time.Time{}, 0, errors.New("游标格式不对")
+	}
+	utime, err := strconv.ParseInt(segs[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(segs[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.UnixMilli(utime), id, nil
+}
+
+func (req PubListReq) limit() int {
+	if req.Limit <= 0 || req.Limit > maxPubListLimit {
+		return defaultPubListLimit
+	}
+	return req.Limit
+}
+
+// encodePubCursor 游标由最后一条数据的 utime 和 id 构成
+func encodePubCursor(utime time.Time, id int64) string {
+	return fmt.Sprintf("%d_%d", utime.UnixMilli(), id)
+}
+
+// PubTagListReq 按照标签查询文章列表
+type PubTagListReq struct {
+	PubListReq
+	Tag string `form:"tag" json:"tag"`
+}
+
+// PubCategoryListReq 按照分类查询文章列表
+type PubCategoryListReq struct {
+	PubListReq
+	Category string `form:"category" json:"category"`
+}
+
+type PopularTagsReq struct {
+	Limit int `form:"limit" json:"limit"`
+}
+
+type TagVO struct {
+	Name string `json:"name"`
+	Cnt  int64  `json:"cnt"`
+}
+
+type PubListVO struct {
+	List []ArticleVO `json:"list"`
+	// Cursor 下一页的游标，为空说明没有下一页了
+	Cursor string `json:"cursor"`
 }
 
 type LikeReq struct {
@@ -32,19 +162,110 @@ type CollectReq struct {
 	Cid int64 `json:"cid"`
 }
 
+// ArticleReq Tags 不传代表不修改标签，传空数组代表清空标签
 type ArticleReq struct {
-	Id      int64  `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Id int64 `json:"id"`
+	// Revision 编辑的时候拿到的版本号，保存成功之后加一
+	// Id 不为 0 的时候必须传
+	Revision int64  `json:"revision"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	// Category 和 Tags 一样，没有传的时候不修改原来的分类
+	Category *string  `json:"category"`
+	Tags     []string `json:"tags"`
+	// Cover 通过 /images/upload 上传之后拿到的 URL
+	// 和 Category 一样，没有传的时候不修改，传空字符串代表去掉封面
+	Cover *string `json:"cover"`
 }
 
 func (req ArticleReq) toDomain(uid int64) domain.Article {
-	return domain.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+	art := domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Tags:     req.Tags,
+		Revision: req.Revision,
 		Author: domain.Author{
 			Id: uid,
 		},
 	}
+	if req.Category != nil {
+		art.Category = *req.Category
+	}
+	if req.Cover != nil {
+		art.Cover = *req.Cover
+	}
+	return art
+}
+
+// RevisionListReq 历史版本列表
+type RevisionListReq struct {
+	Id     int64 `json:"id"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type RevisionReq struct {
+	Id       int64 `json:"id"`
+	Revision int64 `json:"revision"`
+}
+
+// RevisionDiffReq 比较 From 和 To 两个版本
+type RevisionDiffReq struct {
+	Id   int64 `json:"id"`
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+type RevisionVO struct {
+	Revision int64  `json:"revision"`
+	Title    string `json:"title"`
+	Content  string `json:"content,omitempty"`
+	Status   uint8  `json:"status"`
+	// Live 是否是线上正在展示的版本
+	Live  bool   `json:"live"`
+	Ctime string `json:"ctime"`
+}
+
+type RevisionListVO struct {
+	// Live 线上正在展示的版本号，为 0 说明没有发表过
+	Live int64        `json:"live"`
+	List []RevisionVO `json:"list"`
+}
+
+// DiffLineVO Op 是 " "、"+" 或者 "-"，行号为 0 说明这一行在对应的版本里面不存在
+type DiffLineVO struct {
+	Op    string `json:"op"`
+	OldNo int    `json:"oldNo,omitempty"`
+	NewNo int    `json:"newNo,omitempty"`
+	Text  string `json:"text"`
+}
+
+// ScheduleReq 定时发表，PublishAt 是毫秒时间戳
+type ScheduleReq struct {
+	ArticleReq
+	PublishAt int64 `json:"publishAt"`
+}
+
+// RescheduleReq 修改定时发表的时间，PublishAt 是毫秒时间戳
+type RescheduleReq struct {
+	Id        int64 `json:"id"`
+	PublishAt int64 `json:"publishAt"`
+}
+
+// ArticleIdReq 只需要文章 ID 的请求，比如删除和从回收站恢复
+type ArticleIdReq struct {
+	Id int64 `json:"id"`
+}
+
+type CancelScheduleReq struct {
+	Id int64 `json:"id"`
+}
+
+// formatPublishAt 没有定时发表的时候返回空字符串
+func formatPublishAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
 }
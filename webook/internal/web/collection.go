@@ -0,0 +1,147 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*CollectionHandler)(nil)
+
+// CollectionHandler 收藏夹管理，收藏本身还是在 ArticleHandler 里面
+type CollectionHandler struct {
+	artSvc  service.ArticleService
+	intrSvc service.InteractiveService
+	biz     string
+}
+
+func NewCollectionHandler(artSvc service.ArticleService, intrSvc service.InteractiveService) *CollectionHandler {
+	return &CollectionHandler{
+		artSvc:  artSvc,
+		intrSvc: intrSvc,
+		biz:     "article",
+	}
+}
+
+func (h *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/pub/collect")
+	g.POST("/cancel", ginx.WrapReqAndToken[CollectReq, jwt.UserClaims](h.Cancel))
+	g.POST("/move", ginx.WrapReqAndToken[MoveCollectionItemReq, jwt.UserClaims](h.Move))
+	g.POST("/items", ginx.WrapReqAndToken[CollectionItemsReq, jwt.UserClaims](h.Items))
+
+	folder := g.Group("/folder")
+	folder.POST("/create", ginx.WrapReqAndToken[CollectionReq, jwt.UserClaims](h.Create))
+	folder.POST("/rename", ginx.WrapReqAndToken[CollectionReq, jwt.UserClaims](h.Rename))
+	folder.POST("/list", ginx.WrapReqAndToken[Page, jwt.UserClaims](h.List))
+	folder.POST("/delete", ginx.WrapReqAndToken[CollectionReq, jwt.UserClaims](h.Delete))
+}
+
+func (h *CollectionHandler) Create(ctx *gin.Context, req CollectionReq, uc jwt.UserClaims) (ginx.Result, error) {
+	if req.Name == "" {
+		return Result{Code: 4, Msg: "收藏夹名字不能为空"}, nil
+	}
+	id, err := h.intrSvc.CreateCollection(ctx, uc.Uid, req.Name)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: id}, nil
+}
+
+func (h *CollectionHandler) Rename(ctx *gin.Context, req CollectionReq, uc jwt.UserClaims) (ginx.Result, error) {
+	if req.Name == "" {
+		return Result{Code: 4, Msg: "收藏夹名字不能为空"}, nil
+	}
+	err := h.intrSvc.RenameCollection(ctx, req.Id, uc.Uid, req.Name)
+	return h.result(err)
+}
+
+func (h *CollectionHandler) List(ctx *gin.Context, req Page, uc jwt.UserClaims) (ginx.Result, error) {
+	cols, err := h.intrSvc.ListCollections(ctx, uc.Uid, req.Offset, h.limit(req.Limit))
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: slice.Map[domain.Collection, CollectionVO](cols, func(idx int, src domain.Collection) CollectionVO {
+			return CollectionVO{
+				Id:    src.Id,
+				Name:  src.Name,
+				Ctime: src.Ctime.Format(time.DateTime),
+				Utime: src.Utime.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
+
+func (h *CollectionHandler) Delete(ctx *gin.Context, req CollectionReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.intrSvc.DeleteCollection(ctx, req.Id, uc.Uid)
+	return h.result(err)
+}
+
+// Cancel 取消收藏，一个资源只会在一个收藏夹里面，所以不需要 Cid
+func (h *CollectionHandler) Cancel(ctx *gin.Context, req CollectReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.intrSvc.CancelCollect(ctx, h.biz, req.Id, uc.Uid)
+	return h.result(err)
+}
+
+func (h *CollectionHandler) Move(ctx *gin.Context, req MoveCollectionItemReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.intrSvc.MoveCollectionItem(ctx, h.biz, req.Id, uc.Uid, req.Cid)
+	return h.result(err)
+}
+
+func (h *CollectionHandler) Items(ctx *gin.Context, req CollectionItemsReq, uc jwt.UserClaims) (ginx.Result, error) {
+	items, err := h.intrSvc.ListCollectionItems(ctx, req.Cid, uc.Uid, req.Offset, h.limit(req.Limit))
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	ids := slice.Map[domain.CollectionItem, int64](items, func(idx int, src domain.CollectionItem) int64 {
+		return src.BizId
+	})
+	arts, err := h.artSvc.ListPubByIds(ctx, ids)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	artMap := make(map[int64]domain.Article, len(arts))
+	for _, art := range arts {
+		artMap[art.Id] = art
+	}
+	res := make([]CollectionItemVO, 0, len(items))
+	for _, item := range items {
+		// 文章可能已经被撤回了，依旧展示出来，方便用户取消收藏
+		art := artMap[item.BizId]
+		res = append(res, CollectionItemVO{
+			Cid:   item.Cid,
+			Ctime: item.Ctime.Format(time.DateTime),
+			Article: ArticleVO{
+				Id:       item.BizId,
+				Title:    art.Title,
+				Abstract: art.Abstract,
+				Cover:    art.Cover,
+				Author:   art.Author.Name,
+			},
+		})
+	}
+	return Result{Data: res}, nil
+}
+
+func (h *CollectionHandler) result(err error) (ginx.Result, error) {
+	switch {
+	case err == nil:
+		return Result{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrCollectionNotFound):
+		return Result{Code: 4, Msg: "收藏夹不存在"}, err
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *CollectionHandler) limit(limit int) int {
+	if limit <= 0 || limit > maxCollectionPageLimit {
+		return maxCollectionPageLimit
+	}
+	return limit
+}
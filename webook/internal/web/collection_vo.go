@@ -0,0 +1,34 @@
+package web
+
+const maxCollectionPageLimit = 100
+
+type CollectionReq struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type CollectionItemsReq struct {
+	Cid    int64 `json:"cid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type MoveCollectionItemReq struct {
+	// Id 文章 ID
+	Id  int64 `json:"id"`
+	Cid int64 `json:"cid"`
+}
+
+type CollectionVO struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Ctime string `json:"ctime"`
+	Utime string `json:"utime"`
+}
+
+type CollectionItemVO struct {
+	Cid int64 `json:"cid"`
+	// Ctime 收藏的时间
+	Ctime   string    `json:"ctime"`
+	Article ArticleVO `json:"article"`
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*CommentHandler)(nil)
+
+type CommentHandler struct {
+	svc service.CommentService
+}
+
+func NewCommentHandler(svc service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		svc: svc,
+	}
+}
+
+func (h *CommentHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/comments")
+	g.POST("/create", ginx.WrapReqAndToken[CommentReq, jwt.UserClaims](h.Create))
+	g.POST("/list", ginx.WrapReqAndToken[CommentListReq, jwt.UserClaims](h.List))
+	g.POST("/replies", ginx.WrapReqAndToken[CommentRepliesReq, jwt.UserClaims](h.Replies))
+	g.POST("/delete", ginx.WrapReqAndToken[CommentDeleteReq, jwt.UserClaims](h.Delete))
+}
+
+func (h *CommentHandler) Create(ctx *gin.Context, req CommentReq, uc jwt.UserClaims) (ginx.Result, error) {
+	if req.Content == "" || utf8.RuneCountInString(req.Content) > maxCommentLength {
+		return Result{Code: 4, Msg: "评论内容不能为空，也不能太长"}, nil
+	}
+	id, err := h.svc.Comment(ctx, domain.Comment{
+		Uid:      uc.Uid,
+		Biz:      req.Biz,
+		BizId:    req.BizId,
+		ParentId: req.ParentId,
+		Content:  req.Content,
+	})
+	switch {
+	case err == nil:
+		return Result{Data: id}, nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return Result{Code: 4, Msg: "回复的评论不存在"}, nil
+	case errors.Is(err, service.ErrCommentBizNotFound):
+		return Result{Code: 4, Msg: "评论的内容不存在"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *CommentHandler) List(ctx *gin.Context, req CommentListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := h.limit(req.Limit)
+	var (
+		cmts []domain.Comment
+		err  error
+	)
+	if req.Sort == "hot" {
+		cmts, err = h.svc.ListHotRoots(ctx, req.Biz, req.BizId, req.Offset, limit)
+	} else {
+		cmts, err = h.svc.ListRoots(ctx, req.Biz, req.BizId, req.MinId, limit)
+	}
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: h.toVOs(cmts)}, nil
+}
+
+func (h *CommentHandler) Replies(ctx *gin.Context, req CommentRepliesReq, uc jwt.UserClaims) (ginx.Result, error) {
+	cmts, err := h.svc.ListReplies(ctx, req.Rid, req.MaxId, h.limit(req.Limit))
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: h.toVOs(cmts)}, nil
+}
+
+func (h *CommentHandler) Delete(ctx *gin.Context, req CommentDeleteReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, req.Id, uc.Uid)
+	switch {
+	case err == nil:
+		return Result{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrCommentNotFound):
+		return Result{Code: 4, Msg: "评论不存在"}, nil
+	case errors.Is(err, service.ErrCommentNoPermission):
+		return Result{Code: 4, Msg: "只能删除自己的评论"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *CommentHandler) limit(limit int) int {
+	if limit <= 0 || limit > maxCommentPageLimit {
+		return maxCommentPageLimit
+	}
+	return limit
+}
+
+func (h *CommentHandler) toVOs(cmts []domain.Comment) []CommentVO {
+	return slice.Map[domain.Comment, CommentVO](cmts, func(idx int, src domain.Comment) CommentVO {
+		return CommentVO{
+			Id:       src.Id,
+			Uid:      src.Uid,
+			RootId:   src.RootId,
+			ParentId: src.ParentId,
+			Content:  src.Content,
+			ReplyCnt: src.ReplyCnt,
+			Replies:  h.toVOs(src.Replies),
+			Ctime:    src.Ctime.Format(time.DateTime),
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package web
+
+const (
+	maxCommentPageLimit = 50
+	maxCommentLength    = 2000
+)
+
+type CommentReq struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// ParentId 回复哪一条评论，发表根评论的时候不用传
+	ParentId int64  `json:"parentId"`
+	Content  string `json:"content"`
+}
+
+// CommentListReq 查询根评论
+// Sort 为 hot 的时候按照热度排序，使用 Offset 分页；否则按照时间倒序，使用 MinId 分页
+type CommentListReq struct {
+	Biz    string `json:"biz"`
+	BizId  int64  `json:"bizId"`
+	Sort   string `json:"sort"`
+	MinId  int64  `json:"minId"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type CommentRepliesReq struct {
+	// Rid 根评论 ID
+	Rid int64 `json:"rid"`
+	// MaxId 上一页最后一条回复的 ID
+	MaxId int64 `json:"maxId"`
+	Limit int   `json:"limit"`
+}
+
+type CommentDeleteReq struct {
+	Id int64 `json:"id"`
+}
+
+type CommentVO struct {
+	Id       int64       `json:"id"`
+	Uid      int64       `json:"uid"`
+	RootId   int64       `json:"rootId"`
+	ParentId int64       `json:"parentId"`
+	Content  string      `json:"content"`
+	ReplyCnt int64       `json:"replyCnt"`
+	Replies  []CommentVO `json:"replies,omitempty"`
+	Ctime    string      `json:"ctime"`
+}
@@ -0,0 +1,258 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/pkg/logger"
+)
+
+var _ handler = (*FeedHandler)(nil)
+
+const (
+	// 前端页面的路径
+	articlePagePath = "/articles/%d"
+	authorPagePath  = "/users/%d"
+
+	contentTypeRSS  = "application/rss+xml; charset=utf-8"
+	contentTypeAtom = "application/atom+xml; charset=utf-8"
+	contentTypeXML  = "application/xml; charset=utf-8"
+)
+
+type FeedHandlerConfig struct {
+	// BaseURL 站点对外的地址，不带结尾的 /
+	// 文章页面、订阅源和站点地图的链接都基于它
+	BaseURL string
+}
+
+// FeedHandler RSS、Atom 订阅源和站点地图，不需要登录，
+// 都会带上 ETag 和 Last-Modified，客户端缓存还有效的时候返回 304
+type FeedHandler struct {
+	svc service.FeedService
+	l   logger.LoggerV1
+	cfg FeedHandlerConfig
+}
+
+func NewFeedHandler(svc service.FeedService, l logger.LoggerV1, cfg FeedHandlerConfig) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		l:   l,
+		cfg: cfg,
+	}
+}
+
+func (h *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/feeds/authors")
+	g.GET("/:id/rss", h.RSS)
+	g.GET("/:id/atom", h.Atom)
+
+	server.GET("/sitemap.xml", h.SitemapIndex)
+	// 分页的站点地图，比如 /sitemaps/1.xml
+	server.GET("/sitemaps/:page", h.Sitemap)
+}
+
+func (h *FeedHandler) RSS(ctx *gin.Context) {
+	feed, ok := h.authorFeed(ctx)
+	if !ok {
+		return
+	}
+	title := fmt.Sprintf("%s 的文章", feed.Author.Name)
+	vo := rssVO{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannelVO{
+			Title:       title,
+			Link:        h.url(authorPagePath, feed.Author.Id),
+			Description: title,
+			Self: rssSelfVO{
+				Href: h.url("/feeds/authors/%d/rss", feed.Author.Id),
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Items: make([]rssItemVO, 0, len(feed.Items)),
+		},
+	}
+	if !feed.Updated.IsZero() {
+		vo.Channel.LastBuildDate = feed.Updated.Format(time.RFC1123Z)
+	}
+	for _, item := range feed.Items {
+		link := h.url(articlePagePath, item.Id)
+		vo.Channel.Items = append(vo.Channel.Items, rssItemVO{
+			Title:       item.Title,
+			Link:        link,
+			Description: item.Abstract,
+			Category:    item.Category,
+			GUID:        rssGUIDVO{IsPermaLink: true, Value: link},
+			PubDate:     item.Ctime.Format(time.RFC1123Z),
+		})
+	}
+	h.writeXML(ctx, contentTypeRSS, vo, feed.Updated)
+}
+
+func (h *FeedHandler) Atom(ctx *gin.Context) {
+	feed, ok := h.authorFeed(ctx)
+	if !ok {
+		return
+	}
+	authorURL := h.url(authorPagePath, feed.Author.Id)
+	// updated 是必须的，没有文章的时候用 Unix 零点
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	vo := atomFeedVO{
+		NS:      "http://www.w3.org/2005/Atom",
+		Id:      authorURL,
+		Title:   fmt.Sprintf("%s 的文章", feed.Author.Name),
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLinkVO{
+			{Href: authorURL, Rel: "alternate", Type: "text/html"},
+			{Href: h.url("/feeds/authors/%d/atom", feed.Author.Id), Rel: "self", Type: "application/atom+xml"},
+		},
+		Author:  atomPersonVO{Name: feed.Author.Name, URI: authorURL},
+		Entries: make([]atomEntryVO, 0, len(feed.Items)),
+	}
+	for _, item := range feed.Items {
+		link := h.url(articlePagePath, item.Id)
+		entry := atomEntryVO{
+			Id:        link,
+			Title:     item.Title,
+			Link:      atomLinkVO{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: item.Ctime.UTC().Format(time.RFC3339),
+			Updated:   item.Utime.UTC().Format(time.RFC3339),
+			Summary:   atomSummaryVO{Type: "text", Value: item.Abstract},
+		}
+		if item.Category != "" {
+			entry.Category = &atomCategoryVO{Term: item.Category}
+		}
+		vo.Entries = append(vo.Entries, entry)
+	}
+	h.writeXML(ctx, contentTypeAtom, vo, feed.Updated)
+}
+
+func (h *FeedHandler) authorFeed(ctx *gin.Context) (domain.Feed, bool) {
+	uid, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Status(http.StatusNotFound)
+		return domain.Feed{}, false
+	}
+	feed, err := h.svc.AuthorFeed(ctx, uid)
+	switch {
+	case errors.Is(err, service.ErrFeedAuthorNotFound):
+		ctx.Status(http.StatusNotFound)
+		return domain.Feed{}, false
+	case err != nil:
+		h.l.Error("生成订阅源失败", logger.Int64("uid", uid), logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return domain.Feed{}, false
+	}
+	return feed, true
+}
+
+func (h *FeedHandler) SitemapIndex(ctx *gin.Context) {
+	pages, lastMod, err := h.svc.SitemapIndex(ctx)
+	if err != nil {
+		h.l.Error("生成站点地图索引失败", logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	vo := sitemapIndexVO{
+		NS:       "http://www.sitemaps.org/schemas/sitemap/0.9",
+		Sitemaps: make([]sitemapLocVO, 0, pages),
+	}
+	for i := 1; i <= pages; i++ {
+		vo.Sitemaps = append(vo.Sitemaps, sitemapLocVO{Loc: h.url("/sitemaps/%d.xml", i)})
+	}
+	h.writeXML(ctx, contentTypeXML, vo, lastMod)
+}
+
+func (h *FeedHandler) Sitemap(ctx *gin.Context) {
+	pageStr, ok := strings.CutSuffix(ctx.Param("page"), ".xml")
+	page, err := strconv.Atoi(pageStr)
+	if !ok || err != nil || page < 1 {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	entries, lastMod, err := h.svc.SitemapPage(ctx, page)
+	if err != nil {
+		h.l.Error("生成站点地图失败", logger.Int64("page", int64(page)), logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	// 第一页永远存在，后面的页超出范围就是不存在
+	if len(entries) == 0 && page > 1 {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	vo := sitemapURLSetVO{
+		NS:   "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs: make([]sitemapLocVO, 0, len(entries)),
+	}
+	for _, e := range entries {
+		vo.URLs = append(vo.URLs, sitemapLocVO{
+			Loc:     h.url(articlePagePath, e.ArticleId),
+			LastMod: e.Utime.UTC().Format(time.RFC3339),
+		})
+	}
+	h.writeXML(ctx, contentTypeXML, vo, lastMod)
+}
+
+func (h *FeedHandler) url(format string, args ...any) string {
+	return h.cfg.BaseURL + fmt.Sprintf(format, args...)
+}
+
+func (h *FeedHandler) writeXML(ctx *gin.Context, contentType string, vo any, lastMod time.Time) {
+	data, err := xml.Marshal(vo)
+	if err != nil {
+		h.l.Error("序列化 XML 失败", logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	writeConditional(ctx, contentType, append([]byte(xml.Header), data...), lastMod)
+}
+
+// writeConditional ETag 由内容决定，lastMod 为零值的时候不带 Last-Modified
+func writeConditional(ctx *gin.Context, contentType string, body []byte, lastMod time.Time) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	ctx.Header("ETag", etag)
+	if !lastMod.IsZero() {
+		ctx.Header("Last-Modified", lastMod.UTC().Format(http.TimeFormat))
+	}
+	if notModified(ctx.Request, etag, lastMod) {
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+// notModified 有 If-None-Match 的时候忽略 If-Modified-Since，和 RFC 9110 保持一致
+func notModified(req *http.Request, etag string, lastMod time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			// 弱比较，W/ 前缀不影响结果
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims := req.Header.Get("If-Modified-Since")
+	if ims == "" || lastMod.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(ims)
+	// HTTP 的时间只精确到秒
+	return err == nil && !lastMod.Truncate(time.Second).After(t)
+}
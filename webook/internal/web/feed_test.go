@@ -0,0 +1,157 @@
+package web
+
+import (
+	"encoding/xml"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmocks "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func TestFeedHandler_RSS(t *testing.T) {
+	utime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	feed := domain.Feed{
+		Author: domain.Author{Id: 123, Name: "大明"},
+		Items: []domain.FeedItem{
+			{Id: 1, Title: "第一篇", Abstract: "纯文本摘要", Ctime: utime, Utime: utime},
+		},
+		Updated: utime,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockFeedService(ctrl)
+	svc.EXPECT().AuthorFeed(gomock.Any(), int64(123)).Return(feed, nil).Times(4)
+	svc.EXPECT().AuthorFeed(gomock.Any(), int64(234)).Return(domain.Feed{}, service.ErrFeedAuthorNotFound)
+	server := gin.Default()
+	NewFeedHandler(svc, &logger.NoOpLogger{}, FeedHandlerConfig{BaseURL: "https://webook.com"}).
+		RegisterRoutes(server)
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("/feeds/authors/123/rss", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, contentTypeRSS, resp.Header().Get("Content-Type"))
+	assert.Equal(t, "Wed, 01 May 2024 08:00:00 GMT", resp.Header().Get("Last-Modified"))
+	etag := resp.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	var rss rssVO
+	require.NoError(t, xml.Unmarshal(resp.Body.Bytes(), &rss))
+	require.Len(t, rss.Channel.Items, 1)
+	assert.Equal(t, "https://webook.com/articles/1", rss.Channel.Items[0].Link)
+	assert.Equal(t, "纯文本摘要", rss.Channel.Items[0].Description)
+
+	// 内容没有变化，ETag 也不会变
+	resp = get("/feeds/authors/123/rss", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.Bytes())
+
+	resp = get("/feeds/authors/123/rss", map[string]string{
+		"If-Modified-Since": utime.Format(http.TimeFormat),
+	})
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+
+	// If-None-Match 对不上的时候不看 If-Modified-Since
+	resp = get("/feeds/authors/123/rss", map[string]string{
+		"If-None-Match":     `"stale"`,
+		"If-Modified-Since": utime.Format(http.TimeFormat),
+	})
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = get("/feeds/authors/234/rss", nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+// TestFeedHandler_RSSWithdraw 撤回最新的文章之后，剩下的文章都比客户端缓存的时间早，
+// Last-Modified 不能跟着往回走，否则客户端会一直拿到 304，看不到文章已经撤回了
+func TestFeedHandler_RSSWithdraw(t *testing.T) {
+	utime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	withdrawn := utime.Add(time.Hour)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockFeedService(ctrl)
+	svc.EXPECT().AuthorFeed(gomock.Any(), int64(123)).Return(domain.Feed{
+		Author: domain.Author{Id: 123, Name: "大明"},
+		Items: []domain.FeedItem{
+			{Id: 1, Title: "第一篇", Ctime: utime.Add(-time.Hour), Utime: utime.Add(-time.Hour)},
+		},
+		Updated: withdrawn,
+	}, nil).Times(2)
+	server := gin.Default()
+	NewFeedHandler(svc, &logger.NoOpLogger{}, FeedHandlerConfig{BaseURL: "https://webook.com"}).
+		RegisterRoutes(server)
+
+	// 客户端上一次拿到的是撤回之前的版本，最新的文章是 utime 发表的
+	req := httptest.NewRequest(http.MethodGet, "/feeds/authors/123/rss", nil)
+	req.Header.Set("If-Modified-Since", utime.Format(http.TimeFormat))
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "Wed, 01 May 2024 09:00:00 GMT", resp.Header().Get("Last-Modified"))
+	var rss rssVO
+	require.NoError(t, xml.Unmarshal(resp.Body.Bytes(), &rss))
+	require.Len(t, rss.Channel.Items, 1)
+	assert.Equal(t, "https://webook.com/articles/1", rss.Channel.Items[0].Link)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/authors/123/rss", nil)
+	req.Header.Set("If-Modified-Since", withdrawn.Format(http.TimeFormat))
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+}
+
+func TestFeedHandler_Sitemap(t *testing.T) {
+	utime := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := svcmocks.NewMockFeedService(ctrl)
+	svc.EXPECT().SitemapPage(gomock.Any(), 1).Return([]domain.SitemapEntry{
+		{ArticleId: 1, Utime: utime.Add(-time.Hour)},
+		{ArticleId: 2, Utime: utime},
+	}, utime.Add(time.Hour), nil)
+	svc.EXPECT().SitemapPage(gomock.Any(), 2).Return([]domain.SitemapEntry{}, utime.Add(time.Hour), nil)
+	server := gin.Default()
+	NewFeedHandler(svc, &logger.NoOpLogger{}, FeedHandlerConfig{BaseURL: "https://webook.com"}).
+		RegisterRoutes(server)
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemaps/1.xml", nil)
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	// 最后更新时间是线上内容最后一次变化的时间，不是这一页里面最新的文章
+	assert.Equal(t, "Wed, 01 May 2024 09:00:00 GMT", resp.Header().Get("Last-Modified"))
+	var set sitemapURLSetVO
+	require.NoError(t, xml.Unmarshal(resp.Body.Bytes(), &set))
+	assert.Equal(t, []sitemapLocVO{
+		{Loc: "https://webook.com/articles/1", LastMod: "2024-05-01T07:00:00Z"},
+		{Loc: "https://webook.com/articles/2", LastMod: "2024-05-01T08:00:00Z"},
+	}, set.URLs)
+
+	// 超出范围的页不存在
+	req = httptest.NewRequest(http.MethodGet, "/sitemaps/2.xml", nil)
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/sitemaps/abc", nil)
+	resp = httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
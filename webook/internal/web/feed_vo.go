@@ -0,0 +1,100 @@
+package web
+
+import "encoding/xml"
+
+// RSS 2.0，atom:link 是 RSS 推荐带上的自身地址
+type rssVO struct {
+	XMLName xml.Name     `xml:"rss"`
+	Version string       `xml:"version,attr"`
+	AtomNS  string       `xml:"xmlns:atom,attr"`
+	Channel rssChannelVO `xml:"channel"`
+}
+
+type rssChannelVO struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Self          rssSelfVO   `xml:"atom:link"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Items         []rssItemVO `xml:"item"`
+}
+
+type rssSelfVO struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItemVO struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Category    string    `xml:"category,omitempty"`
+	GUID        rssGUIDVO `xml:"guid"`
+	PubDate     string    `xml:"pubDate"`
+}
+
+type rssGUIDVO struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// Atom RFC 4287
+type atomFeedVO struct {
+	XMLName xml.Name      `xml:"feed"`
+	NS      string        `xml:"xmlns,attr"`
+	Id      string        `xml:"id"`
+	Title   string        `xml:"title"`
+	Updated string        `xml:"updated"`
+	Links   []atomLinkVO  `xml:"link"`
+	Author  atomPersonVO  `xml:"author"`
+	Entries []atomEntryVO `xml:"entry"`
+}
+
+type atomLinkVO struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPersonVO struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomEntryVO struct {
+	Id        string          `xml:"id"`
+	Title     string          `xml:"title"`
+	Link      atomLinkVO      `xml:"link"`
+	Published string          `xml:"published"`
+	Updated   string          `xml:"updated"`
+	Category  *atomCategoryVO `xml:"category,omitempty"`
+	Summary   atomSummaryVO   `xml:"summary"`
+}
+
+type atomCategoryVO struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSummaryVO struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// 站点地图 https://www.sitemaps.org/protocol.html
+type sitemapIndexVO struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	NS       string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapLocVO `xml:"sitemap"`
+}
+
+type sitemapURLSetVO struct {
+	XMLName xml.Name       `xml:"urlset"`
+	NS      string         `xml:"xmlns,attr"`
+	URLs    []sitemapLocVO `xml:"url"`
+}
+
+type sitemapLocVO struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
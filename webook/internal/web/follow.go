@@ -0,0 +1,113 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*FollowHandler)(nil)
+
+type FollowHandler struct {
+	svc service.FollowService
+}
+
+func NewFollowHandler(svc service.FollowService) *FollowHandler {
+	return &FollowHandler{
+		svc: svc,
+	}
+}
+
+func (h *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/follow")
+	g.POST("/follow", ginx.WrapReqAndToken[FollowReq, jwt.UserClaims](h.Follow))
+	g.POST("/cancel", ginx.WrapReqAndToken[FollowReq, jwt.UserClaims](h.CancelFollow))
+	g.POST("/followers", ginx.WrapReqAndToken[FollowListReq, jwt.UserClaims](h.Followers))
+	g.POST("/followees", ginx.WrapReqAndToken[FollowListReq, jwt.UserClaims](h.Followees))
+	g.POST("/statics", ginx.WrapReqAndToken[FollowStaticsReq, jwt.UserClaims](h.Statics))
+}
+
+func (h *FollowHandler) Follow(ctx *gin.Context, req FollowReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Follow(ctx, uc.Uid, req.Followee)
+	switch {
+	case err == nil:
+		return Result{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrFollowSelf):
+		return Result{Code: 4, Msg: "不能关注自己"}, nil
+	case errors.Is(err, service.ErrFolloweeNotFound):
+		return Result{Code: 4, Msg: "用户不存在"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *FollowHandler) CancelFollow(ctx *gin.Context, req FollowReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.CancelFollow(ctx, uc.Uid, req.Followee)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (h *FollowHandler) Followers(ctx *gin.Context, req FollowListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	uid, limit := h.listParams(req, uc)
+	frs, err := h.svc.GetFollowers(ctx, uid, req.Offset, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: h.toVOs(frs)}, nil
+}
+
+func (h *FollowHandler) Followees(ctx *gin.Context, req FollowListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	uid, limit := h.listParams(req, uc)
+	frs, err := h.svc.GetFollowees(ctx, uid, req.Offset, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: h.toVOs(frs)}, nil
+}
+
+func (h *FollowHandler) Statics(ctx *gin.Context, req FollowStaticsReq, uc jwt.UserClaims) (ginx.Result, error) {
+	uid := req.Uid
+	if uid <= 0 {
+		uid = uc.Uid
+	}
+	statics, err := h.svc.GetFollowStatics(ctx, uid, uc.Uid)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: FollowStaticsVO{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+			Followed:  statics.Followed,
+		},
+	}, nil
+}
+
+func (h *FollowHandler) listParams(req FollowListReq, uc jwt.UserClaims) (int64, int) {
+	uid := req.Uid
+	if uid <= 0 {
+		uid = uc.Uid
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > maxFollowPageLimit {
+		limit = maxFollowPageLimit
+	}
+	return uid, limit
+}
+
+func (h *FollowHandler) toVOs(frs []domain.FollowRelation) []FollowRelationVO {
+	return slice.Map[domain.FollowRelation, FollowRelationVO](frs, func(idx int, src domain.FollowRelation) FollowRelationVO {
+		return FollowRelationVO{
+			Follower: src.Follower,
+			Followee: src.Followee,
+			Ctime:    src.Ctime.Format(time.DateTime),
+		}
+	})
+}
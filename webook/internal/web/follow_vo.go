@@ -0,0 +1,31 @@
+package web
+
+const maxFollowPageLimit = 100
+
+type FollowReq struct {
+	// Followee 被关注的人
+	Followee int64 `json:"followee"`
+}
+
+type FollowListReq struct {
+	// Uid 查询谁的粉丝或者关注列表，为 0 代表自己
+	Uid    int64 `json:"uid"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+}
+
+type FollowStaticsReq struct {
+	Uid int64 `json:"uid"`
+}
+
+type FollowRelationVO struct {
+	Follower int64  `json:"follower"`
+	Followee int64  `json:"followee"`
+	Ctime    string `json:"ctime"`
+}
+
+type FollowStaticsVO struct {
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	Followed  bool  `json:"followed"`
+}
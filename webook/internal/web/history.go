@@ -0,0 +1,92 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+	"webook/pkg/logger"
+)
+
+var _ handler = (*HistoryHandler)(nil)
+
+// HistoryHandler 我的阅读历史，目前只有文章
+type HistoryHandler struct {
+	svc    service.HistoryService
+	artSvc service.ArticleService
+	l      logger.LoggerV1
+	biz    string
+}
+
+func NewHistoryHandler(svc service.HistoryService, artSvc service.ArticleService, l logger.LoggerV1) *HistoryHandler {
+	return &HistoryHandler{
+		svc:    svc,
+		artSvc: artSvc,
+		l:      l,
+		biz:    "article",
+	}
+}
+
+func (h *HistoryHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/history")
+	g.POST("/list", ginx.WrapReqAndToken[Page, jwt.UserClaims](h.List))
+	g.POST("/delete", ginx.WrapReqAndToken[HistoryReq, jwt.UserClaims](h.Delete))
+	g.POST("/clear", ginx.WrapToken[jwt.UserClaims](h.Clear))
+}
+
+func (h *HistoryHandler) List(ctx *gin.Context, req Page, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > maxHistoryPageLimit {
+		limit = maxHistoryPageLimit
+	}
+	records, err := h.svc.List(ctx, uc.Uid, req.Offset, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	ids := slice.Map[domain.HistoryRecord, int64](records, func(idx int, src domain.HistoryRecord) int64 {
+		return src.BizId
+	})
+	arts, err := h.artSvc.ListPubByIds(ctx, ids)
+	if err != nil {
+		// 文章信息查不到，依旧可以展示阅读时间
+		h.l.Error("查询阅读历史的文章失败", logger.Error(err))
+	}
+	artMap := make(map[int64]domain.Article, len(arts))
+	for _, art := range arts {
+		artMap[art.Id] = art
+	}
+	return Result{
+		Data: slice.Map[domain.HistoryRecord, HistoryVO](records, func(idx int, src domain.HistoryRecord) HistoryVO {
+			art := artMap[src.BizId]
+			return HistoryVO{
+				ReadTime: src.Utime.Format(time.DateTime),
+				Article: ArticleVO{
+					Id:       src.BizId,
+					Title:    art.Title,
+					Abstract: art.Abstract,
+					Cover:    art.Cover,
+					Author:   art.Author.Name,
+				},
+			}
+		}),
+	}, nil
+}
+
+func (h *HistoryHandler) Delete(ctx *gin.Context, req HistoryReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Delete(ctx, uc.Uid, h.biz, req.Id)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+func (h *HistoryHandler) Clear(ctx *gin.Context, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Clear(ctx, uc.Uid)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Msg: "OK"}, nil
+}
@@ -0,0 +1,13 @@
+package web
+
+const maxHistoryPageLimit = 50
+
+type HistoryReq struct {
+	// Id 文章 ID
+	Id int64 `json:"id"`
+}
+
+type HistoryVO struct {
+	ReadTime string    `json:"readTime"`
+	Article  ArticleVO `json:"article"`
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+	"webook/pkg/logger"
+)
+
+var _ handler = (*ImageHandler)(nil)
+
+// rawImagePrefix 本地存储的图片通过这个路径访问，不需要登录
+const rawImagePrefix = "/images/raw/"
+
+type ImageHandler struct {
+	svc service.ImageService
+	l   logger.LoggerV1
+}
+
+func NewImageHandler(svc service.ImageService, l logger.LoggerV1) *ImageHandler {
+	return &ImageHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *ImageHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/images")
+	g.POST("/upload", ginx.WrapReqAndToken[ImageUploadReq, jwt.UserClaims](h.Upload))
+	g.GET("/raw/*key", h.Raw)
+}
+
+func (h *ImageHandler) Upload(ctx *gin.Context, req ImageUploadReq, uc jwt.UserClaims) (ginx.Result, error) {
+	usage, ok := req.usage()
+	if !ok {
+		return Result{Code: 4, Msg: "图片用途不对"}, nil
+	}
+	if req.File == nil {
+		return Result{Code: 4, Msg: "请选择图片"}, nil
+	}
+	if req.File.Size > maxUploadSize {
+		return Result{Code: 4, Msg: "图片太大"}, nil
+	}
+	f, err := req.File.Open()
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("打开上传的文件失败 %w", err)
+	}
+	defer f.Close()
+	// 多读一个字节，超出限制交给 service 判断
+	data, err := io.ReadAll(io.LimitReader(f, maxUploadSize+1))
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("读取上传的文件失败 %w", err)
+	}
+	img, err := h.svc.Upload(ctx, uc.Uid, data, usage)
+	switch {
+	case err == nil:
+		return Result{
+			Data: ImageVO{
+				Id:          img.Id,
+				URL:         img.URL,
+				ContentType: img.ContentType,
+				Size:        img.Size,
+				Width:       img.Width,
+				Height:      img.Height,
+			},
+		}, nil
+	case errors.Is(err, service.ErrImageTooLarge):
+		return Result{Code: 4, Msg: "图片太大"}, nil
+	case errors.Is(err, service.ErrInvalidImage):
+		return Result{Code: 4, Msg: "不支持的图片"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("上传图片失败 uid %d %w", uc.Uid, err)
+	}
+}
+
+// Raw 返回图片内容，key 由内容决定，所以可以长期缓存
+func (h *ImageHandler) Raw(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	data, contentType, err := h.svc.GetObject(ctx, key)
+	if err != nil {
+		if !errors.Is(err, service.ErrImageObjectNotFound) {
+			h.l.Warn("读取图片失败", logger.String("key", key), logger.Error(err))
+		}
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, contentType, data)
+}
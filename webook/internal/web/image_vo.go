@@ -0,0 +1,40 @@
+package web
+
+import (
+	"mime/multipart"
+	"webook/internal/domain"
+)
+
+const (
+	imageUsageContent = "content"
+	imageUsageCover   = "cover"
+
+	// maxUploadSize 超过这个大小的部分不再读取，具体的限制由 service 决定
+	maxUploadSize = 5 << 20
+)
+
+// ImageUploadReq 用 multipart/form-data 上传，Usage 为空的时候当成正文插图
+type ImageUploadReq struct {
+	File  *multipart.FileHeader `form:"file"`
+	Usage string                `form:"usage"`
+}
+
+func (req ImageUploadReq) usage() (domain.ImageUsage, bool) {
+	switch req.Usage {
+	case "", imageUsageContent:
+		return domain.ImageUsageContent, true
+	case imageUsageCover:
+		return domain.ImageUsageCover, true
+	default:
+		return 0, false
+	}
+}
+
+type ImageVO struct {
+	Id          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
@@ -6,13 +6,18 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/redis/go-redis/v9"
 	"net/http"
+	"strings"
 	"time"
 	ijwt "webook/internal/web/jwt"
 )
 
 type LoginJWTMiddlewareBuilder struct {
 	paths []string
-	cmd   redis.Cmdable
+	// prefixes 以这些前缀开头的路径都不需要登录
+	prefixes []string
+	// optionalPrefixes 以这些前缀开头的路径登录是可选的，带了合法的 token 才会有 claims
+	optionalPrefixes []string
+	cmd              redis.Cmdable
 	ijwt.Handler
 }
 
@@ -27,6 +32,16 @@ func (l *LoginJWTMiddlewareBuilder) IgnorePaths(path string) *LoginJWTMiddleware
 	return l
 }
 
+func (l *LoginJWTMiddlewareBuilder) IgnorePrefix(prefix string) *LoginJWTMiddlewareBuilder {
+	l.prefixes = append(l.prefixes, prefix)
+	return l
+}
+
+func (l *LoginJWTMiddlewareBuilder) OptionalPrefix(prefix string) *LoginJWTMiddlewareBuilder {
+	l.optionalPrefixes = append(l.optionalPrefixes, prefix)
+	return l
+}
+
 func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 	// 用 Go 的方式编码解码
 	gob.Register(time.Now())
@@ -37,39 +52,55 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 				return
 			}
 		}
-
-		tokenStr := l.ExtractToken(c)
-		claims := &ijwt.UserClaims{}
-		// ParseWithClaims 里一定要传入 claims 指针，会被解析出来
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte("Cb3cErlIjTEzfHwr6uhsMZ8On5s5EMPK"), nil
-		})
-		if err != nil {
-			// 没登录
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
+		for _, prefix := range l.prefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				return
+			}
 		}
 
-		// 情况3: err为nil, token不为nil
-		if token == nil || !token.Valid || claims.Uid == 0 {
-			// 没登录
-			c.AbortWithStatus(http.StatusUnauthorized)
+		claims, ok := l.parseClaims(c)
+		if ok {
+			c.Set("claims", claims)
 			return
 		}
-
-		if claims.UserAgent != c.Request.UserAgent() {
-			// 出现严重的安全问题 要监控
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
+		for _, prefix := range l.optionalPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				// 当作没有登录，交给后面的 handler 决定
+				return
+			}
 		}
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+}
 
-		err = l.CheckSession(c, claims.Ssid)
-		if err != nil {
-			// 要么 redis 有问题，要么已经退出了登录
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
+// parseClaims 没有 token 或者 token 不合法的时候返回 false
+func (l *LoginJWTMiddlewareBuilder) parseClaims(c *gin.Context) (*ijwt.UserClaims, bool) {
+	tokenStr := l.ExtractToken(c)
+	claims := &ijwt.UserClaims{}
+	// ParseWithClaims 里一定要传入 claims 指针，会被解析出来
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("Cb3cErlIjTEzfHwr6uhsMZ8On5s5EMPK"), nil
+	})
+	if err != nil {
+		// 没登录
+		return nil, false
+	}
+
+	// 情况3: err为nil, token不为nil
+	if token == nil || !token.Valid || claims.Uid == 0 {
+		// 没登录
+		return nil, false
+	}
+
+	if claims.UserAgent != c.Request.UserAgent() {
+		// 出现严重的安全问题 要监控
+		return nil, false
+	}
 
-		c.Set("claims", claims)
+	err = l.CheckSession(c, claims.Ssid)
+	if err != nil {
+		// 要么 redis 有问题，要么已经退出了登录
+		return nil, false
 	}
+	return claims, true
 }
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+	"webook/pkg/logger"
+)
+
+var _ handler = (*NotificationHandler)(nil)
+
+// heartbeatInterval SSE 连接的心跳间隔，避免被中间的代理断开
+const heartbeatInterval = time.Second * 30
+
+type NotificationHandler struct {
+	svc     service.NotificationService
+	userSvc service.UserService
+	l       logger.LoggerV1
+}
+
+func NewNotificationHandler(svc service.NotificationService,
+	userSvc service.UserService, l logger.LoggerV1) *NotificationHandler {
+	return &NotificationHandler{
+		svc:     svc,
+		userSvc: userSvc,
+		l:       l,
+	}
+}
+
+func (h *NotificationHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/notifications")
+	g.POST("/list", ginx.WrapReqAndToken[NotificationListReq, jwt.UserClaims](h.List))
+	g.GET("/unread", ginx.WrapToken[jwt.UserClaims](h.UnreadCnt))
+	g.POST("/read", ginx.WrapReqAndToken[NotificationReadReq, jwt.UserClaims](h.MarkRead))
+	g.GET("/stream", h.Stream)
+}
+
+func (h *NotificationHandler) List(ctx *gin.Context, req NotificationListReq, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > maxNotificationPageLimit {
+		limit = maxNotificationPageLimit
+	}
+	ns, err := h.svc.List(ctx, uc.Uid, req.Offset, limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	names := make(map[int64]string, len(ns))
+	res := make([]NotificationVO, 0, len(ns))
+	for _, n := range ns {
+		res = append(res, h.toVO(ctx, n, names))
+	}
+	return Result{Data: res}, nil
+}
+
+func (h *NotificationHandler) UnreadCnt(ctx *gin.Context, uc jwt.UserClaims) (ginx.Result, error) {
+	cnt, err := h.svc.UnreadCnt(ctx, uc.Uid)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Data: cnt}, nil
+}
+
+func (h *NotificationHandler) MarkRead(ctx *gin.Context, req NotificationReadReq, uc jwt.UserClaims) (ginx.Result, error) {
+	if err := h.svc.MarkRead(ctx, uc.Uid, req.Ids); err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{Msg: "OK"}, nil
+}
+
+// Stream 通过 Server-Sent Events 推送新的通知
+// 连接建立之后先推送一次未读数，之后每来一条通知推送一次
+func (h *NotificationHandler) Stream(ctx *gin.Context) {
+	claims, ok := ctx.MustGet("claims").(*jwt.UserClaims)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		h.l.Error("未发现用户的 session 信息")
+		return
+	}
+	uid := claims.Uid
+	ns, cancel := h.svc.Subscribe(uid)
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// 告诉 nginx 之类的代理不要缓冲
+	ctx.Header("X-Accel-Buffering", "no")
+
+	cnt, err := h.svc.UnreadCnt(ctx, uid)
+	if err != nil {
+		h.l.Error("查询未读数失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+	ctx.SSEvent("unread", cnt)
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	names := make(map[int64]string)
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case n := <-ns:
+			ctx.SSEvent("notification", h.toVO(ctx, n, names))
+		case <-ticker.C:
+			// 注释行，客户端会忽略
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+		}
+		return true
+	})
+}
+
+// toVO names 缓存了已经查询过的昵称，同一个列表里面同一个人只查一次
+func (h *NotificationHandler) toVO(ctx *gin.Context, n domain.Notification, names map[int64]string) NotificationVO {
+	name, ok := names[n.LastActor]
+	if !ok {
+		u, err := h.userSvc.Profile(ctx, n.LastActor)
+		if err != nil {
+			h.l.Error("查询用户信息失败",
+				logger.Int64("uid", n.LastActor),
+				logger.Error(err))
+		}
+		name = u.NickName
+		if name == "" {
+			name = fmt.Sprintf("用户%d", n.LastActor)
+		}
+		names[n.LastActor] = name
+	}
+	return NotificationVO{
+		Id:            n.Id,
+		Type:          uint8(n.Type),
+		Biz:           n.Biz,
+		BizId:         n.BizId,
+		SourceId:      n.SourceId,
+		LastActor:     n.LastActor,
+		LastActorName: name,
+		RecentActors:  n.RecentActors,
+		ActorCnt:      n.ActorCnt,
+		Summary:       notificationSummary(n, name),
+		Content:       n.Content,
+		Read:          n.Read,
+		Utime:         n.Utime.Format(time.DateTime),
+	}
+}
+
+func notificationSummary(n domain.Notification, name string) string {
+	var action string
+	switch n.Type {
+	case domain.NotificationTypeLike:
+		action = "赞了你的文章"
+	case domain.NotificationTypeCollect:
+		action = "收藏了你的文章"
+	case domain.NotificationTypeFollow:
+		action = "关注了你"
+	case domain.NotificationTypeComment:
+		action = "评论了你的文章"
+	case domain.NotificationTypeReply:
+		action = "回复了你的评论"
+	}
+	if n.ActorCnt > 1 {
+		return fmt.Sprintf("%s 和其他 %d 人%s", name, n.ActorCnt-1, action)
+	}
+	return fmt.Sprintf("%s %s", name, action)
+}
@@ -0,0 +1,31 @@
+package web
+
+const maxNotificationPageLimit = 50
+
+type NotificationListReq struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type NotificationReadReq struct {
+	// Ids 为空代表全部已读
+	Ids []int64 `json:"ids"`
+}
+
+type NotificationVO struct {
+	Id    int64  `json:"id"`
+	Type  uint8  `json:"type"`
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// SourceId 评论和回复的 ID
+	SourceId      int64   `json:"sourceId"`
+	LastActor     int64   `json:"lastActor"`
+	LastActorName string  `json:"lastActorName"`
+	RecentActors  []int64 `json:"recentActors"`
+	ActorCnt      int64   `json:"actorCnt"`
+	// Summary 例如 "X 和其他 12 人赞了你的文章"
+	Summary string `json:"summary"`
+	Content string `json:"content"`
+	Read    bool   `json:"read"`
+	Utime   string `json:"utime"`
+}
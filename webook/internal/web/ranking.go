@@ -0,0 +1,48 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*RankingHandler)(nil)
+
+type RankingHandler struct {
+	svc service.RankingService
+}
+
+func NewRankingHandler(svc service.RankingService) *RankingHandler {
+	return &RankingHandler{
+		svc: svc,
+	}
+}
+
+func (h *RankingHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/pub")
+	g.GET("/ranking", ginx.WrapToken[jwt.UserClaims](h.Ranking))
+}
+
+func (h *RankingHandler) Ranking(ctx *gin.Context, uc jwt.UserClaims) (ginx.Result, error) {
+	arts, err := h.svc.GetTopN(ctx)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract,
+				Cover:    src.Cover,
+				Author:   src.Author.Name,
+				Ctime:    src.Ctime.Format(time.DateTime),
+				Utime:    src.Utime.Format(time.DateTime),
+			}
+		}),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*SearchHandler)(nil)
+
+type SearchHandler struct {
+	svc service.SearchService
+}
+
+func NewSearchHandler(svc service.SearchService) *SearchHandler {
+	return &SearchHandler{
+		svc: svc,
+	}
+}
+
+func (h *SearchHandler) RegisterRoutes(server *gin.Engine) {
+	server.GET("/search", ginx.WrapReqAndToken[SearchReq, jwt.UserClaims](h.Search))
+}
+
+func (h *SearchHandler) Search(ctx *gin.Context, req SearchReq, uc jwt.UserClaims) (ginx.Result, error) {
+	q := strings.TrimSpace(req.Q)
+	if q == "" || utf8.RuneCountInString(q) > maxSearchQueryLen {
+		return Result{Code: 4, Msg: "搜索关键字不对"}, nil
+	}
+	if req.Offset < 0 {
+		return Result{Code: 4, Msg: "分页参数不对"}, nil
+	}
+	switch req.Type {
+	case "", searchTypeArticle:
+		res, err := h.svc.SearchArticle(ctx, q, req.Offset, req.limit())
+		if err != nil {
+			return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("搜索文章失败 %w", err)
+		}
+		return Result{
+			Data: SearchVO{
+				Total: res.Total,
+				Articles: slice.Map(res.Articles, func(idx int, src domain.SearchArticle) SearchArticleVO {
+					return SearchArticleVO{
+						Id:        src.Id,
+						Title:     src.Title,
+						Abstract:  src.Abstract,
+						Tags:      src.Tags,
+						AuthorId:  src.AuthorId,
+						Author:    src.Author,
+						Utime:     src.Utime.Format(time.DateTime),
+						Highlight: src.Highlight,
+					}
+				}),
+			},
+		}, nil
+	case searchTypeUser:
+		res, err := h.svc.SearchUser(ctx, q, req.Offset, req.limit())
+		if err != nil {
+			return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("搜索用户失败 %w", err)
+		}
+		return Result{
+			Data: SearchVO{
+				Total: res.Total,
+				Users: slice.Map(res.Users, func(idx int, src domain.SearchUser) SearchUserVO {
+					return SearchUserVO{
+						Id:        src.Id,
+						Nickname:  src.Nickname,
+						Bio:       src.Bio,
+						Highlight: src.Highlight,
+					}
+				}),
+			},
+		}, nil
+	default:
+		return Result{Code: 4, Msg: "搜索类型不对"}, nil
+	}
+}
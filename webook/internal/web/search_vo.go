@@ -0,0 +1,51 @@
+package web
+
+const (
+	searchTypeArticle = "article"
+	searchTypeUser    = "user"
+
+	maxSearchQueryLen  = 100
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// SearchReq Type 为空的时候搜索文章
+type SearchReq struct {
+	Q      string `form:"q" json:"q"`
+	Type   string `form:"type" json:"type"`
+	Offset int    `form:"offset" json:"offset"`
+	Limit  int    `form:"limit" json:"limit"`
+}
+
+func (req SearchReq) limit() int {
+	if req.Limit <= 0 || req.Limit > maxSearchLimit {
+		return defaultSearchLimit
+	}
+	return req.Limit
+}
+
+// SearchVO 根据搜索的类型，Articles 和 Users 只会有一个
+type SearchVO struct {
+	Total    int64             `json:"total"`
+	Articles []SearchArticleVO `json:"articles,omitempty"`
+	Users    []SearchUserVO    `json:"users,omitempty"`
+}
+
+// SearchArticleVO Highlight 里面的片段已经转义过了，前端可以直接当成 HTML 展示
+type SearchArticleVO struct {
+	Id        int64               `json:"id"`
+	Title     string              `json:"title"`
+	Abstract  string              `json:"abstract"`
+	Tags      []string            `json:"tags"`
+	AuthorId  int64               `json:"author_id"`
+	Author    string              `json:"author"`
+	Utime     string              `json:"utime"`
+	Highlight map[string][]string `json:"highlight"`
+}
+
+type SearchUserVO struct {
+	Id        int64               `json:"id"`
+	Nickname  string              `json:"nickname"`
+	Bio       string              `json:"bio"`
+	Highlight map[string][]string `json:"highlight"`
+}
@@ -0,0 +1,169 @@
+package web
+
+import (
+	"errors"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+)
+
+var _ handler = (*SeriesHandler)(nil)
+
+// SeriesHandler 专栏，文章详情里面的上一篇、下一篇在 ArticleHandler 里面
+type SeriesHandler struct {
+	svc service.SeriesService
+}
+
+func NewSeriesHandler(svc service.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		svc: svc,
+	}
+}
+
+func (h *SeriesHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/series")
+	g.POST("/create", ginx.WrapReqAndToken[SeriesReq, jwt.UserClaims](h.Create))
+	g.POST("/update", ginx.WrapReqAndToken[SeriesReq, jwt.UserClaims](h.Update))
+	g.POST("/delete", ginx.WrapReqAndToken[SeriesIdReq, jwt.UserClaims](h.Delete))
+	// 添加、移除和调整章节的顺序都是整体替换
+	g.POST("/articles", ginx.WrapReqAndToken[SeriesArticlesReq, jwt.UserClaims](h.SetArticles))
+	g.POST("/publish", ginx.WrapReqAndToken[SeriesIdReq, jwt.UserClaims](h.Publish))
+	g.POST("/withdraw", ginx.WrapReqAndToken[SeriesIdReq, jwt.UserClaims](h.Withdraw))
+	g.POST("/list", ginx.WrapReqAndToken[Page, jwt.UserClaims](h.List))
+	g.POST("/detail", ginx.WrapReqAndToken[SeriesIdReq, jwt.UserClaims](h.Detail))
+
+	g.GET("/pub/:id", ginx.WrapToken[jwt.UserClaims](h.PubDetail))
+}
+
+func (h *SeriesHandler) Create(ctx *gin.Context, req SeriesReq, uc jwt.UserClaims) (ginx.Result, error) {
+	id, err := h.svc.Create(ctx, domain.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		Author:      domain.Author{Id: uc.Uid},
+	})
+	if err != nil {
+		return h.result(err)
+	}
+	return Result{Data: id}, nil
+}
+
+func (h *SeriesHandler) Update(ctx *gin.Context, req SeriesReq, uc jwt.UserClaims) (ginx.Result, error) {
+	err := h.svc.Update(ctx, domain.Series{
+		Id:          req.Id,
+		Title:       req.Title,
+		Description: req.Description,
+		Author:      domain.Author{Id: uc.Uid},
+	})
+	return h.result(err)
+}
+
+func (h *SeriesHandler) Delete(ctx *gin.Context, req SeriesIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	return h.result(h.svc.Delete(ctx, uc.Uid, req.Id))
+}
+
+func (h *SeriesHandler) SetArticles(ctx *gin.Context, req SeriesArticlesReq, uc jwt.UserClaims) (ginx.Result, error) {
+	return h.result(h.svc.SetArticles(ctx, uc.Uid, req.Id, req.ArticleIds))
+}
+
+func (h *SeriesHandler) Publish(ctx *gin.Context, req SeriesIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	return h.result(h.svc.Publish(ctx, uc.Uid, req.Id))
+}
+
+func (h *SeriesHandler) Withdraw(ctx *gin.Context, req SeriesIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	return h.result(h.svc.Withdraw(ctx, uc.Uid, req.Id))
+}
+
+func (h *SeriesHandler) List(ctx *gin.Context, req Page, uc jwt.UserClaims) (ginx.Result, error) {
+	limit := req.Limit
+	if limit <= 0 || limit > maxSeriesPageLimit {
+		limit = maxSeriesPageLimit
+	}
+	res, err := h.svc.List(ctx, uc.Uid, max(req.Offset, 0), limit)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+	return Result{
+		Data: slice.Map[domain.Series, SeriesVO](res, func(idx int, src domain.Series) SeriesVO {
+			return h.toVO(src)
+		}),
+	}, nil
+}
+
+func (h *SeriesHandler) Detail(ctx *gin.Context, req SeriesIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	s, err := h.svc.Detail(ctx, uc.Uid, req.Id)
+	if err != nil {
+		return h.result(err)
+	}
+	vo := h.toVO(s)
+	vo.Articles = slice.Map[domain.Article, ArticleVO](s.Articles, func(idx int, src domain.Article) ArticleVO {
+		return ArticleVO{
+			Id:     src.Id,
+			Title:  src.Title,
+			Status: src.Status.ToUint8(),
+			Cover:  src.Cover,
+			Ctime:  src.Ctime.Format(time.DateTime),
+			Utime:  src.Utime.Format(time.DateTime),
+		}
+	})
+	return Result{Data: vo}, nil
+}
+
+// PubDetail 读者看到的专栏页
+func (h *SeriesHandler) PubDetail(ctx *gin.Context, uc jwt.UserClaims) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Result{Code: 4, Msg: "参数错误"}, err
+	}
+	s, err := h.svc.GetPublished(ctx, id)
+	if err != nil {
+		return h.result(err)
+	}
+	vo := h.toVO(s)
+	vo.Articles = slice.Map[domain.Article, ArticleVO](s.Articles, func(idx int, src domain.Article) ArticleVO {
+		return ArticleVO{
+			Id:       src.Id,
+			Title:    src.Title,
+			Abstract: src.Abstract,
+			Status:   src.Status.ToUint8(),
+			Cover:    src.Cover,
+			Ctime:    src.Ctime.Format(time.DateTime),
+			Utime:    src.Utime.Format(time.DateTime),
+		}
+	})
+	return Result{Data: vo}, nil
+}
+
+func (h *SeriesHandler) toVO(s domain.Series) SeriesVO {
+	return SeriesVO{
+		Id:          s.Id,
+		Title:       s.Title,
+		Description: s.Description,
+		Status:      s.Status.ToUint8(),
+		AuthorId:    s.Author.Id,
+		Ctime:       s.Ctime.Format(time.DateTime),
+		Utime:       s.Utime.Format(time.DateTime),
+	}
+}
+
+func (h *SeriesHandler) result(err error) (ginx.Result, error) {
+	switch {
+	case err == nil:
+		return Result{Msg: "OK"}, nil
+	case errors.Is(err, service.ErrSeriesNotFound),
+		errors.Is(err, service.ErrPossibleIncorrectAuthor):
+		return Result{Code: 4, Msg: "专栏不存在"}, nil
+	case errors.Is(err, service.ErrInvalidSeries):
+		return Result{Code: 4, Msg: "专栏的标题或者简介不合法"}, nil
+	case errors.Is(err, service.ErrInvalidSeriesArticle):
+		return Result{Code: 4, Msg: "章节太多，或者有不能加入专栏的文章"}, nil
+	case errors.Is(err, service.ErrArticleInOtherSeries):
+		return Result{Code: 4, Msg: "文章已经在别的专栏里面了"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+const maxSeriesPageLimit = 50
+
+type SeriesReq struct {
+	// Id 创建的时候不需要
+	Id          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type SeriesIdReq struct {
+	Id int64 `json:"id"`
+}
+
+// SeriesArticlesReq 整体替换章节，ArticleIds 的顺序就是章节的顺序
+type SeriesArticlesReq struct {
+	Id         int64   `json:"id"`
+	ArticleIds []int64 `json:"articleIds"`
+}
+
+type SeriesVO struct {
+	Id          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      uint8  `json:"status"`
+	AuthorId    int64  `json:"authorId"`
+	// Articles 章节，只在详情里面有
+	Articles []ArticleVO `json:"articles,omitempty"`
+	Ctime    string      `json:"ctime"`
+	Utime    string      `json:"utime"`
+}
+
+// SeriesNavVO 文章详情页的专栏导航，Prev 和 Next 为空代表已经是第一篇或者最后一篇
+type SeriesNavVO struct {
+	Id    int64         `json:"id"`
+	Title string        `json:"title"`
+	Prev  *ArticleNavVO `json:"prev,omitempty"`
+	Next  *ArticleNavVO `json:"next,omitempty"`
+}
+
+type ArticleNavVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+}
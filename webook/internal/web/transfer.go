@@ -0,0 +1,132 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/jwt"
+	"webook/pkg/ginx"
+	"webook/pkg/logger"
+)
+
+var _ handler = (*TransferHandler)(nil)
+
+// TransferHandler 导出和导入文章，都是后台任务，通过 /detail 查询进度
+type TransferHandler struct {
+	svc service.ArticleTransferService
+	l   logger.LoggerV1
+}
+
+func NewTransferHandler(svc service.ArticleTransferService, l logger.LoggerV1) *TransferHandler {
+	return &TransferHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *TransferHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/articles/transfer")
+	g.POST("/export", ginx.WrapToken[jwt.UserClaims](h.Export))
+	g.POST("/import", ginx.WrapReqAndToken[ImportReq, jwt.UserClaims](h.Import))
+	g.POST("/detail", ginx.WrapReqAndToken[TransferIdReq, jwt.UserClaims](h.Detail))
+	// 返回的是文件，不能用 ginx 包装
+	g.GET("/download/:id", h.Download)
+}
+
+func (h *TransferHandler) Export(ctx *gin.Context, uc jwt.UserClaims) (ginx.Result, error) {
+	t, err := h.svc.StartExport(ctx, uc.Uid)
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("开始导出失败 uid %d %w", uc.Uid, err)
+	}
+	return Result{Data: h.toVO(t)}, nil
+}
+
+func (h *TransferHandler) Import(ctx *gin.Context, req ImportReq, uc jwt.UserClaims) (ginx.Result, error) {
+	if req.File == nil {
+		return Result{Code: 4, Msg: "请选择文件"}, nil
+	}
+	if req.File.Size > service.MaxImportSize {
+		return Result{Code: 4, Msg: "文件太大"}, nil
+	}
+	f, err := req.File.Open()
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("打开上传的文件失败 %w", err)
+	}
+	defer f.Close()
+	// 多读一个字节，超出限制交给 service 判断
+	data, err := io.ReadAll(io.LimitReader(f, service.MaxImportSize+1))
+	if err != nil {
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("读取上传的文件失败 %w", err)
+	}
+	t, err := h.svc.StartImport(ctx, uc.Uid, req.File.Filename, data)
+	switch {
+	case err == nil:
+		return Result{Data: h.toVO(t)}, nil
+	case errors.Is(err, service.ErrInvalidImportFile):
+		return Result{Code: 4, Msg: "只支持 zip 和 Markdown 文件，并且不能超过 20MB 和 500 篇文章"}, nil
+	case errors.Is(err, service.ErrTransferBusy):
+		return Result{Code: 4, Msg: "上一次导入还没有结束"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, fmt.Errorf("开始导入失败 uid %d %w", uc.Uid, err)
+	}
+}
+
+func (h *TransferHandler) Detail(ctx *gin.Context, req TransferIdReq, uc jwt.UserClaims) (ginx.Result, error) {
+	t, err := h.svc.Get(ctx, uc.Uid, req.Id)
+	switch {
+	case err == nil:
+		return Result{Data: h.toVO(t)}, nil
+	case errors.Is(err, service.ErrTransferNotFound):
+		return Result{Code: 4, Msg: "任务不存在"}, nil
+	default:
+		return Result{Code: 5, Msg: "系统错误"}, err
+	}
+}
+
+func (h *TransferHandler) Download(ctx *gin.Context) {
+	uc := ctx.MustGet("claims").(*jwt.UserClaims)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	data, err := h.svc.Download(ctx, uc.Uid, id)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrTransferNotFound),
+		errors.Is(err, service.ErrExportNotFound):
+		ctx.Status(http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrTransferNotReady):
+		ctx.Status(http.StatusConflict)
+		return
+	default:
+		h.l.Error("下载导出的文章失败", logger.Int64("uid", uc.Uid),
+			logger.Int64("tid", id), logger.Error(err))
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="webook-articles-%d.zip"`, id))
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Data(http.StatusOK, "application/zip", data)
+}
+
+func (h *TransferHandler) toVO(t domain.ArticleTransfer) TransferVO {
+	return TransferVO{
+		Id:     t.Id,
+		Kind:   uint8(t.Kind),
+		Status: uint8(t.Status),
+		Total:  t.Total,
+		Done:   t.Done,
+		Failed: t.Failed,
+		Msg:    t.Msg,
+		Ctime:  t.Ctime.Format(time.DateTime),
+		Utime:  t.Utime.Format(time.DateTime),
+	}
+}
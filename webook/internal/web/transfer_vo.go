@@ -0,0 +1,28 @@
+package web
+
+import "mime/multipart"
+
+// ImportReq 用 multipart/form-data 上传 zip 或者单个 Markdown 文件
+type ImportReq struct {
+	File *multipart.FileHeader `form:"file"`
+}
+
+type TransferIdReq struct {
+	Id int64 `json:"id"`
+}
+
+// TransferVO 导出或者导入的任务，前端轮询它来展示进度
+type TransferVO struct {
+	Id int64 `json:"id"`
+	// Kind 1 是导出，2 是导入
+	Kind uint8 `json:"kind"`
+	// Status 1 等待执行，2 执行中，3 完成，4 失败
+	Status uint8 `json:"status"`
+	Total  int   `json:"total"`
+	Done   int   `json:"done"`
+	Failed int   `json:"failed"`
+	// Msg 失败的原因，或者处理失败的文章
+	Msg   string `json:"msg,omitempty"`
+	Ctime string `json:"ctime"`
+	Utime string `json:"utime"`
+}
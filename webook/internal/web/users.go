@@ -7,6 +7,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
 	"time"
 	jwt2 "webook/internal/web/jwt"
 	"webook/pkg/ginx"
@@ -30,6 +31,7 @@ var _ handler = (*UserHandler)(nil)
 type UserHandler struct {
 	svc          service.UserService
 	codeSvc      service.CodeService
+	followSvc    service.FollowService
 	emailExp     *regexp.Regexp
 	passwordExp  *regexp.Regexp
 	jwt2.Handler // 组合法
@@ -37,7 +39,8 @@ type UserHandler struct {
 	l            logger.LoggerV1
 }
 
-func NewUserHandler(svc service.UserService, codeSvc service.CodeService, jwtHdl jwt2.Handler, l logger.LoggerV1) *UserHandler {
+func NewUserHandler(svc service.UserService, codeSvc service.CodeService, followSvc service.FollowService,
+	jwtHdl jwt2.Handler, l logger.LoggerV1) *UserHandler {
 	// 信息校验：正则表达式
 	const (
 		emailRegexPattern    = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
@@ -48,9 +51,11 @@ func NewUserHandler(svc service.UserService, codeSvc service.CodeService, jwtHdl
 	return &UserHandler{
 		svc:         svc,
 		codeSvc:     codeSvc,
+		followSvc:   followSvc,
 		emailExp:    emailExp,
 		passwordExp: passwordExp,
 		Handler:     jwtHdl,
+		l:           l,
 	}
 }
 
@@ -263,7 +268,13 @@ func (u *UserHandler) Edit(c *gin.Context) {
 		})
 		return
 	}
-	uc := c.MustGet("user").(jwt2.UserClaims)
+	uc, ok := c.MustGet("claims").(*jwt2.UserClaims)
+	if !ok {
+		c.JSON(http.StatusOK, Result{
+			Code: 5, Msg: "系统错误",
+		})
+		return
+	}
 	err = u.svc.UpdateNonSensitiveInfo(c, domain.User{
 		Id:       uc.Uid,
 		NickName: req.Nickname,
@@ -297,27 +308,58 @@ func (u *UserHandler) Profile(c *gin.Context) {
 		"bio":      uu.Bio})
 }
 
+// ProfileJWT 查看个人资料，带上 id 参数可以查看别人的资料，这时候不会返回联系方式
 func (u *UserHandler) ProfileJWT(c *gin.Context) {
 	// 重新控制 profile 防止密码泄露
 	type Profile struct {
-		Email    string
-		Phone    string
-		Nickname string
-		Birthday string
-		Bio      string
-	}
-	uc := c.MustGet("user").(jwt2.UserClaims)
-	ucId, err := u.svc.Profile(c, uc.Uid) // 类型断言
+		Email     string
+		Phone     string
+		Nickname  string
+		Birthday  string
+		Bio       string
+		Followers int64
+		Followees int64
+		// Followed 当前用户有没有关注他
+		Followed bool
+	}
+	uc, ok := c.MustGet("claims").(*jwt2.UserClaims)
+	if !ok {
+		c.String(http.StatusOK, "system error")
+		return
+	}
+	uid := uc.Uid
+	if idStr := c.Query("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			c.String(http.StatusOK, "invalid id")
+			return
+		}
+		uid = id
+	}
+	user, err := u.svc.Profile(c, uid)
 	if err != nil {
-		c.String(http.StatusOK, "system error"+err.Error())
-	}
-	c.JSON(http.StatusOK, Profile{
-		Email:    ucId.Email,
-		Phone:    ucId.Phone,
-		Nickname: ucId.NickName,
-		Birthday: ucId.Birthday.Format(time.DateOnly),
-		Bio:      ucId.Bio,
-	})
+		c.String(http.StatusOK, "system error")
+		u.l.Error("查询用户资料失败", logger.Int64("uid", uid), logger.Error(err))
+		return
+	}
+	statics, err := u.followSvc.GetFollowStatics(c, uid, uc.Uid)
+	if err != nil {
+		// 关注数据查询失败不影响资料本身的展示
+		u.l.Error("查询关注数据失败", logger.Int64("uid", uid), logger.Error(err))
+	}
+	p := Profile{
+		Nickname:  user.NickName,
+		Birthday:  user.Birthday.Format(time.DateOnly),
+		Bio:       user.Bio,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+		Followed:  statics.Followed,
+	}
+	if uid == uc.Uid {
+		p.Email = user.Email
+		p.Phone = user.Phone
+	}
+	c.JSON(http.StatusOK, p)
 }
 
 func (u *UserHandler) SendLoginSMSCode(c *gin.Context) {
@@ -16,10 +16,11 @@ import (
 	"webook/internal/service"
 	svcmocks "webook/internal/service/mocks"
 	"webook/internal/web/jwt"
+	"webook/pkg/logger"
 )
 
 func TestEncrypt(t *testing.T) {
-	_ = NewUserHandler(nil, nil, nil)
+	_ = NewUserHandler(nil, nil, nil, nil, nil)
 	password := "hello#world123"
 	encrypted, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -188,7 +189,7 @@ func TestUserHandler_SignUp(t *testing.T) {
 			defer ctrl.Finish()
 			server := gin.Default()
 			// 用不上 codeSvc
-			h := NewUserHandler(tc.mock(ctrl), nil, nil)
+			h := NewUserHandler(tc.mock(ctrl), nil, nil, nil, &logger.NoOpLogger{})
 			h.RegisterRoutes(server)
 
 			req, err := http.NewRequest(http.MethodPost,
@@ -0,0 +1,19 @@
+package ioc
+
+import (
+	"fmt"
+	"webook/internal/repository/dao/blob"
+	"webook/pkg/cfg"
+)
+
+func InitBlobStore(c cfg.Config) blob.Store {
+	switch c.Blob.Type {
+	case "", "fs":
+		return blob.NewFSStore(c.Blob.Root, c.Blob.BaseURL)
+	case "s3":
+		// 只有用到 S3 的时候才需要 COS 的环境变量
+		return blob.NewS3Store(InitS3(c), c.Blob.Bucket, c.Blob.BaseURL)
+	default:
+		panic(fmt.Sprintf("未知的 blob 存储类型 %s", c.Blob.Type))
+	}
+}
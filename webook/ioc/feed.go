@@ -0,0 +1,13 @@
+package ioc
+
+import (
+	"strings"
+	"webook/internal/web"
+	"webook/pkg/cfg"
+)
+
+func NewFeedHandlerConfig(c cfg.Config) web.FeedHandlerConfig {
+	return web.FeedHandlerConfig{
+		BaseURL: strings.TrimSuffix(c.Site.BaseURL, "/"),
+	}
+}
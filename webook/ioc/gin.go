@@ -12,18 +12,47 @@ import (
 	web2 "webook/internal/web"
 	ijwt "webook/internal/web/jwt"
 	"webook/internal/web/middleware"
+	"webook/pkg/cfg"
 	"webook/pkg/ginx"
 	"webook/pkg/logger"
 	"webook/pkg/middlewares/accesslog"
 )
 
-func InitWebServer(mdls []gin.HandlerFunc, hdl *web2.UserHandler, oauth2WechatHdl *web2.OAuth2WechatHandler, articleHdl *web2.ArticleHandler, l logger.LoggerV1) *gin.Engine {
+func InitWebServer(c cfg.Config, mdls []gin.HandlerFunc, hdl *web2.UserHandler, oauth2WechatHdl *web2.OAuth2WechatHandler, articleHdl *web2.ArticleHandler,
+	rankingHdl *web2.RankingHandler, collectionHdl *web2.CollectionHandler, historyHdl *web2.HistoryHandler,
+	followHdl *web2.FollowHandler,
+	commentHdl *web2.CommentHandler,
+	notificationHdl *web2.NotificationHandler,
+	searchHdl *web2.SearchHandler,
+	imageHdl *web2.ImageHandler,
+	seriesHdl *web2.SeriesHandler,
+	feedHdl *web2.FeedHandler,
+	transferHdl *web2.TransferHandler,
+	statsHdl *web2.ArticleStatsHandler,
+	analyticsHdl *web2.AnalyticsHandler, l logger.LoggerV1) *gin.Engine {
 	ginx.SetLogger(l)
 	server := gin.Default()
+	// 读者的 IP 要用来给阅读数去重，不能相信客户端自己带上的 X-Forwarded-For
+	if err := server.SetTrustedProxies(c.Web.TrustedProxies); err != nil {
+		panic(err)
+	}
 	server.Use(mdls...)
 	hdl.RegisterRoutes(server)
 	oauth2WechatHdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
+	rankingHdl.RegisterRoutes(server)
+	collectionHdl.RegisterRoutes(server)
+	historyHdl.RegisterRoutes(server)
+	followHdl.RegisterRoutes(server)
+	commentHdl.RegisterRoutes(server)
+	notificationHdl.RegisterRoutes(server)
+	searchHdl.RegisterRoutes(server)
+	imageHdl.RegisterRoutes(server)
+	seriesHdl.RegisterRoutes(server)
+	feedHdl.RegisterRoutes(server)
+	transferHdl.RegisterRoutes(server)
+	statsHdl.RegisterRoutes(server)
+	analyticsHdl.RegisterRoutes(server)
 	return server
 }
 
@@ -49,6 +78,14 @@ func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler, l logger.Lo
 			IgnorePaths("/users/login_sms").
 			IgnorePaths("/oauth2/wechat/authurl").
 			IgnorePaths("/oauth2/wechat/callback").
+			// 本地存储的图片，和 CDN 上的一样不需要登录
+			IgnorePrefix("/images/raw/").
+			// 订阅源和站点地图是给阅读器和搜索引擎用的
+			IgnorePrefix("/feeds/").
+			IgnorePaths("/sitemap.xml").
+			IgnorePrefix("/sitemaps/").
+			// 没有登录也可以看文章详情，这下面别的接口还是会在 ginx 的 Wrap 里面要求登录
+			OptionalPrefix("/articles/pub/").
 			Build(),
 
 		// 使用session 登录校验
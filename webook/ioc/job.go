@@ -0,0 +1,45 @@
+package ioc
+
+import (
+	"time"
+	"webook/internal/job"
+	"webook/internal/service"
+	"webook/pkg/logger"
+	"webook/pkg/redislock"
+)
+
+func InitRankingJob(svc service.RankingService) *job.RankingJob {
+	return job.NewRankingJob(svc, time.Minute)
+}
+
+func InitScheduledPublishJob(svc service.ArticleService) *job.ScheduledPublishJob {
+	return job.NewScheduledPublishJob(svc, time.Minute)
+}
+
+func InitRecycleBinPurgeJob(svc service.ArticleService) *job.RecycleBinPurgeJob {
+	return job.NewRecycleBinPurgeJob(svc, time.Minute*5)
+}
+
+func InitArticleStatsFlushJob(svc service.ArticleStatsService) *job.ArticleStatsFlushJob {
+	return job.NewArticleStatsFlushJob(svc, time.Minute)
+}
+
+func InitTransferCleanupJob(svc service.ArticleTransferService) *job.TransferCleanupJob {
+	return job.NewTransferCleanupJob(svc, time.Minute*5)
+}
+
+func InitScheduler(l logger.LoggerV1, lock *redislock.Client,
+	rankingJob *job.RankingJob, publishJob *job.ScheduledPublishJob,
+	purgeJob *job.RecycleBinPurgeJob, statsJob *job.ArticleStatsFlushJob,
+	transferJob *job.TransferCleanupJob) *job.Scheduler {
+	return job.NewScheduler(l, lock).
+		Register(rankingJob, time.Minute*3).
+		// 定时发表的精度取决于这个间隔
+		Register(publishJob, time.Second*10).
+		// 晚一点删除没有关系
+		Register(purgeJob, time.Hour).
+		// 数据库里面的统计最多落后这么久，今天的数据直接查 Redis
+		Register(statsJob, time.Minute*5).
+		// 间隔和任务中断的判定时间差不多就可以
+		Register(transferJob, time.Minute*10)
+}
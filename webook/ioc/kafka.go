@@ -5,6 +5,8 @@ import (
 	"github.com/spf13/viper"
 	"webook/internal/events"
 	"webook/internal/events/article"
+	"webook/internal/events/notification"
+	"webook/internal/events/search"
 )
 
 func InitKafka() sarama.Client {
@@ -33,6 +35,11 @@ func NewSyncProducer(client sarama.Client) sarama.SyncProducer {
 	return res
 }
 
-func NewConsumers(c1 article.Consumer) []events.Consumer {
-	return []events.Consumer{c1}
+func NewConsumers(c1 *article.InteractiveReadEventConsumer,
+	c2 *article.HistoryConsumer,
+	c3 *notification.Consumer,
+	c4 *search.ArticleConsumer,
+	c5 *search.UserConsumer,
+	c6 *notification.AnalyticsConsumer) []events.Consumer {
+	return []events.Consumer{c1, c2, c3, c4, c5, c6}
 }
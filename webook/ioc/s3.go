@@ -7,14 +7,16 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/ecodeclub/ekit"
 	"github.com/google/wire"
+	"gorm.io/gorm"
 	"os"
 	"webook/internal/repository/dao/article"
+	"webook/pkg/cfg"
 )
 
 // 还需要 DB
-var s3ArticleDAOSet = wire.NewSet(InitS3, article.NewOssDAO)
+var s3ArticleDAOSet = wire.NewSet(InitS3, InitOssArticleDAO)
 
-func InitS3() *s3.S3 {
+func InitS3(c cfg.Config) *s3.S3 {
 	// 腾讯云中对标 s3 和 OSS 的产品叫做 COS
 	cosId, ok := os.LookupEnv("COS_APP_ID")
 	if !ok {
@@ -26,8 +28,8 @@ func InitS3() *s3.S3 {
 	}
 	sess, err := session.NewSession(&aws.Config{
 		Credentials: credentials.NewStaticCredentials(cosId, cosKey, ""),
-		Region:      ekit.ToPtr[string]("ap-nanjing"),
-		Endpoint:    ekit.ToPtr[string]("https://cos.ap-nanjing.myqcloud.com"),
+		Region:      ekit.ToPtr[string](c.S3.Region),
+		Endpoint:    ekit.ToPtr[string](c.S3.Endpoint),
 		// 强制使用 /bucket/key 的形态
 		S3ForcePathStyle: ekit.ToPtr[bool](true),
 	})
@@ -36,3 +38,7 @@ func InitS3() *s3.S3 {
 	}
 	return s3.New(sess)
 }
+
+func InitOssArticleDAO(oss *s3.S3, db *gorm.DB, c cfg.Config) article.ArticleDAO {
+	return article.NewOssDAO(oss, db, c.S3.ArticleBucket)
+}
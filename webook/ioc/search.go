@@ -0,0 +1,27 @@
+package ioc
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"webook/internal/repository/dao/search"
+	"webook/pkg/cfg"
+)
+
+func InitSearchDAO(c cfg.Config) search.SearchDAO {
+	addr := c.Search.ElasticAddr
+	if addr == "" {
+		// 内置的索引重启就没了，多个实例的时候每个实例只能消费到一部分数据，不能悄悄地用在线上
+		if !c.Search.Memory {
+			panic("没有配置 search.elastic_addr，本地开发可以打开 search.memory 使用内置的索引")
+		}
+		return search.NewMemoryDAO()
+	}
+	d := search.NewElasticDAO(addr, &http.Client{Timeout: time.Second * 3})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	if err := d.InitIndexes(ctx); err != nil {
+		panic(err)
+	}
+	return d
+}
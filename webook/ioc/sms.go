@@ -1,10 +1,104 @@
 package ioc
 
 import (
+	"fmt"
+	"github.com/ecodeclub/ekit/retry"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+	tsms "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sms/v20210111"
+	"net/http"
+	"os"
+	"time"
+	"webook/internal/repository"
 	"webook/internal/service/sms"
+	"webook/internal/service/sms/aliyun"
+	"webook/internal/service/sms/async"
+	"webook/internal/service/sms/failover"
 	"webook/internal/service/sms/memory"
+	"webook/internal/service/sms/retryable"
+	"webook/internal/service/sms/tencent"
+	"webook/pkg/cfg"
+	"webook/pkg/logger"
 )
 
-func InitSmsService() sms.Service {
-	return memory.NewService()
+// InitAsyncSmsService 从里到外依次是：切换服务商、重试、转异步
+// 异步发送的 goroutine 由 main 启动和停止，这里只负责组装
+func InitAsyncSmsService(c cfg.Config, repo repository.AsyncSmsRepository, l logger.LoggerV1) *async.Service {
+	sc := c.SMS
+	// 限制住重试次数，ekit 里面小于等于 0 代表无限重试
+	if sc.Retry.MaxRetries <= 0 {
+		panic(fmt.Errorf("sms.retry.max_retries 必须大于 0"))
+	}
+	initial := time.Duration(sc.Retry.InitialIntervalMs) * time.Millisecond
+	maxInterval := time.Duration(sc.Retry.MaxIntervalMs) * time.Millisecond
+	// 提前校验一下配置，后面就不需要处理 error 了
+	if _, err := retry.NewExponentialBackoffRetryStrategy(initial, maxInterval, sc.Retry.MaxRetries); err != nil {
+		panic(err)
+	}
+
+	svc := initSmsFailover(sc.Failover, sc.FailoverThreshold, initSmsProviders(c))
+	svc = retryable.NewService(svc, func() retry.Strategy {
+		s, _ := retry.NewExponentialBackoffRetryStrategy(initial, maxInterval, sc.Retry.MaxRetries)
+		return s
+	})
+	return async.NewService(svc, repo, l)
+}
+
+func InitSmsService(svc *async.Service) sms.Service {
+	return svc
+}
+
+// initSmsFailover 按照配置选择切换服务商的策略
+func initSmsFailover(strategy string, threshold int32, providers []sms.Service) sms.Service {
+	switch strategy {
+	case "", "timeout":
+		if threshold <= 0 {
+			panic(fmt.Errorf("sms.failover_threshold 必须大于 0"))
+		}
+		return failover.NewTimeoutFailoverService(providers, threshold)
+	case "round_robin":
+		return failover.NewFailoverService(providers)
+	default:
+		panic(fmt.Errorf("不支持的 sms.failover: %s", strategy))
+	}
+}
+
+// initSmsProviders 按照配置初始化各个服务商，一个都没有配置的时候用内存实现
+func initSmsProviders(c cfg.Config) []sms.Service {
+	tpls := sms.NewTemplateRegistry()
+	for _, t := range c.SMS.Templates {
+		tpls.Register(t.Biz, t.Provider, sms.Template{
+			Id:         t.Id,
+			Signature:  t.Signature,
+			ParamNames: t.ParamNames,
+		})
+	}
+	var providers []sms.Service
+	if t := c.SMS.Tencent; t.AppId != "" {
+		providers = append(providers, tencent.NewService(initTencentSmsClient(t.Region), t.AppId, tpls))
+	}
+	if a := c.SMS.Aliyun; a.Endpoint != "" {
+		providers = append(providers, aliyun.NewService(a.Endpoint, a.AccessKeyId, a.AccessKeySecret,
+			&http.Client{Timeout: time.Second * 3}, tpls))
+	}
+	if len(providers) == 0 {
+		providers = append(providers, memory.NewService())
+	}
+	return providers
+}
+
+func initTencentSmsClient(region string) *tsms.Client {
+	secretId, ok := os.LookupEnv("SMS_SECRET_ID")
+	if !ok {
+		panic("没有找到环境变量 SMS_SECRET_ID")
+	}
+	secretKey, ok := os.LookupEnv("SMS_SECRET_KEY")
+	if !ok {
+		panic("没有找到环境变量 SMS_SECRET_KEY")
+	}
+	client, err := tsms.NewClient(common.NewCredential(secretId, secretKey), region, profile.NewClientProfile())
+	if err != nil {
+		panic(err)
+	}
+	return client
 }
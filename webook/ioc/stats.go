@@ -0,0 +1,13 @@
+package ioc
+
+import (
+	"time"
+	"webook/internal/repository"
+	"webook/pkg/cfg"
+)
+
+func NewArticleStatsConfig(c cfg.Config) repository.ArticleStatsConfig {
+	return repository.ArticleStatsConfig{
+		DedupeWindow: time.Duration(c.Read.DedupeWindowSec) * time.Second,
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"webook/wire"
@@ -14,6 +15,11 @@ func main() {
 			panic(err)
 		}
 	}
+	app.Scheduler.Start()
+	defer app.Scheduler.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go app.AsyncSms.StartAsyncCycle(ctx)
 
 	server := app.Web
 	server.GET("/hello", func(c *gin.Context) {
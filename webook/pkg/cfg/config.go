@@ -12,4 +12,68 @@ type Config struct {
 	Redis struct {
 		Addr string `toml:"addr"`
 	} `toml:"redis"`
+	SMS struct {
+		// Failover 多个服务商之间怎么切换，timeout 是连续超时之后切换，round_robin 是出错就换下一个
+		Failover string `toml:"failover"`
+		// FailoverThreshold 连续超时多少次就切换服务商，只有 timeout 用得上
+		FailoverThreshold int32 `toml:"failover_threshold"`
+		Retry             struct {
+			// InitialIntervalMs 和 MaxIntervalMs 是指数退避的起始和最大间隔，单位毫秒
+			InitialIntervalMs int64 `toml:"initial_interval_ms"`
+			MaxIntervalMs     int64 `toml:"max_interval_ms"`
+			MaxRetries        int32 `toml:"max_retries"`
+		} `toml:"retry"`
+		// Tencent 没有配置 app_id 的时候，不启用腾讯云，密钥从环境变量读取
+		Tencent struct {
+			AppId  string `toml:"app_id"`
+			Region string `toml:"region"`
+		} `toml:"tencent"`
+		// Aliyun 没有配置 endpoint 的时候，不启用阿里云
+		Aliyun struct {
+			Endpoint        string `toml:"endpoint"`
+			AccessKeyId     string `toml:"access_key_id"`
+			AccessKeySecret string `toml:"access_key_secret"`
+		} `toml:"aliyun"`
+		Templates []struct {
+			Biz        string   `toml:"biz"`
+			Provider   string   `toml:"provider"`
+			Id         string   `toml:"id"`
+			Signature  string   `toml:"signature"`
+			ParamNames []string `toml:"param_names"`
+		} `toml:"templates"`
+	} `toml:"sms"`
+	Search struct {
+		ElasticAddr string `toml:"elastic_addr"`
+		// Memory 没有配置 ElasticAddr 的时候使用内置的倒排索引，数据只在进程内存里面，只适合本地开发
+		Memory bool `toml:"memory"`
+	} `toml:"search"`
+	// S3 对象存储，腾讯云 COS 兼容 S3 协议，密钥从环境变量读取
+	S3 struct {
+		Region        string `toml:"region"`
+		Endpoint      string `toml:"endpoint"`
+		ArticleBucket string `toml:"article_bucket"`
+	} `toml:"s3"`
+	// Blob 图片等附件的存储
+	Blob struct {
+		// Type 为 fs 的时候存到本地目录 Root，为 s3 的时候存到 Bucket
+		Type   string `toml:"type"`
+		Root   string `toml:"root"`
+		Bucket string `toml:"bucket"`
+		// BaseURL 拼接在 key 前面作为访问的 URL
+		BaseURL string `toml:"base_url"`
+	} `toml:"blob"`
+	Web struct {
+		// TrustedProxies 前面的反向代理的地址，只有这些地址转发过来的 X-Forwarded-For 才可信
+		// 为空的时候读者的 IP 直接使用连接的地址
+		TrustedProxies []string `toml:"trusted_proxies"`
+	} `toml:"web"`
+	// Site 站点对外的地址，订阅源和站点地图里面的链接都基于它
+	Site struct {
+		BaseURL string `toml:"base_url"`
+	} `toml:"site"`
+	// Read 阅读数的统计
+	Read struct {
+		// DedupeWindowSec 同一个读者在这段时间里面重复阅读只算一次，单位秒，0 表示不去重
+		DedupeWindowSec int64 `toml:"dedupe_window_sec"`
+	} `toml:"read"`
 }
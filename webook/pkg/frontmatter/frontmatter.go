@@ -0,0 +1,72 @@
+// Package frontmatter 读写带有 YAML front matter 的 Markdown 文件
+//
+//	---
+//	title: 标题
+//	---
+//	正文
+package frontmatter
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const delimiter = "---"
+
+// Marshal 把 meta 序列化成 YAML 放在 body 前面
+func Marshal(meta any, body string) ([]byte, error) {
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(body) + 2*len(delimiter) + 3)
+	buf.WriteString(delimiter + "\n")
+	buf.Write(data)
+	buf.WriteString(delimiter + "\n")
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 把 front matter 解析到 meta 里面，返回剩下的正文
+// 没有 front matter 的时候 meta 保持不变，整个文件都是正文
+func Unmarshal(data []byte, meta any) (string, error) {
+	// 有些编辑器会在开头加上 BOM
+	content := strings.TrimPrefix(string(data), "\ufeff")
+	header, body, ok := split(content)
+	if !ok {
+		return content, nil
+	}
+	if err := yaml.Unmarshal([]byte(header), meta); err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+// split 第一行必须是 ---，到下一个单独成行的 --- 为止都是 front matter
+func split(content string) (string, string, bool) {
+	first, rest, ok := cutLine(content)
+	if !ok || first != delimiter {
+		return "", "", false
+	}
+	offset := 0
+	for offset <= len(rest) {
+		line, next, more := cutLine(rest[offset:])
+		if line == delimiter {
+			return rest[:offset], next, true
+		}
+		if !more {
+			break
+		}
+		offset = len(rest) - len(next)
+	}
+	return "", "", false
+}
+
+// cutLine 切出第一行，兼容 \r\n，ok 表示后面还有内容
+func cutLine(s string) (string, string, bool) {
+	line, rest, ok := strings.Cut(s, "\n")
+	return strings.TrimRight(line, "\r"), rest, ok
+}
@@ -0,0 +1,81 @@
+package frontmatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type meta struct {
+	Title string   `yaml:"title"`
+	Tags  []string `yaml:"tags,omitempty"`
+}
+
+func TestMarshal(t *testing.T) {
+	data, err := Marshal(meta{Title: "标题: 带冒号", Tags: []string{"Go"}}, "# 正文\n")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: '标题: 带冒号'\ntags:\n    - Go\n---\n# 正文\n", string(data))
+
+	var m meta
+	body, err := Unmarshal(data, &m)
+	require.NoError(t, err)
+	assert.Equal(t, meta{Title: "标题: 带冒号", Tags: []string{"Go"}}, m)
+	assert.Equal(t, "# 正文\n", body)
+}
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		wantMeta meta
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "没有 front matter",
+			data:     "# 标题\n正文",
+			wantBody: "# 标题\n正文",
+		},
+		{
+			name:     "Windows 换行和 BOM",
+			data:     "\ufeff---\r\ntitle: 标题\r\n---\r\n正文",
+			wantMeta: meta{Title: "标题"},
+			wantBody: "正文",
+		},
+		{
+			name:     "只有 front matter",
+			data:     "---\ntitle: 标题\n---",
+			wantMeta: meta{Title: "标题"},
+		},
+		{
+			name:     "正文里面的分隔线不受影响",
+			data:     "---\ntitle: 标题\n---\n上面\n---\n下面",
+			wantMeta: meta{Title: "标题"},
+			wantBody: "上面\n---\n下面",
+		},
+		{
+			name:     "没有结束的分隔线，当成正文",
+			data:     "---\ntitle: 标题\n",
+			wantBody: "---\ntitle: 标题\n",
+		},
+		{
+			name:    "YAML 格式不对",
+			data:    "---\ntitle: [\n---\n正文",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var m meta
+			body, err := Unmarshal([]byte(tc.data), &m)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMeta, m)
+			assert.Equal(t, tc.wantBody, body)
+		})
+	}
+}
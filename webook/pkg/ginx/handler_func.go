@@ -53,13 +53,7 @@ func WrapReqAndToken[T any, C jwt.Claims](fn func(ctx *gin.Context, req T, uc C)
 			return
 		}
 
-		val, ok := ctx.Get("claims")
-		if !ok {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		c, ok := val.(C)
+		c, ok := claims[C](ctx)
 		if !ok {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
@@ -77,18 +71,26 @@ func WrapReqAndToken[T any, C jwt.Claims](fn func(ctx *gin.Context, req T, uc C)
 
 func WrapToken[C jwt.Claims](fn func(ctx *gin.Context, uc C) (Result, error)) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		val, ok := ctx.Get("claims")
+		c, ok := claims[C](ctx)
 		if !ok {
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		c, ok := val.(C)
-		if !ok {
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
+		res, err := fn(ctx, c)
+		if err != nil {
+			L.Error("处理业务逻辑出错", logger.String("path", ctx.Request.URL.Path),
+				logger.String("route", ctx.FullPath()),
+				logger.Error(err))
 		}
+		ctx.JSON(http.StatusOK, res)
+	}
+}
 
+// WrapOptionalToken 登录是可选的接口，没有登录的时候 uc 是零值
+func WrapOptionalToken[C jwt.Claims](fn func(ctx *gin.Context, uc C) (Result, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c, _ := claims[C](ctx)
 		res, err := fn(ctx, c)
 		if err != nil {
 			L.Error("处理业务逻辑出错", logger.String("path", ctx.Request.URL.Path),
@@ -98,3 +100,23 @@ func WrapToken[C jwt.Claims](fn func(ctx *gin.Context, uc C) (Result, error)) gi
 		ctx.JSON(http.StatusOK, res)
 	}
 }
+
+// claims 登录校验的 middleware 放进去的是指针，这里两种都兼容
+func claims[C jwt.Claims](ctx *gin.Context) (C, bool) {
+	var zero C
+	val, ok := ctx.Get("claims")
+	if !ok {
+		return zero, false
+	}
+	switch c := val.(type) {
+	case C:
+		return c, true
+	case *C:
+		if c == nil {
+			return zero, false
+		}
+		return *c, true
+	default:
+		return zero, false
+	}
+}
@@ -0,0 +1,200 @@
+// Package linediff 按行比较两段文本，使用线性空间的 Myers 差分算法
+package linediff
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxLines 每一边最多比较多少行
+// 线性空间的 Myers 算法内存是 O(N+M)，但是时间还是 O((N+M)D)，不限制行数的话一次请求就能占满 CPU
+const MaxLines = 5000
+
+var ErrTooLarge = errors.New("内容太多，无法比较")
+
+type Op uint8
+
+const (
+	OpEqual Op = iota
+	OpInsert
+	OpDelete
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpInsert:
+		return "+"
+	case OpDelete:
+		return "-"
+	default:
+		return " "
+	}
+}
+
+// Line 差异里面的一行
+// OldNo 和 NewNo 是从 1 开始的行号，新增的行没有 OldNo，删除的行没有 NewNo
+type Line struct {
+	Op    Op
+	OldNo int
+	NewNo int
+	Text  string
+}
+
+// Diff 按行比较 a 和 b，任何一边超过 MaxLines 行的时候返回 ErrTooLarge
+func Diff(a, b string) ([]Line, error) {
+	al, bl := split(a), split(b)
+	if len(al) > MaxLines || len(bl) > MaxLines {
+		return nil, ErrTooLarge
+	}
+	return DiffLines(al, bl), nil
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// DiffLines Myers 算法，每次找到中间的 snake 把问题一分为二，
+// 时间复杂度 O((N+M)D)，D 是差异的行数，空间复杂度 O(N+M)
+// 不限制输入的大小，调用者自己保证
+func DiffLines(a, b []string) []Line {
+	d := &differ{a: a, b: b, res: make([]Line, 0, len(a)+len(b))}
+	d.diff(0, len(a), 0, len(b))
+	return d.res
+}
+
+type differ struct {
+	a, b []string
+	res  []Line
+}
+
+// diff 比较 a[aLo:aHi] 和 b[bLo:bHi]，结果按顺序追加到 res
+func (d *differ) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.equal(aLo, bLo)
+		aLo++
+		bLo++
+	}
+	// 公共后缀最后再输出
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		d.insert(bLo, bHi)
+	case bLo == bHi:
+		d.delete(aLo, aHi)
+	default:
+		x, y, ok := d.bisect(aLo, aHi, bLo, bHi)
+		if ok {
+			d.diff(aLo, x, bLo, y)
+			d.diff(x, aHi, y, bHi)
+		} else {
+			d.delete(aLo, aHi)
+			d.insert(bLo, bHi)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.equal(aHi+i, bHi+i)
+	}
+}
+
+// bisect 从两头同时往中间找，返回前向和后向路径第一次重叠的位置，
+// 两边一行都对不上的时候 ok 是 false
+// v 只用在这一次调用里面，返回之后才递归，所以任何时候内存都是 O(N+M)
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vf := make([]int, 2*maxD+2)
+	vb := make([]int, 2*maxD+2)
+	for i := range vf {
+		vf[i], vb[i] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+	delta := n - m
+	// delta 是奇数的时候，前向路径会先碰到后向路径
+	front := delta%2 != 0
+	// 越过边界的对角线后面不用再算了
+	var fStart, fEnd, bStart, bEnd int
+	for D := 0; D < maxD; D++ {
+		for k := -D + fStart; k <= D-fEnd; k += 2 {
+			var x1 int
+			if k == -D || (k != D && vf[offset+k-1] < vf[offset+k+1]) {
+				x1 = vf[offset+k+1]
+			} else {
+				x1 = vf[offset+k-1] + 1
+			}
+			y1 := x1 - k
+			for x1 < n && y1 < m && d.a[aLo+x1] == d.b[bLo+y1] {
+				x1++
+				y1++
+			}
+			vf[offset+k] = x1
+			switch {
+			case x1 > n:
+				fEnd += 2
+			case y1 > m:
+				fStart += 2
+			case front:
+				kb := offset + delta - k
+				if kb >= 0 && kb < len(vb) && vb[kb] != -1 && x1 >= n-vb[kb] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+		for k := -D + bStart; k <= D-bEnd; k += 2 {
+			var x2 int
+			if k == -D || (k != D && vb[offset+k-1] < vb[offset+k+1]) {
+				x2 = vb[offset+k+1]
+			} else {
+				x2 = vb[offset+k-1] + 1
+			}
+			y2 := x2 - k
+			for x2 < n && y2 < m && d.a[aHi-1-x2] == d.b[bHi-1-y2] {
+				x2++
+				y2++
+			}
+			vb[offset+k] = x2
+			switch {
+			case x2 > n:
+				bEnd += 2
+			case y2 > m:
+				bStart += 2
+			case !front:
+				kf := offset + delta - k
+				if kf >= 0 && kf < len(vf) && vf[kf] != -1 {
+					x1 := vf[kf]
+					y1 := offset + x1 - kf
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (d *differ) equal(x, y int) {
+	d.res = append(d.res, Line{Op: OpEqual, OldNo: x + 1, NewNo: y + 1, Text: d.a[x]})
+}
+
+func (d *differ) insert(lo, hi int) {
+	for y := lo; y < hi; y++ {
+		d.res = append(d.res, Line{Op: OpInsert, NewNo: y + 1, Text: d.b[y]})
+	}
+}
+
+func (d *differ) delete(lo, hi int) {
+	for x := lo; x < hi; x++ {
+		d.res = append(d.res, Line{Op: OpDelete, OldNo: x + 1, Text: d.a[x]})
+	}
+}
@@ -0,0 +1,144 @@
+package linediff
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "完全一样",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: []Line{
+				{Op: OpEqual, OldNo: 1, NewNo: 1, Text: "a"},
+				{Op: OpEqual, OldNo: 2, NewNo: 2, Text: "b"},
+			},
+		},
+		{
+			name: "从空到有",
+			a:    "",
+			b:    "a\nb",
+			want: []Line{
+				{Op: OpInsert, NewNo: 1, Text: "a"},
+				{Op: OpInsert, NewNo: 2, Text: "b"},
+			},
+		},
+		{
+			name: "全部删除",
+			a:    "a\nb",
+			b:    "",
+			want: []Line{
+				{Op: OpDelete, OldNo: 1, Text: "a"},
+				{Op: OpDelete, OldNo: 2, Text: "b"},
+			},
+		},
+		{
+			name: "修改中间一行",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []Line{
+				{Op: OpEqual, OldNo: 1, NewNo: 1, Text: "a"},
+				{Op: OpDelete, OldNo: 2, Text: "b"},
+				{Op: OpInsert, NewNo: 2, Text: "x"},
+				{Op: OpEqual, OldNo: 3, NewNo: 3, Text: "c"},
+			},
+		},
+		{
+			name: "经典例子",
+			a:    "a\nb\nc\na\nb\nb\na",
+			b:    "c\nb\na\nb\na\nc",
+			want: []Line{
+				{Op: OpDelete, OldNo: 1, Text: "a"},
+				{Op: OpInsert, NewNo: 1, Text: "c"},
+				{Op: OpEqual, OldNo: 2, NewNo: 2, Text: "b"},
+				{Op: OpDelete, OldNo: 3, Text: "c"},
+				{Op: OpEqual, OldNo: 4, NewNo: 3, Text: "a"},
+				{Op: OpEqual, OldNo: 5, NewNo: 4, Text: "b"},
+				{Op: OpDelete, OldNo: 6, Text: "b"},
+				{Op: OpEqual, OldNo: 7, NewNo: 5, Text: "a"},
+				{Op: OpInsert, NewNo: 6, Text: "c"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Diff(tc.a, tc.b)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestDiffTooLarge(t *testing.T) {
+	big := strings.Repeat("a\n", MaxLines+1)
+	_, err := Diff(big, "a")
+	assert.Equal(t, ErrTooLarge, err)
+	_, err = Diff("a", big)
+	assert.Equal(t, ErrTooLarge, err)
+}
+
+// TestDiffLinesRandom 随机生成输入，检查差异能还原出两边的内容，并且差异的行数是最少的
+func TestDiffLinesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	gen := func() []string {
+		res := make([]string, r.Intn(30))
+		for i := range res {
+			res[i] = strconv.Itoa(r.Intn(4))
+		}
+		return res
+	}
+	for i := 0; i < 500; i++ {
+		a, b := gen(), gen()
+		lines := DiffLines(a, b)
+		var oldLines, newLines []string
+		edits := 0
+		for _, l := range lines {
+			switch l.Op {
+			case OpEqual:
+				assert.Equal(t, a[l.OldNo-1], l.Text)
+				assert.Equal(t, b[l.NewNo-1], l.Text)
+				oldLines = append(oldLines, l.Text)
+				newLines = append(newLines, l.Text)
+			case OpDelete:
+				assert.Equal(t, len(oldLines)+1, l.OldNo)
+				oldLines = append(oldLines, l.Text)
+				edits++
+			case OpInsert:
+				assert.Equal(t, len(newLines)+1, l.NewNo)
+				newLines = append(newLines, l.Text)
+				edits++
+			}
+		}
+		assert.Equal(t, strings.Join(a, "\n"), strings.Join(oldLines, "\n"))
+		assert.Equal(t, strings.Join(b, "\n"), strings.Join(newLines, "\n"))
+		assert.Equal(t, len(a)+len(b)-2*lcs(a, b), edits)
+	}
+}
+
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else {
+				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
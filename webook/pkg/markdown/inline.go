@@ -0,0 +1,492 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var entityRe = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+
+// inline 行内元素，delim 不为 nil 的时候是强调的分隔符
+type inline struct {
+	html  string
+	text  string
+	delim *delimiter
+}
+
+// delimiter 连续的 * _ ~
+// open 和 close 是匹配之后生成的标签，count 是还没有匹配的字符数
+type delimiter struct {
+	ch       byte
+	count    int
+	orig     int
+	canOpen  bool
+	canClose bool
+	active   bool
+	open     string
+	close    string
+}
+
+// renderInline 返回 HTML 和纯文本
+func renderInline(s string) (string, string) {
+	return renderNested(s, 0)
+}
+
+// renderNested depth 是链接文字的嵌套层数，超过 maxNesting 之后里面的 [ 只当成普通文本
+func renderNested(s string, depth int) (string, string) {
+	p := &inlineParser{src: s, depth: depth}
+	p.scan()
+	p.parse()
+	p.processEmphasis()
+	var h, t strings.Builder
+	for _, n := range p.nodes {
+		if d := n.delim; d != nil {
+			rest := strings.Repeat(string(d.ch), d.count)
+			// 没有匹配上的字符在开始标签的外面，结束标签的外面
+			h.WriteString(d.close)
+			h.WriteString(rest)
+			h.WriteString(d.open)
+			t.WriteString(rest)
+			continue
+		}
+		h.WriteString(n.html)
+		t.WriteString(n.text)
+	}
+	return h.String(), t.String()
+}
+
+type inlineParser struct {
+	src   string
+	depth int
+	nodes []inline
+	buf   strings.Builder
+	// ticks 每种长度的反引号串出现的位置，从小到大
+	ticks map[int][]int
+	// brackets [ 的位置到匹配的 ] 的位置，没有匹配的不在里面
+	brackets map[int]int
+}
+
+// scan 预先找出所有反引号串和匹配的方括号，整个过程只扫描一遍，
+// 避免每个没有匹配的 [ 或者反引号都扫描到结尾，输入很长的时候变成平方级别
+func (p *inlineParser) scan() {
+	s := p.src
+	p.ticks = make(map[int][]int)
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		n := runLength(s, i, '`')
+		p.ticks[n] = append(p.ticks[n], i)
+		i += n
+	}
+	p.brackets = make(map[int]int)
+	var stack []int
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			// 代码里面的括号不算
+			n := runLength(s, j, '`')
+			if k := p.closingTicks(j, n); k >= 0 {
+				j = k + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			stack = append(stack, j)
+		case ']':
+			if len(stack) > 0 {
+				p.brackets[stack[len(stack)-1]] = j
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// closingTicks 返回 i 开始的 n 个反引号后面第一个长度刚好是 n 的反引号串的位置，没有的时候返回 -1
+func (p *inlineParser) closingTicks(i, n int) int {
+	pos := p.ticks[n]
+	k := sort.SearchInts(pos, i+n)
+	if k == len(pos) {
+		return -1
+	}
+	return pos[k]
+}
+
+func (p *inlineParser) flush() {
+	if p.buf.Len() == 0 {
+		return
+	}
+	s := p.buf.String()
+	p.nodes = append(p.nodes, inline{html: html.EscapeString(s), text: s})
+	p.buf.Reset()
+}
+
+func (p *inlineParser) emit(h, t string) {
+	p.flush()
+	p.nodes = append(p.nodes, inline{html: h, text: t})
+}
+
+func (p *inlineParser) parse() {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			p.emit("<br />\n", "\n")
+			i += 2
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			p.buf.WriteByte(s[i+1])
+			i += 2
+		case c == '\n':
+			// 行尾两个以上的空格是硬换行
+			buf := p.buf.String()
+			trimmed := strings.TrimRight(buf, " ")
+			p.buf.Reset()
+			p.buf.WriteString(trimmed)
+			if len(buf)-len(trimmed) >= 2 {
+				p.emit("<br />\n", "\n")
+			} else {
+				p.emit("\n", " ")
+			}
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+		case c == '`':
+			i = p.codeSpan(i)
+		case c == '!' && i+1 < len(s) && s[i+1] == '[' && p.depth < maxNesting:
+			i = p.link(i+1, true)
+		case c == '[' && p.depth < maxNesting:
+			i = p.link(i, false)
+		case c == '<':
+			i = p.autolink(i)
+		case c == '&':
+			if m := entityRe.FindString(s[i:]); m != "" {
+				p.buf.WriteString(html.UnescapeString(m))
+				i += len(m)
+				continue
+			}
+			p.buf.WriteByte(c)
+			i++
+		case c == '*' || c == '_' || c == '~':
+			i = p.delimiterRun(i)
+		default:
+			p.buf.WriteByte(c)
+			i++
+		}
+	}
+	p.flush()
+}
+
+func (p *inlineParser) codeSpan(i int) int {
+	s := p.src
+	n := runLength(s, i, '`')
+	if j := p.closingTicks(i, n); j >= 0 {
+		code := strings.ReplaceAll(s[i+n:j], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		p.emit("<code>"+html.EscapeString(code)+"</code>", code)
+		return j + n
+	}
+	// 没有结束的反引号，当成普通文本
+	p.buf.WriteString(s[i : i+n])
+	return i + n
+}
+
+// link i 指向 [，图片的时候 i 前面是 !
+func (p *inlineParser) link(i int, image bool) int {
+	s := p.src
+	start := i
+	if image {
+		start = i - 1
+	}
+	end, ok := p.brackets[i]
+	if !ok || end+1 >= len(s) || s[end+1] != '(' {
+		p.buf.WriteString(s[start : i+1])
+		return i + 1
+	}
+	dest, title, next, ok := parseLinkDest(s, end+2)
+	if !ok {
+		p.buf.WriteString(s[start : i+1])
+		return i + 1
+	}
+	h, t := renderNested(s[i+1:end], p.depth+1)
+	if image {
+		src, ok := safeURL(dest, false)
+		if !ok {
+			p.buf.WriteString(t)
+			return next
+		}
+		tag := `<img src="` + src + `" alt="` + html.EscapeString(t) + `"`
+		if title != "" {
+			tag += ` title="` + html.EscapeString(title) + `"`
+		}
+		p.emit(tag+" />", t)
+		return next
+	}
+	href, ok := safeURL(dest, true)
+	if !ok {
+		// 不安全的链接只保留文字
+		p.emit(h, t)
+		return next
+	}
+	tag := `<a href="` + href + `"`
+	if title != "" {
+		tag += ` title="` + html.EscapeString(title) + `"`
+	}
+	p.emit(tag+` rel="nofollow noopener">`+h+"</a>", t)
+	return next
+}
+
+// autolink <https://example.com> 或者 <foo@example.com>
+func (p *inlineParser) autolink(i int) int {
+	s := p.src
+	// 遇到空格、换行或者下一个 < 就可以停下来了，不用找到结尾
+	end := strings.IndexAny(s[i+1:], " <>\n")
+	if end > 0 && s[i+1+end] == '>' {
+		raw := s[i+1 : i+1+end]
+		dest := raw
+		if isEmail(raw) {
+			dest = "mailto:" + raw
+		}
+		if href, ok := safeURL(dest, true); ok && strings.Contains(dest, ":") {
+			p.emit(`<a href="`+href+`" rel="nofollow noopener">`+html.EscapeString(raw)+"</a>", raw)
+			return i + end + 2
+		}
+	}
+	p.buf.WriteByte('<')
+	return i + 1
+}
+
+func (p *inlineParser) delimiterRun(i int) int {
+	s := p.src
+	c := s[i]
+	n := runLength(s, i, c)
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	if i == 0 {
+		before = ' '
+	}
+	after, _ := utf8.DecodeRuneInString(s[i+n:])
+	if i+n >= len(s) {
+		after = ' '
+	}
+	// 参考 CommonMark 的 left-flanking 和 right-flanking
+	left := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	right := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+	d := &delimiter{ch: c, count: n, orig: n, active: true}
+	if c == '_' {
+		// 单词内部的 _ 不是强调，例如 snake_case
+		d.canOpen = left && (!right || isPunct(before))
+		d.canClose = right && (!left || isPunct(after))
+	} else {
+		d.canOpen = left
+		d.canClose = right
+	}
+	p.flush()
+	p.nodes = append(p.nodes, inline{delim: d})
+	return i + n
+}
+
+// openerKey 决定结束分隔符能和哪些开始分隔符匹配的属性
+type openerKey struct {
+	ch      byte
+	canOpen bool
+	mod     int
+	double  bool
+}
+
+// processEmphasis 从左到右找结束的分隔符，再往回找最近的开始分隔符
+func (p *inlineParser) processEmphasis() {
+	var delims []*delimiter
+	for _, n := range p.nodes {
+		if n.delim != nil {
+			delims = append(delims, n.delim)
+		}
+	}
+	// bottom 参考 CommonMark 的 openers_bottom，同样的结束分隔符往回找过一次没有找到，
+	// 下次就不用再找这个位置之前的了，不然 a_ a_ a_ ... 会变成平方级别
+	bottom := make(map[openerKey]int)
+	for ci, c := range delims {
+		for c.canClose && c.count > 0 {
+			key := openerKey{ch: c.ch, canOpen: c.canOpen, mod: c.orig % 3, double: c.count >= 2}
+			low, ok := bottom[key]
+			if !ok {
+				low = -1
+			}
+			oi := ci - 1
+			for ; oi > low; oi-- {
+				o := delims[oi]
+				if o.ch != c.ch || !o.canOpen || !o.active || o.count == 0 {
+					continue
+				}
+				if c.ch == '~' {
+					if o.count >= 2 && c.count >= 2 {
+						break
+					}
+					continue
+				}
+				// 同时可以开始和结束的时候，长度之和是 3 的倍数不能匹配
+				if (o.canClose || c.canOpen) && (o.orig+c.orig)%3 == 0 &&
+					!(o.orig%3 == 0 && c.orig%3 == 0) {
+					continue
+				}
+				break
+			}
+			if oi <= low {
+				bottom[key] = ci - 1
+				break
+			}
+			o := delims[oi]
+			use, tag := 1, "em"
+			switch {
+			case c.ch == '~':
+				use, tag = 2, "del"
+			case o.count >= 2 && c.count >= 2:
+				use, tag = 2, "strong"
+			}
+			o.count -= use
+			c.count -= use
+			// 后匹配的在外层
+			o.open = "<" + tag + ">" + o.open
+			c.close = c.close + "</" + tag + ">"
+			for k := oi + 1; k < ci; k++ {
+				delims[k].active = false
+			}
+		}
+	}
+}
+
+// parseLinkDest 解析 (url "title")，i 指向 ( 后面的第一个字符
+func parseLinkDest(s string, i int) (dest, title string, next int, ok bool) {
+	i = skipSpaces(s, i)
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start, depth := i, 0
+	loop:
+		for ; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+			case c == '(':
+				// 括号嵌套太深的不当成链接，不然 [a]([a]([a](... 每一个都要扫描到结尾
+				if depth++; depth > maxNesting {
+					return "", "", 0, false
+				}
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c == ' ' || c == '\n' || c < 0x20:
+				break loop
+			}
+		}
+		dest = s[start:i]
+	}
+	j := skipSpaces(s, i)
+	if j < len(s) && j > i && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		end := strings.IndexByte(s[j+1:], closer)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = unescape(s[j+1 : j+1+end])
+		j = skipSpaces(s, j+end+2)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return unescape(dest), title, j + 1, true
+}
+
+// safeURL 只允许 http、https 和相对路径，allowMailto 为 true 的时候允许 mailto
+// 返回值已经转义，可以直接放进属性里面
+func safeURL(raw string, allowMailto bool) (string, bool) {
+	u := strings.TrimSpace(raw)
+	for _, c := range u {
+		if c < 0x20 || c == 0x7f {
+			return "", false
+		}
+	}
+	if i := strings.IndexAny(u, ":/?#"); i >= 0 && u[i] == ':' {
+		switch strings.ToLower(u[:i]) {
+		case "http", "https":
+		case "mailto":
+			if !allowMailto {
+				return "", false
+			}
+		default:
+			return "", false
+		}
+	}
+	u = strings.ReplaceAll(u, " ", "%20")
+	return html.EscapeString(u), true
+}
+
+func unescape(s string) string {
+	if !strings.ContainsAny(s, `\&`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		} else if s[i] == '&' {
+			if m := entityRe.FindString(s[i:]); m != "" {
+				sb.WriteString(html.UnescapeString(m))
+				i += len(m) - 1
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func isEmail(s string) bool {
+	at := strings.IndexByte(s, '@')
+	return at > 0 && at < len(s)-1 && !strings.ContainsAny(s, ":/") &&
+		strings.Contains(s[at:], ".")
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < 0x80 && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
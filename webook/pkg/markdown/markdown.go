@@ -0,0 +1,577 @@
+// Package markdown 把 Markdown 渲染成 HTML，同时生成目录和纯文本
+//
+// 支持 CommonMark 的常用语法，以及 GFM 的表格和删除线。
+// 不支持内嵌 HTML，原文里面的 HTML 会被当成普通文本转义，
+// 链接和图片只允许 http、https 和相对路径（链接额外允许 mailto），
+// 所以渲染结果可以直接展示，不需要再做 XSS 过滤。
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Heading 目录里面的一项，Anchor 是标题在 HTML 里面的 id
+type Heading struct {
+	Level  int
+	Text   string
+	Anchor string
+}
+
+type Result struct {
+	HTML string
+	// TOC 按照标题出现的顺序
+	TOC []Heading
+	// Text 去掉了所有标记的纯文本，块之间用换行分隔
+	Text string
+}
+
+// Render 渲染 src，任何输入都能渲染，不会返回错误
+func Render(src string) Result {
+	r := &renderer{anchors: make(map[string]int)}
+	r.blocks(splitLines(src), false)
+	return Result{
+		HTML: strings.TrimRight(r.html.String(), "\n"),
+		TOC:  r.toc,
+		Text: strings.TrimRight(r.text.String(), "\n"),
+	}
+}
+
+// maxNesting 引用、列表和链接文字最多嵌套的层数，更深的当成普通文本，
+// 每一层都要重新处理里面的内容，不限制的话很深的嵌套会变成平方级别
+const maxNesting = 32
+
+type renderer struct {
+	html    strings.Builder
+	text    strings.Builder
+	toc     []Heading
+	anchors map[string]int
+	// depth 当前引用和列表的嵌套层数
+	depth int
+}
+
+func splitLines(src string) []string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return lines
+}
+
+// expandTabs 只处理行首的制表符，缩进的计算都是按照空格来的
+func expandTabs(line string) string {
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			sb.WriteByte(' ')
+		case '\t':
+			sb.WriteString(strings.Repeat(" ", 4-sb.Len()%4))
+		default:
+			if sb.Len() == i {
+				return line
+			}
+			return sb.String() + line[i:]
+		}
+	}
+	return sb.String()
+}
+
+// blocks tight 为 true 的时候段落不输出 <p>，用于紧凑的列表
+func (r *renderer) blocks(lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case isBlank(line):
+			i++
+		case isFence(line):
+			i = r.fencedCode(lines, i)
+		case isHeading(line):
+			r.heading(line)
+			i++
+		case isThematicBreak(line):
+			r.html.WriteString("<hr />\n")
+			i++
+		case isBlockquote(line) && r.depth < maxNesting:
+			i = r.blockquote(lines, i)
+		case isListItem(line) && r.depth < maxNesting:
+			i = r.list(lines, i)
+		case indentOf(line) >= 4:
+			i = r.indentedCode(lines, i)
+		case isTableStart(lines, i):
+			i = r.table(lines, i)
+		default:
+			i = r.paragraph(lines, i, tight)
+		}
+	}
+}
+
+func (r *renderer) fencedCode(lines []string, i int) int {
+	indent := indentOf(lines[i])
+	open := lines[i][indent:]
+	ch := open[0]
+	n := len(open) - len(strings.TrimLeft(open, string(ch)))
+	info := strings.TrimSpace(open[n:])
+	var code []string
+	i++
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isFenceClose(line, ch, n) {
+			i++
+			break
+		}
+		// 去掉和开始标记一样多的缩进
+		code = append(code, line[min(indent, indentOf(line)):])
+	}
+	r.code(code, info)
+	return i
+}
+
+func (r *renderer) indentedCode(lines []string, i int) int {
+	var code []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if !isBlank(line) && indentOf(line) < 4 {
+			break
+		}
+		if len(line) >= 4 {
+			line = line[4:]
+		} else {
+			line = ""
+		}
+		code = append(code, line)
+	}
+	// 结尾的空行不属于代码
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+	r.code(code, "")
+	return i
+}
+
+func (r *renderer) code(code []string, info string) {
+	content := strings.Join(code, "\n")
+	if len(code) > 0 {
+		content += "\n"
+	}
+	r.html.WriteString("<pre><code")
+	if lang := codeLang(info); lang != "" {
+		r.html.WriteString(` class="language-`)
+		r.html.WriteString(lang)
+		r.html.WriteString(`"`)
+	}
+	r.html.WriteString(">")
+	r.html.WriteString(html.EscapeString(content))
+	r.html.WriteString("</code></pre>\n")
+	r.text.WriteString(content)
+}
+
+// codeLang 语言会被放到 class 里面，只保留安全的字符
+func codeLang(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range fields[0] {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '+' || c == '#' {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func (r *renderer) heading(line string) {
+	level, content := parseHeading(line)
+	h, text := renderInline(content)
+	anchor := r.anchor(text)
+	r.toc = append(r.toc, Heading{Level: level, Text: text, Anchor: anchor})
+	tag := "h" + strconv.Itoa(level)
+	r.html.WriteString("<" + tag + ` id="` + anchor + `">`)
+	r.html.WriteString(h)
+	r.html.WriteString("</" + tag + ">\n")
+	r.text.WriteString(text)
+	r.text.WriteString("\n")
+}
+
+// anchor 同一篇文章里面重复的标题加上序号
+func (r *renderer) anchor(text string) string {
+	base := slug(text)
+	cnt := r.anchors[base]
+	r.anchors[base] = cnt + 1
+	if cnt == 0 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(cnt)
+}
+
+func (r *renderer) blockquote(lines []string, i int) int {
+	var inner []string
+	lastBlank := false
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlockquote(line) {
+			s := line[indentOf(line)+1:]
+			s = strings.TrimPrefix(s, " ")
+			inner = append(inner, s)
+			lastBlank = isBlank(s)
+			continue
+		}
+		// 段落可以延续到没有 > 的行
+		if lastBlank || isBlank(line) || startsBlock(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+	r.html.WriteString("<blockquote>\n")
+	r.depth++
+	r.blocks(inner, false)
+	r.depth--
+	r.html.WriteString("</blockquote>\n")
+	return i
+}
+
+func (r *renderer) list(lines []string, i int) int {
+	first, _ := parseListMarker(lines[i])
+	var (
+		items    [][]string
+		cur      = []string{first.content}
+		marker   = first
+		loose    bool
+		sawBlank bool
+	)
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			cur = append(cur, "")
+			sawBlank = true
+			continue
+		}
+		if indentOf(line) >= marker.contentIndent {
+			cur = append(cur, line[marker.contentIndent:])
+			if sawBlank {
+				loose = true
+			}
+			sawBlank = false
+			continue
+		}
+		if m, ok := parseListMarker(line); ok && m.sameList(first) && !isThematicBreak(line) {
+			items = append(items, cur)
+			cur = []string{m.content}
+			marker = m
+			if sawBlank {
+				loose = true
+			}
+			sawBlank = false
+			continue
+		}
+		if !sawBlank && !startsBlock(line) {
+			cur = append(cur, strings.TrimLeft(line, " "))
+			continue
+		}
+		break
+	}
+	items = append(items, cur)
+
+	tag := "ul"
+	if first.ordered {
+		tag = "ol"
+	}
+	r.html.WriteString("<" + tag)
+	if first.ordered && first.start != 1 {
+		r.html.WriteString(` start="` + strconv.Itoa(first.start) + `"`)
+	}
+	r.html.WriteString(">\n")
+	r.depth++
+	for _, item := range items {
+		r.html.WriteString("<li>")
+		r.blocks(item, !loose)
+		r.html.WriteString("</li>\n")
+	}
+	r.depth--
+	r.html.WriteString("</" + tag + ">\n")
+	return i
+}
+
+func (r *renderer) table(lines []string, i int) int {
+	header := splitCells(lines[i])
+	aligns := parseAligns(lines[i+1])
+	r.html.WriteString("<table>\n<thead>\n")
+	r.row(header, aligns, "th")
+	r.html.WriteString("</thead>\n")
+	i += 2
+	hasBody := false
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || startsBlock(line) {
+			break
+		}
+		if !hasBody {
+			r.html.WriteString("<tbody>\n")
+			hasBody = true
+		}
+		r.row(splitCells(line), aligns, "td")
+	}
+	if hasBody {
+		r.html.WriteString("</tbody>\n")
+	}
+	r.html.WriteString("</table>\n")
+	return i
+}
+
+// row 单元格的数量以表头为准，多的丢掉，少的补空
+func (r *renderer) row(cells []string, aligns []string, tag string) {
+	r.html.WriteString("<tr>\n")
+	texts := make([]string, 0, len(aligns))
+	for j, align := range aligns {
+		var cell string
+		if j < len(cells) {
+			cell = cells[j]
+		}
+		h, text := renderInline(cell)
+		r.html.WriteString("<" + tag)
+		if align != "" {
+			r.html.WriteString(` align="` + align + `"`)
+		}
+		r.html.WriteString(">" + h + "</" + tag + ">\n")
+		texts = append(texts, text)
+	}
+	r.html.WriteString("</tr>\n")
+	r.text.WriteString(strings.Join(texts, " "))
+	r.text.WriteString("\n")
+}
+
+func (r *renderer) paragraph(lines []string, i int, tight bool) int {
+	var content []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || len(content) > 0 && (startsBlock(line) || isTableStart(lines, i)) {
+			break
+		}
+		content = append(content, strings.TrimLeft(line, " "))
+	}
+	h, text := renderInline(strings.TrimRight(strings.Join(content, "\n"), " "))
+	if tight {
+		r.html.WriteString(h)
+	} else {
+		r.html.WriteString("<p>" + h + "</p>\n")
+	}
+	r.text.WriteString(text)
+	r.text.WriteString("\n")
+	return i
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// startsBlock 能够打断段落的块
+func startsBlock(line string) bool {
+	if isFence(line) || isHeading(line) || isThematicBreak(line) || isBlockquote(line) {
+		return true
+	}
+	m, ok := parseListMarker(line)
+	// 有序列表只有从 1 开始才能打断段落，避免把 "2024. 年" 之类的句子当成列表
+	return ok && strings.TrimSpace(m.content) != "" && (!m.ordered || m.start == 1)
+}
+
+func isFence(line string) bool {
+	indent := indentOf(line)
+	if indent > 3 {
+		return false
+	}
+	s := line[indent:]
+	if len(s) < 3 || s[0] != '`' && s[0] != '~' {
+		return false
+	}
+	n := len(s) - len(strings.TrimLeft(s, string(s[0])))
+	// 反引号的代码块，信息字符串里面不能有反引号
+	return n >= 3 && !(s[0] == '`' && strings.Contains(s[n:], "`"))
+}
+
+func isFenceClose(line string, ch byte, n int) bool {
+	indent := indentOf(line)
+	if indent > 3 {
+		return false
+	}
+	s := line[indent:]
+	m := len(s) - len(strings.TrimLeft(s, string(ch)))
+	return m >= n && isBlank(s[m:])
+}
+
+func isHeading(line string) bool {
+	level, _ := parseHeading(line)
+	return level > 0
+}
+
+// parseHeading 不是标题的时候 level 为 0
+func parseHeading(line string) (int, string) {
+	indent := indentOf(line)
+	if indent > 3 {
+		return 0, ""
+	}
+	s := line[indent:]
+	level := len(s) - len(strings.TrimLeft(s, "#"))
+	if level == 0 || level > 6 {
+		return 0, ""
+	}
+	s = s[level:]
+	if s != "" && s[0] != ' ' {
+		return 0, ""
+	}
+	s = strings.TrimSpace(s)
+	// 去掉结尾可选的 #
+	if c := strings.TrimRight(s, "#"); c == "" {
+		s = ""
+	} else if len(c) < len(s) && strings.HasSuffix(c, " ") {
+		s = strings.TrimSpace(c)
+	}
+	return level, s
+}
+
+func isThematicBreak(line string) bool {
+	if indentOf(line) > 3 {
+		return false
+	}
+	s := strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+	if len(s) < 3 || s[0] != '-' && s[0] != '*' && s[0] != '_' {
+		return false
+	}
+	return strings.Count(s, s[:1]) == len(s)
+}
+
+func isBlockquote(line string) bool {
+	indent := indentOf(line)
+	return indent <= 3 && strings.HasPrefix(line[indent:], ">")
+}
+
+type listMarker struct {
+	ordered bool
+	// delim 无序列表是 - + *，有序列表是 . )
+	delim         byte
+	start         int
+	contentIndent int
+	content       string
+}
+
+func (m listMarker) sameList(other listMarker) bool {
+	return m.ordered == other.ordered && m.delim == other.delim
+}
+
+func isListItem(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok && !isThematicBreak(line)
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	indent := indentOf(line)
+	if indent > 3 {
+		return listMarker{}, false
+	}
+	s := line[indent:]
+	var m listMarker
+	markerLen := 0
+	switch {
+	case s == "":
+		return listMarker{}, false
+	case s[0] == '-' || s[0] == '+' || s[0] == '*':
+		m.delim = s[0]
+		markerLen = 1
+	default:
+		digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+		if digits == 0 || digits > 9 || digits == len(s) || s[digits] != '.' && s[digits] != ')' {
+			return listMarker{}, false
+		}
+		m.ordered = true
+		m.delim = s[digits]
+		m.start, _ = strconv.Atoi(s[:digits])
+		markerLen = digits + 1
+	}
+	rest := s[markerLen:]
+	if rest != "" && rest[0] != ' ' {
+		return listMarker{}, false
+	}
+	spaces := indentOf(rest)
+	if isBlank(rest) || spaces > 4 {
+		// 内容本身是缩进代码块的情况不考虑，只留一个空格
+		spaces = 1
+	}
+	m.contentIndent = indent + markerLen + spaces
+	if m.contentIndent < len(line) {
+		m.content = line[m.contentIndent:]
+	}
+	return m, true
+}
+
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") {
+		return false
+	}
+	aligns := parseAligns(lines[i+1])
+	return aligns != nil && len(aligns) == len(splitCells(lines[i]))
+}
+
+// parseAligns 解析表格的分隔行，不是分隔行的时候返回 nil
+func parseAligns(line string) []string {
+	if !strings.Contains(line, "-") || indentOf(line) > 3 {
+		return nil
+	}
+	cells := splitCells(line)
+	if len(cells) == 0 || !strings.Contains(line, "|") && len(cells) == 1 {
+		return nil
+	}
+	aligns := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		left := strings.HasPrefix(cell, ":")
+		right := strings.HasSuffix(cell, ":")
+		dashes := strings.Trim(cell, ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil
+		}
+		switch {
+		case left && right:
+			aligns = append(aligns, "center")
+		case left:
+			aligns = append(aligns, "left")
+		case right:
+			aligns = append(aligns, "right")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+	return aligns
+}
+
+// splitCells 按照没有转义的 | 切分，首尾的 | 可以省略
+func splitCells(line string) []string {
+	s := strings.TrimSpace(line)
+	s = strings.TrimPrefix(s, "|")
+	if strings.HasSuffix(s, "|") && !strings.HasSuffix(s, `\|`) {
+		s = s[:len(s)-1]
+	}
+	var (
+		cells []string
+		cur   strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '|':
+			cur.WriteByte('|')
+			i++
+		case s[i] == '|':
+			cells = append(cells, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cur.String()))
+}
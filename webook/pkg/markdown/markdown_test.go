@@ -0,0 +1,177 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		wantHTML string
+		wantText string
+	}{
+		{
+			name:     "标题和段落",
+			src:      "# Hello *World* #\n\n段落 **粗体** 和 _斜体_\n第二行  \n硬换行",
+			wantHTML: "<h1 id=\"hello-world\">Hello <em>World</em></h1>\n<p>段落 <strong>粗体</strong> 和 <em>斜体</em>\n第二行<br />\n硬换行</p>",
+			wantText: "Hello World\n段落 粗体 和 斜体 第二行\n硬换行",
+		},
+		{
+			name:     "嵌套列表",
+			src:      "- a\n- b\n  - c\n\n3. x\n4. y",
+			wantHTML: "<ul>\n<li>a</li>\n<li>b<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n<ol start=\"3\">\n<li>x</li>\n<li>y</li>\n</ol>",
+			wantText: "a\nb\nc\nx\ny",
+		},
+		{
+			name:     "松散列表",
+			src:      "- a\n\n- b",
+			wantHTML: "<ul>\n<li><p>a</p>\n</li>\n<li><p>b</p>\n</li>\n</ul>",
+			wantText: "a\nb",
+		},
+		{
+			name:     "引用和代码块",
+			src:      "> quote\ncontinue\n\n```go\nfmt.Println(\"<x>\")\n```",
+			wantHTML: "<blockquote>\n<p>quote\ncontinue</p>\n</blockquote>\n<pre><code class=\"language-go\">fmt.Println(&#34;&lt;x&gt;&#34;)\n</code></pre>",
+			wantText: "quote continue\nfmt.Println(\"<x>\")",
+		},
+		{
+			name:     "行内代码",
+			src:      "用 `a < b` 和 `` ` ``",
+			wantHTML: "<p>用 <code>a &lt; b</code> 和 <code>`</code></p>",
+			wantText: "用 a < b 和 `",
+		},
+		{
+			name:     "表格",
+			src:      "| a | b |\n|:--|--:|\n| 1 | 2 \\| 3 |",
+			wantHTML: "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"left\">1</td>\n<td align=\"right\">2 | 3</td>\n</tr>\n</tbody>\n</table>",
+			wantText: "a b\n1 2 | 3",
+		},
+		{
+			name:     "强调和删除线",
+			src:      "***both*** ~~del~~ snake_case_name **a *b* c**",
+			wantHTML: "<p><em><strong>both</strong></em> <del>del</del> snake_case_name <strong>a <em>b</em> c</strong></p>",
+			wantText: "both del snake_case_name a b c",
+		},
+		{
+			name:     "链接和图片",
+			src:      "[ok](https://a.com/x?a=1&b=2 \"t\") ![图](/img/a.png) <https://z.com>",
+			wantHTML: "<p><a href=\"https://a.com/x?a=1&amp;b=2\" title=\"t\" rel=\"nofollow noopener\">ok</a> <img src=\"/img/a.png\" alt=\"图\" /> <a href=\"https://z.com\" rel=\"nofollow noopener\">https://z.com</a></p>",
+			wantText: "ok 图 https://z.com",
+		},
+		{
+			name:     "实体和转义",
+			src:      "&copy; &foo; \\*literal\\*",
+			wantHTML: "<p>© &amp;foo; *literal*</p>",
+			wantText: "© &foo; *literal*",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Render(tc.src)
+			assert.Equal(t, tc.wantHTML, res.HTML)
+			assert.Equal(t, tc.wantText, res.Text)
+		})
+	}
+}
+
+func TestRender_XSS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		wantHTML string
+	}{
+		{
+			name:     "HTML 标签",
+			src:      "<script>alert(1)</script>",
+			wantHTML: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		},
+		{
+			name:     "javascript 链接",
+			src:      "[点我](javascript:alert(1)) [大写](JavaScript:alert(1))",
+			wantHTML: "<p>点我 大写</p>",
+		},
+		{
+			name:     "data 图片",
+			src:      "![x](data:image/svg+xml;base64,PHN2Zz4=)",
+			wantHTML: "<p>x</p>",
+		},
+		{
+			name:     "属性注入",
+			src:      "[x](https://a.com/\"onmouseover=\"alert(1))",
+			wantHTML: "<p><a href=\"https://a.com/&#34;onmouseover=&#34;alert(1)\" rel=\"nofollow noopener\">x</a></p>",
+		},
+		{
+			name:     "代码块的语言",
+			src:      "```\"><script>\nx\n```",
+			wantHTML: "<pre><code class=\"language-script\">x\n</code></pre>",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantHTML, Render(tc.src).HTML)
+		})
+	}
+}
+
+func TestRender_TOC(t *testing.T) {
+	res := Render("# Go 入门\n\n## 安装\n\n## 安装\n\n### `go mod` 的用法 ##\n\n    # 代码里面的不算")
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Go 入门", Anchor: "go-入门"},
+		{Level: 2, Text: "安装", Anchor: "安装"},
+		{Level: 2, Text: "安装", Anchor: "安装-1"},
+		{Level: 3, Text: "go mod 的用法", Anchor: "go-mod-的用法"},
+	}, res.TOC)
+}
+
+// TestRender_Pathological 保存文章的时候会同步渲染，这些输入以前都是平方级别的
+func TestRender_Pathological(t *testing.T) {
+	const size = 200 << 10
+	testCases := []struct {
+		name string
+		src  string
+	}{
+		{name: "没有匹配的 [", src: strings.Repeat("[", size)},
+		{name: "没有匹配的图片", src: strings.Repeat("![", size/2)},
+		{name: "没有结束的链接地址", src: strings.Repeat("[a](", size/4)},
+		{name: "嵌套的链接", src: strings.Repeat("[", size/8) + strings.Repeat("](x)", size/8)},
+		{name: "没有匹配的 <", src: strings.Repeat("<", size)},
+		{name: "没有匹配的 _", src: strings.Repeat("a_ ", size/3)},
+		{name: "长度不同的反引号", src: strings.Repeat("`a``b", size/5)},
+		{name: "嵌套的引用", src: strings.Repeat("> ", size/2) + "a"},
+		{name: "嵌套的列表", src: strings.Repeat("- ", size/2) + "a"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Now()
+			Render(tc.src)
+			// 线性的实现只要几十毫秒，平方级别的要几十秒
+			assert.Less(t, time.Since(start), 2*time.Second)
+		})
+	}
+}
+
+func TestRender_Nesting(t *testing.T) {
+	// 链接文字里面的图片照样渲染
+	assert.Equal(t, `<p><a href="/a" rel="nofollow noopener"><img src="/b.png" alt="b" /></a></p>`,
+		Render("[![b](/b.png)](/a)").HTML)
+	// 超过最大嵌套层数的部分当成普通文本
+	res := Render(strings.Repeat("> ", maxNesting+2) + "a")
+	assert.Equal(t, maxNesting, strings.Count(res.HTML, "<blockquote>"))
+	assert.Contains(t, res.HTML, "<p>&gt; &gt; a</p>")
+}
+
+func TestWordCount(t *testing.T) {
+	assert.Equal(t, 0, WordCount(""))
+	assert.Equal(t, 4, WordCount("你好世界"))
+	assert.Equal(t, 6, WordCount("Hello, world! 你好 go1.23"))
+}
+
+func TestAbstract(t *testing.T) {
+	assert.Equal(t, "a b c", Abstract("a\n\nb   c", 10))
+	assert.Equal(t, "一二", Abstract("一二三", 2))
+}
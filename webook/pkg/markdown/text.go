@@ -0,0 +1,59 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// slug 标题的锚点，保留字母、数字和汉字，空白换成 -
+func slug(text string) string {
+	var sb strings.Builder
+	lastDash := false
+	for _, c := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_':
+			sb.WriteRune(c)
+			lastDash = false
+		case unicode.IsSpace(c) || c == '-':
+			if !lastDash && sb.Len() > 0 {
+				sb.WriteByte('-')
+				lastDash = true
+			}
+		}
+	}
+	res := strings.TrimRight(sb.String(), "-")
+	if res == "" {
+		return "section"
+	}
+	return res
+}
+
+// WordCount 字数，每个汉字算一个字，连续的字母和数字算一个单词
+func WordCount(text string) int {
+	cnt := 0
+	inWord := false
+	for _, c := range text {
+		switch {
+		case unicode.Is(unicode.Han, c):
+			cnt++
+			inWord = false
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if !inWord {
+				cnt++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return cnt
+}
+
+// Abstract 把纯文本的空白合并成一个空格，最多保留 n 个字符
+func Abstract(text string, n int) string {
+	cs := []rune(strings.Join(strings.Fields(text), " "))
+	if len(cs) <= n {
+		return string(cs)
+	}
+	return string(cs[:n])
+}
@@ -0,0 +1,129 @@
+package redislock
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+var (
+	//go:embed lua/lock.lua
+	luaLock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+	//go:embed lua/unlock.lua
+	luaUnlock string
+)
+
+var (
+	// ErrFailedToPreemptLock 锁被别人持有
+	ErrFailedToPreemptLock = errors.New("抢锁失败")
+	// ErrLockNotHold 锁已经过期了，或者被别人抢走了
+	ErrLockNotHold = errors.New("未持有锁")
+)
+
+// Client 基于 Redis 的分布式锁
+type Client struct {
+	client redis.Cmdable
+	valuer func() string
+}
+
+func NewClient(client redis.Cmdable) *Client {
+	return &Client{
+		client: client,
+		valuer: func() string {
+			return uuid.New().String()
+		},
+	}
+}
+
+// TryLock 只尝试一次，锁被别人持有的时候返回 ErrFailedToPreemptLock
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	val := c.valuer()
+	res, err := c.client.Eval(ctx, luaLock, []string{key}, val, expiration.Milliseconds()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if res != "OK" {
+		return nil, ErrFailedToPreemptLock
+	}
+	return newLock(c.client, key, val, expiration), nil
+}
+
+type Lock struct {
+	client     redis.Cmdable
+	key        string
+	value      string
+	expiration time.Duration
+
+	unlockOnce sync.Once
+	unlock     chan struct{}
+}
+
+func newLock(client redis.Cmdable, key, value string, expiration time.Duration) *Lock {
+	return &Lock{
+		client:     client,
+		key:        key,
+		value:      value,
+		expiration: expiration,
+		unlock:     make(chan struct{}),
+	}
+}
+
+// Refresh 续约一次
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.client.Eval(ctx, luaRefresh, []string{l.key}, l.value, l.expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// AutoRefresh 每隔 interval 续约一次，每次续约的超时时间是 timeout
+// 会阻塞直到调用了 Unlock，或者续约失败
+// 超时的时候会立刻重试，别的错误直接返回，这时候锁可能已经不在自己手里了
+func (l *Lock) AutoRefresh(interval, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	retry := make(chan struct{}, 1)
+	for {
+		select {
+		case <-ticker.C:
+		case <-retry:
+		case <-l.unlock:
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := l.Refresh(ctx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			retry <- struct{}{}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Unlock 释放锁，同时会让 AutoRefresh 退出
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.unlockOnce.Do(func() {
+		close(l.unlock)
+	})
+	res, err := l.client.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package redislock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"webook/internal/repository/cache/redismocks"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		key        string
+		expiration time.Duration
+
+		wantErr  error
+		wantLock *Lock
+	}{
+		{
+			name: "加锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal("OK")
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job:lock:ranking"},
+					"value", int64(60000)).Return(res)
+				return cmd
+			},
+			key:        "job:lock:ranking",
+			expiration: time.Minute,
+			wantLock: &Lock{
+				key:        "job:lock:ranking",
+				value:      "value",
+				expiration: time.Minute,
+			},
+		},
+		{
+			name: "锁被别人持有",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal("")
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job:lock:ranking"},
+					"value", int64(60000)).Return(res)
+				return cmd
+			},
+			key:        "job:lock:ranking",
+			expiration: time.Minute,
+			wantErr:    ErrFailedToPreemptLock,
+		},
+		{
+			name: "Redis 错误",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetErr(errors.New("mock redis error"))
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"job:lock:ranking"},
+					"value", int64(60000)).Return(res)
+				return cmd
+			},
+			key:        "job:lock:ranking",
+			expiration: time.Minute,
+			wantErr:    errors.New("mock redis error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			c := NewClient(tc.mock(ctrl))
+			c.valuer = func() string {
+				return "value"
+			}
+			l, err := c.TryLock(context.Background(), tc.key, tc.expiration)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantLock.key, l.key)
+			assert.Equal(t, tc.wantLock.value, l.value)
+			assert.Equal(t, tc.wantLock.expiration, l.expiration)
+		})
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "解锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"key"}, "value").Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "锁已经不是自己的",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(0))
+				cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"key"}, "value").Return(res)
+				return cmd
+			},
+			wantErr: ErrLockNotHold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			l := newLock(tc.mock(ctrl), "key", "value", time.Minute)
+			err := l.Unlock(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			// 解锁之后 AutoRefresh 要立刻退出
+			assert.NoError(t, l.AutoRefresh(time.Hour, time.Second))
+		})
+	}
+}
+
+func TestLock_Refresh(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "续约成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"key"},
+					"value", int64(60000)).Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "锁已经过期",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(0))
+				cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"key"},
+					"value", int64(60000)).Return(res)
+				return cmd
+			},
+			wantErr: ErrLockNotHold,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			l := newLock(tc.mock(ctrl), "key", "value", time.Minute)
+			err := l.Refresh(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
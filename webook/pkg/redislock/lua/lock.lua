@@ -0,0 +1,13 @@
+-- 加锁，value 相同说明是自己之前加的锁（例如上一次加锁超时了，但是实际上成功了）
+local val = redis.call('GET', KEYS[1])
+if val == false then
+    -- key 不存在
+    return redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+elseif val == ARGV[1] then
+    -- 刷新过期时间
+    redis.call('PEXPIRE', KEYS[1], ARGV[2])
+    return 'OK'
+else
+    -- 锁被别人持有
+    return ''
+end
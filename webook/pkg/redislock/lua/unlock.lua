@@ -0,0 +1,6 @@
+-- 释放锁，检查是不是自己的锁，避免释放了别人的锁
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+else
+    return 0
+end
@@ -13,6 +13,7 @@ type BatchHandler[T any] struct {
 	fn func(msgs []*sarama.ConsumerMessage, t []T) error
 }
 
+// NewBatchHandler fn 收到的 msgs 和 t 是一一对应的，反序列化失败的消息不会传给 fn
 func NewBatchHandler[T any](l logger.LoggerV1, fn func(msgs []*sarama.ConsumerMessage, t []T) error) *BatchHandler[T] {
 	return &BatchHandler[T]{
 		l:  l,
@@ -49,7 +50,6 @@ func (h *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 					cancel()
 					return nil
 				}
-				msgs = append(msgs, msg)
 				var t T
 				err := json.Unmarshal(msg.Value, &t)
 				if err != nil {
@@ -65,6 +65,7 @@ func (h *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 					session.MarkMessage(msg, "")
 					continue
 				}
+				msgs = append(msgs, msg)
 				ts = append(ts, t)
 			}
 		}
@@ -3,9 +3,14 @@ package wire
 import (
 	"github.com/gin-gonic/gin"
 	"webook/internal/events"
+	"webook/internal/job"
+	"webook/internal/service/sms/async"
 )
 
 type App struct {
 	Web       *gin.Engine
 	Consumers []events.Consumer
+	Scheduler *job.Scheduler
+	// AsyncSms 异步发送短信的 goroutine 跟着应用启动和退出
+	AsyncSms *async.Service
 }
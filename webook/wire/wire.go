@@ -5,6 +5,8 @@ package wire
 import (
 	"github.com/google/wire"
 	article3 "webook/internal/events/article"
+	"webook/internal/events/notification"
+	"webook/internal/events/search"
 	"webook/internal/repository"
 	article2 "webook/internal/repository/article"
 	"webook/internal/repository/cache"
@@ -14,6 +16,7 @@ import (
 	"webook/internal/web"
 	ijwt "webook/internal/web/jwt"
 	"webook/ioc"
+	"webook/pkg/redislock"
 )
 
 func InitApp() *App {
@@ -28,33 +31,83 @@ func InitApp() *App {
 		ioc.NewSyncProducer,
 
 		// events 部分
-		article3.NewKafkaConsumer,
-		article3.NewKafkaProducer,
+		article3.NewInteractiveReadEventConsumer,
+		article3.NewHistoryConsumer,
+		article3.NewSaramaSyncProducer,
+		notification.NewConsumer,
+		notification.NewAnalyticsConsumer,
+		notification.NewSaramaSyncProducer,
+		search.NewArticleConsumer,
+		search.NewUserConsumer,
+		search.NewSaramaSyncProducer,
 
 		// DAO 部分
 		dao.NewUserDAO,
 		dao.NewGORMInteractiveDAO,
+		dao.NewGORMAsyncSmsDAO,
+		dao.NewGORMHistoryDAO,
+		dao.NewGORMFollowDAO,
+		dao.NewGORMCommentDAO,
+		dao.NewGORMNotificationDAO,
 		article.NewGORMArticleDAO,
+		article.NewGORMSeriesDAO,
+		ioc.InitSearchDAO,
+		dao.NewGORMImageDAO,
+		dao.NewGORMArticleTransferDAO,
+		dao.NewGORMArticleStatsDAO,
+		dao.NewGORMAnalyticsDAO,
+		ioc.InitBlobStore,
 
 		// Cache 部分
 		cache.NewRedisUserCache,
 		cache.NewRedisCodeCache,
 		cache.NewRedisArticleCache,
 		cache.NewRedisInteractiveCache,
+		cache.NewRankingRedisCache,
+		cache.NewRankingLocalCache,
+		cache.NewRedisFollowCache,
+		cache.NewRedisNotificationCache,
+		cache.NewRedisArticleStatsCache,
 
 		// repository 部分
 		repository.NewUserRepository,
 		repository.NewCodeRepository,
+		repository.NewAsyncSMSRepository,
+		repository.NewHistoryRecordRepository,
+		repository.NewCachedFollowRepository,
+		repository.NewCachedCommentRepository,
+		repository.NewCachedNotificationRepository,
 		article2.NewArticleRepository,
+		article2.NewSeriesRepository,
 		repository.NewCachedInteractiveRepository,
+		repository.NewCachedRankingRepository,
+		repository.NewSearchRepository,
+		repository.NewImageRepository,
+		repository.NewArticleTransferRepository,
+		repository.NewCachedArticleStatsRepository,
+		ioc.NewArticleStatsConfig,
+		repository.NewAnalyticsRepository,
 
 		// service 部分
+		ioc.InitAsyncSmsService,
 		ioc.InitSmsService,
 		ioc.InitWechatService,
 		service.NewUserService,
 		service.NewSMSCodeService,
 		service.NewArticleService,
 		service.NewInteractiveService,
+		service.NewBatchRankingService,
+		service.NewHistoryService,
+		service.NewFollowService,
+		service.NewCommentService,
+		service.NewNotificationService,
+		service.NewSearchService,
+		service.NewImageService,
+		service.NewSeriesService,
+		service.NewFeedService,
+		service.NewArticleTransferService,
+		service.NewArticleStatsService,
+		service.NewAnalyticsService,
 
 		// handler 部分
 		web.NewUserHandler,
@@ -62,6 +115,29 @@ func InitApp() *App {
 		ioc.NewWechatHandlerConfig,
 		ijwt.NewRedisJWTHandler,
 		web.NewArticleHandler,
+		web.NewRankingHandler,
+		web.NewCollectionHandler,
+		web.NewHistoryHandler,
+		web.NewFollowHandler,
+		web.NewCommentHandler,
+		web.NewNotificationHandler,
+		web.NewSearchHandler,
+		web.NewImageHandler,
+		web.NewSeriesHandler,
+		web.NewFeedHandler,
+		ioc.NewFeedHandlerConfig,
+		web.NewTransferHandler,
+		web.NewArticleStatsHandler,
+		web.NewAnalyticsHandler,
+
+		// 定时任务部分
+		redislock.NewClient,
+		ioc.InitRankingJob,
+		ioc.InitScheduledPublishJob,
+		ioc.InitRecycleBinPurgeJob,
+		ioc.InitArticleStatsFlushJob,
+		ioc.InitTransferCleanupJob,
+		ioc.InitScheduler,
 
 		// gin 的中间件
 		ioc.InitMiddlewares,
@@ -8,6 +8,8 @@ package wire
 
 import (
 	article3 "webook/internal/events/article"
+	"webook/internal/events/notification"
+	"webook/internal/events/search"
 	"webook/internal/repository"
 	article2 "webook/internal/repository/article"
 	"webook/internal/repository/cache"
@@ -17,6 +19,7 @@ import (
 	"webook/internal/web"
 	"webook/internal/web/jwt"
 	"webook/ioc"
+	"webook/pkg/redislock"
 )
 
 // Injectors from wire.go:
@@ -31,33 +34,106 @@ func InitApp() *App {
 	userDAO := dao.NewUserDAO(db)
 	userCache := cache.NewRedisUserCache(cmdable)
 	userRepository := repository.NewUserRepository(userDAO, userCache)
-	userService := service.NewUserService(userRepository, loggerV1)
-	smsService := ioc.InitSmsService()
+	client := ioc.InitKafka()
+	syncProducer := ioc.NewSyncProducer(client)
+	searchProducer := search.NewSaramaSyncProducer(syncProducer)
+	userService := service.NewUserService(userRepository, loggerV1, searchProducer)
+	asyncSmsDAO := dao.NewGORMAsyncSmsDAO(db)
+	asyncSmsRepository := repository.NewAsyncSMSRepository(asyncSmsDAO)
+	asyncService := ioc.InitAsyncSmsService(config, asyncSmsRepository, loggerV1)
+	smsService := ioc.InitSmsService(asyncService)
 	codeCache := cache.NewRedisCodeCache(cmdable)
 	codeRepository := repository.NewCodeRepository(codeCache)
 	codeService := service.NewSMSCodeService(smsService, codeRepository)
-	userHandler := web.NewUserHandler(userService, codeService, handler, loggerV1)
+	followDAO := dao.NewGORMFollowDAO(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCachedFollowRepository(followDAO, followCache, loggerV1)
+	notificationProducer := notification.NewSaramaSyncProducer(syncProducer)
+	followService := service.NewFollowService(followRepository, userRepository, notificationProducer, loggerV1)
+	userHandler := web.NewUserHandler(userService, codeService, followService, handler, loggerV1)
 	wechatService := ioc.InitWechatService()
 	wechatHandlerConfig := ioc.NewWechatHandlerConfig()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, wechatHandlerConfig, handler)
 	articleDAO := article.NewGORMArticleDAO(db)
 	articleCache := cache.NewRedisArticleCache(cmdable)
 	articleRepository := article2.NewArticleRepository(articleDAO, articleCache, userRepository, loggerV1)
-	client := ioc.InitKafka()
-	syncProducer := ioc.NewSyncProducer(client)
-	producer := article3.NewKafkaProducer(syncProducer)
-	articleService := service.NewArticleService(articleRepository, loggerV1, producer)
+	producer := article3.NewSaramaSyncProducer(syncProducer)
+	rankingRedisCache := cache.NewRankingRedisCache(cmdable)
+	rankingLocalCache := cache.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(rankingRedisCache, rankingLocalCache, loggerV1)
+	articleService := service.NewArticleService(articleRepository, rankingRepository, loggerV1, producer, searchProducer)
 	interactiveDAO := dao.NewGORMInteractiveDAO(db)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, loggerV1)
-	interactiveService := service.NewInteractiveService(interactiveRepository, loggerV1)
-	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveService)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, loggerV1)
-	consumer := article3.NewKafkaConsumer(client, interactiveRepository, loggerV1)
-	v2 := ioc.NewConsumers(consumer)
+	interactiveService := service.NewInteractiveService(interactiveRepository, notificationProducer, loggerV1)
+	seriesDAO := article.NewGORMSeriesDAO(db)
+	seriesRepository := article2.NewSeriesRepository(seriesDAO)
+	seriesService := service.NewSeriesService(seriesRepository, articleService, loggerV1)
+	articleHandler := web.NewArticleHandler(articleService, loggerV1, interactiveService, followService, seriesService)
+	rankingService := service.NewBatchRankingService(articleService, interactiveService, userService, rankingRepository, loggerV1)
+	rankingHandler := web.NewRankingHandler(rankingService)
+	collectionHandler := web.NewCollectionHandler(articleService, interactiveService)
+	historyDAO := dao.NewGORMHistoryDAO(db)
+	historyRecordRepository := repository.NewHistoryRecordRepository(historyDAO, loggerV1)
+	historyService := service.NewHistoryService(historyRecordRepository)
+	historyHandler := web.NewHistoryHandler(historyService, articleService, loggerV1)
+	followHandler := web.NewFollowHandler(followService)
+	commentDAO := dao.NewGORMCommentDAO(db)
+	commentRepository := repository.NewCachedCommentRepository(commentDAO, interactiveCache, loggerV1)
+	commentService := service.NewCommentService(commentRepository, articleService, notificationProducer, loggerV1)
+	commentHandler := web.NewCommentHandler(commentService)
+	notificationDAO := dao.NewGORMNotificationDAO(db)
+	notificationCache := cache.NewRedisNotificationCache(cmdable)
+	notificationRepository := repository.NewCachedNotificationRepository(notificationDAO, notificationCache, loggerV1)
+	notificationService := service.NewNotificationService(notificationRepository, loggerV1)
+	notificationHandler := web.NewNotificationHandler(notificationService, userService, loggerV1)
+	searchDAO := ioc.InitSearchDAO(config)
+	searchRepository := repository.NewSearchRepository(searchDAO)
+	searchService := service.NewSearchService(searchRepository)
+	searchHandler := web.NewSearchHandler(searchService)
+	imageDAO := dao.NewGORMImageDAO(db)
+	store := ioc.InitBlobStore(config)
+	imageRepository := repository.NewImageRepository(imageDAO, store)
+	imageService := service.NewImageService(imageRepository)
+	imageHandler := web.NewImageHandler(imageService, loggerV1)
+	seriesHandler := web.NewSeriesHandler(seriesService)
+	feedService := service.NewFeedService(articleRepository, userRepository)
+	feedHandlerConfig := ioc.NewFeedHandlerConfig(config)
+	feedHandler := web.NewFeedHandler(feedService, loggerV1, feedHandlerConfig)
+	articleTransferDAO := dao.NewGORMArticleTransferDAO(db)
+	articleTransferRepository := repository.NewArticleTransferRepository(articleTransferDAO, store)
+	articleTransferService := service.NewArticleTransferService(articleTransferRepository, articleService, imageService, loggerV1)
+	transferHandler := web.NewTransferHandler(articleTransferService, loggerV1)
+	articleStatsCache := cache.NewRedisArticleStatsCache(cmdable)
+	articleStatsDAO := dao.NewGORMArticleStatsDAO(db)
+	articleStatsConfig := ioc.NewArticleStatsConfig(config)
+	articleStatsRepository := repository.NewCachedArticleStatsRepository(articleStatsCache, articleStatsDAO, articleStatsConfig)
+	articleStatsService := service.NewArticleStatsService(articleStatsRepository, articleRepository)
+	articleStatsHandler := web.NewArticleStatsHandler(articleStatsService)
+	analyticsDAO := dao.NewGORMAnalyticsDAO(db)
+	analyticsRepository := repository.NewAnalyticsRepository(analyticsDAO, articleStatsDAO)
+	analyticsService := service.NewAnalyticsService(analyticsRepository, articleRepository)
+	analyticsHandler := web.NewAnalyticsHandler(analyticsService)
+	engine := ioc.InitWebServer(config, v, userHandler, oAuth2WechatHandler, articleHandler, rankingHandler, collectionHandler, historyHandler, followHandler, commentHandler, notificationHandler, searchHandler, imageHandler, seriesHandler, feedHandler, transferHandler, articleStatsHandler, analyticsHandler, loggerV1)
+	interactiveReadEventConsumer := article3.NewInteractiveReadEventConsumer(client, loggerV1, interactiveRepository, articleStatsRepository)
+	historyConsumer := article3.NewHistoryConsumer(client, loggerV1, historyRecordRepository)
+	consumer := notification.NewConsumer(client, loggerV1, notificationRepository, articleRepository)
+	analyticsConsumer := notification.NewAnalyticsConsumer(client, loggerV1, analyticsRepository, articleRepository)
+	articleConsumer := search.NewArticleConsumer(client, loggerV1, searchRepository, userRepository, articleRepository)
+	userConsumer := search.NewUserConsumer(client, loggerV1, searchRepository, userRepository)
+	v2 := ioc.NewConsumers(interactiveReadEventConsumer, historyConsumer, consumer, articleConsumer, userConsumer, analyticsConsumer)
+	rankingJob := ioc.InitRankingJob(rankingService)
+	redislockClient := redislock.NewClient(cmdable)
+	scheduledPublishJob := ioc.InitScheduledPublishJob(articleService)
+	recycleBinPurgeJob := ioc.InitRecycleBinPurgeJob(articleService)
+	articleStatsFlushJob := ioc.InitArticleStatsFlushJob(articleStatsService)
+	transferCleanupJob := ioc.InitTransferCleanupJob(articleTransferService)
+	scheduler := ioc.InitScheduler(loggerV1, redislockClient, rankingJob, scheduledPublishJob, recycleBinPurgeJob, articleStatsFlushJob, transferCleanupJob)
 	app := &App{
 		Web:       engine,
 		Consumers: v2,
+		Scheduler: scheduler,
+		AsyncSms:  asyncService,
 	}
 	return app
 }